@@ -0,0 +1,226 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// adminAPIHandlers implements and provides http handlers for the
+// minio admin API.
+type adminAPIHandlers struct {
+	ObjectAPI ObjectLayer
+	// Heals started by the admin API, running in background.
+	healTasks *healTasks
+}
+
+// healObjects - object layers which can heal, implemented by
+// xlObjects.
+type healObjects interface {
+	heal(bucket, prefix, object string, dryRun bool) (healResult, error)
+}
+
+// healResult - lists what was healed, or what would be healed on dry
+// run.
+type healResult struct {
+	DryRun bool `json:"dryRun"`
+	// Indexes of the disks whose `format.json` was missing.
+	FormatDisks []int `json:"formatDisks,omitempty"`
+	// Buckets missing on some of the disks.
+	Buckets []healBucketResult `json:"buckets,omitempty"`
+	// Objects outdated on some of the disks, or which failed to heal.
+	Objects []healObjectResult `json:"objects,omitempty"`
+	// Number of objects scanned.
+	Scanned int64 `json:"scanned"`
+	// Objects beyond maxHealObjectResults are not listed.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Maximum number of objects listed in a heal result, healing the
+// whole deployment may touch any number of objects.
+const maxHealObjectResults = 1000
+
+// Finished heal tasks are kept for their status to be read for
+// healTaskExpiry.
+const healTaskExpiry = time.Hour
+
+// healTask - status of a heal running in background.
+type healTask struct {
+	ID     string     `json:"id"`
+	Done   bool       `json:"done"`
+	Error  string     `json:"error,omitempty"`
+	Result healResult `json:"result"`
+	// Time the heal finished at.
+	finished time.Time
+}
+
+// healTasks - heals started by the admin API, by task id.
+type healTasks struct {
+	mutex sync.Mutex
+	tasks map[string]*healTask
+}
+
+// newHealTasks - initializes the list of heal tasks.
+func newHealTasks() *healTasks {
+	return &healTasks{tasks: make(map[string]*healTask)}
+}
+
+// start - runs heal in background, returns the initial status of the
+// task. Expired tasks are removed.
+func (t *healTasks) start(heal func() (healResult, error)) healTask {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for id, task := range t.tasks {
+		if task.Done && time.Since(task.finished) > healTaskExpiry {
+			delete(t.tasks, id)
+		}
+	}
+	task := &healTask{ID: getUUID()}
+	t.tasks[task.ID] = task
+	go func() {
+		result, err := heal()
+		if err != nil {
+			errorIf(err, "Unable to heal.")
+		}
+
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+		task.Done = true
+		task.Result = result
+		if err != nil {
+			task.Error = err.Error()
+		}
+		task.finished = time.Now()
+	}()
+	return *task
+}
+
+// status - returns the status of the task, false if no such task.
+func (t *healTasks) status(id string) (healTask, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	task, ok := t.tasks[id]
+	if !ok {
+		return healTask{}, false
+	}
+	return *task, true
+}
+
+// writeHealTask - writes the status of a heal task as JSON.
+func writeHealTask(w http.ResponseWriter, r *http.Request, task healTask) {
+	taskBytes, err := json.Marshal(task)
+	if err != nil {
+		errorIf(err, "Unable to marshal heal status.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	writeSuccessResponse(w, taskBytes)
+}
+
+// healBucketResult - a healed bucket.
+type healBucketResult struct {
+	Bucket string `json:"bucket"`
+	Disks  int    `json:"disks"`
+}
+
+// healObjectResult - a healed object.
+type healObjectResult struct {
+	Bucket string `json:"bucket"`
+	Object string `json:"object"`
+	Disks  int    `json:"disks"`
+	Error  string `json:"error,omitempty"`
+}
+
+// HealHandler - POST /minio/admin/heal
+// -----------------
+// Heals the whole deployment, a bucket, all objects under a prefix or a
+// single object, selected by the `bucket`, `prefix` and `object` query
+// parameters. Healing the whole deployment repairs missing
+// `format.json` of replaced disks as well. With `dryRun` set nothing
+// is healed, the result lists what would be rebuilt. The heal runs in
+// background, the response carries the id of the heal task whose
+// status is read with HealStatusHandler.
+func (api adminAPIHandlers) HealHandler(w http.ResponseWriter, r *http.Request) {
+	if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	healer, ok := api.ObjectAPI.(healObjects)
+	if !ok {
+		writeErrorResponse(w, r, ErrNotImplemented, r.URL.Path)
+		return
+	}
+
+	query := r.URL.Query()
+	bucket, prefix, object := query.Get("bucket"), query.Get("prefix"), query.Get("object")
+	if bucket == "" && (prefix != "" || object != "") || prefix != "" && object != "" {
+		writeErrorResponse(w, r, ErrInvalidQueryParams, r.URL.Path)
+		return
+	}
+	if bucket != "" && !IsValidBucketName(bucket) {
+		writeErrorResponse(w, r, ErrInvalidBucketName, r.URL.Path)
+		return
+	}
+	dryRun := false
+	if dryRunStr := query.Get("dryRun"); dryRunStr != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(dryRunStr); err != nil {
+			writeErrorResponse(w, r, ErrInvalidQueryParams, r.URL.Path)
+			return
+		}
+	}
+
+	// Missing buckets are reported before the heal starts, objects
+	// may be missing on some disks and are looked up by the heal.
+	if bucket != "" {
+		if _, err := api.ObjectAPI.GetBucketInfo(bucket); err != nil {
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+			return
+		}
+	}
+
+	task := api.healTasks.start(func() (healResult, error) {
+		return healer.heal(bucket, prefix, object, dryRun)
+	})
+	writeHealTask(w, r, task)
+}
+
+// HealStatusHandler - GET /minio/admin/heal?id=
+// -----------------
+// Returns the status of a heal task started by HealHandler, along with
+// its result once done.
+func (api adminAPIHandlers) HealStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	task, ok := api.healTasks.status(r.URL.Query().Get("id"))
+	if !ok {
+		writeErrorResponse(w, r, ErrNoSuchHealTask, r.URL.Path)
+		return
+	}
+	writeHealTask(w, r, task)
+}
@@ -0,0 +1,226 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Tests the human readable heal result.
+func TestHealResultString(t *testing.T) {
+	result := healResult{
+		DryRun:      true,
+		FormatDisks: []int{2},
+		Buckets:     []healBucketResult{{Bucket: "bucket", Disks: 1}},
+		Objects: []healObjectResult{
+			{Bucket: "bucket", Object: "object", Disks: 2},
+			{Bucket: "bucket", Object: "broken", Error: "I/O error"},
+		},
+		Scanned:   3,
+		Truncated: true,
+	}
+	expected := "Would heal format.json on disk 2.\n" +
+		"Would heal bucket ‘bucket’ on 1 disk(s).\n" +
+		"Would heal ‘bucket/object’ on 2 disk(s).\n" +
+		"Unable to heal ‘bucket/broken’: I/O error\n" +
+		"Only the first 2 object(s) are listed.\n" +
+		"Scanned 3 object(s)."
+	if result.String() != expected {
+		t.Fatalf("Expected %q, got %q", expected, result.String())
+	}
+}
+
+// Tests the admin heal API.
+func TestAdminHealHandler(t *testing.T) {
+	testServer := StartTestServer(t, "FS")
+	cred := credential{AccessKeyID: testServer.AccessKey, SecretAccessKey: testServer.SecretKey}
+	req, err := newHealRequest(testServer.Server.URL, false, false, cred, "us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotImplemented {
+		t.Fatalf("FS: Expected status %d, got %d", http.StatusNotImplemented, resp.StatusCode)
+	}
+	testServer.Stop()
+
+	testServer = StartTestServer(t, "XL")
+	defer testServer.Stop()
+	cred = credential{AccessKeyID: testServer.AccessKey, SecretAccessKey: testServer.SecretKey}
+
+	expectStatus := func(req *http.Request, status int) []byte {
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		respBody, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != status {
+			t.Fatalf("%s %s: Expected status %d, got %d: %s", req.Method, req.URL, status, resp.StatusCode, respBody)
+		}
+		return respBody
+	}
+	// Heals run in background, their status is polled until done.
+	runHeal := func(target string, recursive, dryRun bool) healTask {
+		req, err := newHealRequest(testServer.Server.URL+target, recursive, dryRun, cred, "us-east-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var task healTask
+		for i := 0; ; i++ {
+			if err = json.Unmarshal(expectStatus(req, http.StatusOK), &task); err != nil {
+				t.Fatal(err)
+			}
+			if task.Done {
+				break
+			}
+			if i == 100 {
+				t.Fatalf("%s: Expected heal to be done", target)
+			}
+			time.Sleep(50 * time.Millisecond)
+			if req, err = newHealStatusRequest(testServer.Server.URL, task.ID, cred, "us-east-1"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return task
+	}
+	heal := func(target string, recursive, dryRun bool) healResult {
+		task := runHeal(target, recursive, dryRun)
+		if task.Error != "" {
+			t.Fatalf("%s: Expected heal to succeed, got %s", target, task.Error)
+		}
+		return task.Result
+	}
+	newRequest := func(method, urlStr string, body []byte) *http.Request {
+		req, err := newTestRequest(method, urlStr, int64(len(body)), bytes.NewReader(body), testServer.AccessKey, testServer.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+	expectHealed := func(disk, object string) {
+		if _, err := os.Stat(filepath.Join(disk, "heal-bucket", object, xlMetaJSONFile)); err != nil {
+			t.Fatalf("Expected %s to be healed, got %s", object, err)
+		}
+	}
+
+	bucketURL := testServer.Server.URL + "/heal-bucket"
+	expectStatus(newRequest("PUT", bucketURL, nil), http.StatusOK)
+	objects := []string{"dir/a", "dir/b", "other"}
+	for _, object := range objects {
+		expectStatus(newRequest("PUT", bucketURL+"/"+object, []byte("hello, heal")), http.StatusOK)
+	}
+	for _, object := range objects {
+		if err = os.RemoveAll(filepath.Join(testServer.Disks[0], "heal-bucket", object)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Dry run only lists what would be healed.
+	result := heal("/heal-bucket/dir/", true, true)
+	if !result.DryRun || result.Scanned != 2 || len(result.Objects) != 2 || result.Objects[0].Object != "dir/a" || result.Objects[0].Disks != 1 {
+		t.Fatalf("Expected objects under prefix to be listed, got %+v", result)
+	}
+	if _, err = os.Stat(filepath.Join(testServer.Disks[0], "heal-bucket", "dir/a")); !os.IsNotExist(err) {
+		t.Fatalf("Expected dry run not to heal, got %v", err)
+	}
+
+	// Single object.
+	result = heal("/heal-bucket/dir/a", false, false)
+	if result.DryRun || result.Scanned != 1 || len(result.Objects) != 1 || result.Objects[0].Disks != 1 {
+		t.Fatalf("Expected object to be healed, got %+v", result)
+	}
+	expectHealed(testServer.Disks[0], "dir/a")
+
+	// Bucket.
+	result = heal("/heal-bucket", false, false)
+	if result.Scanned != 3 || len(result.Objects) != 2 {
+		t.Fatalf("Expected bucket to be healed, got %+v", result)
+	}
+	for _, object := range objects {
+		expectHealed(testServer.Disks[0], object)
+	}
+
+	// Whole deployment after a disk swap.
+	if err = os.RemoveAll(testServer.Disks[1]); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.MkdirAll(testServer.Disks[1], 0755); err != nil {
+		t.Fatal(err)
+	}
+	result = heal("", false, true)
+	if len(result.FormatDisks) != 1 || len(result.Buckets) != 1 || len(result.Objects) != 3 {
+		t.Fatalf("Expected format, bucket and objects to be listed, got %+v", result)
+	}
+	result = heal("", false, false)
+	if len(result.FormatDisks) != 1 || len(result.Buckets) != 1 || len(result.Objects) != 3 {
+		t.Fatalf("Expected format, bucket and objects to be healed, got %+v", result)
+	}
+	for _, objectResult := range result.Objects {
+		if objectResult.Error != "" {
+			t.Fatalf("Expected %s to be healed, got %s", objectResult.Object, objectResult.Error)
+		}
+	}
+	if _, err = os.Stat(filepath.Join(testServer.Disks[1], minioMetaBucket, formatConfigFile)); err != nil {
+		t.Fatalf("Expected format.json to be healed, got %s", err)
+	}
+	for _, object := range objects {
+		expectHealed(testServer.Disks[1], object)
+	}
+	if result = heal("", false, false); len(result.FormatDisks) != 0 || len(result.Buckets) != 0 || len(result.Objects) != 0 {
+		t.Fatalf("Expected nothing to heal, got %+v", result)
+	}
+
+	// Errors.
+	if task := runHeal("/heal-bucket/missing", false, false); task.Error != (ObjectNotFound{Bucket: "heal-bucket", Object: "missing"}).Error() {
+		t.Fatalf("Expected missing object not to be healed, got %q", task.Error)
+	}
+	req, err = newHealRequest(testServer.Server.URL+"/missing-bucket", false, false, cred, "us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(req, http.StatusNotFound)
+	if req, err = newHealStatusRequest(testServer.Server.URL, "missing-task", cred, "us-east-1"); err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(req, http.StatusNotFound)
+	expectStatus(newRequest("POST", testServer.Server.URL+reservedBucket+adminHealPath+"?prefix=dir", nil), http.StatusBadRequest)
+	req, err = newHealRequest(testServer.Server.URL, false, false, credential{AccessKeyID: "minio", SecretAccessKey: "invalid-secret"}, "us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(req, http.StatusForbidden)
+	req, err = http.NewRequest("POST", testServer.Server.URL+reservedBucket+adminHealPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(req, http.StatusForbidden)
+}
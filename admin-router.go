@@ -0,0 +1,32 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import router "github.com/gorilla/mux"
+
+// Admin API path, relative to the reserved bucket.
+const adminHealPath = "/admin/heal"
+
+// registerAdminRouter - registers minio admin APIs.
+func registerAdminRouter(mux *router.Router, api adminAPIHandlers) {
+	adminRouter := mux.NewRoute().PathPrefix(reservedBucket).Subrouter()
+
+	// Heal
+	adminRouter.Methods("POST").Path(adminHealPath).HandlerFunc(api.HealHandler)
+	// Heal status
+	adminRouter.Methods("GET").Path(adminHealPath).Queries("id", "{id:.*}").HandlerFunc(api.HealStatusHandler)
+}
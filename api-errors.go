@@ -64,6 +64,11 @@ const (
 	ErrInvalidRequestBody
 	ErrInvalidCopySource
 	ErrInvalidCopyDest
+	ErrInvalidCopyPartRange
+	ErrInvalidMetadataDirective
+	ErrInvalidTaggingDirective
+	ErrInvalidTag
+	ErrInvalidStorageClass
 	ErrInvalidPolicyDocument
 	ErrMalformedXML
 	ErrMissingContentLength
@@ -73,6 +78,32 @@ const (
 	ErrNoSuchBucketPolicy
 	ErrNoSuchKey
 	ErrNoSuchUpload
+	ErrNoSuchVersion
+	ErrIllegalVersioningConfiguration
+	ErrNoSuchLifecycleConfiguration
+	ErrNoSuchCorsConfiguration
+	ErrCorsNotAllowed
+	ErrNoSuchWebsiteConfiguration
+	ErrEventNotification
+	ErrARNNotification
+	ErrFilterNameInvalid
+	ErrFilterNamePrefix
+	ErrFilterNameSuffix
+	ErrFilterValueInvalid
+	ErrInvalidEncryptionParameters
+	ErrInvalidSSECustomerAlgorithm
+	ErrInvalidSSECustomerKey
+	ErrMissingSSECustomerKey
+	ErrMissingSSECustomerKeyMD5
+	ErrSSECustomerKeyMD5Mismatch
+	ErrSSECustomerKeyMismatch
+	ErrSSEEncryptedObject
+	ErrInsecureSSECustomerRequest
+	ErrInvalidEncryptionMethod
+	ErrMasterKeyNotConfigured
+	ErrMalformedChunkedEncoding
+	ErrNoSuchEncryptionConfiguration
+	ErrNoSuchTagSet
 	ErrNotImplemented
 	ErrPreconditionFailed
 	ErrRequestTimeTooSkewed
@@ -111,6 +142,7 @@ const (
 	ErrStorageFull
 	ErrObjectExistsAsDirectory
 	ErrPolicyNesting
+	ErrNoSuchHealTask
 )
 
 // error code to APIError structure, these fields carry respective
@@ -118,7 +150,7 @@ const (
 var errorCodeResponse = map[APIErrorCode]APIError{
 	ErrInvalidCopyDest: {
 		Code:           "InvalidRequest",
-		Description:    "This copy request is illegal because it is trying to copy an object to itself.",
+		Description:    "This copy request is illegal because it is trying to copy an object to itself without changing the object's metadata.",
 		HTTPStatusCode: http.StatusBadRequest,
 	},
 	ErrInvalidCopySource: {
@@ -126,6 +158,31 @@ var errorCodeResponse = map[APIErrorCode]APIError{
 		Description:    "Copy Source must mention the source bucket and key: sourcebucket/sourcekey.",
 		HTTPStatusCode: http.StatusBadRequest,
 	},
+	ErrInvalidMetadataDirective: {
+		Code:           "InvalidArgument",
+		Description:    "Unknown metadata directive.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidTaggingDirective: {
+		Code:           "InvalidArgument",
+		Description:    "Unknown tagging directive.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidTag: {
+		Code:           "InvalidTag",
+		Description:    "The tag provided was not a valid tag.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidStorageClass: {
+		Code:           "InvalidStorageClass",
+		Description:    "The storage class you specified is not valid.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidCopyPartRange: {
+		Code:           "InvalidArgument",
+		Description:    "The x-amz-copy-source-range value must be of the form bytes=first-last where first and last are the zero-based offsets of the first and last bytes to copy within the source object.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
 	ErrInvalidRequestBody: {
 		Code:           "InvalidArgument",
 		Description:    "Body shouldn't be set for this request.",
@@ -251,6 +308,136 @@ var errorCodeResponse = map[APIErrorCode]APIError{
 		Description:    "The specified multipart upload does not exist.",
 		HTTPStatusCode: http.StatusNotFound,
 	},
+	ErrNoSuchVersion: {
+		Code:           "NoSuchVersion",
+		Description:    "The specified version does not exist.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+	ErrIllegalVersioningConfiguration: {
+		Code:           "IllegalVersioningConfigurationException",
+		Description:    "The versioning configuration specified in the request is invalid.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrNoSuchLifecycleConfiguration: {
+		Code:           "NoSuchLifecycleConfiguration",
+		Description:    "The lifecycle configuration does not exist.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+	ErrNoSuchCorsConfiguration: {
+		Code:           "NoSuchCORSConfiguration",
+		Description:    "The CORS configuration does not exist.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+	ErrCorsNotAllowed: {
+		Code:           "AccessForbidden",
+		Description:    "CORSResponse: This CORS request is not allowed. This is usually because the evaluation of Origin, request method / Access-Control-Request-Method or Access-Control-Request-Headers are not whitelisted by the resource's CORS spec.",
+		HTTPStatusCode: http.StatusForbidden,
+	},
+	ErrNoSuchWebsiteConfiguration: {
+		Code:           "NoSuchWebsiteConfiguration",
+		Description:    "The specified bucket does not have a website configuration.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+	ErrEventNotification: {
+		Code:           "InvalidArgument",
+		Description:    "A specified event is not supported for notifications.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrARNNotification: {
+		Code:           "InvalidArgument",
+		Description:    "A specified destination ARN does not exist or is not well-formed. Verify the destination ARN.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrFilterNameInvalid: {
+		Code:           "InvalidArgument",
+		Description:    "filter rule name must be either prefix or suffix",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrFilterNamePrefix: {
+		Code:           "InvalidArgument",
+		Description:    "Cannot specify more than one prefix rule in a filter.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrFilterNameSuffix: {
+		Code:           "InvalidArgument",
+		Description:    "Cannot specify more than one suffix rule in a filter.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrFilterValueInvalid: {
+		Code:           "InvalidArgument",
+		Description:    "Size of filter rule value cannot exceed 1024 bytes in UTF-8 representation",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidEncryptionParameters: {
+		Code:           "InvalidRequest",
+		Description:    "The encryption parameters are not applicable to this object.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidSSECustomerAlgorithm: {
+		Code:           "InvalidArgument",
+		Description:    "Requests specifying Server Side Encryption with Customer provided keys must provide a valid encryption algorithm.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidSSECustomerKey: {
+		Code:           "InvalidArgument",
+		Description:    "The secret key was invalid for the specified algorithm.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrMissingSSECustomerKey: {
+		Code:           "InvalidArgument",
+		Description:    "Requests specifying Server Side Encryption with Customer provided keys must provide an appropriate secret key.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrMissingSSECustomerKeyMD5: {
+		Code:           "InvalidArgument",
+		Description:    "Requests specifying Server Side Encryption with Customer provided keys must provide the client calculated MD5 of the secret key.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrSSECustomerKeyMD5Mismatch: {
+		Code:           "InvalidArgument",
+		Description:    "The calculated MD5 hash of the key did not match the hash that was provided.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrSSECustomerKeyMismatch: {
+		Code:           "AccessDenied",
+		Description:    "The provided secret key does not match the key the object was encrypted with.",
+		HTTPStatusCode: http.StatusForbidden,
+	},
+	ErrSSEEncryptedObject: {
+		Code:           "InvalidRequest",
+		Description:    "The object was stored using a form of Server Side Encryption. The correct parameters must be provided to retrieve the object.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInsecureSSECustomerRequest: {
+		Code:           "InvalidRequest",
+		Description:    "Requests specifying Server Side Encryption with Customer provided keys must be made over a secure connection.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidEncryptionMethod: {
+		Code:           "InvalidArgument",
+		Description:    "The encryption method specified is not supported.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrMasterKeyNotConfigured: {
+		Code:           "NotImplemented",
+		Description:    "Server side encryption specified but no master key is configured.",
+		HTTPStatusCode: http.StatusNotImplemented,
+	},
+	ErrMalformedChunkedEncoding: {
+		Code:           "InvalidRequest",
+		Description:    "The chunked payload of the request is malformed.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrNoSuchEncryptionConfiguration: {
+		Code:           "ServerSideEncryptionConfigurationNotFoundError",
+		Description:    "The server side encryption configuration was not found.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+	ErrNoSuchTagSet: {
+		Code:           "NoSuchTagSet",
+		Description:    "The TagSet does not exist.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
 	ErrNotImplemented: {
 		Code:           "NotImplemented",
 		Description:    "A header you provided implies functionality that is not implemented.",
@@ -427,6 +614,11 @@ var errorCodeResponse = map[APIErrorCode]APIError{
 		Description:    "Policy nesting conflict has occurred.",
 		HTTPStatusCode: http.StatusConflict,
 	},
+	ErrNoSuchHealTask: {
+		Code:           "XMinioNoSuchHealTask",
+		Description:    "The specified heal task does not exist, or has expired.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
 	// Add your error structure here.
 }
 
@@ -441,6 +633,10 @@ func toAPIErrorCode(err error) (apiErr APIErrorCode) {
 	if err == errSignatureMismatch {
 		return ErrSignatureDoesNotMatch
 	}
+	// Verify if the underlying error is malformed chunked payload.
+	if err == errMalformedEncoding {
+		return ErrMalformedChunkedEncoding
+	}
 	switch err.(type) {
 	case StorageFull:
 		apiErr = ErrStorageFull
@@ -462,6 +658,20 @@ func toAPIErrorCode(err error) (apiErr APIErrorCode) {
 		apiErr = ErrNoSuchKey
 	case ObjectNameInvalid:
 		apiErr = ErrNoSuchKey
+	case BucketLifecycleNotFound:
+		apiErr = ErrNoSuchLifecycleConfiguration
+	case BucketCorsNotFound:
+		apiErr = ErrNoSuchCorsConfiguration
+	case BucketWebsiteNotFound:
+		apiErr = ErrNoSuchWebsiteConfiguration
+	case BucketEncryptionNotFound:
+		apiErr = ErrNoSuchEncryptionConfiguration
+	case BucketTaggingNotFound:
+		apiErr = ErrNoSuchTagSet
+	case VersionNotFound:
+		apiErr = ErrNoSuchVersion
+	case PreconditionFailed:
+		apiErr = ErrPreconditionFailed
 	case InvalidUploadID:
 		apiErr = ErrNoSuchUpload
 	case InvalidPart:
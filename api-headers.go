@@ -71,6 +71,29 @@ func setObjectHeaders(w http.ResponseWriter, objInfo ObjectInfo, contentRange *h
 		w.Header().Set("ETag", "\""+objInfo.MD5Sum+"\"")
 	}
 
+	if objInfo.ContentEncoding != "" {
+		w.Header().Set("Content-Encoding", objInfo.ContentEncoding)
+	}
+
+	// Set all other user defined metadata.
+	for k, v := range objInfo.UserDefined {
+		if isUserMetadataKey(k) {
+			w.Header().Set(k, v)
+		}
+	}
+
+	// Set object version headers.
+	setObjectVersionHeaders(w, objInfo)
+
+	// Set object encryption headers.
+	setObjectEncryptionHeaders(w, objInfo.UserDefined)
+
+	// Set object tagging headers.
+	setObjectTaggingHeaders(w, objInfo.UserDefined)
+
+	// Set object storage class headers.
+	setStorageClassHeaders(w, objInfo.UserDefined)
+
 	w.Header().Set("Content-Length", strconv.FormatInt(objInfo.Size, 10))
 
 	// for providing ranged content
@@ -83,3 +106,26 @@ func setObjectHeaders(w http.ResponseWriter, objInfo ObjectInfo, contentRange *h
 		}
 	}
 }
+
+// Write object version headers
+func setObjectVersionHeaders(w http.ResponseWriter, objInfo ObjectInfo) {
+	if objInfo.VersionID != "" {
+		w.Header().Set("X-Amz-Version-Id", objInfo.VersionID)
+	}
+	if objInfo.DeleteMarker {
+		w.Header().Set("X-Amz-Delete-Marker", "true")
+	}
+}
+
+// Write object encryption headers
+func setObjectEncryptionHeaders(w http.ResponseWriter, metadata map[string]string) {
+	if !isEncrypted(metadata) {
+		return
+	}
+	if keyMD5 := metadata[sseMetaCustomerKeyMD5]; keyMD5 != "" {
+		w.Header().Set(sseCustomerHeaders.algorithm, sseAlgorithmAES256)
+		w.Header().Set(sseCustomerHeaders.keyMD5, keyMD5)
+		return
+	}
+	w.Header().Set(sseHeader, sseAlgorithmAES256)
+}
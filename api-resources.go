@@ -50,6 +50,33 @@ func getListObjectsV2Args(values url.Values) (prefix, token, startAfter, delimit
 	return
 }
 
+// Parse bucket url queries for ?versions
+func getListObjectVersionsArgs(values url.Values) (prefix, keyMarker, versionIDMarker, delimiter string, maxkeys int, encodingType string) {
+	prefix = values.Get("prefix")
+	keyMarker = values.Get("key-marker")
+	versionIDMarker = values.Get("version-id-marker")
+	delimiter = values.Get("delimiter")
+	if values.Get("max-keys") != "" {
+		maxkeys, _ = strconv.Atoi(values.Get("max-keys"))
+	} else {
+		maxkeys = maxObjectList
+	}
+	encodingType = values.Get("encoding-type")
+	return
+}
+
+// Parse bucket url queries for ?events
+func getListenBucketNotificationArgs(values url.Values) (events []string, filterRules []filterRule) {
+	events = values["events"]
+	if prefix := values.Get("prefix"); prefix != "" {
+		filterRules = append(filterRules, filterRule{Name: "prefix", Value: prefix})
+	}
+	if suffix := values.Get("suffix"); suffix != "" {
+		filterRules = append(filterRules, filterRule{Name: "suffix", Value: suffix})
+	}
+	return
+}
+
 // Parse bucket url queries
 func getBucketResources(values url.Values) (listType int, prefix, marker, delimiter string, maxkeys int, encodingType string) {
 	if values.Get("list-type") != "" {
@@ -66,6 +66,38 @@ type ListObjectsResponse struct {
 	Prefix     string
 }
 
+// ListVersionsResponse - format for list object versions response.
+type ListVersionsResponse struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListVersionsResult" json:"-"`
+
+	Name            string
+	Prefix          string
+	KeyMarker       string
+	VersionIDMarker string `xml:"VersionIdMarker"`
+
+	// When response is truncated (the IsTruncated element value in the response
+	// is true), you can use the key name and version id in these fields as
+	// key-marker and version-id-marker in the subsequent request.
+	NextKeyMarker       string `xml:",omitempty"`
+	NextVersionIDMarker string `xml:"NextVersionIdMarker,omitempty"`
+
+	MaxKeys   int
+	Delimiter string
+
+	// Encoding type used to encode object keys in the response.
+	EncodingType string `xml:",omitempty"`
+
+	// A flag that indicates whether or not ListObjectVersions returned all
+	// of the results that satisfied the search criteria.
+	IsTruncated bool
+
+	// Holds ObjectVersion and DeleteMarkerVersion entries in the order
+	// they were listed.
+	Versions []interface{}
+
+	CommonPrefixes []CommonPrefix
+}
+
 // ListObjectsV2Response - format for list objects response.
 type ListObjectsV2Response struct {
 	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBucketResult" json:"-"`
@@ -190,6 +222,33 @@ type Object struct {
 	StorageClass string
 }
 
+// ObjectVersion container for object version metadata
+type ObjectVersion struct {
+	XMLName      xml.Name `xml:"Version" json:"-"`
+	Key          string
+	VersionID    string `xml:"VersionId"`
+	IsLatest     bool
+	LastModified string // time string of format "2006-01-02T15:04:05.000Z"
+	ETag         string
+	Size         int64
+
+	Owner Owner
+
+	// The class of storage used to store the object.
+	StorageClass string
+}
+
+// DeleteMarkerVersion container for delete marker metadata
+type DeleteMarkerVersion struct {
+	XMLName      xml.Name `xml:"DeleteMarker" json:"-"`
+	Key          string
+	VersionID    string `xml:"VersionId"`
+	IsLatest     bool
+	LastModified string // time string of format "2006-01-02T15:04:05.000Z"
+
+	Owner Owner
+}
+
 // CopyObjectResponse container returns ETag and LastModified of the
 // successfully copied object
 type CopyObjectResponse struct {
@@ -198,6 +257,14 @@ type CopyObjectResponse struct {
 	LastModified string // time string of format "2006-01-02T15:04:05.000Z"
 }
 
+// CopyObjectPartResponse container returns ETag and LastModified of the
+// successfully copied object part
+type CopyObjectPartResponse struct {
+	XMLName      xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CopyPartResult" json:"-"`
+	ETag         string
+	LastModified string // time string of format "2006-01-02T15:04:05.000Z"
+}
+
 // Initiator inherit from Owner struct, fields are same
 type Initiator Owner
 
@@ -256,7 +323,7 @@ type DeleteObjectsResponse struct {
 
 // getLocation get URL location.
 func getLocation(r *http.Request) string {
-	return path.Clean(r.URL.Path) // Clean any trailing slashes.
+	return path.Clean(getPathStyleURL(r).Path) // Clean any trailing slashes.
 }
 
 // getObjectLocation gets the relative URL for an object
@@ -336,6 +403,66 @@ func generateListObjectsResponse(bucket, prefix, marker, delimiter string, maxKe
 	return data
 }
 
+// generates an ListObjectVersions response for the said bucket with other enumerated options.
+func generateListVersionsResponse(bucket, prefix, keyMarker, versionIDMarker, delimiter string, maxKeys int, resp ListObjectVersionsInfo) ListVersionsResponse {
+	var versions []interface{}
+	var prefixes []CommonPrefix
+	var owner = Owner{}
+	var data = ListVersionsResponse{}
+
+	owner.ID = "minio"
+	owner.DisplayName = "minio"
+
+	for _, object := range resp.Objects {
+		if object.Name == "" {
+			continue
+		}
+		lastModified := object.ModTime.UTC().Format(timeFormatAMZ)
+		if object.DeleteMarker {
+			versions = append(versions, DeleteMarkerVersion{
+				Key:          object.Name,
+				VersionID:    object.VersionID,
+				IsLatest:     object.IsLatest,
+				LastModified: lastModified,
+				Owner:        owner,
+			})
+			continue
+		}
+		var version = ObjectVersion{}
+		version.Key = object.Name
+		version.VersionID = object.VersionID
+		version.IsLatest = object.IsLatest
+		version.LastModified = lastModified
+		if object.MD5Sum != "" {
+			version.ETag = "\"" + object.MD5Sum + "\""
+		}
+		version.Size = object.Size
+		version.StorageClass = "STANDARD"
+		version.Owner = owner
+		versions = append(versions, version)
+	}
+	// TODO - support EncodingType in xml decoding
+	data.Name = bucket
+	data.Versions = versions
+
+	data.Prefix = prefix
+	data.KeyMarker = keyMarker
+	data.VersionIDMarker = versionIDMarker
+	data.Delimiter = delimiter
+	data.MaxKeys = maxKeys
+
+	data.NextKeyMarker = resp.NextKeyMarker
+	data.NextVersionIDMarker = resp.NextVersionIDMarker
+	data.IsTruncated = resp.IsTruncated
+	for _, prefix := range resp.Prefixes {
+		var prefixItem = CommonPrefix{}
+		prefixItem.Prefix = prefix
+		prefixes = append(prefixes, prefixItem)
+	}
+	data.CommonPrefixes = prefixes
+	return data
+}
+
 // generates an ListObjects response for the said bucket with other enumerated options.
 func generateListObjectsV2Response(bucket, prefix, token, startAfter, delimiter string, maxKeys int, resp ListObjectsInfo) ListObjectsV2Response {
 	var contents []Object
@@ -389,6 +516,14 @@ func generateCopyObjectResponse(etag string, lastModified time.Time) CopyObjectR
 	}
 }
 
+// generateCopyObjectPartResponse
+func generateCopyObjectPartResponse(etag string, lastModified time.Time) CopyObjectPartResponse {
+	return CopyObjectPartResponse{
+		ETag:         "\"" + etag + "\"",
+		LastModified: lastModified.UTC().Format(timeFormatAMZ),
+	}
+}
+
 // generateInitiateMultipartUploadResponse
 func generateInitiateMultipartUploadResponse(bucket, key, uploadID string) InitiateMultipartUploadResponse {
 	return InitiateMultipartUploadResponse{
@@ -28,56 +28,113 @@ func registerAPIRouter(mux *router.Router, api objectAPIHandlers) {
 	// API Router
 	apiRouter := mux.NewRoute().PathPrefix("/").Subrouter()
 
-	// Bucket router
-	bucket := apiRouter.PathPrefix("/{bucket}").Subrouter()
+	// Bucket routers, virtual host style requests to '<bucket>.<domain>'
+	// are routed before path style requests.
+	var routers []*router.Router
+	if globalServerDomain != "" {
+		routers = append(routers, apiRouter.Host("{bucket:.+}."+globalServerDomain).Subrouter())
+	}
+	routers = append(routers, apiRouter.PathPrefix("/{bucket}").Subrouter())
 
-	/// Object operations
+	for _, bucket := range routers {
+		/// Object operations
 
-	// HeadObject
-	bucket.Methods("HEAD").Path("/{object:.+}").HandlerFunc(api.HeadObjectHandler)
-	// PutObjectPart
-	bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutObjectPartHandler).Queries("partNumber", "{partNumber:[0-9]+}", "uploadId", "{uploadId:.*}")
-	// ListObjectPxarts
-	bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.ListObjectPartsHandler).Queries("uploadId", "{uploadId:.*}")
-	// CompleteMultipartUpload
-	bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(api.CompleteMultipartUploadHandler).Queries("uploadId", "{uploadId:.*}")
-	// NewMultipartUpload
-	bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(api.NewMultipartUploadHandler).Queries("uploads", "")
-	// AbortMultipartUpload
-	bucket.Methods("DELETE").Path("/{object:.+}").HandlerFunc(api.AbortMultipartUploadHandler).Queries("uploadId", "{uploadId:.*}")
-	// GetObject
-	bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectHandler)
-	// CopyObject
-	bucket.Methods("PUT").Path("/{object:.+}").HeadersRegexp("X-Amz-Copy-Source", ".*?(\\/).*?").HandlerFunc(api.CopyObjectHandler)
-	// PutObject
-	bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutObjectHandler)
-	// DeleteObject
-	bucket.Methods("DELETE").Path("/{object:.+}").HandlerFunc(api.DeleteObjectHandler)
+		// HeadObject
+		bucket.Methods("HEAD").Path("/{object:.+}").HandlerFunc(api.HeadObjectHandler)
+		// CopyObjectPart
+		bucket.Methods("PUT").Path("/{object:.+}").HeadersRegexp("X-Amz-Copy-Source", ".*?(\\/).*?").HandlerFunc(api.CopyObjectPartHandler).Queries("partNumber", "{partNumber:[0-9]+}", "uploadId", "{uploadId:.*}")
+		// PutObjectPart
+		bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutObjectPartHandler).Queries("partNumber", "{partNumber:[0-9]+}", "uploadId", "{uploadId:.*}")
+		// ListObjectPxarts
+		bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.ListObjectPartsHandler).Queries("uploadId", "{uploadId:.*}")
+		// CompleteMultipartUpload
+		bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(api.CompleteMultipartUploadHandler).Queries("uploadId", "{uploadId:.*}")
+		// NewMultipartUpload
+		bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(api.NewMultipartUploadHandler).Queries("uploads", "")
+		// AbortMultipartUpload
+		bucket.Methods("DELETE").Path("/{object:.+}").HandlerFunc(api.AbortMultipartUploadHandler).Queries("uploadId", "{uploadId:.*}")
+		// GetObjectTagging
+		bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectTaggingHandler).Queries("tagging", "")
+		// GetObject
+		bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectHandler)
+		// PutObjectTagging
+		bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutObjectTaggingHandler).Queries("tagging", "")
+		// CopyObject
+		bucket.Methods("PUT").Path("/{object:.+}").HeadersRegexp("X-Amz-Copy-Source", ".*?(\\/).*?").HandlerFunc(api.CopyObjectHandler)
+		// PutObject
+		bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutObjectHandler)
+		// DeleteObjectTagging
+		bucket.Methods("DELETE").Path("/{object:.+}").HandlerFunc(api.DeleteObjectTaggingHandler).Queries("tagging", "")
+		// DeleteObject
+		bucket.Methods("DELETE").Path("/{object:.+}").HandlerFunc(api.DeleteObjectHandler)
 
-	/// Bucket operations
+		/// Bucket operations
 
-	// GetBucketLocation
-	bucket.Methods("GET").HandlerFunc(api.GetBucketLocationHandler).Queries("location", "")
-	// GetBucketPolicy
-	bucket.Methods("GET").HandlerFunc(api.GetBucketPolicyHandler).Queries("policy", "")
-	// ListMultipartUploads
-	bucket.Methods("GET").HandlerFunc(api.ListMultipartUploadsHandler).Queries("uploads", "")
-	// ListObjects
-	bucket.Methods("GET").HandlerFunc(api.ListObjectsHandler)
-	// PutBucketPolicy
-	bucket.Methods("PUT").HandlerFunc(api.PutBucketPolicyHandler).Queries("policy", "")
-	// PutBucket
-	bucket.Methods("PUT").HandlerFunc(api.PutBucketHandler)
-	// HeadBucket
-	bucket.Methods("HEAD").HandlerFunc(api.HeadBucketHandler)
-	// PostPolicy
-	bucket.Methods("POST").HeadersRegexp("Content-Type", "multipart/form-data*").HandlerFunc(api.PostPolicyBucketHandler)
-	// DeleteMultipleObjects
-	bucket.Methods("POST").HandlerFunc(api.DeleteMultipleObjectsHandler)
-	// DeleteBucketPolicy
-	bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketPolicyHandler).Queries("policy", "")
-	// DeleteBucket
-	bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketHandler)
+		// GetBucketLocation
+		bucket.Methods("GET").HandlerFunc(api.GetBucketLocationHandler).Queries("location", "")
+		// GetBucketPolicy
+		bucket.Methods("GET").HandlerFunc(api.GetBucketPolicyHandler).Queries("policy", "")
+		// GetBucketLifecycle
+		bucket.Methods("GET").HandlerFunc(api.GetBucketLifecycleHandler).Queries("lifecycle", "")
+		// GetBucketEncryption
+		bucket.Methods("GET").HandlerFunc(api.GetBucketEncryptionHandler).Queries("encryption", "")
+		// GetBucketTagging
+		bucket.Methods("GET").HandlerFunc(api.GetBucketTaggingHandler).Queries("tagging", "")
+		// GetBucketCors
+		bucket.Methods("GET").HandlerFunc(api.GetBucketCorsHandler).Queries("cors", "")
+		// GetBucketWebsite
+		bucket.Methods("GET").HandlerFunc(api.GetBucketWebsiteHandler).Queries("website", "")
+		// GetBucketNotification
+		bucket.Methods("GET").HandlerFunc(api.GetBucketNotificationHandler).Queries("notification", "")
+		// GetBucketVersioning
+		bucket.Methods("GET").HandlerFunc(api.GetBucketVersioningHandler).Queries("versioning", "")
+		// ListObjectVersions
+		bucket.Methods("GET").HandlerFunc(api.ListObjectVersionsHandler).Queries("versions", "")
+		// ListenBucketNotification
+		bucket.Methods("GET").HandlerFunc(api.ListenBucketNotificationHandler).Queries("events", "{events:.*}")
+		// ListMultipartUploads
+		bucket.Methods("GET").HandlerFunc(api.ListMultipartUploadsHandler).Queries("uploads", "")
+		// ListObjects
+		bucket.Methods("GET").HandlerFunc(api.ListObjectsHandler)
+		// PutBucketPolicy
+		bucket.Methods("PUT").HandlerFunc(api.PutBucketPolicyHandler).Queries("policy", "")
+		// PutBucketLifecycle
+		bucket.Methods("PUT").HandlerFunc(api.PutBucketLifecycleHandler).Queries("lifecycle", "")
+		// PutBucketEncryption
+		bucket.Methods("PUT").HandlerFunc(api.PutBucketEncryptionHandler).Queries("encryption", "")
+		// PutBucketTagging
+		bucket.Methods("PUT").HandlerFunc(api.PutBucketTaggingHandler).Queries("tagging", "")
+		// PutBucketCors
+		bucket.Methods("PUT").HandlerFunc(api.PutBucketCorsHandler).Queries("cors", "")
+		// PutBucketWebsite
+		bucket.Methods("PUT").HandlerFunc(api.PutBucketWebsiteHandler).Queries("website", "")
+		// PutBucketNotification
+		bucket.Methods("PUT").HandlerFunc(api.PutBucketNotificationHandler).Queries("notification", "")
+		// PutBucketVersioning
+		bucket.Methods("PUT").HandlerFunc(api.PutBucketVersioningHandler).Queries("versioning", "")
+		// PutBucket
+		bucket.Methods("PUT").HandlerFunc(api.PutBucketHandler)
+		// HeadBucket
+		bucket.Methods("HEAD").HandlerFunc(api.HeadBucketHandler)
+		// PostPolicy
+		bucket.Methods("POST").HeadersRegexp("Content-Type", "multipart/form-data*").HandlerFunc(api.PostPolicyBucketHandler)
+		// DeleteMultipleObjects
+		bucket.Methods("POST").HandlerFunc(api.DeleteMultipleObjectsHandler)
+		// DeleteBucketPolicy
+		bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketPolicyHandler).Queries("policy", "")
+		// DeleteBucketLifecycle
+		bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketLifecycleHandler).Queries("lifecycle", "")
+		// DeleteBucketEncryption
+		bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketEncryptionHandler).Queries("encryption", "")
+		// DeleteBucketTagging
+		bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketTaggingHandler).Queries("tagging", "")
+		// DeleteBucketCors
+		bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketCorsHandler).Queries("cors", "")
+		// DeleteBucketWebsite
+		bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketWebsiteHandler).Queries("website", "")
+		// DeleteBucket
+		bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketHandler)
+	}
 
 	/// Root operation
 
@@ -47,6 +47,17 @@ func isRequestSignatureV4(r *http.Request) bool {
 	return false
 }
 
+// Verify if request has AWS Streaming Signature Version '4'.
+func isRequestSignStreamingV4(r *http.Request) bool {
+	return isRequestSignatureV4(r) && r.Method == "PUT" &&
+		r.Header.Get("X-Amz-Content-Sha256") == streamingContentSHA256
+}
+
+// Verify if request payload is not signed, only headers are.
+func isRequestUnsignedPayload(r *http.Request) bool {
+	return r.Header.Get("X-Amz-Content-Sha256") == unsignedPayload
+}
+
 // Verify if request has AWS Presignature Version '4'.
 func isRequestPresignedSignatureV4(r *http.Request) bool {
 	if _, ok := r.URL.Query()["X-Amz-Credential"]; ok {
@@ -55,6 +66,24 @@ func isRequestPresignedSignatureV4(r *http.Request) bool {
 	return false
 }
 
+// Verify if request has AWS Signature Version '2'.
+func isRequestSignatureV2(r *http.Request) bool {
+	if _, ok := r.Header["Authorization"]; ok {
+		if strings.HasPrefix(r.Header.Get("Authorization"), signV2Algorithm+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// Verify if request has AWS Presignature Version '2'.
+func isRequestPresignedSignatureV2(r *http.Request) bool {
+	if _, ok := r.URL.Query()["AWSAccessKeyId"]; ok {
+		return true
+	}
+	return false
+}
+
 // Verify if request has AWS Post policy Signature Version '4'.
 func isRequestPostPolicySignatureV4(r *http.Request) bool {
 	if _, ok := r.Header["Content-Type"]; ok {
@@ -76,14 +105,23 @@ const (
 	authTypePostPolicy
 	authTypeSigned
 	authTypeJWT
+	authTypeSignedV2
+	authTypePresignedV2
+	authTypeStreamingSigned
 )
 
 // Get request authentication type.
 func getRequestAuthType(r *http.Request) authType {
-	if isRequestSignatureV4(r) {
+	if isRequestSignStreamingV4(r) {
+		return authTypeStreamingSigned
+	} else if isRequestSignatureV4(r) {
 		return authTypeSigned
 	} else if isRequestPresignedSignatureV4(r) {
 		return authTypePresigned
+	} else if isRequestSignatureV2(r) {
+		return authTypeSignedV2
+	} else if isRequestPresignedSignatureV2(r) {
+		return authTypePresignedV2
 	} else if isRequestJWT(r) {
 		return authTypeJWT
 	} else if isRequestPostPolicySignatureV4(r) {
@@ -108,7 +146,24 @@ func sumMD5(data []byte) []byte {
 	return hash.Sum(nil)
 }
 
-// Verify if request has valid AWS Signature Version '4'.
+// isReqHeaderAuthenticated - verifies the signature of requests
+// whose payload is streamed, against the payload hash sent with the
+// request. The payload itself is verified as it is read.
+func isReqHeaderAuthenticated(r *http.Request) APIErrorCode {
+	validateRegion := true // Validate region.
+	if isRequestSignatureV4(r) {
+		return doesSignatureMatch(r.Header.Get("X-Amz-Content-Sha256"), r, validateRegion)
+	} else if isRequestPresignedSignatureV4(r) {
+		return doesPresignedSignatureMatch(r.URL.Query().Get("X-Amz-Content-Sha256"), r, validateRegion)
+	} else if isRequestSignatureV2(r) {
+		return doesSignatureV2Match(r)
+	} else if isRequestPresignedSignatureV2(r) {
+		return doesPresignedSignatureV2Match(r)
+	}
+	return ErrAccessDenied
+}
+
+// Verify if request has valid AWS Signature Version '4' or '2'.
 func isReqAuthenticated(r *http.Request) (s3Error APIErrorCode) {
 	if r == nil {
 		return ErrInternalError
@@ -130,6 +185,10 @@ func isReqAuthenticated(r *http.Request) (s3Error APIErrorCode) {
 		return doesSignatureMatch(hex.EncodeToString(sum256(payload)), r, validateRegion)
 	} else if isRequestPresignedSignatureV4(r) {
 		return doesPresignedSignatureMatch(hex.EncodeToString(sum256(payload)), r, validateRegion)
+	} else if isRequestSignatureV2(r) {
+		return doesSignatureV2Match(r)
+	} else if isRequestPresignedSignatureV2(r) {
+		return doesPresignedSignatureV2Match(r)
 	}
 	return ErrAccessDenied
 }
@@ -148,7 +207,7 @@ func setAuthHandler(h http.Handler) http.Handler {
 // handler for validating incoming authorization headers.
 func (a authHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch getRequestAuthType(r) {
-	case authTypeAnonymous, authTypePresigned, authTypeSigned, authTypePostPolicy:
+	case authTypeAnonymous, authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2, authTypePostPolicy, authTypeStreamingSigned:
 		// Let top level caller validate for anonymous and known
 		// signed requests.
 		a.handler.ServeHTTP(w, r)
@@ -0,0 +1,326 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// bucketCorsConfigFile - name of the file holding CORS configuration
+// under the bucket config path.
+const bucketCorsConfigFile = "cors.xml"
+
+// maximum number of rules allowed in a CORS configuration.
+const maxCorsRules = 100
+
+// corsConfiguration - represents the CORS rules of a bucket.
+type corsConfiguration struct {
+	XMLName xml.Name   `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CORSConfiguration" json:"-"`
+	Rules   []corsRule `xml:"CORSRule"`
+}
+
+// corsRule - represents a single CORS rule, a cross origin request is
+// allowed by the rule if its origin, method and headers are allowed.
+type corsRule struct {
+	ID             string   `xml:"ID,omitempty"`
+	AllowedOrigins []string `xml:"AllowedOrigin"`
+	AllowedMethods []string `xml:"AllowedMethod"`
+	AllowedHeaders []string `xml:"AllowedHeader,omitempty"`
+	ExposeHeaders  []string `xml:"ExposeHeader,omitempty"`
+	MaxAgeSeconds  int      `xml:"MaxAgeSeconds,omitempty"`
+}
+
+// Methods allowed in CORS rules.
+var corsMethods = map[string]bool{
+	"GET":    true,
+	"PUT":    true,
+	"HEAD":   true,
+	"POST":   true,
+	"DELETE": true,
+}
+
+// CORS configuration errors.
+var (
+	errCorsNoRules       = errors.New("CORS configuration should have at least one rule")
+	errCorsTooManyRules  = errors.New("CORS configuration allows a maximum of 100 rules")
+	errCorsNoOrigin      = errors.New("CORS rule should have at least one allowed origin")
+	errCorsInvalidOrigin = errors.New("CORS allowed origin can contain at most one wildcard")
+	errCorsNoMethod      = errors.New("CORS rule should have at least one allowed method")
+	errCorsInvalidMethod = errors.New("CORS allowed method must be one of GET, PUT, HEAD, POST or DELETE")
+	errCorsInvalidHeader = errors.New("CORS allowed header can contain at most one wildcard")
+	errCorsInvalidMaxAge = errors.New("CORS max age cannot be negative")
+)
+
+// validate - validates a CORS rule.
+func (rule corsRule) validate() error {
+	if len(rule.AllowedOrigins) == 0 {
+		return errCorsNoOrigin
+	}
+	for _, origin := range rule.AllowedOrigins {
+		if origin == "" || strings.Count(origin, "*") > 1 {
+			return errCorsInvalidOrigin
+		}
+	}
+	if len(rule.AllowedMethods) == 0 {
+		return errCorsNoMethod
+	}
+	for _, method := range rule.AllowedMethods {
+		if !corsMethods[method] {
+			return errCorsInvalidMethod
+		}
+	}
+	for _, header := range rule.AllowedHeaders {
+		if header == "" || strings.Count(header, "*") > 1 {
+			return errCorsInvalidHeader
+		}
+	}
+	if rule.MaxAgeSeconds < 0 {
+		return errCorsInvalidMaxAge
+	}
+	return nil
+}
+
+// allowedOrigin - returns the origin to be sent in
+// Access-Control-Allow-Origin if the origin is allowed, "*" for
+// rules allowing all origins.
+func (rule corsRule) allowedOrigin(origin string) (string, bool) {
+	for _, allowed := range rule.AllowedOrigins {
+		if allowed == "*" {
+			return "*", true
+		}
+		if resourceMatch(allowed, origin) {
+			return origin, true
+		}
+	}
+	return "", false
+}
+
+// isMethodAllowed - returns true if the method is allowed.
+func (rule corsRule) isMethodAllowed(method string) bool {
+	for _, allowed := range rule.AllowedMethods {
+		if allowed == method {
+			return true
+		}
+	}
+	return false
+}
+
+// areHeadersAllowed - returns true if all the headers are allowed,
+// header names are matched case insensitively.
+func (rule corsRule) areHeadersAllowed(headers []string) bool {
+	for _, header := range headers {
+		allowed := false
+		for _, pattern := range rule.AllowedHeaders {
+			if resourceMatch(strings.ToLower(pattern), strings.ToLower(header)) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
+
+// validate - validates a CORS configuration.
+func (cc corsConfiguration) validate() error {
+	if len(cc.Rules) == 0 {
+		return errCorsNoRules
+	}
+	if len(cc.Rules) > maxCorsRules {
+		return errCorsTooManyRules
+	}
+	for _, rule := range cc.Rules {
+		if err := rule.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// match - returns the first rule allowing a request with the origin,
+// method and headers along with the origin to be allowed.
+func (cc corsConfiguration) match(origin, method string, headers []string) (corsRule, string, bool) {
+	for _, rule := range cc.Rules {
+		allowedOrigin, ok := rule.allowedOrigin(origin)
+		if !ok || !rule.isMethodAllowed(method) || !rule.areHeadersAllowed(headers) {
+			continue
+		}
+		return rule, allowedOrigin, true
+	}
+	return corsRule{}, "", false
+}
+
+// maximum number of entries of the CORS configuration cache for which
+// buckets without a configuration are cached, lookups of any bucket
+// name would grow the cache otherwise.
+const maxBucketCorsCacheSize = 10000
+
+// bucketCorsCache - caches bucket CORS configurations, which are
+// looked up on every cross origin request. Entries are keyed by the
+// configuration file, buckets without a configuration are cached as
+// nil while the cache holds less than maxBucketCorsCacheSize entries.
+type bucketCorsCache struct {
+	rwMutex *sync.RWMutex
+	configs map[string]*corsConfiguration
+}
+
+// Global cache of bucket CORS configurations.
+var globalBucketCors = &bucketCorsCache{
+	rwMutex: &sync.RWMutex{},
+	configs: make(map[string]*corsConfiguration),
+}
+
+// load - returns the cached CORS configuration, reading it on a cache
+// miss. Returns nil if there is no configuration.
+func (c *bucketCorsCache) load(bucketCorsFile string) (*corsConfiguration, error) {
+	c.rwMutex.RLock()
+	cc, ok := c.configs[bucketCorsFile]
+	c.rwMutex.RUnlock()
+	if ok {
+		return cc, nil
+	}
+
+	// Read under the write lock so that a concurrent update is not
+	// overwritten with a stale configuration.
+	c.rwMutex.Lock()
+	defer c.rwMutex.Unlock()
+	if cc, ok = c.configs[bucketCorsFile]; ok {
+		return cc, nil
+	}
+	corsBytes, err := ioutil.ReadFile(bucketCorsFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	} else {
+		cc = &corsConfiguration{}
+		if err = xml.Unmarshal(corsBytes, cc); err != nil {
+			return nil, err
+		}
+	}
+	c.set(bucketCorsFile, cc)
+	return cc, nil
+}
+
+// update - runs updateFn to write or remove the configuration file,
+// caching cc once it succeeds.
+func (c *bucketCorsCache) update(bucketCorsFile string, cc *corsConfiguration, updateFn func() error) error {
+	c.rwMutex.Lock()
+	defer c.rwMutex.Unlock()
+	if err := updateFn(); err != nil {
+		// Configuration file state is unknown, read it again.
+		delete(c.configs, bucketCorsFile)
+		return err
+	}
+	c.set(bucketCorsFile, cc)
+	return nil
+}
+
+// set - caches cc, must be called with the write lock held.
+func (c *bucketCorsCache) set(bucketCorsFile string, cc *corsConfiguration) {
+	if cc == nil && len(c.configs) >= maxBucketCorsCacheSize {
+		delete(c.configs, bucketCorsFile)
+		return
+	}
+	c.configs[bucketCorsFile] = cc
+}
+
+// readBucketCors - read bucket CORS configuration.
+func readBucketCors(bucket string) (corsConfiguration, error) {
+	// Verify bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return corsConfiguration{}, BucketNameInvalid{Bucket: bucket}
+	}
+
+	bucketConfigPath, err := getBucketConfigPath(bucket)
+	if err != nil {
+		return corsConfiguration{}, err
+	}
+
+	// Get CORS configuration.
+	bucketCorsFile := filepath.Join(bucketConfigPath, bucketCorsConfigFile)
+	cc, err := globalBucketCors.load(bucketCorsFile)
+	if err != nil {
+		return corsConfiguration{}, err
+	}
+	if cc == nil {
+		return corsConfiguration{}, BucketCorsNotFound{Bucket: bucket}
+	}
+	return *cc, nil
+}
+
+// writeBucketCors - save bucket CORS configuration.
+func writeBucketCors(bucket string, cc corsConfiguration) error {
+	// Verify if bucket path legal
+	if !IsValidBucketName(bucket) {
+		return BucketNameInvalid{Bucket: bucket}
+	}
+
+	// Create bucket config path.
+	if err := createBucketConfigPath(bucket); err != nil {
+		return err
+	}
+
+	bucketConfigPath, err := getBucketConfigPath(bucket)
+	if err != nil {
+		return err
+	}
+
+	corsBytes, err := xml.Marshal(cc)
+	if err != nil {
+		return err
+	}
+
+	// Write bucket CORS configuration.
+	bucketCorsFile := filepath.Join(bucketConfigPath, bucketCorsConfigFile)
+	return globalBucketCors.update(bucketCorsFile, &cc, func() error {
+		return ioutil.WriteFile(bucketCorsFile, corsBytes, 0600)
+	})
+}
+
+// removeBucketCors - remove bucket CORS configuration.
+func removeBucketCors(bucket string) error {
+	// Verify bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return BucketNameInvalid{Bucket: bucket}
+	}
+
+	bucketConfigPath, err := getBucketConfigPath(bucket)
+	if err != nil {
+		return err
+	}
+
+	// Remove CORS file.
+	bucketCorsFile := filepath.Join(bucketConfigPath, bucketCorsConfigFile)
+	return globalBucketCors.update(bucketCorsFile, nil, func() error {
+		if rErr := os.Remove(bucketCorsFile); rErr != nil {
+			if os.IsNotExist(rErr) {
+				return BucketCorsNotFound{Bucket: bucket}
+			}
+			return rErr
+		}
+		return nil
+	})
+}
@@ -0,0 +1,258 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// Tests validating CORS configuration.
+func TestCorsConfigurationValidate(t *testing.T) {
+	rule := corsRule{
+		AllowedOrigins: []string{"https://*.example.com"},
+		AllowedMethods: []string{"GET", "PUT"},
+		AllowedHeaders: []string{"x-amz-*"},
+	}
+	testCases := []struct {
+		modify      func(rule *corsRule)
+		expectedErr error
+	}{
+		{func(rule *corsRule) {}, nil},
+		{func(rule *corsRule) { rule.AllowedOrigins = nil }, errCorsNoOrigin},
+		{func(rule *corsRule) { rule.AllowedOrigins = []string{"https://*.*.com"} }, errCorsInvalidOrigin},
+		{func(rule *corsRule) { rule.AllowedMethods = nil }, errCorsNoMethod},
+		{func(rule *corsRule) { rule.AllowedMethods = []string{"PATCH"} }, errCorsInvalidMethod},
+		{func(rule *corsRule) { rule.AllowedHeaders = []string{""} }, errCorsInvalidHeader},
+		{func(rule *corsRule) { rule.MaxAgeSeconds = -1 }, errCorsInvalidMaxAge},
+	}
+	for i, testCase := range testCases {
+		testRule := rule
+		testCase.modify(&testRule)
+		if err := (corsConfiguration{Rules: []corsRule{testRule}}).validate(); err != testCase.expectedErr {
+			t.Errorf("Test %d: Expected error %v, got %v", i+1, testCase.expectedErr, err)
+		}
+	}
+	if err := (corsConfiguration{}).validate(); err != errCorsNoRules {
+		t.Errorf("Expected error %v, got %v", errCorsNoRules, err)
+	}
+	if err := (corsConfiguration{Rules: make([]corsRule, maxCorsRules+1)}).validate(); err != errCorsTooManyRules {
+		t.Errorf("Expected error %v, got %v", errCorsTooManyRules, err)
+	}
+}
+
+// Tests bucket CORS operations and evaluation of cross origin requests.
+func TestBucketCorsHandlers(t *testing.T) {
+	for _, instanceType := range []string{"FS", "XL"} {
+		testBucketCorsHandlers(instanceType, t)
+	}
+}
+
+func testBucketCorsHandlers(instanceType string, t *testing.T) {
+	testServer := StartTestServer(t, instanceType)
+	defer testServer.Stop()
+
+	client := http.Client{}
+	expectStatus := func(req *http.Request, status int) ([]byte, http.Header) {
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		respBody, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != status {
+			t.Fatalf("%s: %s %s: Expected status %d, got %d: %s", instanceType, req.Method, req.URL, status, resp.StatusCode, respBody)
+		}
+		return respBody, resp.Header
+	}
+	newRequest := func(method, urlStr string, body []byte) *http.Request {
+		req, err := newTestRequest(method, urlStr, int64(len(body)), bytes.NewReader(body), testServer.AccessKey, testServer.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+	newCorsRequest := func(method, urlStr, origin string, headers map[string]string) *http.Request {
+		req, err := http.NewRequest(method, urlStr, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Origin", origin)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		return req
+	}
+	preflight := func(urlStr, origin, method, headers string) *http.Request {
+		return newCorsRequest("OPTIONS", urlStr, origin, map[string]string{
+			"Access-Control-Request-Method":  method,
+			"Access-Control-Request-Headers": headers,
+		})
+	}
+
+	bucketURL := testServer.Server.URL + "/cors-bucket"
+	corsURL := bucketURL + "?cors"
+	objectURL := bucketURL + "/object"
+	expectStatus(newRequest("PUT", bucketURL, nil), http.StatusOK)
+	expectStatus(newRequest("PUT", objectURL, []byte("hello, cors")), http.StatusOK)
+	expectStatus(newRequest("GET", corsURL, nil), http.StatusNotFound)
+
+	// Buckets without CORS configuration allow no cross origin requests.
+	_, respHeader := expectStatus(preflight(objectURL, "https://any.example.org", "PUT", ""), http.StatusForbidden)
+	if respHeader.Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("%s: Expected bucket without CORS configuration to get no CORS headers", instanceType)
+	}
+	unsignedRequest := newCorsRequest("GET", objectURL, "https://any.example.org", nil)
+	if _, respHeader = expectStatus(unsignedRequest, http.StatusForbidden); respHeader.Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("%s: Expected bucket without CORS configuration to get no CORS headers", instanceType)
+	}
+	// Requests to the root use the default policy.
+	if _, respHeader = expectStatus(preflight(testServer.Server.URL, "https://any.example.org", "GET", ""), http.StatusOK); respHeader.Get("Access-Control-Allow-Origin") == "" {
+		t.Fatalf("%s: Expected default CORS policy to allow any origin", instanceType)
+	}
+
+	corsBody := []byte(`<CORSConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+<CORSRule><AllowedOrigin>https://*.example.com</AllowedOrigin><AllowedMethod>GET</AllowedMethod><AllowedMethod>PUT</AllowedMethod><AllowedHeader>x-amz-*</AllowedHeader><AllowedHeader>Content-Type</AllowedHeader><ExposeHeader>ETag</ExposeHeader><MaxAgeSeconds>3000</MaxAgeSeconds></CORSRule>
+<CORSRule><AllowedOrigin>*</AllowedOrigin><AllowedMethod>HEAD</AllowedMethod></CORSRule>
+</CORSConfiguration>`)
+	expectStatus(newRequest("PUT", corsURL, corsBody), http.StatusOK)
+	respData, _ := expectStatus(newRequest("GET", corsURL, nil), http.StatusOK)
+	var cc corsConfiguration
+	if err := xml.Unmarshal(respData, &cc); err != nil {
+		t.Fatal(err)
+	}
+	if len(cc.Rules) != 2 || cc.Rules[0].MaxAgeSeconds != 3000 || len(cc.Rules[0].AllowedHeaders) != 2 {
+		t.Fatalf("%s: Unexpected CORS configuration %s", instanceType, respData)
+	}
+	expectStatus(newRequest("PUT", corsURL, []byte(`<CORSConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><CORSRule><AllowedOrigin>*</AllowedOrigin><AllowedMethod>PATCH</AllowedMethod></CORSRule></CORSConfiguration>`)), http.StatusBadRequest)
+	expectStatus(newRequest("PUT", corsURL, []byte("<CORSConfiguration>")), http.StatusBadRequest)
+
+	// Preflight requests are evaluated against the bucket rules.
+	_, respHeader = expectStatus(preflight(objectURL, "https://app.example.com", "PUT", "Content-Type, X-Amz-Date"), http.StatusOK)
+	expectedHeaders := map[string]string{
+		"Access-Control-Allow-Origin":      "https://app.example.com",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Methods":     "GET, PUT",
+		"Access-Control-Allow-Headers":     "Content-Type, X-Amz-Date",
+		"Access-Control-Expose-Headers":    "ETag",
+		"Access-Control-Max-Age":           "3000",
+	}
+	for key, value := range expectedHeaders {
+		if respHeader.Get(key) != value {
+			t.Fatalf("%s: Expected %s to be %s, got %s", instanceType, key, value, respHeader.Get(key))
+		}
+	}
+	expectStatus(preflight(objectURL, "https://app.example.org", "PUT", ""), http.StatusForbidden)
+	expectStatus(preflight(objectURL, "https://app.example.com", "DELETE", ""), http.StatusForbidden)
+	expectStatus(preflight(objectURL, "https://app.example.com", "PUT", "Authorization"), http.StatusForbidden)
+	_, respHeader = expectStatus(preflight(objectURL, "https://app.example.org", "HEAD", ""), http.StatusOK)
+	if respHeader.Get("Access-Control-Allow-Origin") != "*" || respHeader.Get("Access-Control-Allow-Credentials") != "" {
+		t.Fatalf("%s: Expected wildcard origin to be allowed without credentials", instanceType)
+	}
+
+	// Configurations are served from the cache.
+	bucketConfigPath, err := getBucketConfigPath("cors-bucket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	corsBytes, err := ioutil.ReadFile(filepath.Join(bucketConfigPath, bucketCorsConfigFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Remove(filepath.Join(bucketConfigPath, bucketCorsConfigFile)); err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(preflight(objectURL, "https://app.example.com", "PUT", ""), http.StatusOK)
+	if err = ioutil.WriteFile(filepath.Join(bucketConfigPath, bucketCorsConfigFile), corsBytes, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Actual requests are served, with CORS headers only if allowed.
+	signedRequest := func(origin string) *http.Request {
+		req := newRequest("GET", objectURL, nil)
+		req.Header.Set("Origin", origin)
+		return req
+	}
+	_, respHeader = expectStatus(signedRequest("https://app.example.com"), http.StatusOK)
+	if respHeader.Get("Access-Control-Allow-Origin") != "https://app.example.com" || respHeader.Get("Access-Control-Expose-Headers") != "ETag" {
+		t.Fatalf("%s: Expected allowed origin to get CORS headers", instanceType)
+	}
+	_, respHeader = expectStatus(signedRequest("https://app.example.org"), http.StatusOK)
+	if respHeader.Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("%s: Expected disallowed origin to get no CORS headers", instanceType)
+	}
+
+	// CORS configuration is removed.
+	expectStatus(newRequest("DELETE", corsURL, nil), http.StatusNoContent)
+	expectStatus(newRequest("GET", corsURL, nil), http.StatusNotFound)
+	expectStatus(newRequest("DELETE", corsURL, nil), http.StatusNoContent)
+	expectStatus(preflight(objectURL, "https://app.example.com", "PUT", ""), http.StatusForbidden)
+
+	// CORS configuration is removed with the bucket.
+	expectStatus(newRequest("PUT", corsURL, corsBody), http.StatusOK)
+	expectStatus(newRequest("DELETE", objectURL, nil), http.StatusNoContent)
+	expectStatus(newRequest("DELETE", bucketURL, nil), http.StatusNoContent)
+	expectStatus(newRequest("PUT", bucketURL, nil), http.StatusOK)
+	expectStatus(newRequest("GET", corsURL, nil), http.StatusNotFound)
+	expectStatus(preflight(objectURL, "https://app.example.com", "PUT", ""), http.StatusForbidden)
+}
+
+// Tests buckets without CORS configuration are cached up to the cache
+// size.
+func TestBucketCorsCacheSize(t *testing.T) {
+	configDir, err := ioutil.TempDir("", "cors-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(configDir)
+
+	cache := &bucketCorsCache{
+		rwMutex: &sync.RWMutex{},
+		configs: make(map[string]*corsConfiguration),
+	}
+	for i := 0; i < maxBucketCorsCacheSize+10; i++ {
+		cc, err := cache.load(filepath.Join(configDir, strconv.Itoa(i), bucketCorsConfigFile))
+		if err != nil || cc != nil {
+			t.Fatalf("Expected no configuration, got %v, %v", cc, err)
+		}
+	}
+	if len(cache.configs) != maxBucketCorsCacheSize {
+		t.Fatalf("Expected %d cache entries, got %d", maxBucketCorsCacheSize, len(cache.configs))
+	}
+
+	// Configurations are cached regardless.
+	bucketCorsFile := filepath.Join(configDir, bucketCorsConfigFile)
+	if err = ioutil.WriteFile(bucketCorsFile, []byte(`<CORSConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></CORSConfiguration>`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if cc, err := cache.load(bucketCorsFile); err != nil || cc == nil {
+		t.Fatalf("Expected configuration, got %v, %v", cc, err)
+	}
+	if _, ok := cache.configs[bucketCorsFile]; !ok {
+		t.Fatal("Expected configuration to be cached")
+	}
+}
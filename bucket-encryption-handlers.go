@@ -0,0 +1,172 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	mux "github.com/gorilla/mux"
+)
+
+// maximum supported encryption configuration size.
+const maxEncryptionConfigSize = 1 * 1024 * 1024 // 1MiB.
+
+// PutBucketEncryptionHandler - PUT Bucket encryption
+// -----------------
+// This implementation of the PUT operation sets the default encryption
+// applied to objects written to an existing bucket.
+func (api objectAPIHandlers) PutBucketEncryptionHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	switch getRequestAuthType(r) {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
+		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	// Default encryption requires a master key.
+	if globalSSEMasterKey == nil {
+		writeErrorResponse(w, r, ErrMasterKeyNotConfigured, r.URL.Path)
+		return
+	}
+
+	// Verify if bucket exists.
+	if _, err := api.ObjectAPI.GetBucketInfo(bucket); err != nil {
+		errorIf(err, "Unable to fetch bucket info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Read encryption configuration, limited to maxEncryptionConfigSize.
+	encryptionBytes, err := ioutil.ReadAll(io.LimitReader(r.Body, maxEncryptionConfigSize))
+	if err != nil {
+		errorIf(err, "Unable to read encryption configuration.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+
+	var ec encryptionConfiguration
+	if err = xml.Unmarshal(encryptionBytes, &ec); err != nil {
+		errorIf(err, "Unable to parse encryption configuration.")
+		writeErrorResponse(w, r, ErrMalformedXML, r.URL.Path)
+		return
+	}
+	if err = ec.validate(); err != nil {
+		errorIf(err, "Invalid encryption configuration.")
+		writeErrorResponse(w, r, ErrMalformedXML, r.URL.Path)
+		return
+	}
+
+	// Save encryption configuration.
+	if err = writeBucketEncryption(bucket, ec); err != nil {
+		errorIf(err, "Unable to write encryption configuration.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	writeSuccessResponse(w, nil)
+}
+
+// GetBucketEncryptionHandler - GET Bucket encryption
+// -----------------
+// This implementation of the GET operation returns the default
+// encryption configuration of a bucket.
+func (api objectAPIHandlers) GetBucketEncryptionHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	switch getRequestAuthType(r) {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
+		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	// Verify if bucket exists.
+	if _, err := api.ObjectAPI.GetBucketInfo(bucket); err != nil {
+		errorIf(err, "Unable to fetch bucket info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	ec, err := readBucketEncryption(bucket)
+	if err != nil {
+		errorIf(err, "Unable to read encryption configuration.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	encodedSuccessResponse := encodeResponse(ec)
+	// Write headers
+	setCommonHeaders(w)
+	// Write success response.
+	writeSuccessResponse(w, encodedSuccessResponse)
+}
+
+// DeleteBucketEncryptionHandler - DELETE Bucket encryption
+// -----------------
+// This implementation of the DELETE operation removes the default
+// encryption configuration of a bucket, objects already written stay
+// encrypted.
+func (api objectAPIHandlers) DeleteBucketEncryptionHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	switch getRequestAuthType(r) {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
+		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	// Verify if bucket exists.
+	if _, err := api.ObjectAPI.GetBucketInfo(bucket); err != nil {
+		errorIf(err, "Unable to fetch bucket info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	if err := removeBucketEncryption(bucket); err != nil {
+		// Deleting an encryption configuration which does not exist
+		// is not an error.
+		if _, ok := err.(BucketEncryptionNotFound); !ok {
+			errorIf(err, "Unable to remove encryption configuration.")
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+			return
+		}
+	}
+	writeSuccessNoContent(w)
+}
@@ -0,0 +1,151 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// bucketEncryptionConfigFile - name of the file holding default
+// encryption configuration under the bucket config path.
+const bucketEncryptionConfigFile = "encryption.xml"
+
+// encryptionConfiguration - represents the default encryption applied
+// to objects written to a bucket.
+type encryptionConfiguration struct {
+	XMLName xml.Name         `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ServerSideEncryptionConfiguration" json:"-"`
+	Rules   []encryptionRule `xml:"Rule"`
+}
+
+// encryptionRule - represents a single default encryption rule.
+type encryptionRule struct {
+	DefaultEncryption encryptionByDefault `xml:"ApplyServerSideEncryptionByDefault"`
+}
+
+// encryptionByDefault - server side encryption algorithm applied to
+// objects written without encryption headers.
+type encryptionByDefault struct {
+	SSEAlgorithm string `xml:"SSEAlgorithm"`
+}
+
+// Encryption configuration errors.
+var (
+	errEncryptionNoRule        = errors.New("Encryption configuration should have exactly one rule")
+	errEncryptionInvalidMethod = errors.New("Encryption configuration only supports the AES256 algorithm")
+)
+
+// validate - validates the encryption configuration.
+func (ec encryptionConfiguration) validate() error {
+	if len(ec.Rules) != 1 {
+		return errEncryptionNoRule
+	}
+	if ec.Rules[0].DefaultEncryption.SSEAlgorithm != sseAlgorithmAES256 {
+		return errEncryptionInvalidMethod
+	}
+	return nil
+}
+
+// algorithm - returns the default encryption algorithm of the bucket.
+func (ec encryptionConfiguration) algorithm() string {
+	if len(ec.Rules) == 0 {
+		return ""
+	}
+	return ec.Rules[0].DefaultEncryption.SSEAlgorithm
+}
+
+// readBucketEncryption - read bucket encryption configuration.
+func readBucketEncryption(bucket string) (encryptionConfiguration, error) {
+	// Verify bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return encryptionConfiguration{}, BucketNameInvalid{Bucket: bucket}
+	}
+
+	bucketConfigPath, err := getBucketConfigPath(bucket)
+	if err != nil {
+		return encryptionConfiguration{}, err
+	}
+
+	// Get encryption file.
+	bucketEncryptionFile := filepath.Join(bucketConfigPath, bucketEncryptionConfigFile)
+	encryptionBytes, err := ioutil.ReadFile(bucketEncryptionFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return encryptionConfiguration{}, BucketEncryptionNotFound{Bucket: bucket}
+		}
+		return encryptionConfiguration{}, err
+	}
+
+	var ec encryptionConfiguration
+	if err = xml.Unmarshal(encryptionBytes, &ec); err != nil {
+		return encryptionConfiguration{}, err
+	}
+	return ec, nil
+}
+
+// writeBucketEncryption - save bucket encryption configuration.
+func writeBucketEncryption(bucket string, ec encryptionConfiguration) error {
+	// Verify if bucket path legal
+	if !IsValidBucketName(bucket) {
+		return BucketNameInvalid{Bucket: bucket}
+	}
+
+	// Create bucket config path.
+	if err := createBucketConfigPath(bucket); err != nil {
+		return err
+	}
+
+	bucketConfigPath, err := getBucketConfigPath(bucket)
+	if err != nil {
+		return err
+	}
+
+	encryptionBytes, err := xml.Marshal(ec)
+	if err != nil {
+		return err
+	}
+
+	// Write bucket encryption.
+	bucketEncryptionFile := filepath.Join(bucketConfigPath, bucketEncryptionConfigFile)
+	return ioutil.WriteFile(bucketEncryptionFile, encryptionBytes, 0600)
+}
+
+// removeBucketEncryption - remove bucket encryption configuration.
+func removeBucketEncryption(bucket string) error {
+	// Verify bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return BucketNameInvalid{Bucket: bucket}
+	}
+
+	bucketConfigPath, err := getBucketConfigPath(bucket)
+	if err != nil {
+		return err
+	}
+
+	// Remove encryption file.
+	bucketEncryptionFile := filepath.Join(bucketConfigPath, bucketEncryptionConfigFile)
+	if err = os.Remove(bucketEncryptionFile); err != nil {
+		if os.IsNotExist(err) {
+			return BucketEncryptionNotFound{Bucket: bucket}
+		}
+		return err
+	}
+	return nil
+}
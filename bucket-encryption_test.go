@@ -0,0 +1,197 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Tests loading the master key from a file and from the environment.
+func TestLoadMasterKey(t *testing.T) {
+	keyHex := strings.Repeat("0f", encObjectKeySize)
+	tmpDir, err := ioutil.TempDir("", "minio-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(tmpDir)
+	keyFile := filepath.Join(tmpDir, "master.key")
+	if err = ioutil.WriteFile(keyFile, []byte(keyHex+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("MINIO_TEST_MASTER_KEY", keyHex)
+	defer os.Unsetenv("MINIO_TEST_MASTER_KEY")
+	os.Setenv("MINIO_TEST_SHORT_MASTER_KEY", "0f0f")
+	defer os.Unsetenv("MINIO_TEST_SHORT_MASTER_KEY")
+
+	testCases := []struct {
+		encConfig encryptionConfig
+		hasKey    bool
+		err       error
+	}{
+		// Not configured.
+		{encryptionConfig{}, false, nil},
+		{encryptionConfig{MasterKeyFile: keyFile}, true, nil},
+		{encryptionConfig{MasterKeyEnv: "MINIO_TEST_MASTER_KEY"}, true, nil},
+		{encryptionConfig{MasterKeyFile: keyFile, MasterKeyEnv: "MINIO_TEST_MASTER_KEY"}, false, errMasterKeyConflict},
+		{encryptionConfig{MasterKeyEnv: "MINIO_TEST_UNSET_MASTER_KEY"}, false, errMasterKeyEmpty},
+		{encryptionConfig{MasterKeyEnv: "MINIO_TEST_SHORT_MASTER_KEY"}, false, errMasterKeyInvalid},
+	}
+	for i, testCase := range testCases {
+		masterKey, err := loadMasterKey(testCase.encConfig)
+		if err != testCase.err {
+			t.Fatalf("Test %d: Expected error %v, got %v", i+1, testCase.err, err)
+		}
+		if (masterKey != nil) != testCase.hasKey {
+			t.Fatalf("Test %d: Expected master key %v, got %v", i+1, testCase.hasKey, masterKey != nil)
+		}
+	}
+}
+
+// Tests objects encrypted with the master key on request and by
+// bucket default encryption.
+func TestSSES3Handlers(t *testing.T) {
+	for _, instanceType := range []string{"FS", "XL"} {
+		testSSES3Handlers(instanceType, t)
+	}
+}
+
+func testSSES3Handlers(instanceType string, t *testing.T) {
+	testServer := StartTestServer(t, instanceType)
+	defer testServer.Stop()
+	defer func() { globalSSEMasterKey = nil }()
+
+	data := bytes.Repeat([]byte("minio"), 30000)
+	client := http.Client{}
+	doRequest := func(method, urlStr string, body []byte, sse bool) *http.Response {
+		req, err := newTestRequest(method, urlStr, int64(len(body)), bytes.NewReader(body), testServer.AccessKey, testServer.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sse {
+			req.Header.Set(sseHeader, sseAlgorithmAES256)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	expectStatus := func(resp *http.Response, status int) []byte {
+		defer resp.Body.Close()
+		respBody, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != status {
+			t.Fatalf("%s: Expected status %d, got %d: %s", instanceType, status, resp.StatusCode, respBody)
+		}
+		return respBody
+	}
+	obj, err := newObjectLayer(testServer.Disks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectEncrypted := func(object string, encrypted bool) {
+		objInfo, err := obj.GetObjectInfo("sse-bucket", object)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isEncrypted(objInfo.UserDefined) != encrypted {
+			t.Fatalf("%s: Expected %s encrypted %v", instanceType, object, encrypted)
+		}
+		if encrypted && (isSSECustomerEncrypted(objInfo.UserDefined) || objInfo.Size != encryptedSize(int64(len(data)))) {
+			t.Fatalf("%s: Expected %s encrypted with the master key", instanceType, object)
+		}
+	}
+
+	bucketURL := testServer.Server.URL + "/sse-bucket"
+	encryptionURL := bucketURL + "?encryption"
+	encryptionConfig := []byte(`<ServerSideEncryptionConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Rule><ApplyServerSideEncryptionByDefault><SSEAlgorithm>AES256</SSEAlgorithm></ApplyServerSideEncryptionByDefault></Rule></ServerSideEncryptionConfiguration>`)
+	expectStatus(doRequest("PUT", bucketURL, nil, false), http.StatusOK)
+
+	// SSE-S3 requires a master key.
+	globalSSEMasterKey = nil
+	expectStatus(doRequest("PUT", bucketURL+"/object", data, true), http.StatusNotImplemented)
+	expectStatus(doRequest("PUT", encryptionURL, encryptionConfig, false), http.StatusNotImplemented)
+	globalSSEMasterKey = bytes.Repeat([]byte("m"), encObjectKeySize)
+
+	resp := doRequest("PUT", bucketURL+"/object", data, true)
+	expectStatus(resp, http.StatusOK)
+	if resp.Header.Get(sseHeader) != sseAlgorithmAES256 {
+		t.Fatalf("%s: Expected SSE header in response", instanceType)
+	}
+	expectEncrypted("object", true)
+	resp = doRequest("GET", bucketURL+"/object", nil, false)
+	if respData := expectStatus(resp, http.StatusOK); !bytes.Equal(respData, data) {
+		t.Fatalf("%s: Decrypted object does not match", instanceType)
+	}
+	if resp.Header.Get(sseHeader) != sseAlgorithmAES256 {
+		t.Fatalf("%s: Expected SSE header in response", instanceType)
+	}
+
+	// Copies of encrypted objects are encrypted as requested.
+	copyObject := func(object string, sse bool) {
+		req, err := newTestRequest("PUT", bucketURL+"/"+object, 0, nil, testServer.AccessKey, testServer.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-Amz-Copy-Source", "/sse-bucket/object")
+		if sse {
+			req.Header.Set(sseHeader, sseAlgorithmAES256)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectStatus(resp, http.StatusOK)
+		if respData := expectStatus(doRequest("GET", bucketURL+"/"+object, nil, false), http.StatusOK); !bytes.Equal(respData, data) {
+			t.Fatalf("%s: Copied object %s does not match", instanceType, object)
+		}
+	}
+	copyObject("encrypted-copy", true)
+	expectEncrypted("encrypted-copy", true)
+	copyObject("plain-copy", false)
+	expectEncrypted("plain-copy", false)
+
+	// Objects are written unencrypted without default encryption.
+	expectStatus(doRequest("PUT", bucketURL+"/plain", data, false), http.StatusOK)
+	expectEncrypted("plain", false)
+
+	// Default encryption applies to all objects written afterwards.
+	expectStatus(doRequest("GET", encryptionURL, nil, false), http.StatusNotFound)
+	expectStatus(doRequest("PUT", encryptionURL, []byte("<ServerSideEncryptionConfiguration/>"), false), http.StatusBadRequest)
+	expectStatus(doRequest("PUT", encryptionURL, encryptionConfig, false), http.StatusOK)
+	if respData := expectStatus(doRequest("GET", encryptionURL, nil, false), http.StatusOK); !bytes.Contains(respData, []byte("<SSEAlgorithm>AES256</SSEAlgorithm>")) {
+		t.Fatalf("%s: Unexpected encryption configuration %s", instanceType, respData)
+	}
+	expectStatus(doRequest("PUT", bucketURL+"/default", data, false), http.StatusOK)
+	expectEncrypted("default", true)
+	if respData := expectStatus(doRequest("GET", bucketURL+"/default", nil, false), http.StatusOK); !bytes.Equal(respData, data) {
+		t.Fatalf("%s: Decrypted object does not match", instanceType)
+	}
+
+	expectStatus(doRequest("DELETE", encryptionURL, nil, false), http.StatusNoContent)
+	expectStatus(doRequest("GET", encryptionURL, nil, false), http.StatusNotFound)
+	expectStatus(doRequest("PUT", bucketURL+"/plain", data, false), http.StatusOK)
+	expectEncrypted("plain", false)
+}
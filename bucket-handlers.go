@@ -33,6 +33,12 @@ import (
 
 // http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
 func enforceBucketPolicy(action string, bucket string, reqURL *url.URL) (s3Error APIErrorCode) {
+	return enforceBucketPolicyTags(action, bucket, reqURL, nil)
+}
+
+// enforceBucketPolicyTags - same as enforceBucketPolicy, conditions
+// on object tags are matched against tagConditions.
+func enforceBucketPolicyTags(action string, bucket string, reqURL *url.URL, tagConditions map[string]string) (s3Error APIErrorCode) {
 	// Read saved bucket policy.
 	policy, err := readBucketPolicy(bucket)
 	if err != nil {
@@ -54,14 +60,22 @@ func enforceBucketPolicy(action string, bucket string, reqURL *url.URL) (s3Error
 		return ErrAccessDenied
 	}
 
-	// Construct resource in 'arn:aws:s3:::examplebucket/object' format.
+	// Construct resource in 'arn:aws:s3:::examplebucket/object' format,
+	// reqURL is path style.
 	resource := AWSResourcePrefix + strings.TrimPrefix(reqURL.Path, "/")
 
 	// Get conditions for policy verification.
 	conditions := make(map[string]string)
 	for queryParam := range reqURL.Query() {
+		// Tag conditions cannot be set by query parameters.
+		if isTagConditionKey("s3:" + queryParam) {
+			continue
+		}
 		conditions[queryParam] = reqURL.Query().Get(queryParam)
 	}
+	for key, value := range tagConditions {
+		conditions[key] = value
+	}
 
 	// Validate action, resource and conditions with current policy statements.
 	if !bucketPolicyEvalStatements(action, resource, conditions, bucketPolicy.Statements) {
@@ -84,11 +98,11 @@ func (api objectAPIHandlers) GetBucketLocationHandler(w http.ResponseWriter, r *
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
-		if s3Error := enforceBucketPolicy("s3:GetBucketLocation", bucket, r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy("s3:GetBucketLocation", bucket, getPathStyleURL(r)); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
-	case authTypeSigned, authTypePresigned:
+	case authTypeSigned, authTypePresigned, authTypeSignedV2, authTypePresignedV2:
 		payload, err := ioutil.ReadAll(r.Body)
 		if err != nil {
 			writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
@@ -109,6 +123,10 @@ func (api objectAPIHandlers) GetBucketLocationHandler(w http.ResponseWriter, r *
 			s3Error = doesSignatureMatch(hex.EncodeToString(sum256(payload)), r, validateRegion)
 		} else if isRequestPresignedSignatureV4(r) {
 			s3Error = doesPresignedSignatureMatch(hex.EncodeToString(sum256(payload)), r, validateRegion)
+		} else if isRequestSignatureV2(r) {
+			s3Error = doesSignatureV2Match(r)
+		} else if isRequestPresignedSignatureV2(r) {
+			s3Error = doesPresignedSignatureV2Match(r)
 		}
 		if s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
@@ -154,11 +172,11 @@ func (api objectAPIHandlers) ListMultipartUploadsHandler(w http.ResponseWriter,
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/mpuAndPermissions.html
-		if s3Error := enforceBucketPolicy("s3:ListBucketMultipartUploads", bucket, r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy("s3:ListBucketMultipartUploads", bucket, getPathStyleURL(r)); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
-	case authTypePresigned, authTypeSigned:
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
 		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
@@ -210,11 +228,11 @@ func (api objectAPIHandlers) ListObjectsHandler(w http.ResponseWriter, r *http.R
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
-		if s3Error := enforceBucketPolicy("s3:ListBucket", bucket, r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy("s3:ListBucket", bucket, getPathStyleURL(r)); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
-	case authTypeSigned, authTypePresigned:
+	case authTypeSigned, authTypePresigned, authTypeSignedV2, authTypePresignedV2:
 		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
@@ -287,7 +305,7 @@ func (api objectAPIHandlers) ListBucketsHandler(w http.ResponseWriter, r *http.R
 		// For all unknown auth types return error.
 		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
 		return
-	case authTypeSigned, authTypePresigned:
+	case authTypeSigned, authTypePresigned, authTypeSignedV2, authTypePresignedV2:
 		payload, e := ioutil.ReadAll(r.Body)
 		if e != nil {
 			writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
@@ -308,6 +326,10 @@ func (api objectAPIHandlers) ListBucketsHandler(w http.ResponseWriter, r *http.R
 			s3Error = doesSignatureMatch(hex.EncodeToString(sum256(payload)), r, validateRegion)
 		} else if isRequestPresignedSignatureV4(r) {
 			s3Error = doesPresignedSignatureMatch(hex.EncodeToString(sum256(payload)), r, validateRegion)
+		} else if isRequestSignatureV2(r) {
+			s3Error = doesSignatureV2Match(r)
+		} else if isRequestPresignedSignatureV2(r) {
+			s3Error = doesPresignedSignatureV2Match(r)
 		}
 		if s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
@@ -342,11 +364,11 @@ func (api objectAPIHandlers) DeleteMultipleObjectsHandler(w http.ResponseWriter,
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
-		if s3Error := enforceBucketPolicy("s3:DeleteObject", bucket, r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy("s3:DeleteObject", bucket, getPathStyleURL(r)); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
-	case authTypePresigned, authTypeSigned:
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
 		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
@@ -394,6 +416,17 @@ func (api objectAPIHandlers) DeleteMultipleObjectsHandler(w http.ResponseWriter,
 			deletedObjects = append(deletedObjects, ObjectIdentifier{
 				ObjectName: object.ObjectName,
 			})
+			// Notify object removed event.
+			eventNotify(eventData{
+				Type:   ObjectRemovedDelete,
+				Bucket: bucket,
+				ObjInfo: ObjectInfo{
+					Name: object.ObjectName,
+				},
+				ReqParams: map[string]string{
+					"sourceIPAddress": r.RemoteAddr,
+				},
+			})
 		} else {
 			errorIf(err, "Unable to delete object.")
 			deleteErrors = append(deleteErrors, DeleteError{
@@ -425,7 +458,7 @@ func (api objectAPIHandlers) PutBucketHandler(w http.ResponseWriter, r *http.Req
 		// For all unknown auth types return error.
 		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
 		return
-	case authTypePresigned, authTypeSigned:
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
 		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
@@ -503,7 +536,12 @@ func (api objectAPIHandlers) PostPolicyBucketHandler(w http.ResponseWriter, r *h
 	object := formValues["Key"]
 
 	// Verify policy signature.
-	apiErr := doesPolicySignatureMatch(formValues)
+	var apiErr APIErrorCode
+	if isPolicySignatureV2(formValues) {
+		apiErr = doesPolicySignatureV2Match(formValues)
+	} else {
+		apiErr = doesPolicySignatureMatch(formValues)
+	}
 	if apiErr != ErrNone {
 		writeErrorResponse(w, r, apiErr, r.URL.Path)
 		return
@@ -515,9 +553,19 @@ func (api objectAPIHandlers) PostPolicyBucketHandler(w http.ResponseWriter, r *h
 
 	// Save metadata.
 	metadata := make(map[string]string)
-	// Nothing to store right now.
 
-	md5Sum, err := api.ObjectAPI.PutObject(bucket, object, -1, fileBody, metadata)
+	// Encrypt the object if requested by the form or by bucket default.
+	formHeader := make(http.Header)
+	for key, value := range formValues {
+		formHeader.Set(key, value)
+	}
+	objectKey, apiErr := newRequestObjectKey(formHeader, bucket, object, metadata)
+	if apiErr != ErrNone {
+		writeErrorResponse(w, r, apiErr, r.URL.Path)
+		return
+	}
+
+	md5Sum, err := api.ObjectAPI.PutObject(bucket, object, -1, encryptRequestReader(fileBody, objectKey, nil), metadata)
 	if err != nil {
 		errorIf(err, "Unable to create object.")
 		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
@@ -534,6 +582,24 @@ func (api objectAPIHandlers) PostPolicyBucketHandler(w http.ResponseWriter, r *h
 	})
 	setCommonHeaders(w)
 	writeSuccessResponse(w, encodedSuccessResponse)
+
+	if globalEventNotifier.IsBucketNotificationSet(bucket) {
+		// Fetch object info for notifications.
+		objInfo, err := api.ObjectAPI.GetObjectInfo(bucket, object)
+		if err != nil {
+			errorIf(err, "Unable to fetch object info for %s/%s.", bucket, object)
+			return
+		}
+		// Notify object created event.
+		eventNotify(eventData{
+			Type:    ObjectCreatedPost,
+			Bucket:  bucket,
+			ObjInfo: objInfo,
+			ReqParams: map[string]string{
+				"sourceIPAddress": r.RemoteAddr,
+			},
+		})
+	}
 }
 
 // HeadBucketHandler - HEAD Bucket
@@ -553,11 +619,11 @@ func (api objectAPIHandlers) HeadBucketHandler(w http.ResponseWriter, r *http.Re
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
-		if s3Error := enforceBucketPolicy("s3:ListBucket", bucket, r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy("s3:ListBucket", bucket, getPathStyleURL(r)); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
-	case authTypePresigned, authTypeSigned:
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
 		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
@@ -582,7 +648,7 @@ func (api objectAPIHandlers) DeleteBucketHandler(w http.ResponseWriter, r *http.
 		// For all unknown auth types return error.
 		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
 		return
-	case authTypePresigned, authTypeSigned:
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
 		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
@@ -598,6 +664,28 @@ func (api objectAPIHandlers) DeleteBucketHandler(w http.ResponseWriter, r *http.
 	// Delete bucket access policy, if present - ignore any errors.
 	removeBucketPolicy(bucket)
 
+	// Delete bucket versioning configuration, if present - ignore any errors.
+	removeBucketVersioning(bucket)
+
+	// Delete bucket lifecycle configuration, if present - ignore any errors.
+	removeBucketLifecycle(bucket)
+
+	// Delete bucket encryption configuration, if present - ignore any errors.
+	removeBucketEncryption(bucket)
+
+	// Delete bucket tags, if present - ignore any errors.
+	removeBucketTagging(bucket)
+
+	// Delete bucket CORS configuration, if present - ignore any errors.
+	removeBucketCors(bucket)
+
+	// Delete bucket website configuration, if present - ignore any errors.
+	removeBucketWebsite(bucket)
+
+	// Delete bucket notification configuration, if present - ignore any errors.
+	removeBucketNotification(bucket)
+	globalEventNotifier.SetBucketNotificationConfig(bucket, nil)
+
 	// Write success response.
 	writeSuccessNoContent(w)
 }
@@ -0,0 +1,165 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	mux "github.com/gorilla/mux"
+)
+
+// maximum supported lifecycle configuration size.
+const maxLifecycleConfigSize = 20 * 1024 * 1024 // 20MiB.
+
+// PutBucketLifecycleHandler - PUT Bucket lifecycle
+// -----------------
+// This implementation of the PUT operation replaces the lifecycle
+// configuration of an existing bucket.
+func (api objectAPIHandlers) PutBucketLifecycleHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	switch getRequestAuthType(r) {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
+		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	// Verify if bucket exists.
+	if _, err := api.ObjectAPI.GetBucketInfo(bucket); err != nil {
+		errorIf(err, "Unable to fetch bucket info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Read lifecycle configuration, limited to maxLifecycleConfigSize.
+	lifecycleBytes, err := ioutil.ReadAll(io.LimitReader(r.Body, maxLifecycleConfigSize))
+	if err != nil {
+		errorIf(err, "Unable to read lifecycle configuration.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+
+	var lc lifecycleConfiguration
+	if err = xml.Unmarshal(lifecycleBytes, &lc); err != nil {
+		errorIf(err, "Unable to parse lifecycle configuration.")
+		writeErrorResponse(w, r, ErrMalformedXML, r.URL.Path)
+		return
+	}
+	if err = lc.validate(); err != nil {
+		errorIf(err, "Invalid lifecycle configuration.")
+		writeErrorResponse(w, r, ErrMalformedXML, r.URL.Path)
+		return
+	}
+
+	// Save lifecycle configuration.
+	if err = writeBucketLifecycle(bucket, lc); err != nil {
+		errorIf(err, "Unable to write lifecycle configuration.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	writeSuccessResponse(w, nil)
+}
+
+// GetBucketLifecycleHandler - GET Bucket lifecycle
+// -----------------
+// This implementation of the GET operation returns the lifecycle
+// configuration of a bucket.
+func (api objectAPIHandlers) GetBucketLifecycleHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	switch getRequestAuthType(r) {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
+		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	// Verify if bucket exists.
+	if _, err := api.ObjectAPI.GetBucketInfo(bucket); err != nil {
+		errorIf(err, "Unable to fetch bucket info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	lc, err := readBucketLifecycle(bucket)
+	if err != nil {
+		errorIf(err, "Unable to read lifecycle configuration.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	encodedSuccessResponse := encodeResponse(lc)
+	// Write headers
+	setCommonHeaders(w)
+	// Write success response.
+	writeSuccessResponse(w, encodedSuccessResponse)
+}
+
+// DeleteBucketLifecycleHandler - DELETE Bucket lifecycle
+// -----------------
+// This implementation of the DELETE operation removes the lifecycle
+// configuration of a bucket.
+func (api objectAPIHandlers) DeleteBucketLifecycleHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	switch getRequestAuthType(r) {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
+		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	// Verify if bucket exists.
+	if _, err := api.ObjectAPI.GetBucketInfo(bucket); err != nil {
+		errorIf(err, "Unable to fetch bucket info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	if err := removeBucketLifecycle(bucket); err != nil {
+		// Deleting a lifecycle configuration which does not exist
+		// is not an error.
+		if _, ok := err.(BucketLifecycleNotFound); !ok {
+			errorIf(err, "Unable to remove lifecycle configuration.")
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+			return
+		}
+	}
+	writeSuccessNoContent(w)
+}
@@ -0,0 +1,141 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import "time"
+
+// Interval at which lifecycle rules are applied on all buckets.
+const lifecycleInterval = 1 * time.Hour
+
+// treeWalkObjects - object layers which can walk their namespace
+// using the tree walk, implemented by both fsObjects and xlObjects.
+type treeWalkObjects interface {
+	startTreeWalk(bucket, prefix, marker string, recursive bool, isLeaf func(string, string) bool, endWalkCh chan struct{}) chan treeWalkResult
+	isObject(bucket, prefix string) bool
+}
+
+// startLifecycleWorker - applies lifecycle rules of all buckets on
+// start and then at every interval until doneCh is closed.
+func startLifecycleWorker(objAPI ObjectLayer, interval time.Duration, doneCh <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		applyLifecycleRules(objAPI, time.Now().UTC())
+		select {
+		case <-doneCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// applyLifecycleRules - applies enabled lifecycle rules of all
+// buckets as of the given time.
+func applyLifecycleRules(objAPI ObjectLayer, now time.Time) {
+	buckets, err := objAPI.ListBuckets()
+	if err != nil {
+		errorIf(err, "Unable to list buckets.")
+		return
+	}
+	for _, bucket := range buckets {
+		lc, err := readBucketLifecycle(bucket.Name)
+		if err != nil {
+			if _, ok := err.(BucketLifecycleNotFound); !ok {
+				errorIf(err, "Unable to read bucket lifecycle configuration.")
+			}
+			continue
+		}
+		for _, rule := range lc.Rules {
+			if rule.Status != lifecycleStatusEnabled {
+				continue
+			}
+			if rule.Expiration != nil {
+				err = expireObjects(objAPI, bucket.Name, rule.prefix(), *rule.Expiration, now)
+				errorIf(err, "Unable to expire objects of bucket %s.", bucket.Name)
+			}
+			if rule.AbortIncompleteMultipartUpload != nil {
+				days := rule.AbortIncompleteMultipartUpload.DaysAfterInitiation
+				err = abortIncompleteUploads(objAPI, bucket.Name, rule.prefix(), days, now)
+				errorIf(err, "Unable to abort incomplete uploads of bucket %s.", bucket.Name)
+			}
+		}
+	}
+}
+
+// expireObjects - walks all objects under prefix and deletes the
+// ones expired as of the given time.
+func expireObjects(objAPI ObjectLayer, bucket, prefix string, expiration lifecycleExpiration, now time.Time) error {
+	walker, ok := objAPI.(treeWalkObjects)
+	if !ok {
+		return nil
+	}
+	endWalkCh := make(chan struct{})
+	defer close(endWalkCh)
+
+	walkResultCh := walker.startTreeWalk(bucket, prefix, "", true, walker.isObject, endWalkCh)
+	for {
+		walkResult, ok := <-walkResultCh
+		if !ok {
+			return nil
+		}
+		// For any walk error return right away.
+		if walkResult.err != nil {
+			// File not found is a valid case.
+			if walkResult.err == errFileNotFound {
+				return nil
+			}
+			return walkResult.err
+		}
+		objInfo, err := objAPI.GetObjectInfo(bucket, walkResult.entry)
+		if err == nil && expiration.isExpired(objInfo.ModTime, now) {
+			err = objAPI.DeleteObject(bucket, objInfo.Name)
+			errorIf(err, "Unable to delete expired object %s/%s.", bucket, objInfo.Name)
+		}
+		if walkResult.end {
+			return nil
+		}
+	}
+}
+
+// abortIncompleteUploads - aborts all multipart uploads under prefix
+// initiated more than the given days ago.
+func abortIncompleteUploads(objAPI ObjectLayer, bucket, prefix string, days int, now time.Time) error {
+	var expiredUploads []uploadMetadata
+	var keyMarker, uploadIDMarker string
+	for {
+		result, err := objAPI.ListMultipartUploads(bucket, prefix, keyMarker, uploadIDMarker, "", maxUploadsList)
+		if err != nil {
+			return err
+		}
+		for _, upload := range result.Uploads {
+			if !now.Before(lifecycleExpiryTime(upload.Initiated, days)) {
+				expiredUploads = append(expiredUploads, upload)
+			}
+		}
+		if !result.IsTruncated {
+			break
+		}
+		keyMarker, uploadIDMarker = result.NextKeyMarker, result.NextUploadIDMarker
+	}
+	// Abort after listing, since aborting modifies `uploads.json`
+	// the listing is based on.
+	for _, upload := range expiredUploads {
+		err := objAPI.AbortMultipartUpload(bucket, upload.Object, upload.UploadID)
+		errorIf(err, "Unable to abort incomplete upload %s/%s.", bucket, upload.Object)
+	}
+	return nil
+}
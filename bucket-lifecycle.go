@@ -0,0 +1,264 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Supported lifecycle rule states.
+const (
+	lifecycleStatusEnabled  = "Enabled"
+	lifecycleStatusDisabled = "Disabled"
+)
+
+// bucketLifecycleConfigFile - name of the file holding lifecycle
+// configuration under the bucket config path.
+const bucketLifecycleConfigFile = "lifecycle.xml"
+
+// maximum number of rules allowed in a lifecycle configuration.
+const maxLifecycleRules = 1000
+
+// lifecycleConfiguration - represents the lifecycle rules of a bucket.
+type lifecycleConfiguration struct {
+	XMLName xml.Name        `xml:"http://s3.amazonaws.com/doc/2006-03-01/ LifecycleConfiguration" json:"-"`
+	Rules   []lifecycleRule `xml:"Rule"`
+}
+
+// lifecycleRule - represents a single lifecycle rule.
+type lifecycleRule struct {
+	ID     string           `xml:"ID,omitempty"`
+	Prefix string           `xml:"Prefix"`
+	Filter *lifecycleFilter `xml:"Filter,omitempty"`
+	Status string           `xml:"Status"`
+
+	Expiration                     *lifecycleExpiration                     `xml:"Expiration,omitempty"`
+	AbortIncompleteMultipartUpload *lifecycleAbortIncompleteMultipartUpload `xml:"AbortIncompleteMultipartUpload,omitempty"`
+}
+
+// lifecycleFilter - selects the objects a rule applies to.
+type lifecycleFilter struct {
+	Prefix string `xml:"Prefix"`
+}
+
+// lifecycleExpiration - expires objects after a number of days
+// since their last modification or on a given date.
+type lifecycleExpiration struct {
+	Days int    `xml:"Days,omitempty"`
+	Date string `xml:"Date,omitempty"`
+}
+
+// lifecycleAbortIncompleteMultipartUpload - aborts multipart uploads
+// which are not completed within a number of days since initiation.
+type lifecycleAbortIncompleteMultipartUpload struct {
+	DaysAfterInitiation int `xml:"DaysAfterInitiation"`
+}
+
+// Lifecycle configuration errors.
+var (
+	errLifecycleNoRules         = errors.New("Lifecycle configuration should have at least one rule")
+	errLifecycleTooManyRules    = errors.New("Lifecycle configuration allows a maximum of 1000 rules")
+	errLifecycleDuplicateRuleID = errors.New("Lifecycle rule ID must be unique")
+	errLifecycleInvalidStatus   = errors.New("Lifecycle rule status must be either Enabled or Disabled")
+	errLifecycleNoAction        = errors.New("Lifecycle rule should have at least one action")
+	errLifecycleInvalidDays     = errors.New("Lifecycle rule days must be a positive integer")
+	errLifecycleInvalidDate     = errors.New("Lifecycle rule date must be at midnight UTC in ISO 8601 format")
+	errLifecycleDaysAndDate     = errors.New("Lifecycle rule expiration should specify either days or date")
+)
+
+// prefix - returns the object prefix the rule applies to.
+func (rule lifecycleRule) prefix() string {
+	if rule.Filter != nil {
+		return rule.Filter.Prefix
+	}
+	return rule.Prefix
+}
+
+// validate - validates a lifecycle rule.
+func (rule lifecycleRule) validate() error {
+	if rule.Status != lifecycleStatusEnabled && rule.Status != lifecycleStatusDisabled {
+		return errLifecycleInvalidStatus
+	}
+	if rule.Expiration == nil && rule.AbortIncompleteMultipartUpload == nil {
+		return errLifecycleNoAction
+	}
+	if rule.Expiration != nil {
+		if err := rule.Expiration.validate(); err != nil {
+			return err
+		}
+	}
+	if rule.AbortIncompleteMultipartUpload != nil && rule.AbortIncompleteMultipartUpload.DaysAfterInitiation <= 0 {
+		return errLifecycleInvalidDays
+	}
+	return nil
+}
+
+// validate - validates lifecycle expiration, either of days or
+// date should be set.
+func (expiration lifecycleExpiration) validate() error {
+	if expiration.Days != 0 && expiration.Date != "" {
+		return errLifecycleDaysAndDate
+	}
+	if expiration.Date != "" {
+		_, err := expiration.parseDate()
+		return err
+	}
+	if expiration.Days <= 0 {
+		return errLifecycleInvalidDays
+	}
+	return nil
+}
+
+// parseDate - parses expiration date, which is required to be at
+// midnight UTC.
+func (expiration lifecycleExpiration) parseDate() (time.Time, error) {
+	date, err := time.Parse(time.RFC3339, expiration.Date)
+	if err != nil {
+		return time.Time{}, errLifecycleInvalidDate
+	}
+	date = date.UTC()
+	if !date.Equal(date.Truncate(24 * time.Hour)) {
+		return time.Time{}, errLifecycleInvalidDate
+	}
+	return date, nil
+}
+
+// isExpired - returns true if an object last modified at modTime is
+// expired at the given time.
+func (expiration lifecycleExpiration) isExpired(modTime, now time.Time) bool {
+	if expiration.Date != "" {
+		date, err := expiration.parseDate()
+		if err != nil {
+			return false
+		}
+		return !now.Before(date)
+	}
+	return !now.Before(lifecycleExpiryTime(modTime, expiration.Days))
+}
+
+// lifecycleExpiryTime - returns the time of expiry after the given
+// number of days, rounded up to the next midnight UTC.
+func lifecycleExpiryTime(t time.Time, days int) time.Time {
+	return t.UTC().Add(time.Duration(days) * 24 * time.Hour).Truncate(24 * time.Hour).Add(24 * time.Hour)
+}
+
+// validate - validates a lifecycle configuration.
+func (lc lifecycleConfiguration) validate() error {
+	if len(lc.Rules) == 0 {
+		return errLifecycleNoRules
+	}
+	if len(lc.Rules) > maxLifecycleRules {
+		return errLifecycleTooManyRules
+	}
+	ruleIDs := make(map[string]struct{})
+	for _, rule := range lc.Rules {
+		if rule.ID != "" {
+			if _, ok := ruleIDs[rule.ID]; ok {
+				return errLifecycleDuplicateRuleID
+			}
+			ruleIDs[rule.ID] = struct{}{}
+		}
+		if err := rule.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readBucketLifecycle - read bucket lifecycle configuration.
+func readBucketLifecycle(bucket string) (lifecycleConfiguration, error) {
+	// Verify bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return lifecycleConfiguration{}, BucketNameInvalid{Bucket: bucket}
+	}
+
+	bucketConfigPath, err := getBucketConfigPath(bucket)
+	if err != nil {
+		return lifecycleConfiguration{}, err
+	}
+
+	// Get lifecycle file.
+	bucketLifecycleFile := filepath.Join(bucketConfigPath, bucketLifecycleConfigFile)
+	lifecycleBytes, err := ioutil.ReadFile(bucketLifecycleFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lifecycleConfiguration{}, BucketLifecycleNotFound{Bucket: bucket}
+		}
+		return lifecycleConfiguration{}, err
+	}
+
+	var lc lifecycleConfiguration
+	if err = xml.Unmarshal(lifecycleBytes, &lc); err != nil {
+		return lifecycleConfiguration{}, err
+	}
+	return lc, nil
+}
+
+// writeBucketLifecycle - save bucket lifecycle configuration.
+func writeBucketLifecycle(bucket string, lc lifecycleConfiguration) error {
+	// Verify if bucket path legal
+	if !IsValidBucketName(bucket) {
+		return BucketNameInvalid{Bucket: bucket}
+	}
+
+	// Create bucket config path.
+	if err := createBucketConfigPath(bucket); err != nil {
+		return err
+	}
+
+	bucketConfigPath, err := getBucketConfigPath(bucket)
+	if err != nil {
+		return err
+	}
+
+	lifecycleBytes, err := xml.Marshal(lc)
+	if err != nil {
+		return err
+	}
+
+	// Write bucket lifecycle.
+	bucketLifecycleFile := filepath.Join(bucketConfigPath, bucketLifecycleConfigFile)
+	return ioutil.WriteFile(bucketLifecycleFile, lifecycleBytes, 0600)
+}
+
+// removeBucketLifecycle - remove bucket lifecycle configuration.
+func removeBucketLifecycle(bucket string) error {
+	// Verify bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return BucketNameInvalid{Bucket: bucket}
+	}
+
+	bucketConfigPath, err := getBucketConfigPath(bucket)
+	if err != nil {
+		return err
+	}
+
+	// Remove lifecycle file.
+	bucketLifecycleFile := filepath.Join(bucketConfigPath, bucketLifecycleConfigFile)
+	if err = os.Remove(bucketLifecycleFile); err != nil {
+		if os.IsNotExist(err) {
+			return BucketLifecycleNotFound{Bucket: bucket}
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tests validating lifecycle configuration.
+func TestLifecycleConfigurationValidate(t *testing.T) {
+	testCases := []struct {
+		lifecycleXML string
+		expectedErr  error
+	}{
+		// Test case - 1.
+		// Valid expiration in days.
+		{`<LifecycleConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Rule><ID>1</ID><Prefix>logs/</Prefix><Status>Enabled</Status><Expiration><Days>30</Days></Expiration></Rule></LifecycleConfiguration>`, nil},
+		// Test case - 2.
+		// Valid expiration date with a filter.
+		{`<LifecycleConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Rule><Filter><Prefix>logs/</Prefix></Filter><Status>Enabled</Status><Expiration><Date>2016-08-01T00:00:00Z</Date></Expiration></Rule></LifecycleConfiguration>`, nil},
+		// Test case - 3.
+		// Valid abort incomplete multipart upload.
+		{`<LifecycleConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Rule><Prefix></Prefix><Status>Disabled</Status><AbortIncompleteMultipartUpload><DaysAfterInitiation>7</DaysAfterInitiation></AbortIncompleteMultipartUpload></Rule></LifecycleConfiguration>`, nil},
+		// Test case - 4.
+		// No rules.
+		{`<LifecycleConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></LifecycleConfiguration>`, errLifecycleNoRules},
+		// Test case - 5.
+		// Invalid status.
+		{`<LifecycleConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Rule><Status>enabled</Status><Expiration><Days>1</Days></Expiration></Rule></LifecycleConfiguration>`, errLifecycleInvalidStatus},
+		// Test case - 6.
+		// No action.
+		{`<LifecycleConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Rule><Status>Enabled</Status></Rule></LifecycleConfiguration>`, errLifecycleNoAction},
+		// Test case - 7.
+		// Invalid days.
+		{`<LifecycleConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Rule><Status>Enabled</Status><Expiration><Days>-1</Days></Expiration></Rule></LifecycleConfiguration>`, errLifecycleInvalidDays},
+		// Test case - 8.
+		// Date not at midnight.
+		{`<LifecycleConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Rule><Status>Enabled</Status><Expiration><Date>2016-08-01T10:00:00Z</Date></Expiration></Rule></LifecycleConfiguration>`, errLifecycleInvalidDate},
+		// Test case - 9.
+		// Both days and date.
+		{`<LifecycleConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Rule><Status>Enabled</Status><Expiration><Days>1</Days><Date>2016-08-01T00:00:00Z</Date></Expiration></Rule></LifecycleConfiguration>`, errLifecycleDaysAndDate},
+		// Test case - 10.
+		// Duplicate rule ids.
+		{`<LifecycleConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Rule><ID>1</ID><Status>Enabled</Status><Expiration><Days>1</Days></Expiration></Rule><Rule><ID>1</ID><Status>Enabled</Status><Expiration><Days>2</Days></Expiration></Rule></LifecycleConfiguration>`, errLifecycleDuplicateRuleID},
+		// Test case - 11.
+		// Invalid days after initiation.
+		{`<LifecycleConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Rule><Status>Enabled</Status><AbortIncompleteMultipartUpload><DaysAfterInitiation>0</DaysAfterInitiation></AbortIncompleteMultipartUpload></Rule></LifecycleConfiguration>`, errLifecycleInvalidDays},
+	}
+	for i, testCase := range testCases {
+		var lc lifecycleConfiguration
+		if err := xml.Unmarshal([]byte(testCase.lifecycleXML), &lc); err != nil {
+			t.Fatalf("Test %d: Unable to parse lifecycle configuration: %s", i+1, err)
+		}
+		if err := lc.validate(); err != testCase.expectedErr {
+			t.Errorf("Test %d: Expected error %v, got %v", i+1, testCase.expectedErr, err)
+		}
+	}
+}
+
+// Tests lifecycle expiry time calculation.
+func TestLifecycleExpiryTime(t *testing.T) {
+	testCases := []struct {
+		modTime  time.Time
+		days     int
+		expected time.Time
+	}{
+		{time.Date(2016, 8, 1, 10, 30, 0, 0, time.UTC), 1, time.Date(2016, 8, 3, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2016, 8, 1, 23, 59, 59, 0, time.UTC), 30, time.Date(2016, 9, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for i, testCase := range testCases {
+		if expiry := lifecycleExpiryTime(testCase.modTime, testCase.days); !expiry.Equal(testCase.expected) {
+			t.Errorf("Test %d: Expected %s, got %s", i+1, testCase.expected, expiry)
+		}
+	}
+}
+
+// Wrapper for calling lifecycle worker tests for both XL multiple disks and single node setup.
+func TestApplyLifecycleRules(t *testing.T) {
+	rootPath, err := ioutil.TempDir("", "minio-lifecycle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(rootPath)
+	setGlobalConfigPath(rootPath)
+	defer setGlobalConfigPath("")
+
+	ExecObjectLayerTest(t, testApplyLifecycleRules)
+}
+
+// Testing applying lifecycle rules.
+func testApplyLifecycleRules(obj ObjectLayer, instanceType string, t *testing.T) {
+	bucket := "test-lifecycle-" + strings.ToLower(instanceType)
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	for _, object := range []string{"logs/2016/a.log", "logs/b.log", "data/c.dat"} {
+		if _, err := obj.PutObject(bucket, object, int64(len("data")), bytes.NewBufferString("data"), nil); err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+	}
+	for _, object := range []string{"logs/upload", "data/upload"} {
+		if _, err := obj.NewMultipartUpload(bucket, object, nil); err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+	}
+
+	lc := lifecycleConfiguration{
+		Rules: []lifecycleRule{
+			{
+				Prefix:     "logs/",
+				Status:     lifecycleStatusEnabled,
+				Expiration: &lifecycleExpiration{Days: 7},
+				AbortIncompleteMultipartUpload: &lifecycleAbortIncompleteMultipartUpload{
+					DaysAfterInitiation: 1,
+				},
+			},
+			{
+				Prefix:     "data/",
+				Status:     lifecycleStatusDisabled,
+				Expiration: &lifecycleExpiration{Days: 1},
+			},
+		},
+	}
+	if err := writeBucketLifecycle(bucket, lc); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+
+	// Nothing is expired yet.
+	applyLifecycleRules(obj, time.Now().UTC())
+	if _, err := obj.GetObjectInfo(bucket, "logs/b.log"); err != nil {
+		t.Fatalf("%s: Expected object to be present, got %s", instanceType, err)
+	}
+
+	// Objects and uploads under `logs/` expire after 8 days.
+	applyLifecycleRules(obj, time.Now().UTC().Add(8*24*time.Hour))
+	for _, object := range []string{"logs/2016/a.log", "logs/b.log"} {
+		if _, err := obj.GetObjectInfo(bucket, object); err == nil {
+			t.Errorf("%s: Expected object %s to be expired", instanceType, object)
+		}
+	}
+	if _, err := obj.GetObjectInfo(bucket, "data/c.dat"); err != nil {
+		t.Errorf("%s: Expected object data/c.dat to be present, got %s", instanceType, err)
+	}
+	result, err := obj.ListMultipartUploads(bucket, "", "", "", "", maxUploadsList)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if len(result.Uploads) != 1 || result.Uploads[0].Object != "data/upload" {
+		t.Errorf("%s: Expected only data/upload to be pending, got %#v", instanceType, result.Uploads)
+	}
+}
+
+// Tests the lifecycle worker applies rules on start and returns once
+// stopped.
+func TestStartLifecycleWorker(t *testing.T) {
+	rootPath, err := ioutil.TempDir("", "minio-lifecycle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(rootPath)
+	setGlobalConfigPath(rootPath)
+	defer setGlobalConfigPath("")
+
+	obj, fsDir, err := getSingleNodeObjectLayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(fsDir)
+
+	bucket := "test-lifecycle-worker"
+	if err = obj.MakeBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = obj.PutObject(bucket, "logs/a.log", int64(len("data")), bytes.NewBufferString("data"), nil); err != nil {
+		t.Fatal(err)
+	}
+	lc := lifecycleConfiguration{
+		Rules: []lifecycleRule{{
+			Prefix:     "logs/",
+			Status:     lifecycleStatusEnabled,
+			Expiration: &lifecycleExpiration{Date: "2016-01-01T00:00:00Z"},
+		}},
+	}
+	if err = writeBucketLifecycle(bucket, lc); err != nil {
+		t.Fatal(err)
+	}
+
+	doneCh := make(chan struct{})
+	stoppedCh := make(chan struct{})
+	go func() {
+		startLifecycleWorker(obj, time.Hour, doneCh)
+		close(stoppedCh)
+	}()
+	for i := 0; ; i++ {
+		if _, err = obj.GetObjectInfo(bucket, "logs/a.log"); err != nil {
+			break
+		}
+		if i == 100 {
+			t.Fatal("Expected object to be expired on start")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	close(doneCh)
+	select {
+	case <-stoppedCh:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Expected lifecycle worker to return once stopped")
+	}
+}
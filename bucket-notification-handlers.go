@@ -0,0 +1,255 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	mux "github.com/gorilla/mux"
+)
+
+// maximum supported notification configuration size.
+const maxNotificationConfigSize = 1 * 1024 * 1024 // 1MiB.
+
+// GetBucketNotificationHandler - GET Bucket notification
+// -----------------
+// This implementation of the GET operation returns the notification
+// configuration of a bucket, empty if notifications are not configured.
+func (api objectAPIHandlers) GetBucketNotificationHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	switch getRequestAuthType(r) {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
+		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	// Verify if bucket exists.
+	if _, err := api.ObjectAPI.GetBucketInfo(bucket); err != nil {
+		errorIf(err, "Unable to fetch bucket info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	nConfig, err := readBucketNotification(bucket)
+	if err != nil {
+		// Notification not configured is returned as an empty configuration.
+		if _, ok := err.(BucketNotificationNotFound); !ok {
+			errorIf(err, "Unable to read notification configuration.")
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+			return
+		}
+	}
+
+	encodedSuccessResponse := encodeResponse(nConfig)
+	// Write headers
+	setCommonHeaders(w)
+	// Write success response.
+	writeSuccessResponse(w, encodedSuccessResponse)
+}
+
+// PutBucketNotificationHandler - PUT Bucket notification
+// -----------------
+// This implementation of the PUT operation replaces the notification
+// configuration of an existing bucket, an empty configuration disables
+// notifications.
+func (api objectAPIHandlers) PutBucketNotificationHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	switch getRequestAuthType(r) {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
+		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	// Verify if bucket exists.
+	if _, err := api.ObjectAPI.GetBucketInfo(bucket); err != nil {
+		errorIf(err, "Unable to fetch bucket info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Read notification configuration, limited to maxNotificationConfigSize.
+	notificationBytes, err := ioutil.ReadAll(io.LimitReader(r.Body, maxNotificationConfigSize))
+	if err != nil {
+		errorIf(err, "Unable to read notification configuration.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+
+	var nConfig notificationConfig
+	if err = xml.Unmarshal(notificationBytes, &nConfig); err != nil {
+		errorIf(err, "Unable to parse notification configuration.")
+		writeErrorResponse(w, r, ErrMalformedXML, r.URL.Path)
+		return
+	}
+	if s3Error := validateNotificationConfig(nConfig); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	// Empty configuration disables notifications.
+	if len(nConfig.QueueConfigs) == 0 {
+		if err = removeBucketNotification(bucket); err != nil {
+			if _, ok := err.(BucketNotificationNotFound); !ok {
+				errorIf(err, "Unable to remove notification configuration.")
+				writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+				return
+			}
+		}
+		globalEventNotifier.SetBucketNotificationConfig(bucket, nil)
+		writeSuccessResponse(w, nil)
+		return
+	}
+
+	// Save notification configuration.
+	if err = writeBucketNotification(bucket, nConfig); err != nil {
+		errorIf(err, "Unable to write notification configuration.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	globalEventNotifier.SetBucketNotificationConfig(bucket, &nConfig)
+	writeSuccessResponse(w, nil)
+}
+
+// ListenBucketNotificationHandler - GET Bucket listen notification
+// -----------------
+// This implementation of the GET operation streams events of a bucket
+// matching the requested events, prefix and suffix as newline delimited
+// JSON for as long as the client stays connected.
+func (api objectAPIHandlers) ListenBucketNotificationHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	switch getRequestAuthType(r) {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
+		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	events, filterRules := getListenBucketNotificationArgs(r.URL.Query())
+	if s3Error := checkEvents(events); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+	if s3Error := checkFilterRules(filterRules); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	// Verify if bucket exists.
+	if _, err := api.ObjectAPI.GetBucketInfo(bucket); err != nil {
+		errorIf(err, "Unable to fetch bucket info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	listener := newListenerConfig(events, filterRules)
+	globalEventNotifier.AddListener(bucket, listener)
+	defer globalEventNotifier.RemoveListener(bucket, listener)
+
+	// Write headers right away, events follow as they happen.
+	setCommonHeaders(w)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.(http.Flusher).Flush()
+
+	var closeNotifyCh <-chan bool
+	if closeNotifier, ok := w.(http.CloseNotifier); ok {
+		closeNotifyCh = closeNotifier.CloseNotify()
+	}
+
+	// Whitespace keeps the connection alive while there are no events.
+	writer := &streamWriter{w: w}
+	defer writer.close()
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	go sendWhiteSpaceChars(writer, doneCh)
+
+	encoder := json.NewEncoder(writer)
+	for {
+		select {
+		case event := <-listener.eventCh:
+			// Each event is sent as a single line.
+			if err := encoder.Encode(map[string][]notificationEvent{
+				"Records": {event},
+			}); err != nil {
+				return
+			}
+		case <-listener.overflowCh:
+			// Events were missed, the client is to listen again.
+			return
+		case <-closeNotifyCh:
+			return
+		}
+	}
+}
+
+// streamWriter - serializes writes to a streamed response, each write
+// is flushed to the client. Writes fail once closed, the response is
+// not written to after the handler returns.
+type streamWriter struct {
+	mutex  sync.Mutex
+	w      http.ResponseWriter
+	closed bool
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return 0, io.ErrClosedPipe
+	}
+	n, err := s.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	s.w.(http.Flusher).Flush()
+	return n, nil
+}
+
+// close - stops all further writes.
+func (s *streamWriter) close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.closed = true
+}
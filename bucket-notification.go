@@ -0,0 +1,260 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// bucketNotificationConfigFile - name of the file holding notification
+// configuration under the bucket config path.
+const bucketNotificationConfigFile = "notification.xml"
+
+// filterRule - a single prefix or suffix rule of a key filter.
+type filterRule struct {
+	Name  string `xml:"Name"`
+	Value string `xml:"Value"`
+}
+
+// keyFilter - collection of filter rules on object keys.
+type keyFilter struct {
+	FilterRules []filterRule `xml:"FilterRule,omitempty"`
+}
+
+// notificationFilter - filters events by object key.
+type notificationFilter struct {
+	Key keyFilter `xml:"S3Key,omitempty"`
+}
+
+// queueConfig - notification configuration publishing events to a
+// queue target.
+type queueConfig struct {
+	ID       string             `xml:"Id"`
+	Filter   notificationFilter `xml:"Filter"`
+	QueueARN string             `xml:"Queue"`
+	Events   []string           `xml:"Event"`
+}
+
+// topicConfig - notification configuration publishing events to a
+// topic, not supported.
+type topicConfig struct {
+	ID       string `xml:"Id"`
+	TopicARN string `xml:"Topic"`
+}
+
+// lambdaConfig - notification configuration invoking a cloud function,
+// not supported.
+type lambdaConfig struct {
+	ID        string `xml:"Id"`
+	LambdaARN string `xml:"CloudFunction"`
+}
+
+// notificationConfig - represents the notification configuration of a
+// bucket.
+type notificationConfig struct {
+	XMLName       xml.Name       `xml:"http://s3.amazonaws.com/doc/2006-03-01/ NotificationConfiguration" json:"-"`
+	QueueConfigs  []queueConfig  `xml:"QueueConfiguration"`
+	TopicConfigs  []topicConfig  `xml:"TopicConfiguration"`
+	LambdaConfigs []lambdaConfig `xml:"CloudFunctionConfiguration"`
+}
+
+// List of all supported notification events.
+var supportedEventNames = map[string]struct{}{
+	"s3:ObjectCreated:*":                       {},
+	"s3:ObjectCreated:Put":                     {},
+	"s3:ObjectCreated:Post":                    {},
+	"s3:ObjectCreated:Copy":                    {},
+	"s3:ObjectCreated:CompleteMultipartUpload": {},
+	"s3:ObjectRemoved:*":                       {},
+	"s3:ObjectRemoved:Delete":                  {},
+}
+
+// checkEvents - validates all the events of a notification configuration.
+func checkEvents(events []string) APIErrorCode {
+	if len(events) == 0 {
+		return ErrEventNotification
+	}
+	for _, event := range events {
+		if _, ok := supportedEventNames[event]; !ok {
+			return ErrEventNotification
+		}
+	}
+	return ErrNone
+}
+
+// checkFilterRules - validates filter rules, only a single prefix and
+// a single suffix rule are allowed.
+func checkFilterRules(filterRules []filterRule) APIErrorCode {
+	var prefixSet, suffixSet bool
+	for _, rule := range filterRules {
+		switch rule.Name {
+		case "prefix":
+			if prefixSet {
+				return ErrFilterNamePrefix
+			}
+			prefixSet = true
+		case "suffix":
+			if suffixSet {
+				return ErrFilterNameSuffix
+			}
+			suffixSet = true
+		default:
+			return ErrFilterNameInvalid
+		}
+		// Filter rule value cannot exceed 1024 bytes, same as object names.
+		if len(rule.Value) > 1024 || !utf8.ValidString(rule.Value) {
+			return ErrFilterValueInvalid
+		}
+	}
+	return ErrNone
+}
+
+// validateNotificationConfig - validates a notification configuration.
+func validateNotificationConfig(nConfig notificationConfig) APIErrorCode {
+	// Only queue configurations are supported.
+	if len(nConfig.TopicConfigs) > 0 || len(nConfig.LambdaConfigs) > 0 {
+		return ErrNotImplemented
+	}
+	for _, qConfig := range nConfig.QueueConfigs {
+		if s3Error := checkEvents(qConfig.Events); s3Error != ErrNone {
+			return s3Error
+		}
+		if s3Error := checkFilterRules(qConfig.Filter.Key.FilterRules); s3Error != ErrNone {
+			return s3Error
+		}
+		if !isValidQueueARN(qConfig.QueueARN) {
+			return ErrARNNotification
+		}
+	}
+	return ErrNone
+}
+
+// eventMatch - returns true if the event matches any of the configured
+// events, configured events may use a '*' wildcard.
+func eventMatch(event eventName, events []string) bool {
+	eventStr := event.String()
+	for _, configEvent := range events {
+		if configEvent == eventStr {
+			return true
+		}
+		if strings.HasSuffix(configEvent, "*") && strings.HasPrefix(eventStr, strings.TrimSuffix(configEvent, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterMatch - returns true if the object name matches all filter rules.
+func filterMatch(object string, filterRules []filterRule) bool {
+	for _, rule := range filterRules {
+		switch rule.Name {
+		case "prefix":
+			if !strings.HasPrefix(object, rule.Value) {
+				return false
+			}
+		case "suffix":
+			if !strings.HasSuffix(object, rule.Value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// readBucketNotification - read bucket notification configuration.
+func readBucketNotification(bucket string) (notificationConfig, error) {
+	// Verify bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return notificationConfig{}, BucketNameInvalid{Bucket: bucket}
+	}
+
+	bucketConfigPath, err := getBucketConfigPath(bucket)
+	if err != nil {
+		return notificationConfig{}, err
+	}
+
+	// Get notification file.
+	bucketNotificationFile := filepath.Join(bucketConfigPath, bucketNotificationConfigFile)
+	notificationBytes, err := ioutil.ReadFile(bucketNotificationFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return notificationConfig{}, BucketNotificationNotFound{Bucket: bucket}
+		}
+		return notificationConfig{}, err
+	}
+
+	var nConfig notificationConfig
+	if err = xml.Unmarshal(notificationBytes, &nConfig); err != nil {
+		return notificationConfig{}, err
+	}
+	return nConfig, nil
+}
+
+// writeBucketNotification - save bucket notification configuration.
+func writeBucketNotification(bucket string, nConfig notificationConfig) error {
+	// Verify if bucket path legal
+	if !IsValidBucketName(bucket) {
+		return BucketNameInvalid{Bucket: bucket}
+	}
+
+	// Create bucket config path.
+	if err := createBucketConfigPath(bucket); err != nil {
+		return err
+	}
+
+	bucketConfigPath, err := getBucketConfigPath(bucket)
+	if err != nil {
+		return err
+	}
+
+	notificationBytes, err := xml.Marshal(nConfig)
+	if err != nil {
+		return err
+	}
+
+	// Write bucket notification.
+	bucketNotificationFile := filepath.Join(bucketConfigPath, bucketNotificationConfigFile)
+	return ioutil.WriteFile(bucketNotificationFile, notificationBytes, 0600)
+}
+
+// removeBucketNotification - remove bucket notification configuration.
+func removeBucketNotification(bucket string) error {
+	// Verify bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return BucketNameInvalid{Bucket: bucket}
+	}
+
+	bucketConfigPath, err := getBucketConfigPath(bucket)
+	if err != nil {
+		return err
+	}
+
+	// Remove notification file.
+	bucketNotificationFile := filepath.Join(bucketConfigPath, bucketNotificationConfigFile)
+	if err = os.Remove(bucketNotificationFile); err != nil {
+		if os.IsNotExist(err) {
+			return BucketNotificationNotFound{Bucket: bucket}
+		}
+		return err
+	}
+	return nil
+}
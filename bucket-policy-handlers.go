@@ -125,8 +125,29 @@ func bucketPolicyConditionMatch(conditions map[string]string, statement policySt
 	// Supported applicable condition keys for each conditions.
 	// - s3:prefix
 	// - s3:max-keys
+	// - s3:ExistingObjectTag/<key>
+	// - s3:RequestObjectTag/<key>
 	var conditionMatches = true
 	for condition, conditionKeys := range statement.Conditions {
+		// Tag condition keys are matched only against the tags, other
+		// keys are not required to be absent if only tags are set.
+		onlyTagKeys := true
+		for key, value := range conditionKeys {
+			if !isTagConditionKey(key) {
+				onlyTagKeys = false
+				continue
+			}
+			tagValue, ok := conditions[strings.TrimPrefix(key, "s3:")]
+			if condition == "StringEquals" && (!ok || tagValue != value) {
+				return false
+			}
+			if condition == "StringNotEquals" && ok && tagValue == value {
+				return false
+			}
+		}
+		if onlyTagKeys && len(conditionKeys) > 0 {
+			continue
+		}
 		if condition == "StringEquals" {
 			if conditionKeys["s3:prefix"] != conditions["prefix"] {
 				conditionMatches = false
@@ -163,7 +184,7 @@ func (api objectAPIHandlers) PutBucketPolicyHandler(w http.ResponseWriter, r *ht
 		// For all unknown auth types return error.
 		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
 		return
-	case authTypePresigned, authTypeSigned:
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
 		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
@@ -236,7 +257,7 @@ func (api objectAPIHandlers) DeleteBucketPolicyHandler(w http.ResponseWriter, r
 		// For all unknown auth types return error.
 		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
 		return
-	case authTypePresigned, authTypeSigned:
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
 		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
@@ -272,7 +293,7 @@ func (api objectAPIHandlers) GetBucketPolicyHandler(w http.ResponseWriter, r *ht
 		// For all unknown auth types return error.
 		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
 		return
-	case authTypePresigned, authTypeSigned:
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
 		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
@@ -36,12 +36,16 @@ const (
 var supportedActionMap = map[string]struct{}{
 	"s3:GetObject":                  {},
 	"s3:ListBucket":                 {},
+	"s3:ListBucketVersions":         {},
 	"s3:PutObject":                  {},
 	"s3:GetBucketLocation":          {},
 	"s3:DeleteObject":               {},
 	"s3:AbortMultipartUpload":       {},
 	"s3:ListBucketMultipartUploads": {},
 	"s3:ListMultipartUploadParts":   {},
+	"s3:GetObjectTagging":           {},
+	"s3:PutObjectTagging":           {},
+	"s3:DeleteObjectTagging":        {},
 }
 
 // supported Conditions type.
@@ -57,6 +61,19 @@ var supportedConditionsKey = map[string]struct{}{
 	"s3:max-keys": {},
 }
 
+// Prefixes of condition keys on object tags, followed by the tag key.
+const (
+	existingObjectTagConditionPrefix = "s3:ExistingObjectTag/"
+	requestObjectTagConditionPrefix  = "s3:RequestObjectTag/"
+)
+
+// isTagConditionKey - returns true if the condition key is on an
+// object tag.
+func isTagConditionKey(key string) bool {
+	return (strings.HasPrefix(key, existingObjectTagConditionPrefix) && key != existingObjectTagConditionPrefix) ||
+		(strings.HasPrefix(key, requestObjectTagConditionPrefix) && key != requestObjectTagConditionPrefix)
+}
+
 // User - canonical users list.
 type policyUser struct {
 	AWS []string
@@ -177,7 +194,7 @@ func isValidConditions(conditions map[string]map[string]string) (err error) {
 		}
 		for key := range conditions[conditionType] {
 			_, validKey := supportedConditionsKey[key]
-			if !validKey {
+			if !validKey && !isTagConditionKey(key) {
 				err = fmt.Errorf("Unsupported condition key '%s', please validate your policy document.", conditionType)
 				return err
 			}
@@ -196,6 +213,7 @@ func isValidConditions(conditions map[string]map[string]string) (err error) {
 var invalidPrefixActions = map[string]struct{}{
 	"s3:GetBucketLocation":          {},
 	"s3:ListBucket":                 {},
+	"s3:ListBucketVersions":         {},
 	"s3:ListBucketMultipartUploads": {},
 	// Add actions which do not honor prefixes.
 }
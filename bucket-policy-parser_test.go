@@ -376,6 +376,9 @@ func TestIsValidConditions(t *testing.T) {
 		generateConditions("StringEquals", "s3:max-keys", "100"),
 		generateConditions("StringNotEquals", "s3:prefix", "Asia/"),
 		generateConditions("StringNotEquals", "s3:max-keys", "100"),
+		generateConditions("StringEquals", "s3:ExistingObjectTag/class", "public"),
+		generateConditions("StringNotEquals", "s3:RequestObjectTag/class", "secret"),
+		generateConditions("StringEquals", "s3:ExistingObjectTag/", "public"),
 	}
 
 	testCases := []struct {
@@ -421,6 +424,15 @@ func TestIsValidConditions(t *testing.T) {
 		{testConditions[10], nil, true},
 		// Test case 10.
 		{testConditions[11], nil, true},
+		// Test case - 13.
+		// Test cases with conditions on object tags.
+		{testConditions[12], nil, true},
+		// Test case - 14.
+		{testConditions[13], nil, true},
+		// Test case - 15.
+		// Tag condition keys require the tag key.
+		{testConditions[14], fmt.Errorf("Unsupported condition key 'StringEquals', " +
+			"please validate your policy document."), false},
 	}
 	for i, testCase := range testCases {
 		actualErr := isValidConditions(testCase.inputCondition)
@@ -0,0 +1,162 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	mux "github.com/gorilla/mux"
+)
+
+// PutBucketTaggingHandler - PUT Bucket tagging
+// -----------------
+// This implementation of the PUT operation replaces the tags of an
+// existing bucket.
+func (api objectAPIHandlers) PutBucketTaggingHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	switch getRequestAuthType(r) {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
+		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	// Verify if bucket exists.
+	if _, err := api.ObjectAPI.GetBucketInfo(bucket); err != nil {
+		errorIf(err, "Unable to fetch bucket info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Read tagging request, limited to maxTaggingSize.
+	taggingBytes, err := ioutil.ReadAll(io.LimitReader(r.Body, maxTaggingSize))
+	if err != nil {
+		errorIf(err, "Unable to read tagging request.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+
+	var tagging Tagging
+	if err = xml.Unmarshal(taggingBytes, &tagging); err != nil {
+		errorIf(err, "Unable to parse tagging request.")
+		writeErrorResponse(w, r, ErrMalformedXML, r.URL.Path)
+		return
+	}
+	tags, err := tagging.toTags(maxBucketTags)
+	if err != nil {
+		errorIf(err, "Invalid bucket tags.")
+		writeErrorResponse(w, r, ErrInvalidTag, r.URL.Path)
+		return
+	}
+
+	// Save bucket tags, sorted by key.
+	if err = writeBucketTagging(bucket, newTagging(tags)); err != nil {
+		errorIf(err, "Unable to write bucket tags.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	writeSuccessNoContent(w)
+}
+
+// GetBucketTaggingHandler - GET Bucket tagging
+// -----------------
+// This implementation of the GET operation returns the tags of a
+// bucket.
+func (api objectAPIHandlers) GetBucketTaggingHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	switch getRequestAuthType(r) {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
+		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	// Verify if bucket exists.
+	if _, err := api.ObjectAPI.GetBucketInfo(bucket); err != nil {
+		errorIf(err, "Unable to fetch bucket info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	tagging, err := readBucketTagging(bucket)
+	if err != nil {
+		errorIf(err, "Unable to read bucket tags.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	encodedSuccessResponse := encodeResponse(tagging)
+	// Write headers
+	setCommonHeaders(w)
+	// Write success response.
+	writeSuccessResponse(w, encodedSuccessResponse)
+}
+
+// DeleteBucketTaggingHandler - DELETE Bucket tagging
+// -----------------
+// This implementation of the DELETE operation removes all the tags of
+// a bucket.
+func (api objectAPIHandlers) DeleteBucketTaggingHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	switch getRequestAuthType(r) {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
+		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	// Verify if bucket exists.
+	if _, err := api.ObjectAPI.GetBucketInfo(bucket); err != nil {
+		errorIf(err, "Unable to fetch bucket info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	if err := removeBucketTagging(bucket); err != nil {
+		// Deleting tags which do not exist is not an error.
+		if _, ok := err.(BucketTaggingNotFound); !ok {
+			errorIf(err, "Unable to remove bucket tags.")
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+			return
+		}
+	}
+	writeSuccessNoContent(w)
+}
@@ -0,0 +1,107 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// bucketTaggingConfigFile - name of the file holding the tags of a
+// bucket under the bucket config path.
+const bucketTaggingConfigFile = "tagging.xml"
+
+// readBucketTagging - read bucket tags.
+func readBucketTagging(bucket string) (Tagging, error) {
+	// Verify bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return Tagging{}, BucketNameInvalid{Bucket: bucket}
+	}
+
+	bucketConfigPath, err := getBucketConfigPath(bucket)
+	if err != nil {
+		return Tagging{}, err
+	}
+
+	// Get tagging file.
+	bucketTaggingFile := filepath.Join(bucketConfigPath, bucketTaggingConfigFile)
+	taggingBytes, err := ioutil.ReadFile(bucketTaggingFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Tagging{}, BucketTaggingNotFound{Bucket: bucket}
+		}
+		return Tagging{}, err
+	}
+
+	var tagging Tagging
+	if err = xml.Unmarshal(taggingBytes, &tagging); err != nil {
+		return Tagging{}, err
+	}
+	return tagging, nil
+}
+
+// writeBucketTagging - save bucket tags.
+func writeBucketTagging(bucket string, tagging Tagging) error {
+	// Verify if bucket path legal
+	if !IsValidBucketName(bucket) {
+		return BucketNameInvalid{Bucket: bucket}
+	}
+
+	// Create bucket config path.
+	if err := createBucketConfigPath(bucket); err != nil {
+		return err
+	}
+
+	bucketConfigPath, err := getBucketConfigPath(bucket)
+	if err != nil {
+		return err
+	}
+
+	taggingBytes, err := xml.Marshal(tagging)
+	if err != nil {
+		return err
+	}
+
+	// Write bucket tagging.
+	bucketTaggingFile := filepath.Join(bucketConfigPath, bucketTaggingConfigFile)
+	return ioutil.WriteFile(bucketTaggingFile, taggingBytes, 0600)
+}
+
+// removeBucketTagging - remove bucket tags.
+func removeBucketTagging(bucket string) error {
+	// Verify bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return BucketNameInvalid{Bucket: bucket}
+	}
+
+	bucketConfigPath, err := getBucketConfigPath(bucket)
+	if err != nil {
+		return err
+	}
+
+	// Remove tagging file.
+	bucketTaggingFile := filepath.Join(bucketConfigPath, bucketTaggingConfigFile)
+	if err = os.Remove(bucketTaggingFile); err != nil {
+		if os.IsNotExist(err) {
+			return BucketTaggingNotFound{Bucket: bucket}
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// Tests bucket tagging operations.
+func TestBucketTaggingHandlers(t *testing.T) {
+	for _, instanceType := range []string{"FS", "XL"} {
+		testBucketTaggingHandlers(instanceType, t)
+	}
+}
+
+func testBucketTaggingHandlers(instanceType string, t *testing.T) {
+	testServer := StartTestServer(t, instanceType)
+	defer testServer.Stop()
+
+	client := http.Client{}
+	expectStatus := func(method, urlStr string, body []byte, status int) []byte {
+		req, err := newTestRequest(method, urlStr, int64(len(body)), bytes.NewReader(body), testServer.AccessKey, testServer.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		respBody, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != status {
+			t.Fatalf("%s: %s %s: Expected status %d, got %d: %s", instanceType, method, urlStr, status, resp.StatusCode, respBody)
+		}
+		return respBody
+	}
+	taggingBody := func(tags ...string) []byte {
+		var buf bytes.Buffer
+		buf.WriteString(`<Tagging xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><TagSet>`)
+		for _, t := range tags {
+			kv := strings.SplitN(t, "=", 2)
+			fmt.Fprintf(&buf, "<Tag><Key>%s</Key><Value>%s</Value></Tag>", kv[0], kv[1])
+		}
+		buf.WriteString(`</TagSet></Tagging>`)
+		return buf.Bytes()
+	}
+
+	bucketURL := testServer.Server.URL + "/tagging-bucket"
+	taggingURL := bucketURL + "?tagging"
+	expectStatus("PUT", bucketURL, nil, http.StatusOK)
+	expectStatus("GET", taggingURL, nil, http.StatusNotFound)
+	expectStatus("GET", testServer.Server.URL+"/missing-bucket?tagging", nil, http.StatusNotFound)
+
+	expectStatus("PUT", taggingURL, taggingBody("owner=storage", "cost-center=1234"), http.StatusNoContent)
+	var tagging Tagging
+	if err := xml.Unmarshal(expectStatus("GET", taggingURL, nil, http.StatusOK), &tagging); err != nil {
+		t.Fatal(err)
+	}
+	var tags []string
+	for _, tag := range tagging.TagSet.Tags {
+		tags = append(tags, tag.Key+"="+tag.Value)
+	}
+	if strings.Join(tags, "&") != "cost-center=1234&owner=storage" {
+		t.Fatalf("%s: Unexpected bucket tags %v", instanceType, tags)
+	}
+	if webTags := getWebBucketTags("tagging-bucket"); webTags["owner"] != "storage" || len(webTags) != 2 {
+		t.Fatalf("%s: Unexpected web bucket tags %v", instanceType, webTags)
+	}
+
+	// Invalid tag sets are rejected.
+	var manyTags []string
+	for i := 0; i <= maxBucketTags; i++ {
+		manyTags = append(manyTags, fmt.Sprintf("k%d=v", i))
+	}
+	expectStatus("PUT", taggingURL, taggingBody(manyTags[:maxBucketTags]...), http.StatusNoContent)
+	expectStatus("PUT", taggingURL, taggingBody(manyTags...), http.StatusBadRequest)
+	expectStatus("PUT", taggingURL, taggingBody("a=1", "a=2"), http.StatusBadRequest)
+	expectStatus("PUT", taggingURL, taggingBody("=1"), http.StatusBadRequest)
+	expectStatus("PUT", taggingURL, []byte("<Tagging>"), http.StatusBadRequest)
+
+	// Tags are removed.
+	expectStatus("DELETE", taggingURL, nil, http.StatusNoContent)
+	expectStatus("GET", taggingURL, nil, http.StatusNotFound)
+	expectStatus("DELETE", taggingURL, nil, http.StatusNoContent)
+
+	// Tags are removed with the bucket.
+	expectStatus("PUT", taggingURL, taggingBody("owner=storage"), http.StatusNoContent)
+	expectStatus("DELETE", bucketURL, nil, http.StatusNoContent)
+	expectStatus("PUT", bucketURL, nil, http.StatusOK)
+	expectStatus("GET", taggingURL, nil, http.StatusNotFound)
+}
@@ -0,0 +1,186 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	mux "github.com/gorilla/mux"
+)
+
+// maximum supported versioning configuration size.
+const maxVersioningConfigSize = 1 * 1024 * 1024 // 1MiB.
+
+// PutBucketVersioningHandler - PUT Bucket versioning
+// -----------------
+// This implementation of the PUT operation sets the versioning state
+// of an existing bucket to either Enabled or Suspended.
+func (api objectAPIHandlers) PutBucketVersioningHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	switch getRequestAuthType(r) {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
+		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	// Verify if bucket exists.
+	if _, err := api.ObjectAPI.GetBucketInfo(bucket); err != nil {
+		errorIf(err, "Unable to fetch bucket info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	// Read versioning configuration, limited to maxVersioningConfigSize.
+	versioningBytes, err := ioutil.ReadAll(io.LimitReader(r.Body, maxVersioningConfigSize))
+	if err != nil {
+		errorIf(err, "Unable to read versioning configuration.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+
+	var versioningConfig versioningConfiguration
+	if err = xml.Unmarshal(versioningBytes, &versioningConfig); err != nil {
+		errorIf(err, "Unable to parse versioning configuration.")
+		writeErrorResponse(w, r, ErrMalformedXML, r.URL.Path)
+		return
+	}
+
+	// Versioning cannot be turned off once configured, only suspended.
+	if !isValidVersioningStatus(versioningConfig.Status) {
+		writeErrorResponse(w, r, ErrIllegalVersioningConfiguration, r.URL.Path)
+		return
+	}
+
+	// Save versioning configuration.
+	if err = writeBucketVersioning(bucket, versioningConfig); err != nil {
+		errorIf(err, "Unable to write versioning configuration.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	writeSuccessResponse(w, nil)
+}
+
+// GetBucketVersioningHandler - GET Bucket versioning
+// -----------------
+// This implementation of the GET operation returns the versioning state
+// of a bucket, the status is omitted if versioning was never configured.
+func (api objectAPIHandlers) GetBucketVersioningHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	switch getRequestAuthType(r) {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
+		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	// Verify if bucket exists.
+	if _, err := api.ObjectAPI.GetBucketInfo(bucket); err != nil {
+		errorIf(err, "Unable to fetch bucket info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	versioningConfig, err := readBucketVersioning(bucket)
+	if err != nil {
+		errorIf(err, "Unable to read versioning configuration.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	encodedSuccessResponse := encodeResponse(versioningConfig)
+	// Write headers
+	setCommonHeaders(w)
+	// Write success response.
+	writeSuccessResponse(w, encodedSuccessResponse)
+}
+
+// ListObjectVersionsHandler - GET Bucket versions
+// -----------------
+// This implementation of the GET operation returns metadata about all
+// of the versions of objects in a bucket, including delete markers.
+func (api objectAPIHandlers) ListObjectVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	switch getRequestAuthType(r) {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypeAnonymous:
+		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
+		if s3Error := enforceBucketPolicy("s3:ListBucketVersions", bucket, getPathStyleURL(r)); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	case authTypeSigned, authTypePresigned, authTypeSignedV2, authTypePresignedV2:
+		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	// TODO handle encoding type.
+	prefix, keyMarker, versionIDMarker, delimiter, maxkeys, _ := getListObjectVersionsArgs(r.URL.Query())
+	if maxkeys < 0 {
+		writeErrorResponse(w, r, ErrInvalidMaxKeys, r.URL.Path)
+		return
+	}
+	// Verify if delimiter is anything other than '/', which we do not support.
+	if delimiter != "" && delimiter != "/" {
+		writeErrorResponse(w, r, ErrNotImplemented, r.URL.Path)
+		return
+	}
+	// Key marker not common with prefix is not implemented.
+	if keyMarker != "" && !strings.HasPrefix(keyMarker, prefix) {
+		writeErrorResponse(w, r, ErrNotImplemented, r.URL.Path)
+		return
+	}
+
+	listVersionsInfo, err := api.ObjectAPI.ListObjectVersions(bucket, prefix, keyMarker, versionIDMarker, delimiter, maxkeys)
+	if err != nil {
+		errorIf(err, "Unable to list object versions.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	response := generateListVersionsResponse(bucket, prefix, keyMarker, versionIDMarker, delimiter, maxkeys, listVersionsInfo)
+	encodedSuccessResponse := encodeResponse(response)
+	// Write headers
+	setCommonHeaders(w)
+	// Write success response.
+	writeSuccessResponse(w, encodedSuccessResponse)
+}
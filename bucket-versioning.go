@@ -0,0 +1,149 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Supported bucket versioning states.
+const (
+	versioningEnabled   = "Enabled"
+	versioningSuspended = "Suspended"
+)
+
+// bucketVersioningConfigFile - name of the file holding versioning
+// configuration under the bucket config path.
+const bucketVersioningConfigFile = "versioning.xml"
+
+// versioningConfiguration - represents the versioning state of a bucket.
+type versioningConfiguration struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ VersioningConfiguration" json:"-"`
+	Status  string   `xml:"Status,omitempty"`
+}
+
+// isValidVersioningStatus - validates if the input status is supported.
+func isValidVersioningStatus(status string) bool {
+	return status == versioningEnabled || status == versioningSuspended
+}
+
+// readBucketVersioning - read bucket versioning configuration.
+func readBucketVersioning(bucket string) (versioningConfiguration, error) {
+	// Verify bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return versioningConfiguration{}, BucketNameInvalid{Bucket: bucket}
+	}
+
+	bucketConfigPath, err := getBucketConfigPath(bucket)
+	if err != nil {
+		return versioningConfiguration{}, err
+	}
+
+	// Get versioning file, versioning is never configured if not found.
+	bucketVersioningFile := filepath.Join(bucketConfigPath, bucketVersioningConfigFile)
+	versioningBytes, err := ioutil.ReadFile(bucketVersioningFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return versioningConfiguration{}, nil
+		}
+		return versioningConfiguration{}, err
+	}
+
+	var versioningConfig versioningConfiguration
+	if err = xml.Unmarshal(versioningBytes, &versioningConfig); err != nil {
+		return versioningConfiguration{}, err
+	}
+	return versioningConfig, nil
+}
+
+// writeBucketVersioning - save bucket versioning configuration.
+func writeBucketVersioning(bucket string, versioningConfig versioningConfiguration) error {
+	// Verify if bucket path legal
+	if !IsValidBucketName(bucket) {
+		return BucketNameInvalid{Bucket: bucket}
+	}
+
+	// Create bucket config path.
+	if err := createBucketConfigPath(bucket); err != nil {
+		return err
+	}
+
+	bucketConfigPath, err := getBucketConfigPath(bucket)
+	if err != nil {
+		return err
+	}
+
+	versioningBytes, err := xml.Marshal(versioningConfig)
+	if err != nil {
+		return err
+	}
+
+	// Write bucket versioning.
+	bucketVersioningFile := filepath.Join(bucketConfigPath, bucketVersioningConfigFile)
+	return ioutil.WriteFile(bucketVersioningFile, versioningBytes, 0600)
+}
+
+// removeBucketVersioning - remove bucket versioning configuration.
+func removeBucketVersioning(bucket string) error {
+	// Verify bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return BucketNameInvalid{Bucket: bucket}
+	}
+
+	bucketConfigPath, err := getBucketConfigPath(bucket)
+	if err != nil {
+		return err
+	}
+
+	// Remove versioning file, ignore if it was never configured.
+	bucketVersioningFile := filepath.Join(bucketConfigPath, bucketVersioningConfigFile)
+	if err = os.Remove(bucketVersioningFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// isBucketVersioned - returns true if versioning was ever configured
+// on the bucket, objects written to it get a version id.
+func isBucketVersioned(bucket string) (bool, error) {
+	versioningConfig, err := readBucketVersioning(bucket)
+	if err != nil {
+		return false, err
+	}
+	return versioningConfig.Status != "", nil
+}
+
+// newObjectVersionID - returns the version id to be assigned to a
+// newly written object or delete marker in the bucket. Returns an
+// empty version id if versioning was never configured on the bucket,
+// "null" if versioning is suspended and a unique id otherwise.
+func newObjectVersionID(bucket string) (string, error) {
+	versioningConfig, err := readBucketVersioning(bucket)
+	if err != nil {
+		return "", err
+	}
+	switch versioningConfig.Status {
+	case versioningEnabled:
+		return getUUID(), nil
+	case versioningSuspended:
+		return nullVersionID, nil
+	}
+	return "", nil
+}
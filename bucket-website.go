@@ -0,0 +1,303 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// bucketWebsiteConfigFile - name of the file holding website
+// configuration under the bucket config path.
+const bucketWebsiteConfigFile = "website.xml"
+
+// maximum number of routing rules allowed in a website configuration.
+const maxWebsiteRoutingRules = 50
+
+// websiteConfiguration - represents the website configuration of a
+// bucket, either all requests are redirected to another host or
+// objects are served with an index document, an optional error
+// document and routing rules.
+type websiteConfiguration struct {
+	XMLName               xml.Name              `xml:"http://s3.amazonaws.com/doc/2006-03-01/ WebsiteConfiguration" json:"-"`
+	RedirectAllRequestsTo *websiteRedirectAll   `xml:"RedirectAllRequestsTo,omitempty"`
+	IndexDocument         *websiteIndexDocument `xml:"IndexDocument,omitempty"`
+	ErrorDocument         *websiteErrorDocument `xml:"ErrorDocument,omitempty"`
+	RoutingRules          []websiteRoutingRule  `xml:"RoutingRules>RoutingRule,omitempty"`
+}
+
+// websiteRedirectAll - redirects all requests to another host.
+type websiteRedirectAll struct {
+	HostName string `xml:"HostName"`
+	Protocol string `xml:"Protocol,omitempty"`
+}
+
+// websiteIndexDocument - suffix appended to directory-style keys.
+type websiteIndexDocument struct {
+	Suffix string `xml:"Suffix"`
+}
+
+// websiteErrorDocument - object served on errors.
+type websiteErrorDocument struct {
+	Key string `xml:"Key"`
+}
+
+// websiteRoutingRule - redirects requests matching the condition.
+type websiteRoutingRule struct {
+	Condition *websiteCondition `xml:"Condition,omitempty"`
+	Redirect  websiteRedirect   `xml:"Redirect"`
+}
+
+// websiteCondition - matches requests by key prefix and by the error
+// returned, rules without an error code are applied before serving.
+type websiteCondition struct {
+	KeyPrefixEquals             string `xml:"KeyPrefixEquals,omitempty"`
+	HTTPErrorCodeReturnedEquals int    `xml:"HttpErrorCodeReturnedEquals,omitempty"`
+}
+
+// websiteRedirect - location requests are redirected to, host name
+// and protocol default to those of the request.
+type websiteRedirect struct {
+	Protocol             string `xml:"Protocol,omitempty"`
+	HostName             string `xml:"HostName,omitempty"`
+	ReplaceKeyPrefixWith string `xml:"ReplaceKeyPrefixWith,omitempty"`
+	ReplaceKeyWith       string `xml:"ReplaceKeyWith,omitempty"`
+	HTTPRedirectCode     int    `xml:"HttpRedirectCode,omitempty"`
+}
+
+// Website configuration errors.
+var (
+	errWebsiteNoIndexDocument     = errors.New("Website configuration should have an index document or redirect all requests")
+	errWebsiteRedirectAllRequests = errors.New("Website configuration redirecting all requests cannot have other settings")
+	errWebsiteInvalidSuffix       = errors.New("Index document suffix must not be empty or contain a slash")
+	errWebsiteInvalidErrorKey     = errors.New("Error document key must not be empty")
+	errWebsiteNoHostName          = errors.New("Website redirect should have a host name")
+	errWebsiteInvalidProtocol     = errors.New("Website redirect protocol must be either http or https")
+	errWebsiteTooManyRules        = errors.New("Website configuration allows a maximum of 50 routing rules")
+	errWebsiteInvalidReplaceKey   = errors.New("Website redirect cannot replace both key and key prefix")
+	errWebsiteInvalidRedirectCode = errors.New("Website redirect code must be a 3XX status code")
+	errWebsiteInvalidErrorCode    = errors.New("Website condition error code must be a 4XX or 5XX status code")
+)
+
+// isValidWebsiteProtocol - returns true for empty, http and https
+// protocols.
+func isValidWebsiteProtocol(protocol string) bool {
+	return protocol == "" || protocol == "http" || protocol == "https"
+}
+
+// validate - validates a routing rule.
+func (rule websiteRoutingRule) validate() error {
+	if rule.Condition != nil && rule.Condition.HTTPErrorCodeReturnedEquals != 0 {
+		if code := rule.Condition.HTTPErrorCodeReturnedEquals; code < 400 || code > 599 {
+			return errWebsiteInvalidErrorCode
+		}
+	}
+	redirect := rule.Redirect
+	if !isValidWebsiteProtocol(redirect.Protocol) {
+		return errWebsiteInvalidProtocol
+	}
+	if redirect.ReplaceKeyPrefixWith != "" && redirect.ReplaceKeyWith != "" {
+		return errWebsiteInvalidReplaceKey
+	}
+	if redirect.HTTPRedirectCode != 0 && (redirect.HTTPRedirectCode < 300 || redirect.HTTPRedirectCode > 399) {
+		return errWebsiteInvalidRedirectCode
+	}
+	return nil
+}
+
+// matches - returns true if the rule applies to the key, errorCode
+// is 0 before the object is served.
+func (rule websiteRoutingRule) matches(key string, errorCode int) bool {
+	if rule.Condition == nil {
+		return errorCode == 0
+	}
+	return strings.HasPrefix(key, rule.Condition.KeyPrefixEquals) && rule.Condition.HTTPErrorCodeReturnedEquals == errorCode
+}
+
+// location - returns the location and status code of the redirect
+// for the key, pathPrefix is prepended to keys redirected to the
+// host of the request.
+func (rule websiteRoutingRule) location(r *http.Request, pathPrefix, key string) (string, int) {
+	redirect := rule.Redirect
+	switch {
+	case redirect.ReplaceKeyWith != "":
+		key = redirect.ReplaceKeyWith
+	case redirect.ReplaceKeyPrefixWith != "":
+		prefix := ""
+		if rule.Condition != nil {
+			prefix = rule.Condition.KeyPrefixEquals
+		}
+		key = redirect.ReplaceKeyPrefixWith + strings.TrimPrefix(key, prefix)
+	}
+	hostName := redirect.HostName
+	if hostName == "" {
+		hostName = r.Host
+	} else {
+		pathPrefix = "/"
+	}
+	code := redirect.HTTPRedirectCode
+	if code == 0 {
+		code = http.StatusMovedPermanently
+	}
+	return websiteProtocol(r, redirect.Protocol) + "://" + hostName + pathPrefix + key, code
+}
+
+// location - returns the location all requests for the key are
+// redirected to.
+func (redirect websiteRedirectAll) location(r *http.Request, key string) string {
+	return websiteProtocol(r, redirect.Protocol) + "://" + redirect.HostName + "/" + key
+}
+
+// websiteProtocol - returns the protocol of a redirect, defaulting to
+// the protocol of the request.
+func websiteProtocol(r *http.Request, protocol string) string {
+	if protocol != "" {
+		return protocol
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// validate - validates a website configuration.
+func (wc websiteConfiguration) validate() error {
+	if wc.RedirectAllRequestsTo != nil {
+		if wc.IndexDocument != nil || wc.ErrorDocument != nil || len(wc.RoutingRules) > 0 {
+			return errWebsiteRedirectAllRequests
+		}
+		if wc.RedirectAllRequestsTo.HostName == "" {
+			return errWebsiteNoHostName
+		}
+		if !isValidWebsiteProtocol(wc.RedirectAllRequestsTo.Protocol) {
+			return errWebsiteInvalidProtocol
+		}
+		return nil
+	}
+	if wc.IndexDocument == nil {
+		return errWebsiteNoIndexDocument
+	}
+	if suffix := wc.IndexDocument.Suffix; suffix == "" || strings.Contains(suffix, "/") {
+		return errWebsiteInvalidSuffix
+	}
+	if wc.ErrorDocument != nil && wc.ErrorDocument.Key == "" {
+		return errWebsiteInvalidErrorKey
+	}
+	if len(wc.RoutingRules) > maxWebsiteRoutingRules {
+		return errWebsiteTooManyRules
+	}
+	for _, rule := range wc.RoutingRules {
+		if err := rule.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// routingRule - returns the first routing rule applying to the key.
+func (wc websiteConfiguration) routingRule(key string, errorCode int) (websiteRoutingRule, bool) {
+	for _, rule := range wc.RoutingRules {
+		if rule.matches(key, errorCode) {
+			return rule, true
+		}
+	}
+	return websiteRoutingRule{}, false
+}
+
+// readBucketWebsite - read bucket website configuration.
+func readBucketWebsite(bucket string) (websiteConfiguration, error) {
+	// Verify bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return websiteConfiguration{}, BucketNameInvalid{Bucket: bucket}
+	}
+
+	bucketConfigPath, err := getBucketConfigPath(bucket)
+	if err != nil {
+		return websiteConfiguration{}, err
+	}
+
+	// Get website file.
+	bucketWebsiteFile := filepath.Join(bucketConfigPath, bucketWebsiteConfigFile)
+	websiteBytes, err := ioutil.ReadFile(bucketWebsiteFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return websiteConfiguration{}, BucketWebsiteNotFound{Bucket: bucket}
+		}
+		return websiteConfiguration{}, err
+	}
+
+	var wc websiteConfiguration
+	if err = xml.Unmarshal(websiteBytes, &wc); err != nil {
+		return websiteConfiguration{}, err
+	}
+	return wc, nil
+}
+
+// writeBucketWebsite - save bucket website configuration.
+func writeBucketWebsite(bucket string, wc websiteConfiguration) error {
+	// Verify if bucket path legal
+	if !IsValidBucketName(bucket) {
+		return BucketNameInvalid{Bucket: bucket}
+	}
+
+	// Create bucket config path.
+	if err := createBucketConfigPath(bucket); err != nil {
+		return err
+	}
+
+	bucketConfigPath, err := getBucketConfigPath(bucket)
+	if err != nil {
+		return err
+	}
+
+	websiteBytes, err := xml.Marshal(wc)
+	if err != nil {
+		return err
+	}
+
+	// Write bucket website configuration.
+	bucketWebsiteFile := filepath.Join(bucketConfigPath, bucketWebsiteConfigFile)
+	return ioutil.WriteFile(bucketWebsiteFile, websiteBytes, 0600)
+}
+
+// removeBucketWebsite - remove bucket website configuration.
+func removeBucketWebsite(bucket string) error {
+	// Verify bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return BucketNameInvalid{Bucket: bucket}
+	}
+
+	bucketConfigPath, err := getBucketConfigPath(bucket)
+	if err != nil {
+		return err
+	}
+
+	// Remove website file.
+	bucketWebsiteFile := filepath.Join(bucketConfigPath, bucketWebsiteConfigFile)
+	if err = os.Remove(bucketWebsiteFile); err != nil {
+		if os.IsNotExist(err) {
+			return BucketWebsiteNotFound{Bucket: bucket}
+		}
+		return err
+	}
+	return nil
+}
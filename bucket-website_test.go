@@ -0,0 +1,188 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Tests validating website configuration.
+func TestWebsiteConfigurationValidate(t *testing.T) {
+	index := &websiteIndexDocument{Suffix: "index.html"}
+	testCases := []struct {
+		wc          websiteConfiguration
+		expectedErr error
+	}{
+		{websiteConfiguration{IndexDocument: index}, nil},
+		{websiteConfiguration{IndexDocument: index, ErrorDocument: &websiteErrorDocument{Key: "error.html"}}, nil},
+		{websiteConfiguration{RedirectAllRequestsTo: &websiteRedirectAll{HostName: "example.com", Protocol: "https"}}, nil},
+		{websiteConfiguration{}, errWebsiteNoIndexDocument},
+		{websiteConfiguration{IndexDocument: &websiteIndexDocument{Suffix: "a/index.html"}}, errWebsiteInvalidSuffix},
+		{websiteConfiguration{IndexDocument: index, ErrorDocument: &websiteErrorDocument{}}, errWebsiteInvalidErrorKey},
+		{websiteConfiguration{RedirectAllRequestsTo: &websiteRedirectAll{HostName: "example.com"}, IndexDocument: index}, errWebsiteRedirectAllRequests},
+		{websiteConfiguration{RedirectAllRequestsTo: &websiteRedirectAll{}}, errWebsiteNoHostName},
+		{websiteConfiguration{RedirectAllRequestsTo: &websiteRedirectAll{HostName: "example.com", Protocol: "ftp"}}, errWebsiteInvalidProtocol},
+		{websiteConfiguration{IndexDocument: index, RoutingRules: make([]websiteRoutingRule, maxWebsiteRoutingRules+1)}, errWebsiteTooManyRules},
+		{websiteConfiguration{IndexDocument: index, RoutingRules: []websiteRoutingRule{{Redirect: websiteRedirect{ReplaceKeyWith: "a", ReplaceKeyPrefixWith: "b"}}}}, errWebsiteInvalidReplaceKey},
+		{websiteConfiguration{IndexDocument: index, RoutingRules: []websiteRoutingRule{{Redirect: websiteRedirect{HTTPRedirectCode: 200}}}}, errWebsiteInvalidRedirectCode},
+		{websiteConfiguration{IndexDocument: index, RoutingRules: []websiteRoutingRule{{Condition: &websiteCondition{HTTPErrorCodeReturnedEquals: 200}}}}, errWebsiteInvalidErrorCode},
+	}
+	for i, testCase := range testCases {
+		if err := testCase.wc.validate(); err != testCase.expectedErr {
+			t.Errorf("Test %d: Expected error %v, got %v", i+1, testCase.expectedErr, err)
+		}
+	}
+}
+
+// Tests bucket website operations and the website endpoint.
+func TestBucketWebsiteHandlers(t *testing.T) {
+	for _, instanceType := range []string{"FS", "XL"} {
+		testBucketWebsiteHandlers(instanceType, t)
+	}
+}
+
+func testBucketWebsiteHandlers(instanceType string, t *testing.T) {
+	testServer := StartTestServer(t, instanceType)
+	defer testServer.Stop()
+
+	globalWebsiteDomain = "website.local"
+	defer func() {
+		globalWebsiteDomain = ""
+	}()
+
+	// Redirects are not followed.
+	expectStatus := func(req *http.Request, status int) ([]byte, http.Header) {
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		respBody, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != status {
+			t.Fatalf("%s: %s %s%s: Expected status %d, got %d: %s", instanceType, req.Method, req.Host, req.URL.Path, status, resp.StatusCode, respBody)
+		}
+		return respBody, resp.Header
+	}
+	newRequest := func(method, urlStr string, body []byte) *http.Request {
+		req, err := newTestRequest(method, urlStr, int64(len(body)), bytes.NewReader(body), testServer.AccessKey, testServer.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+	websiteRequest := func(method, path string) *http.Request {
+		req, err := http.NewRequest(method, testServer.Server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Host = "site.website.local"
+		return req
+	}
+	expectContent := func(req *http.Request, status int, content string) {
+		if respData, _ := expectStatus(req, status); string(respData) != content {
+			t.Fatalf("%s: %s: Expected %s, got %s", instanceType, req.URL.Path, content, respData)
+		}
+	}
+	expectRedirect := func(req *http.Request, status int, location string) {
+		if _, respHeader := expectStatus(req, status); respHeader.Get("Location") != location {
+			t.Fatalf("%s: %s: Expected redirect to %s, got %s", instanceType, req.URL.Path, location, respHeader.Get("Location"))
+		}
+	}
+
+	bucketURL := testServer.Server.URL + "/site"
+	websiteURL := bucketURL + "?website"
+	expectStatus(newRequest("PUT", bucketURL, nil), http.StatusOK)
+	for object, content := range map[string]string{
+		"index.html":      "home",
+		"docs/index.html": "docs",
+		"error.html":      "not found",
+	} {
+		expectStatus(newRequest("PUT", bucketURL+"/"+object, []byte(content)), http.StatusOK)
+	}
+	expectStatus(newRequest("GET", websiteURL, nil), http.StatusNotFound)
+	expectStatus(websiteRequest("GET", "/"), http.StatusNotFound)
+
+	websiteBody := []byte(`<WebsiteConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+<IndexDocument><Suffix>index.html</Suffix></IndexDocument>
+<ErrorDocument><Key>error.html</Key></ErrorDocument>
+<RoutingRules>
+<RoutingRule><Condition><KeyPrefixEquals>old/</KeyPrefixEquals></Condition><Redirect><ReplaceKeyPrefixWith>docs/</ReplaceKeyPrefixWith></Redirect></RoutingRule>
+<RoutingRule><Condition><KeyPrefixEquals>moved/</KeyPrefixEquals><HttpErrorCodeReturnedEquals>404</HttpErrorCodeReturnedEquals></Condition><Redirect><HostName>example.com</HostName><Protocol>https</Protocol><HttpRedirectCode>302</HttpRedirectCode></Redirect></RoutingRule>
+</RoutingRules>
+</WebsiteConfiguration>`)
+	expectStatus(newRequest("PUT", websiteURL, websiteBody), http.StatusOK)
+	if respData, _ := expectStatus(newRequest("GET", websiteURL, nil), http.StatusOK); !bytes.Contains(respData, []byte("<Suffix>index.html</Suffix>")) {
+		t.Fatalf("%s: Unexpected website configuration %s", instanceType, respData)
+	}
+	expectStatus(newRequest("PUT", websiteURL, []byte(`<WebsiteConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></WebsiteConfiguration>`)), http.StatusBadRequest)
+	expectStatus(newRequest("PUT", websiteURL, []byte("<WebsiteConfiguration>")), http.StatusBadRequest)
+
+	// Website content is private without a bucket policy.
+	expectStatus(websiteRequest("GET", "/"), http.StatusForbidden)
+	policy := `{"Version": "2012-10-17", "Statement": [{"Action": ["s3:GetObject"], "Effect": "Allow", "Principal": {"AWS": ["*"]}, "Resource": ["arn:aws:s3:::site/*"]}]}`
+	expectStatus(newRequest("PUT", bucketURL+"?policy", []byte(policy)), http.StatusNoContent)
+
+	// Buckets are served under the website domain.
+	expectContent(websiteRequest("GET", "/"), http.StatusOK, "home")
+	expectContent(websiteRequest("GET", "/docs/"), http.StatusOK, "docs")
+	expectContent(websiteRequest("GET", "/index.html"), http.StatusOK, "home")
+	expectContent(websiteRequest("HEAD", "/"), http.StatusOK, "")
+	expectRedirect(websiteRequest("GET", "/docs"), http.StatusFound, "/docs/")
+	expectContent(websiteRequest("GET", "/missing"), http.StatusNotFound, "not found")
+	expectRedirect(websiteRequest("GET", "/old/index.html"), http.StatusMovedPermanently, "http://site.website.local/docs/index.html")
+	expectRedirect(websiteRequest("GET", "/moved/page"), http.StatusFound, "https://example.com/moved/page")
+	expectStatus(websiteRequest("PUT", "/index.html"), http.StatusMethodNotAllowed)
+
+	// Buckets are served path style by the website server, which
+	// serves nothing but the website endpoint.
+	obj, err := newObjectLayer(testServer.Disks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	websiteServer := httptest.NewServer(configureWebsiteHandler(obj))
+	defer websiteServer.Close()
+	pathRequest := func(method, path string) *http.Request {
+		req, err := http.NewRequest(method, websiteServer.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+	expectContent(pathRequest("GET", "/site/"), http.StatusOK, "home")
+	expectRedirect(pathRequest("GET", "/site/docs"), http.StatusFound, "/site/docs/")
+	expectContent(pathRequest("GET", "/site/missing"), http.StatusNotFound, "not found")
+	expectStatus(pathRequest("GET", "/missing-bucket/"), http.StatusNotFound)
+	expectStatus(pathRequest("PUT", "/site/index.html"), http.StatusMethodNotAllowed)
+	// API resources are not served by the website server.
+	expectContent(pathRequest("GET", "/site/?website"), http.StatusOK, "home")
+
+	// All requests are redirected.
+	expectStatus(newRequest("PUT", websiteURL, []byte(`<WebsiteConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><RedirectAllRequestsTo><HostName>example.com</HostName></RedirectAllRequestsTo></WebsiteConfiguration>`)), http.StatusOK)
+	expectRedirect(websiteRequest("GET", "/docs/"), http.StatusMovedPermanently, "http://example.com/docs/")
+
+	// Website configuration is removed.
+	expectStatus(newRequest("DELETE", websiteURL, nil), http.StatusNoContent)
+	expectStatus(newRequest("GET", websiteURL, nil), http.StatusNotFound)
+	expectStatus(websiteRequest("GET", "/"), http.StatusNotFound)
+}
@@ -32,6 +32,8 @@ func migrateConfig() {
 	migrateV2ToV3()
 	// Migrate version '3' to '4'.
 	migrateV3ToV4()
+	// Migrate version '4' to '5'.
+	migrateV4ToV5()
 }
 
 // Version '1' is not supported anymore and deprecated, safe to delete.
@@ -124,8 +126,8 @@ func migrateV3ToV4() {
 	}
 
 	// Save only the new fields, ignore the rest.
-	srvConfig := &serverConfigV4{}
-	srvConfig.Version = globalMinioConfigVersion
+	srvConfig := &configV4{}
+	srvConfig.Version = "4"
 	srvConfig.Credential = cv3.Credential
 	srvConfig.Region = cv3.Region
 	if srvConfig.Region == "" {
@@ -146,3 +148,37 @@ func migrateV3ToV4() {
 
 	console.Println("Migration from version ‘" + cv3.Version + "’ to ‘" + srvConfig.Version + "’ completed successfully.")
 }
+
+// Version '4' to '5' migrates config, adds notification targets
+// configuration.
+func migrateV4ToV5() {
+	cv4, err := loadConfigV4()
+	if err != nil && os.IsNotExist(err) {
+		return
+	}
+	fatalIf(err, "Unable to load config version ‘4’.")
+	if cv4.Version != "4" {
+		return
+	}
+
+	// Copy over fields from V4 into V5 config struct.
+	srvConfig := &serverConfigV5{}
+	srvConfig.Version = globalMinioConfigVersion
+	srvConfig.Credential = cv4.Credential
+	srvConfig.Region = cv4.Region
+	if srvConfig.Region == "" {
+		// Region needs to be set for AWS Signature Version 4.
+		srvConfig.Region = "us-east-1"
+	}
+	srvConfig.Logger = cv4.Logger
+
+	qc, err := quick.New(srvConfig)
+	fatalIf(err, "Unable to initialize the quick config.")
+	configFile, err := getConfigFile()
+	fatalIf(err, "Unable to get config file.")
+
+	err = qc.Save(configFile)
+	fatalIf(err, "Failed to migrate config from ‘%s’ to ‘%s’.", cv4.Version, srvConfig.Version)
+
+	console.Println("Migration from version ‘" + cv4.Version + "’ to ‘" + srvConfig.Version + "’ completed successfully.")
+}
@@ -23,17 +23,30 @@ import (
 	"github.com/minio/minio/pkg/quick"
 )
 
-// serverConfigV4 server configuration version '4'.
-type serverConfigV4 struct {
+// serverConfigV5 server configuration version '5'.
+type serverConfigV5 struct {
 	Version string `json:"version"`
 
 	// S3 API configuration.
 	Credential credential `json:"credential"`
 	Region     string     `json:"region"`
+	Domain     string     `json:"domain"`
 
 	// Additional error logging configuration.
 	Logger logger `json:"logger"`
 
+	// Notification queue configuration.
+	Notify notifier `json:"notify"`
+
+	// Server side encryption configuration.
+	Encryption encryptionConfig `json:"encryption"`
+
+	// Background heal scanner configuration.
+	Heal healConfig `json:"heal"`
+
+	// Parity disks of each storage class.
+	StorageClass storageClassConfig `json:"storageClass"`
+
 	// Read Write mutex.
 	rwMutex *sync.RWMutex
 }
@@ -41,7 +54,7 @@ type serverConfigV4 struct {
 // initConfig - initialize server config. config version (called only once).
 func initConfig() error {
 	if !isConfigFileExists() {
-		srvCfg := &serverConfigV4{}
+		srvCfg := &serverConfigV5{}
 		srvCfg.Version = globalMinioConfigVersion
 		srvCfg.Region = "us-east-1"
 		srvCfg.Credential = mustGenAccessKeys()
@@ -76,7 +89,7 @@ func initConfig() error {
 	if _, err = os.Stat(configFile); err != nil {
 		return err
 	}
-	srvCfg := &serverConfigV4{}
+	srvCfg := &serverConfigV5{}
 	srvCfg.Version = globalMinioConfigVersion
 	srvCfg.rwMutex = &sync.RWMutex{}
 	qc, err := quick.New(srvCfg)
@@ -94,10 +107,10 @@ func initConfig() error {
 }
 
 // serverConfig server config.
-var serverConfig *serverConfigV4
+var serverConfig *serverConfigV5
 
 // GetVersion get current config version.
-func (s serverConfigV4) GetVersion() string {
+func (s serverConfigV5) GetVersion() string {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Version
@@ -106,77 +119,171 @@ func (s serverConfigV4) GetVersion() string {
 /// Logger related.
 
 // SetFileLogger set new file logger.
-func (s *serverConfigV4) SetFileLogger(flogger fileLogger) {
+func (s *serverConfigV5) SetFileLogger(flogger fileLogger) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Logger.File = flogger
 }
 
 // GetFileLogger get current file logger.
-func (s serverConfigV4) GetFileLogger() fileLogger {
+func (s serverConfigV5) GetFileLogger() fileLogger {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Logger.File
 }
 
 // SetConsoleLogger set new console logger.
-func (s *serverConfigV4) SetConsoleLogger(clogger consoleLogger) {
+func (s *serverConfigV5) SetConsoleLogger(clogger consoleLogger) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Logger.Console = clogger
 }
 
 // GetConsoleLogger get current console logger.
-func (s serverConfigV4) GetConsoleLogger() consoleLogger {
+func (s serverConfigV5) GetConsoleLogger() consoleLogger {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Logger.Console
 }
 
 // SetSyslogLogger set new syslog logger.
-func (s *serverConfigV4) SetSyslogLogger(slogger syslogLogger) {
+func (s *serverConfigV5) SetSyslogLogger(slogger syslogLogger) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Logger.Syslog = slogger
 }
 
 // GetSyslogLogger get current syslog logger.
-func (s *serverConfigV4) GetSyslogLogger() syslogLogger {
+func (s *serverConfigV5) GetSyslogLogger() syslogLogger {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Logger.Syslog
 }
 
+/// Notification related.
+
+// GetWebhookNotifyByID get current webhook notification config for
+// the account id.
+func (s serverConfigV5) GetWebhookNotifyByID(accountID string) webhookNotify {
+	s.rwMutex.RLock()
+	defer s.rwMutex.RUnlock()
+	return s.Notify.Webhook[accountID]
+}
+
+// SetWebhookNotifyByID set new webhook notification config for the
+// account id.
+func (s *serverConfigV5) SetWebhookNotifyByID(accountID string, whNotify webhookNotify) {
+	s.rwMutex.Lock()
+	defer s.rwMutex.Unlock()
+	if s.Notify.Webhook == nil {
+		s.Notify.Webhook = make(map[string]webhookNotify)
+	}
+	s.Notify.Webhook[accountID] = whNotify
+}
+
+// GetWebhook get all webhook notification configs.
+func (s serverConfigV5) GetWebhook() map[string]webhookNotify {
+	s.rwMutex.RLock()
+	defer s.rwMutex.RUnlock()
+	webhooks := make(map[string]webhookNotify, len(s.Notify.Webhook))
+	for accountID, whNotify := range s.Notify.Webhook {
+		webhooks[accountID] = whNotify
+	}
+	return webhooks
+}
+
+/// Encryption related.
+
+// SetEncryption set new server side encryption config.
+func (s *serverConfigV5) SetEncryption(encConfig encryptionConfig) {
+	s.rwMutex.Lock()
+	defer s.rwMutex.Unlock()
+	s.Encryption = encConfig
+}
+
+// GetEncryption get current server side encryption config.
+func (s serverConfigV5) GetEncryption() encryptionConfig {
+	s.rwMutex.RLock()
+	defer s.rwMutex.RUnlock()
+	return s.Encryption
+}
+
+/// Heal related.
+
+// SetHeal set new background heal scanner config.
+func (s *serverConfigV5) SetHeal(hConfig healConfig) {
+	s.rwMutex.Lock()
+	defer s.rwMutex.Unlock()
+	s.Heal = hConfig
+}
+
+// GetHeal get current background heal scanner config.
+func (s serverConfigV5) GetHeal() healConfig {
+	s.rwMutex.RLock()
+	defer s.rwMutex.RUnlock()
+	return s.Heal
+}
+
+/// Storage class related.
+
+// SetStorageClass set new storage class config.
+func (s *serverConfigV5) SetStorageClass(scConfig storageClassConfig) {
+	s.rwMutex.Lock()
+	defer s.rwMutex.Unlock()
+	s.StorageClass = scConfig
+}
+
+// GetStorageClass get current storage class config.
+func (s serverConfigV5) GetStorageClass() storageClassConfig {
+	s.rwMutex.RLock()
+	defer s.rwMutex.RUnlock()
+	return s.StorageClass
+}
+
 // SetRegion set new region.
-func (s *serverConfigV4) SetRegion(region string) {
+func (s *serverConfigV5) SetRegion(region string) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Region = region
 }
 
 // GetRegion get current region.
-func (s serverConfigV4) GetRegion() string {
+func (s serverConfigV5) GetRegion() string {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Region
 }
 
+// SetDomain set new server domain.
+func (s *serverConfigV5) SetDomain(domain string) {
+	s.rwMutex.Lock()
+	defer s.rwMutex.Unlock()
+	s.Domain = domain
+}
+
+// GetDomain get current server domain.
+func (s serverConfigV5) GetDomain() string {
+	s.rwMutex.RLock()
+	defer s.rwMutex.RUnlock()
+	return s.Domain
+}
+
 // SetCredentials set new credentials.
-func (s *serverConfigV4) SetCredential(creds credential) {
+func (s *serverConfigV5) SetCredential(creds credential) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
 	s.Credential = creds
 }
 
 // GetCredentials get current credentials.
-func (s serverConfigV4) GetCredential() credential {
+func (s serverConfigV5) GetCredential() credential {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 	return s.Credential
 }
 
 // Save config.
-func (s serverConfigV4) Save() error {
+func (s serverConfigV5) Save() error {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
 
@@ -0,0 +1,74 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// encryptionConfig - configures the master key sealing the object keys
+// of objects encrypted with SSE-S3. The master key is 32 bytes hex
+// encoded, read either from a file or from an environment variable.
+type encryptionConfig struct {
+	MasterKeyFile string `json:"masterKeyFile"`
+	MasterKeyEnv  string `json:"masterKeyEnv"`
+}
+
+// Master key errors.
+var (
+	errMasterKeyConflict = errors.New("Master key should be configured either as a file or as an environment variable")
+	errMasterKeyEmpty    = errors.New("Master key is empty")
+	errMasterKeyInvalid  = errors.New("Master key must be 64 hex characters")
+)
+
+// Global master key sealing SSE-S3 object keys, nil if SSE-S3 is
+// not configured.
+var globalSSEMasterKey []byte
+
+// loadMasterKey - loads the master key configured, returns nil if no
+// master key is configured.
+func loadMasterKey(encConfig encryptionConfig) ([]byte, error) {
+	var keyStr string
+	switch {
+	case encConfig.MasterKeyFile != "" && encConfig.MasterKeyEnv != "":
+		return nil, errMasterKeyConflict
+	case encConfig.MasterKeyFile != "":
+		keyBytes, err := ioutil.ReadFile(encConfig.MasterKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		keyStr = string(keyBytes)
+	case encConfig.MasterKeyEnv != "":
+		keyStr = os.Getenv(encConfig.MasterKeyEnv)
+	default:
+		return nil, nil
+	}
+
+	keyStr = strings.TrimSpace(keyStr)
+	if keyStr == "" {
+		return nil, errMasterKeyEmpty
+	}
+	masterKey, err := hex.DecodeString(keyStr)
+	if err != nil || len(masterKey) != encObjectKeySize {
+		return nil, errMasterKeyInvalid
+	}
+	return masterKey, nil
+}
@@ -0,0 +1,332 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Encrypted objects are split into packages, each package holds at
+// most encPayloadSize bytes of the object and is encrypted and
+// authenticated independently with AES-256-GCM. This allows reading
+// any range of an encrypted object by decrypting only the packages
+// overlapping the range.
+//
+// Package layout:
+//
+//   header  - 16 bytes, authenticated but not encrypted.
+//     [0]     - format version.
+//     [1]     - cipher suite, the highest bit marks the final package.
+//     [2:4]   - payload size minus one, little endian.
+//     [4:8]   - package sequence number, little endian.
+//     [8:16]  - random nonce shared by all packages of a stream.
+//   payload - encrypted object data.
+//   tag     - 16 bytes GCM authentication tag.
+//
+// The GCM nonce of a package is the stream nonce followed by its
+// sequence number, packages cannot be re-ordered, truncated or moved
+// to other streams without failing authentication.
+const (
+	encVersionV1         = 0x10
+	encCipherAES256GCM   = 0x00
+	encFinalFlag         = 0x80
+	encHeaderSize        = 16
+	encTagSize           = 16
+	encPayloadSize       = 64 * 1024
+	encPackageSize       = encHeaderSize + encPayloadSize + encTagSize
+	encPackageOverhead   = encHeaderSize + encTagSize
+	encObjectKeySize     = 32
+	encStreamNonceSize   = 8
+	encMaxPackageSeqNums = 1<<32 - 1
+)
+
+// errObjectTampered - encrypted object failed authentication.
+var errObjectTampered = errors.New("The requested object was modified and may be compromised")
+
+// errUnsupportedEncFormat - encrypted object is of unknown format.
+var errUnsupportedEncFormat = errors.New("Unsupported encryption format")
+
+// newObjectKeyAEAD - returns AES-256-GCM initialized with the object key.
+func newObjectKeyAEAD(objectKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(objectKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptedSize - returns the size of the encrypted stream for an
+// object of size bytes.
+func encryptedSize(size int64) int64 {
+	if size <= 0 {
+		return size
+	}
+	encSize := (size / encPayloadSize) * encPackageSize
+	if rem := size % encPayloadSize; rem > 0 {
+		encSize += rem + encPackageOverhead
+	}
+	return encSize
+}
+
+// decryptedSize - returns the size of the object stored as an
+// encrypted stream of encSize bytes.
+func decryptedSize(encSize int64) (int64, error) {
+	size := (encSize / encPackageSize) * encPayloadSize
+	if rem := encSize % encPackageSize; rem > 0 {
+		if rem <= encPackageOverhead {
+			return 0, errObjectTampered
+		}
+		size += rem - encPackageOverhead
+	}
+	return size, nil
+}
+
+// encryptReader - encrypts data read from the underlying reader.
+type encryptReader struct {
+	src   io.Reader
+	aead  cipher.AEAD
+	nonce [encStreamNonceSize]byte
+	seq   uint32
+
+	// Plain text staging buffer, holds one byte more than a package
+	// payload to detect the final package.
+	plain    []byte
+	plainLen int
+	// Encrypted package not yet returned to the caller.
+	pkg     []byte
+	pkgBuf  []byte
+	isFinal bool
+	err     error
+}
+
+// newEncryptReader - returns a reader encrypting the data read from
+// src with the object key.
+func newEncryptReader(src io.Reader, objectKey []byte) io.Reader {
+	r := &encryptReader{
+		src:    src,
+		plain:  make([]byte, encPayloadSize+1),
+		pkgBuf: make([]byte, encPackageSize),
+	}
+	r.aead, r.err = newObjectKeyAEAD(objectKey)
+	if r.err == nil {
+		_, r.err = io.ReadFull(rand.Reader, r.nonce[:])
+	}
+	return r
+}
+
+// Read - implements io.Reader.
+func (r *encryptReader) Read(p []byte) (n int, err error) {
+	for n < len(p) {
+		if len(r.pkg) == 0 {
+			if r.err != nil {
+				break
+			}
+			r.err = r.nextPackage()
+			continue
+		}
+		m := copy(p[n:], r.pkg)
+		r.pkg = r.pkg[m:]
+		n += m
+	}
+	if n > 0 {
+		return n, nil
+	}
+	return 0, r.err
+}
+
+// nextPackage - reads the next payload from the source and encrypts it.
+func (r *encryptReader) nextPackage() error {
+	if r.isFinal {
+		return io.EOF
+	}
+	m, err := io.ReadFull(r.src, r.plain[r.plainLen:])
+	r.plainLen += m
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	payloadLen := r.plainLen
+	if err == nil {
+		// More data follows, keep the extra byte for the next package.
+		payloadLen = encPayloadSize
+	} else {
+		r.isFinal = true
+		// Empty stream has no packages.
+		if payloadLen == 0 {
+			return io.EOF
+		}
+	}
+	if r.seq == encMaxPackageSeqNums {
+		return errUnsupportedEncFormat
+	}
+
+	header := r.pkgBuf[:encHeaderSize]
+	header[0] = encVersionV1
+	header[1] = encCipherAES256GCM
+	if r.isFinal {
+		header[1] |= encFinalFlag
+	}
+	binary.LittleEndian.PutUint16(header[2:4], uint16(payloadLen-1))
+	binary.LittleEndian.PutUint32(header[4:8], r.seq)
+	copy(header[8:], r.nonce[:])
+
+	sealed := r.aead.Seal(r.pkgBuf[encHeaderSize:encHeaderSize], header[4:16], r.plain[:payloadLen], header)
+	r.pkg = r.pkgBuf[:encHeaderSize+len(sealed)]
+	r.seq++
+
+	// Move the remaining byte to the start of the staging buffer.
+	r.plainLen = copy(r.plain, r.plain[payloadLen:r.plainLen])
+	return nil
+}
+
+// decryptWriter - decrypts packages of one or more consecutive
+// encrypted streams written to it and writes the requested range of
+// plain text to the underlying writer.
+type decryptWriter struct {
+	dst  io.Writer
+	aead cipher.AEAD
+
+	// Sizes of the encrypted streams still to be written, the first
+	// entry is the remaining size of the current stream.
+	streamSizes []int64
+	// Expected sequence number and nonce of the next package.
+	seq      uint32
+	nonce    []byte
+	hasNonce bool
+
+	// Plain text bytes to skip and to write.
+	skip   int64
+	length int64
+
+	pkg    []byte
+	pkgLen int
+	plain  []byte
+}
+
+// newDecryptWriter - returns a writer decrypting packages starting
+// with sequence number seq. streamSizes are the sizes of the encrypted
+// streams written to it, the first one starting at the package. skip
+// bytes of plain text are discarded before length bytes are written
+// to dst.
+func newDecryptWriter(dst io.Writer, objectKey []byte, seq uint32, streamSizes []int64, skip, length int64) (io.WriteCloser, error) {
+	aead, err := newObjectKeyAEAD(objectKey)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptWriter{
+		dst:         dst,
+		aead:        aead,
+		streamSizes: streamSizes,
+		seq:         seq,
+		skip:        skip,
+		length:      length,
+		pkg:         make([]byte, encPackageSize),
+		plain:       make([]byte, encPayloadSize),
+	}, nil
+}
+
+// Write - implements io.Writer.
+func (w *decryptWriter) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		if len(w.streamSizes) == 0 {
+			return n, errObjectTampered
+		}
+		// Fill the header first to learn the size of the package.
+		want := encHeaderSize
+		if w.pkgLen >= encHeaderSize {
+			want = encHeaderSize + int(binary.LittleEndian.Uint16(w.pkg[2:4])) + 1 + encTagSize
+		}
+		m := copy(w.pkg[w.pkgLen:want], p)
+		w.pkgLen += m
+		n += m
+		p = p[m:]
+		if w.pkgLen < want || want == encHeaderSize {
+			continue
+		}
+		if err = w.decryptPackage(); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// decryptPackage - authenticates and decrypts the buffered package.
+func (w *decryptWriter) decryptPackage() error {
+	header := w.pkg[:encHeaderSize]
+	if header[0] != encVersionV1 || header[1]&^encFinalFlag != encCipherAES256GCM {
+		return errUnsupportedEncFormat
+	}
+	if binary.LittleEndian.Uint32(header[4:8]) != w.seq {
+		return errObjectTampered
+	}
+	if w.hasNonce && string(header[8:16]) != string(w.nonce) {
+		return errObjectTampered
+	}
+	// Only the last package of a stream is marked final.
+	remaining := w.streamSizes[0] - int64(w.pkgLen)
+	if remaining < 0 || (header[1]&encFinalFlag != 0) != (remaining == 0) {
+		return errObjectTampered
+	}
+
+	plain, err := w.aead.Open(w.plain[:0], header[4:16], w.pkg[encHeaderSize:w.pkgLen], header)
+	if err != nil {
+		return errObjectTampered
+	}
+	w.pkgLen = 0
+
+	// Advance to the next package or stream.
+	if remaining == 0 {
+		w.streamSizes = w.streamSizes[1:]
+		w.seq = 0
+		w.hasNonce = false
+	} else {
+		w.streamSizes[0] = remaining
+		w.seq++
+		w.nonce = append(w.nonce[:0], header[8:16]...)
+		w.hasNonce = true
+	}
+
+	if w.skip >= int64(len(plain)) {
+		w.skip -= int64(len(plain))
+		return nil
+	}
+	plain = plain[w.skip:]
+	w.skip = 0
+	if int64(len(plain)) > w.length {
+		plain = plain[:w.length]
+	}
+	if len(plain) == 0 {
+		return nil
+	}
+	if _, err = w.dst.Write(plain); err != nil {
+		return err
+	}
+	w.length -= int64(len(plain))
+	return nil
+}
+
+// Close - verifies the complete requested range was decrypted.
+func (w *decryptWriter) Close() error {
+	if w.pkgLen > 0 || w.length > 0 {
+		return errObjectTampered
+	}
+	return nil
+}
@@ -17,8 +17,6 @@
 package main
 
 import (
-	"encoding/hex"
-	"hash"
 	"io"
 	"sync"
 
@@ -26,15 +24,14 @@ import (
 )
 
 // erasureCreateFile - writes an entire stream by erasure coding to
-// all the disks, writes also calculate individual block's checksum
-// for future bit-rot protection.
+// all the disks, each block is written along with its hash for
+// bit-rot protection as it is read.
 func erasureCreateFile(disks []StorageAPI, volume string, path string, partName string, data io.Reader, eInfos []erasureInfo, writeQuorum int) (newEInfos []erasureInfo, size int64, err error) {
 	// Just pick one eInfo.
 	eInfo := pickValidErasureInfo(eInfos)
 
 	// Allocated blockSized buffer for reading.
 	buf := make([]byte, eInfo.BlockSize)
-	hashWriters := newHashWriters(len(disks))
 
 	// Read until io.EOF, erasure codes data and writes to all disks.
 	for {
@@ -47,7 +44,7 @@ func erasureCreateFile(disks []StorageAPI, volume string, path string, partName
 			// data. Will create a 0byte file instead.
 			if size == 0 {
 				blocks = make([][]byte, len(disks))
-				err = appendFile(disks, volume, path, blocks, eInfo.Distribution, hashWriters, writeQuorum)
+				err = appendFile(disks, volume, path, blocks, eInfo.Distribution, writeQuorum)
 				if err != nil {
 					return nil, 0, err
 				}
@@ -67,30 +64,24 @@ func erasureCreateFile(disks []StorageAPI, volume string, path string, partName
 		}
 
 		// Write to all disks.
-		err = appendFile(disks, volume, path, blocks, eInfo.Distribution, hashWriters, writeQuorum)
+		err = appendFile(disks, volume, path, blocks, eInfo.Distribution, writeQuorum)
 		if err != nil {
 			return nil, 0, err
 		}
 	}
 
-	// Save the checksums.
-	checkSums := make([]checkSumInfo, len(disks))
-	for index := range disks {
-		blockIndex := eInfo.Distribution[index] - 1
-		checkSums[blockIndex] = checkSumInfo{
-			Name:      partName,
-			Algorithm: "blake2b",
-			Hash:      hex.EncodeToString(hashWriters[blockIndex].Sum(nil)),
-		}
+	// Blocks carry their own hashes, save the algorithm.
+	checkSum := checkSumInfo{
+		Name:      partName,
+		Algorithm: blockBitRotAlgorithm,
 	}
 
 	// Erasure info update for checksum for each disks.
 	newEInfos = make([]erasureInfo, len(disks))
 	for index, eInfo := range eInfos {
 		if eInfo.IsValid() {
-			blockIndex := eInfo.Distribution[index] - 1
 			newEInfos[index] = eInfo
-			newEInfos[index].Checksum = append(newEInfos[index].Checksum, checkSums[blockIndex])
+			newEInfos[index].Checksum = append(newEInfos[index].Checksum, checkSum)
 		}
 	}
 
@@ -122,8 +113,9 @@ func encodeData(dataBuffer []byte, dataBlocks, parityBlocks int) ([][]byte, erro
 	return blocks, nil
 }
 
-// appendFile - append data buffer at path.
-func appendFile(disks []StorageAPI, volume, path string, enBlocks [][]byte, distribution []int, hashWriters []hash.Hash, writeQuorum int) (err error) {
+// appendFile - append data buffer at path, each block prefixed with
+// its hash.
+func appendFile(disks []StorageAPI, volume, path string, enBlocks [][]byte, distribution []int, writeQuorum int) (err error) {
 	var wg = &sync.WaitGroup{}
 	var wErrs = make([]error, len(disks))
 	// Write encoded data to quorum disks in parallel.
@@ -137,15 +129,12 @@ func appendFile(disks []StorageAPI, volume, path string, enBlocks [][]byte, dist
 			defer wg.Done()
 			// Pick the block from the distribution.
 			blockIndex := distribution[index] - 1
-			wErr := disk.AppendFile(volume, path, enBlocks[blockIndex])
+			wErr := disk.AppendFile(volume, path, newBitRotBlock(enBlocks[blockIndex]))
 			if wErr != nil {
 				wErrs[index] = wErr
 				return
 			}
 
-			// Calculate hash for each blocks.
-			hashWriters[blockIndex].Write(enBlocks[blockIndex])
-
 			// Successfully wrote.
 			wErrs[index] = nil
 		}(index, disk)
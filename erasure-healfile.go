@@ -0,0 +1,123 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import "encoding/hex"
+
+// erasureHealFile - rebuilds the erasure coded blocks of a part for
+// the outdated disks from the blocks on the latest disks, block by
+// block. Rebuilt blocks are written at healBucket, healPath on each
+// outdated disk, with block hashes if the part on the latest disks
+// has them. Returns the checksums of the rebuilt blocks for each
+// outdated disk, disks which fail to be written are skipped and have
+// no checksum.
+func erasureHealFile(latestDisks []StorageAPI, outDatedDisks []StorageAPI, volume, path, healBucket, healPath, partName string, size int64, eInfo erasureInfo) ([]checkSumInfo, error) {
+	// Outdated disks which fail to be written are removed.
+	outDatedDisks = append([]StorageAPI(nil), outDatedDisks...)
+	var writeErr error
+	hashWriters := newHashWriters(len(outDatedDisks))
+	blockCheckSum := eInfo.PartObjectChecksum(partName)
+	blockHashes := blockCheckSum.Algorithm == blockBitRotAlgorithm
+
+	// chunkSize is the size of the encoded block of a full data block.
+	chunkSize := getEncodedBlockLen(eInfo.BlockSize, eInfo.DataBlocks)
+
+	// Zero sized parts are empty on every disk.
+	if size == 0 {
+		for index, disk := range outDatedDisks {
+			if disk == nil {
+				continue
+			}
+			if err := disk.AppendFile(healBucket, healPath, nil); err != nil {
+				outDatedDisks[index], writeErr = nil, err
+			}
+		}
+	}
+
+	for block, remaining := int64(0), size; remaining > 0; block++ {
+		// Last block can be smaller than the block size.
+		blockSize := eInfo.BlockSize
+		if remaining < blockSize {
+			blockSize = remaining
+		}
+		remaining -= blockSize
+		curChunkSize := getEncodedBlockLen(blockSize, eInfo.DataBlocks)
+
+		// Read the block from all the latest disks, blocks which
+		// cannot be read are rebuilt along with the outdated ones.
+		enBlocks := make([][]byte, len(latestDisks))
+		for index, disk := range latestDisks {
+			if disk == nil {
+				continue
+			}
+			chunk, err := readChunk(disk, volume, path, blockCheckSum, block, chunkSize, curChunkSize)
+			if err != nil {
+				continue
+			}
+			enBlocks[eInfo.Distribution[index]-1] = chunk
+		}
+		if !isSuccessDecodeBlocks(enBlocks, eInfo.DataBlocks) {
+			return nil, errXLReadQuorum
+		}
+		if err := decodeData(enBlocks, eInfo.DataBlocks, eInfo.ParityBlocks); err != nil {
+			return nil, err
+		}
+
+		// Write the rebuilt blocks to the outdated disks.
+		for index, disk := range outDatedDisks {
+			if disk == nil {
+				continue
+			}
+			blockIndex := eInfo.Distribution[index] - 1
+			if blockHashes {
+				if err := disk.AppendFile(healBucket, healPath, newBitRotBlock(enBlocks[blockIndex])); err != nil {
+					outDatedDisks[index], writeErr = nil, err
+				}
+				continue
+			}
+			if err := disk.AppendFile(healBucket, healPath, enBlocks[blockIndex]); err != nil {
+				outDatedDisks[index], writeErr = nil, err
+				continue
+			}
+			hashWriters[index].Write(enBlocks[blockIndex])
+		}
+		if diskCount(outDatedDisks) == 0 {
+			return nil, writeErr
+		}
+	}
+
+	// Save the checksums of the rebuilt blocks.
+	checkSums := make([]checkSumInfo, len(outDatedDisks))
+	for index, disk := range outDatedDisks {
+		if disk == nil {
+			continue
+		}
+		if blockHashes {
+			checkSums[index] = checkSumInfo{
+				Name:      partName,
+				Algorithm: blockBitRotAlgorithm,
+			}
+			continue
+		}
+		checkSums[index] = checkSumInfo{
+			Name:      partName,
+			Algorithm: wholeBitRotAlgorithm,
+			Hash:      hex.EncodeToString(hashWriters[index].Sum(nil)),
+		}
+	}
+	return checkSums, nil
+}
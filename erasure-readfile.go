@@ -24,6 +24,7 @@ import (
 	"sync"
 
 	"github.com/klauspost/reedsolomon"
+	"github.com/minio/blake2b-simd"
 )
 
 // isSuccessDecodeBlocks - do we have all the blocks to be
@@ -43,8 +44,9 @@ func isSuccessDecodeBlocks(enBlocks [][]byte, dataBlocks int) bool {
 		} // else { // update parity block count.
 		successParityBlocksCount++
 	}
-	// Returns true if we have atleast dataBlocks + 1 parity.
-	return successDataBlocksCount == dataBlocks || successDataBlocksCount+successParityBlocksCount >= dataBlocks+1
+	// Returns true if we have atleast dataBlocks, which is all
+	// Reed-Solomon needs to reconstruct the missing ones.
+	return successDataBlocksCount+successParityBlocksCount >= dataBlocks
 }
 
 // isSuccessDataBlocks - do we have all the data blocks?
@@ -122,11 +124,48 @@ func getReadDisks(orderedDisks []StorageAPI, index int, dataBlocks int) (readDis
 			return readDisks, i + 1, nil
 		}
 	}
+	// Objects with less parity may have just enough disks left to
+	// reconstruct, read from all of them.
+	if dataDisks+parityDisks == dataBlocks {
+		return readDisks, len(orderedDisks), nil
+	}
 	return nil, 0, errXLReadQuorum
 }
 
+// readChunk - reads the chunk of the block from the disk. Chunks of
+// parts written with block hashes are verified against the hash in
+// front of them, returns errBitRot if the chunk fails its hash.
+func readChunk(disk StorageAPI, volume, path string, blockCheckSum checkSumInfo, block int64, chunkSize int64, curChunkSize int64) ([]byte, error) {
+	hashSize := int64(0)
+	if blockCheckSum.Algorithm == blockBitRotAlgorithm {
+		hashSize = blockBitRotHashSize
+	}
+
+	// NOTE: That for the offset calculation we have to use chunkSize and
+	// not curChunkSize. If we use curChunkSize for offset calculation
+	// then it can result in wrong offset for the last block.
+	chunkWriter := bytes.NewBuffer(make([]byte, 0, hashSize+curChunkSize))
+	if err := copyN(chunkWriter, disk, volume, path, block*(hashSize+chunkSize), hashSize+curChunkSize); err != nil {
+		return nil, err
+	}
+	chunk := chunkWriter.Bytes()
+	if hashSize == 0 {
+		return chunk, nil
+	}
+
+	// Verify the chunk against its hash.
+	if int64(len(chunk)) != hashSize+curChunkSize {
+		return nil, errBitRot
+	}
+	chunkHash := blake2b.Sum256(chunk[hashSize:])
+	if !bytes.Equal(chunkHash[:], chunk[:hashSize]) {
+		return nil, errBitRot
+	}
+	return chunk[hashSize:], nil
+}
+
 // parallelRead - reads chunks in parallel from the disks specified in []readDisks.
-func parallelRead(volume, path string, readDisks []StorageAPI, orderedDisks []StorageAPI, enBlocks [][]byte, blockOffset int64, curChunkSize int64, bitRotVerify func(diskIndex int) bool) {
+func parallelRead(volume, path string, readDisks []StorageAPI, orderedDisks []StorageAPI, blockCheckSums []checkSumInfo, enBlocks [][]byte, block int64, chunkSize int64, curChunkSize int64, bitRotVerify func(diskIndex int) bool) {
 	// WaitGroup to synchronise the read go-routines.
 	wg := &sync.WaitGroup{}
 
@@ -147,11 +186,9 @@ func parallelRead(volume, path string, readDisks []StorageAPI, orderedDisks []St
 				return
 			}
 
-			// Chunk writer.
-			chunkWriter := bytes.NewBuffer(make([]byte, 0, curChunkSize))
-
-			// CopyN - copies until current chunk size.
-			err := copyN(chunkWriter, readDisks[index], volume, path, blockOffset, curChunkSize)
+			// Read the chunk, a chunk failing its hash is treated as
+			// missing.
+			chunk, err := readChunk(readDisks[index], volume, path, blockCheckSums[index], block, chunkSize, curChunkSize)
 			if err != nil {
 				// So that we don't read from this disk for the next block.
 				orderedDisks[index] = nil
@@ -159,7 +196,7 @@ func parallelRead(volume, path string, readDisks []StorageAPI, orderedDisks []St
 			}
 
 			// Copy the read blocks.
-			enBlocks[index] = chunkWriter.Bytes()
+			enBlocks[index] = chunk
 
 			// Successfully read.
 		}(index)
@@ -173,8 +210,10 @@ func parallelRead(volume, path string, readDisks []StorageAPI, orderedDisks []St
 // Erasure coded files are read block by block as per given erasureInfo and data chunks
 // are decoded into a data block. Data block is trimmed for given offset and length,
 // then written to given writer. This function also supports bit-rot detection by
-// verifying checksum of individual block's checksum.
-func erasureReadFile(writer io.Writer, disks []StorageAPI, volume string, path string, partName string, eInfos []erasureInfo, offset int64, length int64, totalLength int64) (int64, error) {
+// verifying the hash of each block as it is read, or the hash of the whole part
+// for parts written without block hashes. Returned heal is true if blocks on any
+// of the disks could not be read or failed their checksum.
+func erasureReadFile(writer io.Writer, disks []StorageAPI, volume string, path string, partName string, eInfos []erasureInfo, offset int64, length int64, totalLength int64) (n int64, heal bool, err error) {
 	// Pick one erasure info.
 	eInfo := pickValidErasureInfo(eInfos)
 
@@ -184,6 +223,7 @@ func erasureReadFile(writer io.Writer, disks []StorageAPI, volume string, path s
 	// []orderedDisks will have first eInfo.DataBlocks disks as data
 	// disks and rest will be parity.
 	orderedDisks, orderedBlockCheckSums := getOrderedDisks(eInfo.Distribution, disks, blockCheckSums)
+	initialOrderedDisks := append([]StorageAPI(nil), orderedDisks...)
 
 	// bitRotVerify verifies if the file on a particular disk doesn't have bitrot
 	// by verifying the hash of the contents of the file.
@@ -193,12 +233,16 @@ func erasureReadFile(writer io.Writer, disks []StorageAPI, volume string, path s
 		// not recalculate the hash on it every time the function is
 		// called for the same disk.
 		return func(diskIndex int) bool {
+			if orderedBlockCheckSums[diskIndex].Algorithm == blockBitRotAlgorithm {
+				// Verified block by block as it is read.
+				return true
+			}
 			if verified[diskIndex] {
 				// Already validated.
 				return true
 			}
 			// Is this a valid block?
-			isValid := isValidBlock(orderedDisks[diskIndex], volume, path, orderedBlockCheckSums[diskIndex])
+			isValid := isValidBlock(orderedDisks[diskIndex], volume, path, totalLength, eInfo, orderedBlockCheckSums[diskIndex])
 			verified[diskIndex] = isValid
 			return isValid
 		}
@@ -239,12 +283,6 @@ func erasureReadFile(writer io.Writer, disks []StorageAPI, volume string, path s
 			blockSize = totalLength % eInfo.BlockSize
 		}
 
-		// Block offset.
-		// NOTE: That for the offset calculation we have to use chunkSize and
-		// not curChunkSize. If we use curChunkSize for offset calculation
-		// then it can result in wrong offset for the last block.
-		blockOffset := block * chunkSize
-
 		// nextIndex - index from which next set of parallel reads
 		// should happen.
 		nextIndex := 0
@@ -255,16 +293,16 @@ func erasureReadFile(writer io.Writer, disks []StorageAPI, volume string, path s
 			var err error
 			readDisks, nextIndex, err = getReadDisks(orderedDisks, nextIndex, eInfo.DataBlocks)
 			if err != nil {
-				return bytesWritten, err
+				return bytesWritten, false, err
 			}
-			parallelRead(volume, path, readDisks, orderedDisks, enBlocks, blockOffset, curChunkSize, bitRotVerify)
+			parallelRead(volume, path, readDisks, orderedDisks, orderedBlockCheckSums, enBlocks, block, chunkSize, curChunkSize, bitRotVerify)
 			if isSuccessDecodeBlocks(enBlocks, eInfo.DataBlocks) {
 				// If enough blocks are available to do rs.Reconstruct()
 				break
 			}
 			if nextIndex == len(orderedDisks) {
 				// No more disks to read from.
-				return bytesWritten, errXLReadQuorum
+				return bytesWritten, false, errXLReadQuorum
 			}
 		}
 
@@ -272,7 +310,7 @@ func erasureReadFile(writer io.Writer, disks []StorageAPI, volume string, path s
 		if !isSuccessDataBlocks(enBlocks, eInfo.DataBlocks) {
 			// Reconstruct the missing data blocks.
 			if err := decodeData(enBlocks, eInfo.DataBlocks, eInfo.ParityBlocks); err != nil {
-				return bytesWritten, err
+				return bytesWritten, false, err
 			}
 		}
 
@@ -292,15 +330,22 @@ func erasureReadFile(writer io.Writer, disks []StorageAPI, volume string, path s
 		// Write data blocks.
 		n, err := writeDataBlocks(writer, enBlocks, eInfo.DataBlocks, outOffset, outSize)
 		if err != nil {
-			return bytesWritten, err
+			return bytesWritten, false, err
 		}
 
 		// Update total bytes written.
 		bytesWritten += n
 	}
 
+	// Disks dropped while reading need to be healed.
+	for index := range orderedDisks {
+		if orderedDisks[index] == nil && initialOrderedDisks[index] != nil {
+			heal = true
+		}
+	}
+
 	// Success.
-	return bytesWritten, nil
+	return bytesWritten, heal, nil
 }
 
 // PartObjectChecksum - returns the checksum for the part name from the checksum slice.
@@ -326,13 +371,18 @@ func metaPartBlockChecksums(disks []StorageAPI, eInfos []erasureInfo, partName s
 	return blockCheckSums
 }
 
-// isValidBlock - calculates the checksum hash for the block and
-// validates if its correct returns true for valid cases, false otherwise.
-func isValidBlock(disk StorageAPI, volume, path string, blockCheckSum checkSumInfo) (ok bool) {
+// isValidBlock - calculates the checksum hash for the part of the
+// given size and validates if its correct returns true for valid
+// cases, false otherwise. Parts written with block hashes are
+// validated block by block.
+func isValidBlock(disk StorageAPI, volume, path string, size int64, eInfo erasureInfo, blockCheckSum checkSumInfo) (ok bool) {
 	// Disk is not available, not a valid block.
 	if disk == nil {
 		return false
 	}
+	if blockCheckSum.Algorithm == blockBitRotAlgorithm {
+		return isValidBitRotBlocks(disk, volume, path, size, eInfo, blockCheckSum)
+	}
 	// Read everything for a given block and calculate hash.
 	hashWriter := newHash(blockCheckSum.Algorithm)
 	hashBytes, err := hashSum(disk, volume, path, hashWriter)
@@ -343,6 +393,38 @@ func isValidBlock(disk StorageAPI, volume, path string, blockCheckSum checkSumIn
 	return hex.EncodeToString(hashBytes) == blockCheckSum.Hash
 }
 
+// isValidBitRotBlocks - validates each block of the part against the
+// hash in front of it, the part should hold exactly the blocks of the
+// given size.
+func isValidBitRotBlocks(disk StorageAPI, volume, path string, size int64, eInfo erasureInfo, blockCheckSum checkSumInfo) bool {
+	chunkSize := getEncodedBlockLen(eInfo.BlockSize, eInfo.DataBlocks)
+
+	// Truncated parts fail without being read.
+	fileSize := (size / eInfo.BlockSize) * (blockBitRotHashSize + chunkSize)
+	if lastBlockSize := size % eInfo.BlockSize; lastBlockSize > 0 {
+		fileSize += blockBitRotHashSize + getEncodedBlockLen(lastBlockSize, eInfo.DataBlocks)
+	}
+	fi, err := disk.StatFile(volume, path)
+	if err != nil || fi.Size != fileSize {
+		return false
+	}
+
+	for block, remaining := int64(0), size; remaining > 0; block++ {
+		curChunkSize := chunkSize
+		if remaining < eInfo.BlockSize {
+			curChunkSize = getEncodedBlockLen(remaining, eInfo.DataBlocks)
+		}
+		remaining -= eInfo.BlockSize
+		if _, err = readChunk(disk, volume, path, blockCheckSum, block, chunkSize, curChunkSize); err != nil {
+			if err != errBitRot {
+				errorIf(err, "Unable to read block %d of %s/%s", block, volume, path)
+			}
+			return false
+		}
+	}
+	return true
+}
+
 // decodeData - decode encoded blocks.
 func decodeData(enBlocks [][]byte, dataBlocks, parityBlocks int) error {
 	// Initialized reedsolomon.
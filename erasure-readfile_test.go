@@ -0,0 +1,163 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Tests bit-rot detection of each block as it is read.
+func TestErasureReadFileBitRot(t *testing.T) {
+	objLayer, disks, err := getXLObjectLayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(disks)
+	xl := objLayer.(xlObjects)
+	storageDisks := xl.storageDisks
+
+	volume := "bitrot-bucket"
+	for _, disk := range storageDisks {
+		if err = disk.MakeVol(volume); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Small blocks, the part spans three full blocks and a last
+	// smaller one.
+	eInfo := newXLMetaV1(xl.dataBlocks, xl.parityBlocks).Erasure
+	eInfo.BlockSize = 64
+	eInfos := make([]erasureInfo, len(storageDisks))
+	for index := range eInfos {
+		eInfos[index] = eInfo
+	}
+	data := make([]byte, 200)
+	rand.New(rand.NewSource(1)).Read(data)
+	size := int64(len(data))
+	newEInfos, n, err := erasureCreateFile(storageDisks, volume, "object", "part.1", bytes.NewReader(data), eInfos, xl.writeQuorum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != size {
+		t.Fatalf("Expected %d bytes written, got %d", size, n)
+	}
+	for index, disk := range storageDisks {
+		checkSum := newEInfos[index].PartObjectChecksum("part.1")
+		if checkSum.Algorithm != blockBitRotAlgorithm {
+			t.Fatalf("Disk %d: Expected algorithm %s, got %s", index, blockBitRotAlgorithm, checkSum.Algorithm)
+		}
+		if !isValidBlock(disk, volume, "object", size, newEInfos[index], checkSum) {
+			t.Fatalf("Disk %d: Expected part to pass its checksum", index)
+		}
+	}
+	readFile := func(path, partName string, eInfos []erasureInfo, offset, length, totalLength int64) ([]byte, bool) {
+		var buffer bytes.Buffer
+		_, heal, rErr := erasureReadFile(&buffer, storageDisks, volume, path, partName, eInfos, offset, length, totalLength)
+		if rErr != nil {
+			t.Fatal(rErr)
+		}
+		return buffer.Bytes(), heal
+	}
+
+	// Corrupt the second block of the first data block's disk.
+	corruptIndex := 0
+	for index, blockIndex := range eInfo.Distribution {
+		if blockIndex == 1 {
+			corruptIndex = index
+		}
+	}
+	chunkSize := getEncodedBlockLen(eInfo.BlockSize, eInfo.DataBlocks)
+	partFile, err := os.OpenFile(filepath.Join(disks[corruptIndex], volume, "object"), os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = partFile.WriteAt([]byte("x"), blockBitRotHashSize+chunkSize+blockBitRotHashSize); err != nil {
+		t.Fatal(err)
+	}
+	partFile.Close()
+
+	// Blocks which are not read are not verified.
+	if readData, heal := readFile("object", "part.1", newEInfos, 0, eInfo.BlockSize, size); !bytes.Equal(readData, data[:eInfo.BlockSize]) || heal {
+		t.Fatalf("Expected first block to be read without healing, got heal %v", heal)
+	}
+	// Corrupted block is rebuilt from parity.
+	if readData, heal := readFile("object", "part.1", newEInfos, 0, size, size); !bytes.Equal(readData, data) || !heal {
+		t.Fatalf("Expected corrupted block to be rebuilt and healed, got heal %v", heal)
+	}
+	checkSum := newEInfos[corruptIndex].PartObjectChecksum("part.1")
+	if isValidBlock(storageDisks[corruptIndex], volume, "object", size, newEInfos[corruptIndex], checkSum) {
+		t.Fatal("Expected corrupted part to fail its checksum")
+	}
+
+	// Truncated part fails its checksum.
+	truncateIndex := (corruptIndex + 1) % len(storageDisks)
+	if err = os.Truncate(filepath.Join(disks[truncateIndex], volume, "object"), 3*(blockBitRotHashSize+chunkSize)); err != nil {
+		t.Fatal(err)
+	}
+	checkSum = newEInfos[truncateIndex].PartObjectChecksum("part.1")
+	if isValidBlock(storageDisks[truncateIndex], volume, "object", size, newEInfos[truncateIndex], checkSum) {
+		t.Fatal("Expected truncated part to fail its checksum")
+	}
+
+	// Healed blocks carry their hashes.
+	latestDisks := append([]StorageAPI(nil), storageDisks...)
+	outDatedDisks := make([]StorageAPI, len(storageDisks))
+	outDatedDisks[corruptIndex], latestDisks[corruptIndex] = latestDisks[corruptIndex], nil
+	outDatedDisks[truncateIndex], latestDisks[truncateIndex] = latestDisks[truncateIndex], nil
+	checkSums, err := erasureHealFile(latestDisks, outDatedDisks, volume, "object", volume, "healed", "part.1", size, newEInfos[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, index := range []int{corruptIndex, truncateIndex} {
+		if checkSums[index].Algorithm != blockBitRotAlgorithm {
+			t.Fatalf("Disk %d: Expected algorithm %s, got %s", index, blockBitRotAlgorithm, checkSums[index].Algorithm)
+		}
+		if !isValidBlock(storageDisks[index], volume, "healed", size, newEInfos[index], checkSums[index]) {
+			t.Fatalf("Disk %d: Expected healed part to pass its checksum", index)
+		}
+	}
+
+	// Parts with a whole part hash are still read.
+	blocks, err := encodeData(data, eInfo.DataBlocks, eInfo.ParityBlocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacyEInfo := newXLMetaV1(xl.dataBlocks, xl.parityBlocks).Erasure
+	legacyEInfos := make([]erasureInfo, len(storageDisks))
+	for index, disk := range storageDisks {
+		block := blocks[legacyEInfo.Distribution[index]-1]
+		if err = disk.AppendFile(volume, "legacy", block); err != nil {
+			t.Fatal(err)
+		}
+		hashWriter := newHash(wholeBitRotAlgorithm)
+		hashWriter.Write(block)
+		legacyEInfos[index] = legacyEInfo
+		legacyEInfos[index].Checksum = []checkSumInfo{{
+			Name:      "part.1",
+			Algorithm: wholeBitRotAlgorithm,
+			Hash:      hex.EncodeToString(hashWriter.Sum(nil)),
+		}}
+	}
+	if readData, heal := readFile("legacy", "part.1", legacyEInfos, 0, size, size); !bytes.Equal(readData, data) || heal {
+		t.Fatalf("Expected part with whole part hash to be read, got heal %v", heal)
+	}
+}
@@ -26,15 +26,36 @@ import (
 	"github.com/minio/blake2b-simd"
 )
 
+const (
+	// Hash of the whole part on each disk, verified before the part
+	// is read. Parts written by older releases carry this checksum.
+	wholeBitRotAlgorithm = "blake2b"
+	// Hash of each block, written in front of the block on each disk
+	// and verified as the block is read.
+	blockBitRotAlgorithm = "blake2b256-block"
+	// Size of the hash in front of each block.
+	blockBitRotHashSize = 32
+)
+
 // newHashWriters - inititialize a slice of hashes for the disk count.
 func newHashWriters(diskCount int) []hash.Hash {
 	hashWriters := make([]hash.Hash, diskCount)
 	for index := range hashWriters {
-		hashWriters[index] = newHash("blake2b")
+		hashWriters[index] = newHash(wholeBitRotAlgorithm)
 	}
 	return hashWriters
 }
 
+// newBitRotBlock - returns the block prefixed with its hash, as
+// written to the disk. Empty parts carry no blocks.
+func newBitRotBlock(block []byte) []byte {
+	if len(block) == 0 {
+		return block
+	}
+	blockHash := blake2b.Sum256(block)
+	return append(blockHash[:], block...)
+}
+
 // newHash - gives you a newly allocated hash depending on the input algorithm.
 func newHash(algo string) hash.Hash {
 	switch algo {
@@ -0,0 +1,360 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// errNotifyNotEnabled - notification target is not enabled.
+var errNotifyNotEnabled = errors.New("Notification target is not enabled")
+
+// errListenerBusy - listener is not keeping up with the events.
+var errListenerBusy = errors.New("Listener is not keeping up with events")
+
+// eventName - type of a bucket notification event.
+type eventName int
+
+// List of all supported bucket notification events.
+const (
+	// ObjectCreatedPut is s3:ObjectCreated:Put
+	ObjectCreatedPut eventName = iota
+	// ObjectCreatedPost is s3:ObjectCreated:Post
+	ObjectCreatedPost
+	// ObjectCreatedCopy is s3:ObjectCreated:Copy
+	ObjectCreatedCopy
+	// ObjectCreatedCompleteMultipartUpload is s3:ObjectCreated:CompleteMultipartUpload
+	ObjectCreatedCompleteMultipartUpload
+	// ObjectRemovedDelete is s3:ObjectRemoved:Delete
+	ObjectRemovedDelete
+)
+
+// Stringer interface for event name.
+func (eventName eventName) String() string {
+	switch eventName {
+	case ObjectCreatedPut:
+		return "s3:ObjectCreated:Put"
+	case ObjectCreatedPost:
+		return "s3:ObjectCreated:Post"
+	case ObjectCreatedCopy:
+		return "s3:ObjectCreated:Copy"
+	case ObjectCreatedCompleteMultipartUpload:
+		return "s3:ObjectCreated:CompleteMultipartUpload"
+	case ObjectRemovedDelete:
+		return "s3:ObjectRemoved:Delete"
+	default:
+		return "s3:Unknown"
+	}
+}
+
+// identity represents the accessKey who caused the event.
+type identity struct {
+	PrincipalID string `json:"principalId"`
+}
+
+// bucketMeta - notification event bucket metadata.
+type bucketMeta struct {
+	Name          string   `json:"name"`
+	OwnerIdentity identity `json:"ownerIdentity"`
+	ARN           string   `json:"arn"`
+}
+
+// objectMeta - notification event object metadata.
+type objectMeta struct {
+	Key       string `json:"key"`
+	Size      int64  `json:"size,omitempty"`
+	ETag      string `json:"eTag,omitempty"`
+	VersionID string `json:"versionId,omitempty"`
+	Sequencer string `json:"sequencer"`
+}
+
+// eventMeta - notification event s3 metadata.
+type eventMeta struct {
+	SchemaVersion   string     `json:"s3SchemaVersion"`
+	ConfigurationID string     `json:"configurationId"`
+	Bucket          bucketMeta `json:"bucket"`
+	Object          objectMeta `json:"object"`
+}
+
+// notificationEvent represents an S3 notification event record.
+type notificationEvent struct {
+	EventVersion      string            `json:"eventVersion"`
+	EventSource       string            `json:"eventSource"`
+	AwsRegion         string            `json:"awsRegion"`
+	EventTime         string            `json:"eventTime"`
+	EventName         string            `json:"eventName"`
+	UserIdentity      identity          `json:"userIdentity"`
+	RequestParameters map[string]string `json:"requestParameters"`
+	ResponseElements  map[string]string `json:"responseElements"`
+	S3                eventMeta         `json:"s3"`
+}
+
+// eventData - represents an event to be notified, sent by the
+// object handlers.
+type eventData struct {
+	Type      eventName
+	Bucket    string
+	ObjInfo   ObjectInfo
+	ReqParams map[string]string
+}
+
+// newNotificationEvent - constructs the S3 event record for the
+// notification configuration id.
+func newNotificationEvent(event eventData, configID string) notificationEvent {
+	region := serverConfig.GetRegion()
+	now := time.Now().UTC()
+	// Event names in records do not carry the "s3:" prefix.
+	eventStr := event.Type.String()[len("s3:"):]
+	return notificationEvent{
+		EventVersion:      "2.0",
+		EventSource:       "aws:s3",
+		AwsRegion:         region,
+		EventTime:         now.Format(timeFormatAMZ),
+		EventName:         eventStr,
+		UserIdentity:      identity{serverConfig.GetCredential().AccessKeyID},
+		RequestParameters: event.ReqParams,
+		ResponseElements:  map[string]string{},
+		S3: eventMeta{
+			SchemaVersion:   "1.0",
+			ConfigurationID: configID,
+			Bucket: bucketMeta{
+				Name:          event.Bucket,
+				OwnerIdentity: identity{serverConfig.GetCredential().AccessKeyID},
+				ARN:           "arn:aws:s3:::" + event.Bucket,
+			},
+			Object: objectMeta{
+				Key:       url.QueryEscape(event.ObjInfo.Name),
+				Size:      event.ObjInfo.Size,
+				ETag:      event.ObjInfo.MD5Sum,
+				VersionID: event.ObjInfo.VersionID,
+				Sequencer: fmt.Sprintf("%X", now.UnixNano()),
+			},
+		},
+	}
+}
+
+// Number of events buffered for a listener, listeners not keeping up
+// miss events and are closed.
+const listenerEventBuffer = 1000
+
+// listenerConfig - represents a client listening for bucket events.
+type listenerConfig struct {
+	events      []string
+	filterRules []filterRule
+	eventCh     chan notificationEvent
+	// Closed once the listener has missed an event.
+	overflowCh   chan struct{}
+	overflowOnce *sync.Once
+}
+
+// newListenerConfig - initializes a listener for the events matching
+// the filter rules.
+func newListenerConfig(events []string, filterRules []filterRule) *listenerConfig {
+	return &listenerConfig{
+		events:       events,
+		filterRules:  filterRules,
+		eventCh:      make(chan notificationEvent, listenerEventBuffer),
+		overflowCh:   make(chan struct{}),
+		overflowOnce: &sync.Once{},
+	}
+}
+
+// overflow - signals the listener missed an event, its stream is to
+// be closed for the client to listen again.
+func (l *listenerConfig) overflow() {
+	l.overflowOnce.Do(func() {
+		close(l.overflowCh)
+	})
+}
+
+// eventNotifier - holds notification configuration of all buckets and
+// the queue targets and listeners events are published to.
+type eventNotifier struct {
+	rwMutex             *sync.RWMutex
+	notificationConfigs map[string]*notificationConfig
+	queueTargets        map[string]*logrus.Logger
+	listeners           map[string][]*listenerConfig
+}
+
+// Global event notifier, initialized by initEventNotifier.
+var globalEventNotifier *eventNotifier
+
+// initEventNotifier - loads notification configuration of all buckets
+// and initializes all enabled queue targets.
+func initEventNotifier(objAPI ObjectLayer) error {
+	buckets, err := objAPI.ListBuckets()
+	if err != nil {
+		return err
+	}
+	notificationConfigs := make(map[string]*notificationConfig)
+	for _, bucket := range buckets {
+		nConfig, err := readBucketNotification(bucket.Name)
+		if err != nil {
+			if _, ok := err.(BucketNotificationNotFound); ok {
+				continue
+			}
+			return err
+		}
+		notificationConfigs[bucket.Name] = &nConfig
+	}
+
+	queueTargets := make(map[string]*logrus.Logger)
+	for accountID, whNotify := range serverConfig.GetWebhook() {
+		if !whNotify.Enable {
+			continue
+		}
+		webhookLog, err := newWebhookNotify(accountID)
+		if err != nil {
+			return err
+		}
+		queueARN := queueARN{
+			Region:    serverConfig.GetRegion(),
+			AccountID: accountID,
+			Type:      queueTypeWebhook,
+		}
+		queueTargets[queueARN.String()] = webhookLog
+	}
+
+	globalEventNotifier = &eventNotifier{
+		rwMutex:             &sync.RWMutex{},
+		notificationConfigs: notificationConfigs,
+		queueTargets:        queueTargets,
+		listeners:           make(map[string][]*listenerConfig),
+	}
+	return nil
+}
+
+// GetBucketNotificationConfig - returns the notification configuration
+// of the bucket, nil if not configured.
+func (en *eventNotifier) GetBucketNotificationConfig(bucket string) *notificationConfig {
+	if en == nil {
+		return nil
+	}
+	en.rwMutex.RLock()
+	defer en.rwMutex.RUnlock()
+	return en.notificationConfigs[bucket]
+}
+
+// SetBucketNotificationConfig - sets the notification configuration of
+// the bucket, nil removes the configuration.
+func (en *eventNotifier) SetBucketNotificationConfig(bucket string, nConfig *notificationConfig) {
+	if en == nil {
+		return
+	}
+	en.rwMutex.Lock()
+	defer en.rwMutex.Unlock()
+	if nConfig == nil {
+		delete(en.notificationConfigs, bucket)
+		return
+	}
+	en.notificationConfigs[bucket] = nConfig
+}
+
+// AddListener - adds a listener for events of the bucket.
+func (en *eventNotifier) AddListener(bucket string, listener *listenerConfig) {
+	en.rwMutex.Lock()
+	defer en.rwMutex.Unlock()
+	en.listeners[bucket] = append(en.listeners[bucket], listener)
+}
+
+// RemoveListener - removes a listener added by AddListener.
+func (en *eventNotifier) RemoveListener(bucket string, listener *listenerConfig) {
+	en.rwMutex.Lock()
+	defer en.rwMutex.Unlock()
+	listeners := en.listeners[bucket]
+	for i, l := range listeners {
+		if l == listener {
+			listeners = append(listeners[:i], listeners[i+1:]...)
+			break
+		}
+	}
+	if len(listeners) == 0 {
+		delete(en.listeners, bucket)
+		return
+	}
+	en.listeners[bucket] = listeners
+}
+
+// getListeners - returns all listeners of the bucket.
+func (en *eventNotifier) getListeners(bucket string) []*listenerConfig {
+	if en == nil {
+		return nil
+	}
+	en.rwMutex.RLock()
+	defer en.rwMutex.RUnlock()
+	return append([]*listenerConfig(nil), en.listeners[bucket]...)
+}
+
+// IsBucketNotificationSet - returns true if notifications are
+// configured on the bucket or clients are listening for its events.
+func (en *eventNotifier) IsBucketNotificationSet(bucket string) bool {
+	return en.GetBucketNotificationConfig(bucket) != nil || len(en.getListeners(bucket)) > 0
+}
+
+// getQueueTarget - returns the queue target for the queue ARN.
+func (en *eventNotifier) getQueueTarget(queueARN string) *logrus.Logger {
+	en.rwMutex.RLock()
+	defer en.rwMutex.RUnlock()
+	return en.queueTargets[queueARN]
+}
+
+// eventNotify - publishes the event to all queue targets and listeners
+// whose notification configuration matches the event.
+func eventNotify(event eventData) {
+	for _, listener := range globalEventNotifier.getListeners(event.Bucket) {
+		if !eventMatch(event.Type, listener.events) {
+			continue
+		}
+		if !filterMatch(event.ObjInfo.Name, listener.filterRules) {
+			continue
+		}
+		select {
+		case listener.eventCh <- newNotificationEvent(event, ""):
+		default:
+			errorIf(errListenerBusy, "Dropped event %s for %s/%s, closing listener.", event.Type, event.Bucket, event.ObjInfo.Name)
+			listener.overflow()
+		}
+	}
+
+	nConfig := globalEventNotifier.GetBucketNotificationConfig(event.Bucket)
+	if nConfig == nil {
+		return
+	}
+	for _, qConfig := range nConfig.QueueConfigs {
+		if !eventMatch(event.Type, qConfig.Events) {
+			continue
+		}
+		if !filterMatch(event.ObjInfo.Name, qConfig.Filter.Key.FilterRules) {
+			continue
+		}
+		target := globalEventNotifier.getQueueTarget(qConfig.QueueARN)
+		if target == nil {
+			continue
+		}
+		target.WithFields(logrus.Fields{
+			"EventType": event.Type.String(),
+			"Key":       event.Bucket + "/" + event.ObjInfo.Name,
+			"Records":   []notificationEvent{newNotificationEvent(event, qConfig.ID)},
+		}).Info()
+	}
+}
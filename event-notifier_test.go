@@ -0,0 +1,295 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Tests matching events and filter rules.
+func TestEventAndFilterMatch(t *testing.T) {
+	testCases := []struct {
+		event       eventName
+		events      []string
+		object      string
+		filterRules []filterRule
+		expected    bool
+	}{
+		// Test case - 1.
+		// Exact event match without filters.
+		{ObjectCreatedPut, []string{"s3:ObjectCreated:Put"}, "photos/a.jpg", nil, true},
+		// Test case - 2.
+		// Wildcard event match.
+		{ObjectCreatedCompleteMultipartUpload, []string{"s3:ObjectCreated:*"}, "photos/a.jpg", nil, true},
+		// Test case - 3.
+		// Event mismatch.
+		{ObjectRemovedDelete, []string{"s3:ObjectCreated:*"}, "photos/a.jpg", nil, false},
+		// Test case - 4.
+		// Prefix and suffix match.
+		{ObjectCreatedPost, []string{"s3:ObjectCreated:Post"}, "photos/a.jpg", []filterRule{{"prefix", "photos/"}, {"suffix", ".jpg"}}, true},
+		// Test case - 5.
+		// Suffix mismatch.
+		{ObjectCreatedCopy, []string{"s3:ObjectCreated:Copy"}, "photos/a.png", []filterRule{{"prefix", "photos/"}, {"suffix", ".jpg"}}, false},
+		// Test case - 6.
+		// Prefix mismatch.
+		{ObjectRemovedDelete, []string{"s3:ObjectRemoved:*"}, "videos/a.jpg", []filterRule{{"prefix", "photos/"}}, false},
+	}
+	for i, testCase := range testCases {
+		matched := eventMatch(testCase.event, testCase.events) && filterMatch(testCase.object, testCase.filterRules)
+		if matched != testCase.expected {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.expected, matched)
+		}
+	}
+}
+
+// Initializes server config with a webhook target pointing to the
+// endpoint, returns the root path of the config.
+func initWebhookTestConfig(t *testing.T, endpoint string) string {
+	rootPath, err := ioutil.TempDir("", "minio-notify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	setGlobalConfigPath(rootPath)
+	if err = initConfig(); err != nil {
+		t.Fatal(err)
+	}
+	serverConfig.SetWebhookNotifyByID("1", webhookNotify{
+		Enable:   true,
+		Endpoint: endpoint,
+	})
+	return rootPath
+}
+
+// Tests validating notification configuration.
+func TestValidateNotificationConfig(t *testing.T) {
+	rootPath := initWebhookTestConfig(t, "http://localhost:3000")
+	defer removeAll(rootPath)
+
+	validARN := "arn:minio:sqs:" + serverConfig.GetRegion() + ":1:webhook"
+	testCases := []struct {
+		qConfig  queueConfig
+		expected APIErrorCode
+	}{
+		// Test case - 1.
+		// Valid configuration.
+		{queueConfig{QueueARN: validARN, Events: []string{"s3:ObjectCreated:*"}}, ErrNone},
+		// Test case - 2.
+		// Unsupported event.
+		{queueConfig{QueueARN: validARN, Events: []string{"s3:ReducedRedundancyLostObject"}}, ErrEventNotification},
+		// Test case - 3.
+		// Unknown account id.
+		{queueConfig{QueueARN: "arn:minio:sqs:" + serverConfig.GetRegion() + ":2:webhook", Events: []string{"s3:ObjectCreated:*"}}, ErrARNNotification},
+		// Test case - 4.
+		// Malformed ARN.
+		{queueConfig{QueueARN: "arn:minio:sqs:webhook", Events: []string{"s3:ObjectCreated:*"}}, ErrARNNotification},
+		// Test case - 5.
+		// Invalid filter rule name.
+		{queueConfig{QueueARN: validARN, Events: []string{"s3:ObjectCreated:*"}, Filter: notificationFilter{keyFilter{[]filterRule{{"infix", "a"}}}}}, ErrFilterNameInvalid},
+		// Test case - 6.
+		// Repeated prefix rule.
+		{queueConfig{QueueARN: validARN, Events: []string{"s3:ObjectCreated:*"}, Filter: notificationFilter{keyFilter{[]filterRule{{"prefix", "a"}, {"prefix", "b"}}}}}, ErrFilterNamePrefix},
+	}
+	for i, testCase := range testCases {
+		nConfig := notificationConfig{QueueConfigs: []queueConfig{testCase.qConfig}}
+		if s3Error := validateNotificationConfig(nConfig); s3Error != testCase.expected {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.expected, s3Error)
+		}
+	}
+}
+
+// Tests events are delivered to a webhook endpoint.
+func TestWebhookEventNotify(t *testing.T) {
+	var mu sync.Mutex
+	var received []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		received = append(received, body)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	rootPath := initWebhookTestConfig(t, server.URL)
+	defer removeAll(rootPath)
+
+	obj, fsDir, err := getSingleNodeObjectLayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(fsDir)
+	if err = obj.MakeBucket("bucket"); err != nil {
+		t.Fatal(err)
+	}
+	if err = initEventNotifier(obj); err != nil {
+		t.Fatal(err)
+	}
+	globalEventNotifier.SetBucketNotificationConfig("bucket", &notificationConfig{
+		QueueConfigs: []queueConfig{{
+			ID:       "1",
+			QueueARN: "arn:minio:sqs:" + serverConfig.GetRegion() + ":1:webhook",
+			Events:   []string{"s3:ObjectCreated:*"},
+			Filter:   notificationFilter{keyFilter{[]filterRule{{"suffix", ".jpg"}}}},
+		}},
+	})
+	defer globalEventNotifier.SetBucketNotificationConfig("bucket", nil)
+
+	eventNotify(eventData{Type: ObjectCreatedPut, Bucket: "bucket", ObjInfo: ObjectInfo{Name: "a.jpg", Size: 10}})
+	// Does not match the suffix.
+	eventNotify(eventData{Type: ObjectCreatedPut, Bucket: "bucket", ObjInfo: ObjectInfo{Name: "a.png"}})
+	// Does not match the event.
+	eventNotify(eventData{Type: ObjectRemovedDelete, Bucket: "bucket", ObjInfo: ObjectInfo{Name: "a.jpg"}})
+
+	// Events are delivered in background.
+	for i := 0; ; i++ {
+		mu.Lock()
+		count := len(received)
+		mu.Unlock()
+		if count > 0 {
+			break
+		}
+		if i == 100 {
+			t.Fatal("Expected event to be delivered")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(received))
+	}
+	if received[0]["EventType"] != "s3:ObjectCreated:Put" || received[0]["Key"] != "bucket/a.jpg" {
+		t.Fatalf("Unexpected event %v", received[0])
+	}
+	records, ok := received[0]["Records"].([]interface{})
+	if !ok || len(records) != 1 {
+		t.Fatalf("Unexpected event records %v", received[0]["Records"])
+	}
+	record := records[0].(map[string]interface{})
+	if record["eventName"] != "ObjectCreated:Put" || record["eventSource"] != "aws:s3" {
+		t.Fatalf("Unexpected event record %v", record)
+	}
+	s3Meta := record["s3"].(map[string]interface{})
+	if s3Meta["configurationId"] != "1" || s3Meta["object"].(map[string]interface{})["key"] != "a.jpg" {
+		t.Fatalf("Unexpected event record %v", record)
+	}
+}
+
+// Tests streaming events to a client listening for bucket notifications.
+func TestListenBucketNotification(t *testing.T) {
+	testServer := StartTestServer(t, "FS")
+	defer testServer.Stop()
+
+	client := http.Client{}
+	bucketURL := testServer.Server.URL + "/listenbucket"
+	request, err := newTestRequest("PUT", bucketURL, 0, nil, testServer.AccessKey, testServer.SecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Unable to create bucket, received %s", response.Status)
+	}
+
+	// Unsupported events are rejected.
+	request, err = newTestRequest("GET", bucketURL+"?events=s3:Unknown", 0, nil, testServer.AccessKey, testServer.SecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err = client.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected %d, got %d", http.StatusBadRequest, response.StatusCode)
+	}
+
+	request, err = newTestRequest("GET", bucketURL+"?events=s3:ObjectCreated:*&prefix=photos/&suffix=.jpg",
+		0, nil, testServer.AccessKey, testServer.SecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err = client.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected %d, got %d", http.StatusOK, response.StatusCode)
+	}
+
+	// Only the last object matches the prefix and suffix.
+	for _, object := range []string{"videos/a.jpg", "photos/a.png", "photos/a.jpg"} {
+		data := []byte("hello")
+		request, err = newTestRequest("PUT", bucketURL+"/"+object, int64(len(data)), bytes.NewReader(data),
+			testServer.AccessKey, testServer.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var putResponse *http.Response
+		putResponse, err = client.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		putResponse.Body.Close()
+		if putResponse.StatusCode != http.StatusOK {
+			t.Fatalf("Unable to upload %s, received %s", object, putResponse.Status)
+		}
+	}
+
+	var event struct {
+		Records []notificationEvent
+	}
+	if err = json.NewDecoder(response.Body).Decode(&event); err != nil {
+		t.Fatal(err)
+	}
+	if len(event.Records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(event.Records))
+	}
+	record := event.Records[0]
+	if record.EventName != "ObjectCreated:Put" || record.S3.Object.Key != url.QueryEscape("photos/a.jpg") {
+		t.Fatalf("Unexpected event record %v", record)
+	}
+
+	// Listeners missing events are closed.
+	listener := newListenerConfig([]string{"s3:ObjectCreated:*"}, nil)
+	globalEventNotifier.AddListener("listenbucket", listener)
+	defer globalEventNotifier.RemoveListener("listenbucket", listener)
+	for i := 0; i <= listenerEventBuffer; i++ {
+		eventNotify(eventData{Type: ObjectCreatedPut, Bucket: "listenbucket", ObjInfo: ObjectInfo{Name: "object"}})
+	}
+	select {
+	case <-listener.overflowCh:
+	default:
+		t.Fatal("Expected listener missing events to be closed")
+	}
+}
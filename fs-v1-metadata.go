@@ -18,7 +18,11 @@ type fsMetaV1 struct {
 	Minio   struct {
 		Release string `json:"release"`
 	} `json:"minio"`
-	Parts []objectPartInfo `json:"parts,omitempty"`
+	// Version id of the object, empty if versioning was never configured.
+	VersionID string `json:"versionId,omitempty"`
+	// Metadata map for current object `fs.json`.
+	Meta  map[string]string `json:"meta,omitempty"`
+	Parts []objectPartInfo  `json:"parts,omitempty"`
 }
 
 // ObjectPartIndex - returns the index of matching object part number.
@@ -69,6 +73,12 @@ func readFSMetadata(disk StorageAPI, bucket, object string) (fsMeta fsMetaV1, er
 		return fsMetaV1{}, err
 	}
 
+	// `fs.json` written by older releases carry no metadata map,
+	// allocate one so that callers can update it safely.
+	if fsMeta.Meta == nil {
+		fsMeta.Meta = make(map[string]string)
+	}
+
 	// Success.
 	return fsMeta, nil
 }
@@ -107,6 +117,9 @@ func writeFSFormatData(storage StorageAPI, fsFormat formatConfigV1) error {
 }
 
 // writeFSMetadata - writes `fs.json` metadata.
+//
+// Note: `fs.json` is appended to, callers should always write to a
+// fresh temporary prefix and rename it to its final location.
 func (fs fsObjects) writeFSMetadata(bucket, prefix string, fsMeta fsMetaV1) error {
 	metadataBytes, err := json.Marshal(fsMeta)
 	if err != nil {
@@ -117,3 +130,21 @@ func (fs fsObjects) writeFSMetadata(bucket, prefix string, fsMeta fsMetaV1) erro
 	}
 	return nil
 }
+
+// writeObjectFSMetadata - writes `fs.json` for a committed object at
+// '.minio/buckets/bucket/object/fs.json', replacing any previous content.
+func (fs fsObjects) writeObjectFSMetadata(bucket, object string, fsMeta fsMetaV1) error {
+	tempMetaPath := path.Join(tmpMetaPrefix, getUUID())
+	if err := fs.writeFSMetadata(minioMetaBucket, tempMetaPath, fsMeta); err != nil {
+		return err
+	}
+	fsMetaPath := path.Join(bucketMetaPrefix, bucket, object, fsMetaJSONFile)
+	err := fs.storage.RenameFile(minioMetaBucket, path.Join(tempMetaPath, fsMetaJSONFile), minioMetaBucket, fsMetaPath)
+	if err != nil {
+		if dErr := fs.storage.DeleteFile(minioMetaBucket, path.Join(tempMetaPath, fsMetaJSONFile)); dErr != nil {
+			return dErr
+		}
+		return err
+	}
+	return nil
+}
@@ -212,8 +212,14 @@ func (fs fsObjects) newMultipartUpload(bucket string, object string, meta map[st
 		meta = make(map[string]string)
 	}
 
+	// If not set default to "application/octet-stream"
+	if meta["content-type"] == "" {
+		meta["content-type"] = getUploadContentType(object)
+	}
+
 	// Initialize `fs.json` values.
 	fsMeta := newFSMetaV1()
+	fsMeta.Meta = meta
 
 	// This lock needs to be held for any changes to the directory contents of ".minio/multipart/object/"
 	nsMutex.Lock(minioMetaBucket, pathJoin(mpartMetaPrefix, bucket, object))
@@ -247,7 +253,6 @@ func (fs fsObjects) newMultipartUpload(bucket string, object string, meta map[st
 //
 // Implements S3 compatible initiate multipart API.
 func (fs fsObjects) NewMultipartUpload(bucket, object string, meta map[string]string) (string, error) {
-	meta = make(map[string]string) // Reset the meta value, we are not going to save headers for fs.
 	// Verify if bucket name is valid.
 	if !IsValidBucketName(bucket) {
 		return "", BucketNameInvalid{Bucket: bucket}
@@ -373,6 +378,14 @@ func (fs fsObjects) listObjectParts(bucket, object, uploadID string, partNumberM
 	if err != nil {
 		return ListPartsInfo{}, toObjectErr(err, minioMetaBucket, uploadIDPath)
 	}
+	result.UserDefined = fsMeta.Meta
+	// For maxParts as zero, return right here.
+	if maxParts == 0 {
+		result.Bucket = bucket
+		result.Object = object
+		result.UploadID = uploadID
+		return result, nil
+	}
 	// Only parts with higher part numbers will be listed.
 	partIdx := fsMeta.ObjectPartIndex(partNumberMarker)
 	parts := fsMeta.Parts
@@ -449,16 +462,24 @@ func (fs fsObjects) ListObjectParts(bucket, object, uploadID string, partNumberM
 //
 // Implements S3 compatible Complete multipart API.
 func (fs fsObjects) CompleteMultipartUpload(bucket string, object string, uploadID string, parts []completePart) (string, error) {
+	objInfo, err := fs.CompleteMultipartUploadIf(bucket, object, uploadID, parts, writeCondition{})
+	return objInfo.MD5Sum, err
+}
+
+// CompleteMultipartUploadIf - completes an ongoing multipart
+// transaction if the current object meets the write condition, the
+// upload is left intact otherwise.
+func (fs fsObjects) CompleteMultipartUploadIf(bucket string, object string, uploadID string, parts []completePart, cond writeCondition) (ObjectInfo, error) {
 	// Verify if bucket is valid.
 	if !IsValidBucketName(bucket) {
-		return "", BucketNameInvalid{Bucket: bucket}
+		return ObjectInfo{}, BucketNameInvalid{Bucket: bucket}
 	}
 	// Verify whether the bucket exists.
 	if !fs.isBucketExist(bucket) {
-		return "", BucketNotFound{Bucket: bucket}
+		return ObjectInfo{}, BucketNotFound{Bucket: bucket}
 	}
 	if !IsValidObjectName(object) {
-		return "", ObjectNameInvalid{
+		return ObjectInfo{}, ObjectNameInvalid{
 			Bucket: bucket,
 			Object: object,
 		}
@@ -473,19 +494,19 @@ func (fs fsObjects) CompleteMultipartUpload(bucket string, object string, upload
 	defer nsMutex.Unlock(minioMetaBucket, pathJoin(mpartMetaPrefix, bucket, object, uploadID))
 
 	if !fs.isUploadIDExists(bucket, object, uploadID) {
-		return "", InvalidUploadID{UploadID: uploadID}
+		return ObjectInfo{}, InvalidUploadID{UploadID: uploadID}
 	}
 
 	// Read saved fs metadata for ongoing multipart.
 	fsMeta, err := readFSMetadata(fs.storage, minioMetaBucket, uploadIDPath)
 	if err != nil {
-		return "", toObjectErr(err, minioMetaBucket, uploadIDPath)
+		return ObjectInfo{}, toObjectErr(err, minioMetaBucket, uploadIDPath)
 	}
 
 	// Calculate s3 compatible md5sum for complete multipart.
 	s3MD5, err := completeMultipartMD5(parts...)
 	if err != nil {
-		return "", err
+		return ObjectInfo{}, err
 	}
 
 	tempObj := path.Join(tmpMetaPrefix, uploadID, "part.1")
@@ -493,23 +514,27 @@ func (fs fsObjects) CompleteMultipartUpload(bucket string, object string, upload
 	// Allocate 128KiB of staging buffer.
 	var buf = make([]byte, readSizeV1)
 
+	// Parts making up the final object.
+	var objectParts []objectPartInfo
+
 	// Loop through all parts, validate them and then commit to disk.
 	for i, part := range parts {
 		partIdx := fsMeta.ObjectPartIndex(part.PartNumber)
 		if partIdx == -1 {
-			return "", InvalidPart{}
+			return ObjectInfo{}, InvalidPart{}
 		}
 		if fsMeta.Parts[partIdx].ETag != part.ETag {
-			return "", BadDigest{}
+			return ObjectInfo{}, BadDigest{}
 		}
 		// All parts except the last part has to be atleast 5MB.
 		if (i < len(parts)-1) && !isMinAllowedPartSize(fsMeta.Parts[partIdx].Size) {
-			return "", PartTooSmall{
+			return ObjectInfo{}, PartTooSmall{
 				PartNumber: part.PartNumber,
 				PartSize:   fsMeta.Parts[partIdx].Size,
 				PartETag:   part.ETag,
 			}
 		}
+		objectParts = append(objectParts, fsMeta.Parts[partIdx])
 		// Construct part suffix.
 		partSuffix := fmt.Sprintf("object%d", part.PartNumber)
 		multipartPartFile := path.Join(mpartMetaPrefix, bucket, object, uploadID, partSuffix)
@@ -524,7 +549,7 @@ func (fs fsObjects) CompleteMultipartUpload(bucket string, object string, upload
 			n, err = fs.storage.ReadFile(minioMetaBucket, multipartPartFile, offset, buf[:curLeft])
 			if n > 0 {
 				if err = fs.storage.AppendFile(minioMetaBucket, tempObj, buf[:n]); err != nil {
-					return "", toObjectErr(err, minioMetaBucket, tempObj)
+					return ObjectInfo{}, toObjectErr(err, minioMetaBucket, tempObj)
 				}
 			}
 			if err != nil {
@@ -532,27 +557,30 @@ func (fs fsObjects) CompleteMultipartUpload(bucket string, object string, upload
 					break
 				}
 				if err == errFileNotFound {
-					return "", InvalidPart{}
+					return ObjectInfo{}, InvalidPart{}
 				}
-				return "", toObjectErr(err, minioMetaBucket, multipartPartFile)
+				return ObjectInfo{}, toObjectErr(err, minioMetaBucket, multipartPartFile)
 			}
 			offset += n
 			totalLeft -= n
 		}
 	}
 
-	// Rename the file back to original location, if not delete the temporary object.
-	err = fs.storage.RenameFile(minioMetaBucket, tempObj, bucket, object)
+	// Save the object metadata carried over from the upload along
+	// with successfully calculated md5sum.
+	fsMeta.Meta["md5Sum"] = s3MD5
+	// Only the parts making up the object are kept in final `fs.json`.
+	fsMeta.Parts = objectParts
+
+	// Rename the file back to original location along with its `fs.json`.
+	objInfo, err := fs.commitObject(bucket, object, tempObj, fsMeta, cond)
 	if err != nil {
-		if dErr := fs.storage.DeleteFile(minioMetaBucket, tempObj); dErr != nil {
-			return "", toObjectErr(dErr, minioMetaBucket, tempObj)
-		}
-		return "", toObjectErr(err, bucket, object)
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
 	}
 
 	// Cleanup all the parts if everything else has been safely committed.
 	if err = cleanupUploadedParts(bucket, object, uploadID, fs.storage); err != nil {
-		return "", err
+		return ObjectInfo{}, err
 	}
 
 	// Hold the lock so that two parallel complete-multipart-uploads do not
@@ -564,7 +592,7 @@ func (fs fsObjects) CompleteMultipartUpload(bucket string, object string, upload
 	// the object, if yes do not attempt to delete 'uploads.json'.
 	uploadsJSON, err := readUploadsJSON(bucket, object, fs.storage)
 	if err != nil {
-		return "", toObjectErr(err, minioMetaBucket, object)
+		return ObjectInfo{}, toObjectErr(err, minioMetaBucket, object)
 	}
 	// If we have successfully read `uploads.json`, then we proceed to
 	// purge or update `uploads.json`.
@@ -574,18 +602,18 @@ func (fs fsObjects) CompleteMultipartUpload(bucket string, object string, upload
 	}
 	if len(uploadsJSON.Uploads) > 0 {
 		if err = fs.updateUploadsJSON(bucket, object, uploadsJSON); err != nil {
-			return "", toObjectErr(err, minioMetaBucket, path.Join(mpartMetaPrefix, bucket, object))
+			return ObjectInfo{}, toObjectErr(err, minioMetaBucket, path.Join(mpartMetaPrefix, bucket, object))
 		}
 		// Return success.
-		return s3MD5, nil
+		return objInfo, nil
 	}
 
 	if err = fs.storage.DeleteFile(minioMetaBucket, path.Join(mpartMetaPrefix, bucket, object, uploadsJSONFile)); err != nil {
-		return "", toObjectErr(err, minioMetaBucket, path.Join(mpartMetaPrefix, bucket, object))
+		return ObjectInfo{}, toObjectErr(err, minioMetaBucket, path.Join(mpartMetaPrefix, bucket, object))
 	}
 
-	// Return md5sum.
-	return s3MD5, nil
+	// Return info of the object.
+	return objInfo, nil
 }
 
 // abortMultipartUpload - wrapper for purging an ongoing multipart
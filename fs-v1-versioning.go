@@ -0,0 +1,288 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"path"
+)
+
+// Returns if the prefix is an object.
+func (fs fsObjects) isObject(bucket, prefix string) bool {
+	_, err := fs.storage.StatFile(bucket, prefix)
+	return err == nil
+}
+
+// Returns if the prefix has saved object versions.
+func (fs fsObjects) isVersionedObject(bucket, prefix string) bool {
+	_, err := fs.storage.StatFile(bucket, pathJoin(prefix, versionsJSONFile))
+	return err == nil
+}
+
+// getArchivedObjectInfo - constructs ObjectInfo of an archived
+// object version.
+func (fs fsObjects) getArchivedObjectInfo(bucket, object, versionID string) (ObjectInfo, error) {
+	versionPath := objectVersionPath(bucket, object, versionID)
+	fi, err := fs.storage.StatFile(minioMetaBucket, path.Join(versionPath, "part.1"))
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	fsMeta, err := readFSMetadata(fs.storage, minioMetaBucket, versionPath)
+	if err != nil && err != errFileNotFound {
+		return ObjectInfo{}, err
+	}
+	return newFSObjectInfo(bucket, object, fi, fsMeta), nil
+}
+
+// readVersions - reads `versions.json` of an object.
+func (fs fsObjects) readVersions(bucket, object string) (versionsV1, error) {
+	versions, err := readVersionsJSON(bucket, object, fs.storage)
+	if err == errFileNotFound {
+		// Set versions format to `fs`.
+		return newVersionsV1("fs"), nil
+	}
+	return versions, err
+}
+
+// writeVersions - writes `versions.json` of an object, removes it if
+// there are no versions left.
+func (fs fsObjects) writeVersions(bucket, object string, versions versionsV1) error {
+	versionsPath := path.Join(versionsMetaPrefix, bucket, object, versionsJSONFile)
+	if len(versions.Versions) == 0 {
+		err := fs.storage.DeleteFile(minioMetaBucket, versionsPath)
+		if err != nil && err != errFileNotFound {
+			return err
+		}
+		return nil
+	}
+	versionsBytes, err := json.Marshal(versions)
+	if err != nil {
+		return err
+	}
+	tmpVersionsPath := path.Join(tmpMetaPrefix, getUUID())
+	if err = fs.storage.AppendFile(minioMetaBucket, tmpVersionsPath, versionsBytes); err != nil {
+		return err
+	}
+	if err = fs.storage.RenameFile(minioMetaBucket, tmpVersionsPath, minioMetaBucket, versionsPath); err != nil {
+		if dErr := fs.storage.DeleteFile(minioMetaBucket, tmpVersionsPath); dErr != nil {
+			return dErr
+		}
+		return err
+	}
+	return nil
+}
+
+// archiveObject - moves the object along with its `fs.json` to
+// '.minio/versions/bucket/object/versionID'.
+func (fs fsObjects) archiveObject(bucket, object, versionID string) error {
+	versionPath := objectVersionPath(bucket, object, versionID)
+	if err := fs.storage.RenameFile(bucket, object, minioMetaBucket, path.Join(versionPath, "part.1")); err != nil {
+		return err
+	}
+	// Objects written by older releases do not have `fs.json`.
+	objectMetaPath := path.Join(bucketMetaPrefix, bucket, object)
+	err := fs.storage.RenameFile(minioMetaBucket, path.Join(objectMetaPath, fsMetaJSONFile), minioMetaBucket, path.Join(versionPath, fsMetaJSONFile))
+	if err != nil && err != errFileNotFound {
+		return err
+	}
+	// Cleanup the empty directories left behind.
+	deleteEmptyDir(fs.storage, bucket, path.Dir(object))
+	deleteEmptyDir(fs.storage, minioMetaBucket, objectMetaPath)
+	return nil
+}
+
+// restoreObject - moves an archived object version back as the
+// current version of the object.
+func (fs fsObjects) restoreObject(bucket, object, versionID string) error {
+	versionPath := objectVersionPath(bucket, object, versionID)
+	if err := fs.storage.RenameFile(minioMetaBucket, path.Join(versionPath, "part.1"), bucket, object); err != nil {
+		return err
+	}
+	err := fs.storage.RenameFile(minioMetaBucket, path.Join(versionPath, fsMetaJSONFile), minioMetaBucket, path.Join(bucketMetaPrefix, bucket, object, fsMetaJSONFile))
+	if err != nil && err != errFileNotFound {
+		return err
+	}
+	deleteEmptyDir(fs.storage, minioMetaBucket, versionPath)
+	return nil
+}
+
+// deleteObjectVersion - deletes an archived object version.
+func (fs fsObjects) deleteObjectVersion(bucket, object, versionID string) error {
+	versionPath := objectVersionPath(bucket, object, versionID)
+	if err := fs.storage.DeleteFile(minioMetaBucket, path.Join(versionPath, "part.1")); err != nil {
+		return err
+	}
+	err := fs.storage.DeleteFile(minioMetaBucket, path.Join(versionPath, fsMetaJSONFile))
+	if err != nil && err != errFileNotFound {
+		return err
+	}
+	return nil
+}
+
+// commitObject - renames the object written at the temporary location
+// to its actual location and saves its `fs.json`, if the current object
+// meets the write condition. With versioning configured on the bucket
+// the current version of the object is archived before it is replaced.
+// Returns the info of the committed object.
+func (fs fsObjects) commitObject(bucket, object, tempObj string, fsMeta fsMetaV1, cond writeCondition) (ObjectInfo, error) {
+	// Hold write lock on the destination before rename.
+	nsMutex.Lock(bucket, object)
+	defer nsMutex.Unlock(bucket, object)
+
+	if err := checkWriteCondition(fs, bucket, object, cond); err != nil {
+		fs.storage.DeleteFile(minioMetaBucket, tempObj)
+		return ObjectInfo{}, err
+	}
+
+	// Version of the object is decided under the lock.
+	versionID, err := newObjectVersionID(bucket)
+	if err != nil {
+		fs.storage.DeleteFile(minioMetaBucket, tempObj)
+		return ObjectInfo{}, err
+	}
+	fsMeta.VersionID = versionID
+
+	var versions versionsV1
+	if versionID != "" {
+		if versions, err = prepareObjectVersion(fs, bucket, object, versionID); err != nil {
+			fs.storage.DeleteFile(minioMetaBucket, tempObj)
+			return ObjectInfo{}, err
+		}
+	}
+
+	// Rename the object to its actual location, if not delete the temporary object.
+	if err = fs.storage.RenameFile(minioMetaBucket, tempObj, bucket, object); err != nil {
+		if dErr := fs.storage.DeleteFile(minioMetaBucket, tempObj); dErr != nil {
+			return ObjectInfo{}, dErr
+		}
+		return ObjectInfo{}, err
+	}
+
+	// Save object metadata in `fs.json`.
+	if err = fs.writeObjectFSMetadata(bucket, object, fsMeta); err != nil {
+		return ObjectInfo{}, err
+	}
+
+	fi, err := fs.storage.StatFile(bucket, object)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	if versionID != "" {
+		err = commitObjectVersion(fs, bucket, object, versions, versionInfo{
+			VersionID: versionID,
+			ModTime:   fi.ModTime,
+			Size:      fi.Size,
+			MD5Sum:    fsMeta.Meta["md5Sum"],
+		})
+		if err != nil {
+			return ObjectInfo{}, err
+		}
+	}
+	return newFSObjectInfo(bucket, object, fi, fsMeta), nil
+}
+
+// GetObjectVersion - get a version of an object, empty versionID
+// gets the latest version.
+func (fs fsObjects) GetObjectVersion(bucket, object, versionID string, offset int64, length int64, writer io.Writer) error {
+	// Verify if bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return BucketNameInvalid{Bucket: bucket}
+	}
+	// Verify if object is valid.
+	if !IsValidObjectName(object) {
+		return ObjectNameInvalid{Bucket: bucket, Object: object}
+	}
+	nsMutex.RLock(bucket, object)
+	defer nsMutex.RUnlock(bucket, object)
+
+	objInfo, archived, err := resolveObjectVersion(fs, bucket, object, versionID)
+	if err != nil {
+		return toVersionErr(err, bucket, object, versionID)
+	}
+	// Delete markers have no data.
+	if objInfo.DeleteMarker {
+		return toObjectErr(errFileNotFound, bucket, object)
+	}
+	if !archived {
+		return toObjectErr(fs.getObject(bucket, object, offset, length, writer), bucket, object)
+	}
+	versionPath := objectVersionPath(bucket, object, objInfo.VersionID)
+	err = fs.getObject(minioMetaBucket, path.Join(versionPath, "part.1"), offset, length, writer)
+	return toVersionErr(err, bucket, object, versionID)
+}
+
+// GetObjectVersionInfo - get object info of a version of an object,
+// empty versionID gets the latest version.
+func (fs fsObjects) GetObjectVersionInfo(bucket, object, versionID string) (ObjectInfo, error) {
+	// Verify if bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return ObjectInfo{}, BucketNameInvalid{Bucket: bucket}
+	}
+	// Verify if object is valid.
+	if !IsValidObjectName(object) {
+		return ObjectInfo{}, ObjectNameInvalid{Bucket: bucket, Object: object}
+	}
+	nsMutex.RLock(bucket, object)
+	defer nsMutex.RUnlock(bucket, object)
+
+	objInfo, _, err := resolveObjectVersion(fs, bucket, object, versionID)
+	if err != nil {
+		return ObjectInfo{}, toVersionErr(err, bucket, object, versionID)
+	}
+	return objInfo, nil
+}
+
+// DeleteObjectVersion - permanently deletes a version of an object,
+// returns the object info of the deleted version.
+func (fs fsObjects) DeleteObjectVersion(bucket, object, versionID string) (ObjectInfo, error) {
+	// Verify if bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return ObjectInfo{}, BucketNameInvalid{Bucket: bucket}
+	}
+	if !IsValidObjectName(object) {
+		return ObjectInfo{}, ObjectNameInvalid{Bucket: bucket, Object: object}
+	}
+	nsMutex.Lock(bucket, object)
+	defer nsMutex.Unlock(bucket, object)
+
+	objInfo, _, err := resolveObjectVersion(fs, bucket, object, versionID)
+	if err != nil {
+		return ObjectInfo{}, toVersionErr(err, bucket, object, versionID)
+	}
+	if err = removeObjectVersion(fs, bucket, object, versionID); err != nil {
+		return ObjectInfo{}, toVersionErr(err, bucket, object, versionID)
+	}
+	return objInfo, nil
+}
+
+// ListObjectVersions - list all versions of all objects.
+func (fs fsObjects) ListObjectVersions(bucket, prefix, keyMarker, versionIDMarker, delimiter string, maxKeys int) (ListObjectVersionsInfo, error) {
+	// Verify if bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return ListObjectVersionsInfo{}, BucketNameInvalid{Bucket: bucket}
+	}
+	// Verify if bucket exists.
+	if !fs.isBucketExist(bucket) {
+		return ListObjectVersionsInfo{}, BucketNotFound{Bucket: bucket}
+	}
+	result, err := listBucketVersions(fs, bucket, prefix, keyMarker, versionIDMarker, delimiter, maxKeys)
+	if err != nil {
+		return ListObjectVersionsInfo{}, toObjectErr(err, bucket, prefix)
+	}
+	return result, nil
+}
@@ -21,14 +21,13 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"io"
+	"net/url"
 	"os"
 	"path"
-	"path/filepath"
 	"sort"
 	"strings"
 
 	"github.com/minio/minio/pkg/disk"
-	"github.com/minio/minio/pkg/mimedb"
 )
 
 // fsObjects - Implements fs object layer.
@@ -67,15 +66,18 @@ func shutdownFS(storage StorageAPI) {
 	_, err := storage.ListDir(minioMetaBucket, mpartMetaPrefix)
 	if err != errFileNotFound {
 		// Multipart directory is not empty hence do not remove .minio volume.
-		os.Exit(0)
+		return
+	}
+	_, err = storage.ListDir(minioMetaBucket, bucketMetaPrefix)
+	if err != errFileNotFound {
+		// Object metadata directory is not empty hence do not remove .minio volume.
+		return
 	}
 	prefix := ""
 	if err := cleanupDir(storage, minioMetaBucket, prefix); err != nil {
-		os.Exit(0)
 		return
 	}
 	storage.DeleteVol(minioMetaBucket)
-	os.Exit(0)
 }
 
 // newFSObjects - initialize new fs object layer.
@@ -184,9 +186,17 @@ func (fs fsObjects) DeleteBucket(bucket string) error {
 	if !IsValidBucketName(bucket) {
 		return BucketNameInvalid{Bucket: bucket}
 	}
+	// Bucket with object versions left is not empty.
+	if hasObjectVersions(bucket, fs.storage) {
+		return BucketNotEmpty{Bucket: bucket}
+	}
 	if err := fs.storage.DeleteVol(bucket); err != nil {
 		return toObjectErr(err, bucket)
 	}
+	// Cleanup any left over object metadata for the bucket.
+	if err := cleanupDir(fs.storage, minioMetaBucket, path.Join(bucketMetaPrefix, bucket)); err != nil {
+		return toObjectErr(err, bucket)
+	}
 	return nil
 }
 
@@ -202,6 +212,12 @@ func (fs fsObjects) GetObject(bucket, object string, offset int64, length int64,
 	if !IsValidObjectName(object) {
 		return ObjectNameInvalid{Bucket: bucket, Object: object}
 	}
+	return toObjectErr(fs.getObject(bucket, object, offset, length, writer), bucket, object)
+}
+
+// getObject - wrapper for reading an object, writes length bytes of
+// the object at offset to the writer.
+func (fs fsObjects) getObject(bucket, object string, offset int64, length int64, writer io.Writer) (err error) {
 	var totalLeft = length
 	buf := make([]byte, readSizeV1) // Allocate a 128KiB staging buffer.
 	for totalLeft > 0 {
@@ -240,7 +256,7 @@ func (fs fsObjects) GetObject(bucket, object string, offset int64, length int64,
 		}
 	}
 	// Returns any error.
-	return toObjectErr(err, bucket, object)
+	return err
 }
 
 // GetObjectInfo - get object info.
@@ -253,38 +269,72 @@ func (fs fsObjects) GetObjectInfo(bucket, object string) (ObjectInfo, error) {
 	if !IsValidObjectName(object) {
 		return ObjectInfo{}, (ObjectNameInvalid{Bucket: bucket, Object: object})
 	}
-	fi, err := fs.storage.StatFile(bucket, object)
+	objInfo, err := fs.getObjectInfo(bucket, object)
 	if err != nil {
 		return ObjectInfo{}, toObjectErr(err, bucket, object)
 	}
+	return objInfo, nil
+}
+
+// getObjectInfo - wrapper for reading object stat and metadata and
+// constructs ObjectInfo.
+func (fs fsObjects) getObjectInfo(bucket, object string) (ObjectInfo, error) {
+	fi, err := fs.storage.StatFile(bucket, object)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	// Read saved metadata if any, objects written by older releases
+	// do not have `fs.json` hence fall back to defaults.
+	fsMeta, err := readFSMetadata(fs.storage, minioMetaBucket, path.Join(bucketMetaPrefix, bucket, object))
+	if err != nil && err != errFileNotFound {
+		return ObjectInfo{}, err
+	}
+	return newFSObjectInfo(bucket, object, fi, fsMeta), nil
+}
+
+// newFSObjectInfo - constructs ObjectInfo from the file stat and the
+// saved object metadata.
+func newFSObjectInfo(bucket, object string, fi FileInfo, fsMeta fsMetaV1) ObjectInfo {
+	if fsMeta.Meta == nil {
+		fsMeta.Meta = make(map[string]string)
+	}
 
 	// Guess content-type from the extension if possible.
-	contentType := ""
-	if objectExt := filepath.Ext(object); objectExt != "" {
-		if content, ok := mimedb.DB[strings.ToLower(strings.TrimPrefix(objectExt, "."))]; ok {
-			contentType = content.ContentType
-		}
+	if fsMeta.Meta["content-type"] == "" {
+		fsMeta.Meta["content-type"] = guessContentType(object)
 	}
 
 	return ObjectInfo{
-		Bucket:      bucket,
-		Name:        object,
-		ModTime:     fi.ModTime,
-		Size:        fi.Size,
-		IsDir:       fi.Mode.IsDir(),
-		ContentType: contentType,
-		MD5Sum:      "", // Read from metadata.
-	}, nil
+		Bucket:          bucket,
+		Name:            object,
+		ModTime:         fi.ModTime,
+		Size:            fi.Size,
+		IsDir:           fi.Mode.IsDir(),
+		MD5Sum:          fsMeta.Meta["md5Sum"],
+		ContentType:     fsMeta.Meta["content-type"],
+		ContentEncoding: fsMeta.Meta["content-encoding"],
+		UserDefined:     fsMeta.Meta,
+		VersionID:       fsMeta.VersionID,
+		Parts:           fsMeta.Parts,
+	}
 }
 
 // PutObject - create an object.
 func (fs fsObjects) PutObject(bucket string, object string, size int64, data io.Reader, metadata map[string]string) (string, error) {
+	objInfo, err := fs.PutObjectIf(bucket, object, size, data, metadata, writeCondition{})
+	return objInfo.MD5Sum, err
+}
+
+// PutObjectIf - create an object if the current object meets the
+// write condition.
+func (fs fsObjects) PutObjectIf(bucket string, object string, size int64, data io.Reader, metadata map[string]string, cond writeCondition) (ObjectInfo, error) {
 	// Verify if bucket is valid.
 	if !IsValidBucketName(bucket) {
-		return "", BucketNameInvalid{Bucket: bucket}
+		return ObjectInfo{}, BucketNameInvalid{Bucket: bucket}
 	}
 	if !IsValidObjectName(object) {
-		return "", ObjectNameInvalid{
+		return ObjectInfo{}, ObjectNameInvalid{
 			Bucket: bucket,
 			Object: object,
 		}
@@ -304,7 +354,7 @@ func (fs fsObjects) PutObject(bucket string, object string, size int64, data io.
 		// For size 0 we write a 0byte file.
 		err := fs.storage.AppendFile(minioMetaBucket, tempObj, []byte(""))
 		if err != nil {
-			return "", toObjectErr(err, bucket, object)
+			return ObjectInfo{}, toObjectErr(err, bucket, object)
 		}
 	} else {
 		// Allocate a buffer to Read() the object upload stream.
@@ -314,14 +364,14 @@ func (fs fsObjects) PutObject(bucket string, object string, size int64, data io.
 		for {
 			n, rErr := data.Read(buf)
 			if rErr != nil && rErr != io.EOF {
-				return "", toObjectErr(rErr, bucket, object)
+				return ObjectInfo{}, toObjectErr(rErr, bucket, object)
 			}
 			if n > 0 {
 				// Update md5 writer.
 				md5Writer.Write(buf[:n])
 				wErr := fs.storage.AppendFile(minioMetaBucket, tempObj, buf[:n])
 				if wErr != nil {
-					return "", toObjectErr(wErr, bucket, object)
+					return ObjectInfo{}, toObjectErr(wErr, bucket, object)
 				}
 			}
 			if rErr == io.EOF {
@@ -338,31 +388,267 @@ func (fs fsObjects) PutObject(bucket string, object string, size int64, data io.
 	}
 	if md5Hex != "" {
 		if newMD5Hex != md5Hex {
-			return "", BadDigest{md5Hex, newMD5Hex}
+			// MD5 mismatch, delete the temporary object.
+			fs.storage.DeleteFile(minioMetaBucket, tempObj)
+			return ObjectInfo{}, BadDigest{md5Hex, newMD5Hex}
 		}
 	}
 
-	// Entire object was written to the temp location, now it's safe to rename it
-	// to the actual location.
-	err := fs.storage.RenameFile(minioMetaBucket, tempObj, bucket, object)
+	// No metadata is set, allocate a new one.
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+
+	// Save the calculated md5sum.
+	metadata["md5Sum"] = newMD5Hex
+
+	// If not set default to "application/octet-stream"
+	if metadata["content-type"] == "" {
+		metadata["content-type"] = getUploadContentType(object)
+	}
+
+	// Entire object was written to the temp location, now it's safe to
+	// rename it to the actual location along with its `fs.json`.
+	fsMeta := newFSMetaV1()
+	fsMeta.Meta = metadata
+	objInfo, err := fs.commitObject(bucket, object, tempObj, fsMeta, cond)
 	if err != nil {
-		return "", toObjectErr(err, bucket, object)
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
 	}
 
-	// Return md5sum, successfully wrote object.
-	return newMD5Hex, nil
+	// Successfully wrote object.
+	return objInfo, nil
+}
+
+// UpdateObjectMetadata - replaces the metadata of an existing object,
+// object data and md5sum are left unchanged. In versioned buckets the
+// object is copied onto itself instead, as a new version.
+func (fs fsObjects) UpdateObjectMetadata(bucket, object string, metadata map[string]string) (ObjectInfo, error) {
+	versioned, err := isBucketVersioned(bucket)
+	if err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket)
+	}
+	if versioned {
+		return fs.CopyObject(bucket, object, bucket, object, metadata)
+	}
+	return fs.updateObjectMetadata(bucket, object, func(map[string]string) map[string]string {
+		return metadata
+	})
+}
+
+// UpdateObjectTags - replaces the tags of an existing object, rest of
+// the object metadata is left unchanged.
+func (fs fsObjects) UpdateObjectTags(bucket, object string, tags url.Values) (ObjectInfo, error) {
+	return fs.updateObjectMetadata(bucket, object, func(metadata map[string]string) map[string]string {
+		setObjectTags(metadata, tags)
+		return metadata
+	})
+}
+
+// updateObjectMetadata - replaces the metadata of an existing object
+// with the one returned by updateMeta for its current metadata, under
+// the object write lock.
+func (fs fsObjects) updateObjectMetadata(bucket, object string, updateMeta func(metadata map[string]string) map[string]string) (ObjectInfo, error) {
+	// Verify if bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return ObjectInfo{}, BucketNameInvalid{Bucket: bucket}
+	}
+	if !IsValidObjectName(object) {
+		return ObjectInfo{}, ObjectNameInvalid{Bucket: bucket, Object: object}
+	}
+
+	nsMutex.Lock(bucket, object)
+	defer nsMutex.Unlock(bucket, object)
+
+	fi, err := fs.storage.StatFile(bucket, object)
+	if err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+	// Objects written by older releases do not have `fs.json`.
+	fsMeta, err := readFSMetadata(fs.storage, minioMetaBucket, path.Join(bucketMetaPrefix, bucket, object))
+	if err != nil {
+		if err != errFileNotFound {
+			return ObjectInfo{}, toObjectErr(err, bucket, object)
+		}
+		fsMeta = newFSMetaV1()
+	}
+
+	metadata := make(map[string]string)
+	for key, value := range fsMeta.Meta {
+		metadata[key] = value
+	}
+	newMeta := make(map[string]string)
+	for key, value := range updateMeta(metadata) {
+		newMeta[key] = value
+	}
+	newMeta["md5Sum"] = fsMeta.Meta["md5Sum"]
+	fsMeta.Meta = newMeta
+	if err = fs.writeObjectFSMetadata(bucket, object, fsMeta); err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+	return newFSObjectInfo(bucket, object, fi, fsMeta), nil
+}
+
+// CopyObject - copies an object, the data of the source is copied to
+// the destination which gets a new `fs.json` with the given metadata,
+// md5sum of the source is preserved.
+func (fs fsObjects) CopyObject(srcBucket, srcObject, destBucket, destObject string, metadata map[string]string) (ObjectInfo, error) {
+	// Verify if buckets are valid.
+	if !IsValidBucketName(srcBucket) {
+		return ObjectInfo{}, BucketNameInvalid{Bucket: srcBucket}
+	}
+	if !IsValidBucketName(destBucket) {
+		return ObjectInfo{}, BucketNameInvalid{Bucket: destBucket}
+	}
+	if !IsValidObjectName(srcObject) {
+		return ObjectInfo{}, ObjectNameInvalid{Bucket: srcBucket, Object: srcObject}
+	}
+	if !IsValidObjectName(destObject) {
+		return ObjectInfo{}, ObjectNameInvalid{Bucket: destBucket, Object: destObject}
+	}
+
+	// Copying an object onto itself only replaces its metadata, unless
+	// a new version of it is to be created.
+	if srcBucket == destBucket && srcObject == destObject {
+		versioned, err := isBucketVersioned(destBucket)
+		if err != nil {
+			return ObjectInfo{}, toObjectErr(err, destBucket)
+		}
+		if !versioned {
+			return fs.updateObjectMetadata(destBucket, destObject, func(map[string]string) map[string]string {
+				return metadata
+			})
+		}
+	}
+	if _, err := fs.storage.StatVol(destBucket); err != nil {
+		return ObjectInfo{}, toObjectErr(err, destBucket)
+	}
+
+	// Copy the data of the source to a temporary location, source is
+	// not locked beyond this point.
+	tempObj := path.Join(tmpMetaPrefix, getUUID())
+	srcMeta, err := fs.copyObjectData(srcBucket, srcObject, tempObj)
+	if err != nil {
+		fs.storage.DeleteFile(minioMetaBucket, tempObj)
+		return ObjectInfo{}, toObjectErr(err, srcBucket, srcObject)
+	}
+
+	fsMeta := newFSMetaV1()
+	fsMeta.Meta = make(map[string]string)
+	for key, value := range metadata {
+		fsMeta.Meta[key] = value
+	}
+	fsMeta.Meta["md5Sum"] = srcMeta.Meta["md5Sum"]
+	fsMeta.Parts = srcMeta.Parts
+	objInfo, err := fs.commitObject(destBucket, destObject, tempObj, fsMeta, writeCondition{})
+	if err != nil {
+		return ObjectInfo{}, toObjectErr(err, destBucket, destObject)
+	}
+	return objInfo, nil
+}
+
+// copyObjectData - copies the data of an object to tempObj under the
+// meta bucket, holding the read lock of the object. Data is copied
+// instead of linked so that the copy has its own modification time.
+// Returns `fs.json` of the object.
+func (fs fsObjects) copyObjectData(bucket, object, tempObj string) (fsMetaV1, error) {
+	// Lock the object before reading.
+	nsMutex.RLock(bucket, object)
+	defer nsMutex.RUnlock(bucket, object)
+
+	fi, err := fs.storage.StatFile(bucket, object)
+	if err != nil {
+		return fsMetaV1{}, err
+	}
+	// Objects written by older releases do not have `fs.json`.
+	fsMeta, err := readFSMetadata(fs.storage, minioMetaBucket, path.Join(bucketMetaPrefix, bucket, object))
+	if err != nil && err != errFileNotFound {
+		return fsMetaV1{}, err
+	}
+
+	if fi.Size == 0 {
+		// For size 0 we write a 0byte file.
+		return fsMeta, fs.storage.AppendFile(minioMetaBucket, tempObj, []byte(""))
+	}
+	buf := make([]byte, readSizeV1)
+	offset := int64(0)
+	totalLeft := fi.Size
+	for totalLeft > 0 {
+		curLeft := int64(readSizeV1)
+		if totalLeft < readSizeV1 {
+			curLeft = totalLeft
+		}
+		n, rErr := fs.storage.ReadFile(bucket, object, offset, buf[:curLeft])
+		if n > 0 {
+			if err = fs.storage.AppendFile(minioMetaBucket, tempObj, buf[:n]); err != nil {
+				return fsMetaV1{}, err
+			}
+		}
+		if rErr != nil {
+			if rErr == io.EOF || rErr == io.ErrUnexpectedEOF {
+				break
+			}
+			return fsMetaV1{}, rErr
+		}
+		offset += n
+		totalLeft -= n
+	}
+	return fsMeta, nil
 }
 
 func (fs fsObjects) DeleteObject(bucket, object string) error {
+	_, err := fs.DeleteObjectIf(bucket, object, writeCondition{})
+	return err
+}
+
+// DeleteObjectIf - deletes an object if it meets the write condition.
+// With versioning configured, returns the info of the delete marker
+// placed.
+func (fs fsObjects) DeleteObjectIf(bucket, object string, cond writeCondition) (ObjectInfo, error) {
 	// Verify if bucket is valid.
 	if !IsValidBucketName(bucket) {
-		return BucketNameInvalid{Bucket: bucket}
+		return ObjectInfo{}, BucketNameInvalid{Bucket: bucket}
 	}
 	if !IsValidObjectName(object) {
-		return ObjectNameInvalid{Bucket: bucket, Object: object}
+		return ObjectInfo{}, ObjectNameInvalid{Bucket: bucket, Object: object}
+	}
+
+	nsMutex.Lock(bucket, object)
+	defer nsMutex.Unlock(bucket, object)
+
+	if err := checkWriteCondition(fs, bucket, object, cond); err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+
+	// With versioning configured the object is preserved behind a
+	// delete marker.
+	versionID, err := newObjectVersionID(bucket)
+	if err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
 	}
+	if versionID != "" {
+		markerInfo, err := deleteObjectWithMarker(fs, bucket, object, versionID)
+		if err != nil {
+			return ObjectInfo{}, toObjectErr(err, bucket, object)
+		}
+		return markerInfo, nil
+	}
+	if err = fs.deleteObject(bucket, object); err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+	return ObjectInfo{}, nil
+}
+
+// deleteObject - deletes an object along with its `fs.json`.
+func (fs fsObjects) deleteObject(bucket, object string) error {
 	if err := fs.storage.DeleteFile(bucket, object); err != nil {
-		return toObjectErr(err, bucket, object)
+		return err
+	}
+	// Delete the object metadata, objects written by older releases
+	// do not have `fs.json` hence ignore if not found.
+	err := fs.storage.DeleteFile(minioMetaBucket, path.Join(bucketMetaPrefix, bucket, object, fsMetaJSONFile))
+	if err != nil && err != errFileNotFound {
+		return err
 	}
 	return nil
 }
@@ -17,9 +17,12 @@
 package main
 
 import (
+	"bytes"
 	"os"
+	"path"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 // TestNewFS - tests initialization of all input disks
@@ -37,3 +40,81 @@ func TestNewFS(t *testing.T) {
 		t.Fatalf("Unable to initialize erasure, %s", err)
 	}
 }
+
+// TestFSGetObjectInfoWithoutMetadata - tests that objects without
+// `fs.json`, as written by older releases, are still served.
+func TestFSGetObjectInfoWithoutMetadata(t *testing.T) {
+	obj, fsDir, err := getSingleNodeObjectLayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(fsDir)
+
+	bucket := "bucket"
+	object := "object.jpg"
+	if err = obj.MakeBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = obj.PutObject(bucket, object, int64(len("hello")), bytes.NewBufferString("hello"), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Remove `fs.json` to simulate an object from an older release.
+	fs := obj.(fsObjects)
+	if err = fs.storage.DeleteFile(minioMetaBucket, path.Join(bucketMetaPrefix, bucket, object, fsMetaJSONFile)); err != nil {
+		t.Fatal(err)
+	}
+
+	objInfo, err := obj.GetObjectInfo(bucket, object)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objInfo.ContentType != "image/jpeg" {
+		t.Fatalf("Expected content type to be image/jpeg, found %s", objInfo.ContentType)
+	}
+
+	// Delete should succeed without `fs.json` as well.
+	if err = obj.DeleteObject(bucket, object); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestFSCopyObjectModTime - tests that copies have their own
+// modification time, not the one of the source.
+func TestFSCopyObjectModTime(t *testing.T) {
+	obj, fsDir, err := getSingleNodeObjectLayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(fsDir)
+
+	bucket := "bucket"
+	if err = obj.MakeBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = obj.PutObject(bucket, "source", int64(len("hello")), bytes.NewBufferString("hello"), nil); err != nil {
+		t.Fatal(err)
+	}
+	srcModTime := time.Now().Add(-48 * time.Hour)
+	if err = os.Chtimes(filepath.Join(fsDir, bucket, "source"), srcModTime, srcModTime); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = obj.CopyObject(bucket, "source", bucket, "copy", nil); err != nil {
+		t.Fatal(err)
+	}
+	objInfo, err := obj.GetObjectInfo(bucket, "copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(objInfo.ModTime) > time.Hour {
+		t.Fatalf("Expected copy to have a new modification time, got %s", objInfo.ModTime)
+	}
+	srcInfo, err := obj.GetObjectInfo(bucket, "source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !srcInfo.ModTime.Equal(srcModTime) {
+		t.Fatalf("Expected source modification time %s, got %s", srcModTime, srcInfo.ModTime)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"net/http"
 	"path"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -55,8 +56,10 @@ func setBrowserRedirectHandler(h http.Handler) http.Handler {
 }
 
 func (h redirectHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	// Re-direction handled specifically for browsers.
-	if strings.Contains(r.Header.Get("User-Agent"), "Mozilla") {
+	// Re-direction handled specifically for browsers, website and
+	// virtual host style requests are served as is.
+	_, isVirtualHost := getVirtualHostBucket(r)
+	if strings.Contains(r.Header.Get("User-Agent"), "Mozilla") && !isWebsiteRequest(r) && !isVirtualHost {
 		// '/' is redirected to 'locationPrefix/'
 		// '/webrpc' is redirected to 'locationPrefix/webrpc'
 		// '/login' is redirected to 'locationPrefix/login'
@@ -112,7 +115,7 @@ func setPrivateBucketHandler(h http.Handler) http.Handler {
 
 func (h minioPrivateBucketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// For all non browser requests, reject access to 'reservedBucket'.
-	if !strings.Contains(r.Header.Get("User-Agent"), "Mozilla") && path.Clean(r.URL.Path) == reservedBucket {
+	if !strings.Contains(r.Header.Get("User-Agent"), "Mozilla") && path.Clean(getPathStyleURL(r).Path) == reservedBucket {
 		writeErrorResponse(w, r, ErrAllAccessDisabled, r.URL.Path)
 		return
 	}
@@ -188,10 +191,37 @@ func (h timeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.handler.ServeHTTP(w, r)
 }
 
+type sseTLSHandler struct {
+	handler http.Handler
+}
+
+// setSSETLSHandler - rejects requests carrying SSE-C headers over
+// plain HTTP, customer provided keys are never accepted in clear.
+func setSSETLSHandler(h http.Handler) http.Handler {
+	return sseTLSHandler{h}
+}
+
+func (h sseTLSHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.TLS == nil && (hasSSECustomerHeader(r.Header, sseCustomerHeaders) || hasSSECustomerHeader(r.Header, sseCopyCustomerHeaders)) {
+		writeErrorResponse(w, r, ErrInsecureSSECustomerRequest, r.URL.Path)
+		return
+	}
+	h.handler.ServeHTTP(w, r)
+}
+
 type resourceHandler struct {
 	handler http.Handler
 }
 
+// Adds CORS headers, cross origin requests to buckets are evaluated
+// against the bucket's CORS configuration, buckets without one allow
+// no cross origin requests. Requests to the browser and to the root
+// are evaluated against the default CORS policy.
+type corsHandler struct {
+	handler        http.Handler
+	defaultHandler http.Handler
+}
+
 // setCorsHandler handler for CORS (Cross Origin Resource Sharing)
 func setCorsHandler(h http.Handler) http.Handler {
 	c := cors.New(cors.Options{
@@ -200,7 +230,92 @@ func setCorsHandler(h http.Handler) http.Handler {
 		AllowedHeaders: []string{"*"},
 		ExposedHeaders: []string{"ETag"},
 	})
-	return c.Handler(h)
+	return corsHandler{handler: h, defaultHandler: c.Handler(h)}
+}
+
+func (h corsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+
+	// Requests to the browser and to the root use the default policy.
+	bucket, _ := getRequestBucketObject(r)
+	if isWebsiteRequest(r) {
+		bucket, _, _ = getWebsiteBucketObject(r)
+	}
+	if bucket == "" || bucket == path.Base(reservedBucket) {
+		h.defaultHandler.ServeHTTP(w, r)
+		return
+	}
+	cc, err := readBucketCors(bucket)
+	if err != nil {
+		// Buckets without readable rules allow no cross origin
+		// requests, actual requests are served without CORS headers.
+		switch err.(type) {
+		case BucketCorsNotFound, BucketNameInvalid:
+		default:
+			errorIf(err, "Unable to read CORS configuration.")
+		}
+		if isCorsPreflight(r) {
+			writeErrorResponse(w, r, ErrCorsNotAllowed, r.URL.Path)
+			return
+		}
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+
+	if isCorsPreflight(r) {
+		var requestHeaders []string
+		for _, header := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+			if header = strings.TrimSpace(header); header != "" {
+				requestHeaders = append(requestHeaders, header)
+			}
+		}
+		rule, allowedOrigin, ok := cc.match(origin, r.Header.Get("Access-Control-Request-Method"), requestHeaders)
+		if !ok {
+			writeErrorResponse(w, r, ErrCorsNotAllowed, r.URL.Path)
+			return
+		}
+		setCorsHeaders(w, rule, allowedOrigin)
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(rule.AllowedMethods, ", "))
+		if len(requestHeaders) > 0 {
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(requestHeaders, ", "))
+		}
+		if rule.MaxAgeSeconds > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(rule.MaxAgeSeconds))
+		}
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Actual requests are served whether allowed or not, browsers do
+	// not expose responses without CORS headers.
+	if rule, allowedOrigin, ok := cc.match(origin, r.Method, nil); ok {
+		setCorsHeaders(w, rule, allowedOrigin)
+	}
+	h.handler.ServeHTTP(w, r)
+}
+
+// isCorsPreflight - returns true for CORS preflight requests.
+func isCorsPreflight(r *http.Request) bool {
+	return r.Method == "OPTIONS" && r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// setCorsHeaders - sets the CORS headers common to preflight and
+// actual requests allowed by the rule.
+func setCorsHeaders(w http.ResponseWriter, rule corsRule, allowedOrigin string) {
+	w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
+	if allowedOrigin != "*" {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+	if len(rule.ExposeHeaders) > 0 {
+		w.Header().Set("Access-Control-Expose-Headers", strings.Join(rule.ExposeHeaders, ", "))
+	}
+	w.Header().Add("Vary", "Origin")
 }
 
 // setIgnoreResourcesHandler -
@@ -213,18 +328,9 @@ func setIgnoreResourcesHandler(h http.Handler) http.Handler {
 
 // Resource handler ServeHTTP() wrapper
 func (h resourceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	// Skip the first element which is usually '/' and split the rest.
-	splits := strings.SplitN(r.URL.Path[1:], "/", 2)
-
-	// Save bucketName and objectName extracted from url Path.
-	var bucketName, objectName string
-	if len(splits) == 1 {
-		bucketName = splits[0]
-	}
-	if len(splits) == 2 {
-		bucketName = splits[0]
-		objectName = splits[1]
-	}
+	// Save bucketName and objectName extracted from url Path, or
+	// from the host of virtual host style requests.
+	bucketName, objectName := getRequestBucketObject(r)
 	// If bucketName is present and not objectName check for bucket
 	// level resource queries.
 	if bucketName != "" && objectName == "" {
@@ -240,8 +346,8 @@ func (h resourceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
-	// A put method on path "/" doesn't make sense, ignore it.
-	if r.Method == "PUT" && r.URL.Path == "/" {
+	// A put method without a bucket doesn't make sense, ignore it.
+	if r.Method == "PUT" && bucketName == "" {
 		writeErrorResponse(w, r, ErrNotImplemented, r.URL.Path)
 		return
 	}
@@ -273,16 +379,9 @@ func ignoreNotImplementedObjectResources(req *http.Request) bool {
 // List of not implemented bucket queries
 var notimplementedBucketResourceNames = map[string]bool{
 	"acl":            true,
-	"cors":           true,
-	"lifecycle":      true,
 	"logging":        true,
-	"notification":   true,
 	"replication":    true,
-	"tagging":        true,
-	"versions":       true,
 	"requestPayment": true,
-	"versioning":     true,
-	"website":        true,
 }
 
 // List of not implemented object queries
@@ -25,7 +25,7 @@ const (
 
 // minio configuration related constants.
 const (
-	globalMinioConfigVersion = "4"
+	globalMinioConfigVersion = "5"
 	globalMinioConfigDir     = ".minio"
 	globalMinioCertsDir      = ".minio/certs"
 	globalMinioCertFile      = "public.crt"
@@ -43,6 +43,13 @@ var (
 	// Maximum connections handled per
 	// server, defaults to 0 (unlimited).
 	globalMaxConn = 0
+
+	// Domain of the server, buckets are addressed virtual host
+	// style as '<bucket>.<domain>'.
+	globalServerDomain = ""
+	// Domain of the website endpoint, buckets are served as
+	// websites under '<bucket>.<domain>'.
+	globalWebsiteDomain = ""
 	// Add new variable global values here.
 )
 
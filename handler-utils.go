@@ -18,8 +18,52 @@ package main
 
 import (
 	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/minio/minio/pkg/mimedb"
 )
 
+// getVirtualHostBucket - returns the bucket of virtual host style
+// requests to '<bucket>.<domain>', ok is false for path style
+// requests.
+func getVirtualHostBucket(r *http.Request) (bucket string, ok bool) {
+	if globalServerDomain == "" {
+		return "", false
+	}
+	host, _ := splitHostPort(r.Host)
+	if !strings.HasSuffix(host, "."+globalServerDomain) {
+		return "", false
+	}
+	bucket = strings.TrimSuffix(host, "."+globalServerDomain)
+	return bucket, bucket != ""
+}
+
+// getPathStyleURL - returns the URL of the request in path style,
+// virtual host style requests have the bucket prepended to the path.
+func getPathStyleURL(r *http.Request) *url.URL {
+	bucket, ok := getVirtualHostBucket(r)
+	if !ok {
+		return r.URL
+	}
+	pathStyleURL := *r.URL
+	pathStyleURL.Path = "/" + bucket + r.URL.Path
+	return &pathStyleURL
+}
+
+// getRequestBucketObject - returns the bucket and object of path
+// style and virtual host style requests.
+func getRequestBucketObject(r *http.Request) (bucket, object string) {
+	splits := strings.SplitN(strings.TrimPrefix(getPathStyleURL(r).Path, "/"), "/", 2)
+	bucket = splits[0]
+	if len(splits) == 2 {
+		object = splits[1]
+	}
+	return bucket, object
+}
+
 // validates location constraint from the request body.
 // the location value in the request body should match the Region in serverConfig.
 // other values of location are not accepted.
@@ -52,3 +96,107 @@ func isValidLocationContraint(reqBody io.Reader, serverRegion string) APIErrorCo
 	}
 	return errCode
 }
+
+// extractMetadataFromHeader - extracts standard and user defined
+// metadata from the request header, user defined metadata keys are
+// saved in their canonical form.
+func extractMetadataFromHeader(header http.Header) map[string]string {
+	metadata := make(map[string]string)
+	// Save standard metadata if available.
+	metadata["content-type"] = header.Get("Content-Type")
+	metadata["content-encoding"] = trimAwsChunkedContentEncoding(header.Get("Content-Encoding"))
+	// Save all user defined metadata.
+	for key, values := range header {
+		if len(values) == 0 || !isUserMetadataKey(key) {
+			continue
+		}
+		metadata[http.CanonicalHeaderKey(key)] = values[0]
+	}
+	return metadata
+}
+
+// isValidMetadataDirective - returns true if x-amz-metadata-directive
+// is either unset, COPY or REPLACE.
+func isValidMetadataDirective(header http.Header) bool {
+	switch header.Get("X-Amz-Metadata-Directive") {
+	case "", "COPY", "REPLACE":
+		return true
+	}
+	return false
+}
+
+// isMetadataReplace - returns true if the metadata of a copied object
+// is replaced with the metadata sent in the request.
+func isMetadataReplace(header http.Header) bool {
+	return header.Get("X-Amz-Metadata-Directive") == "REPLACE"
+}
+
+// isValidTaggingDirective - returns true if x-amz-tagging-directive
+// is either unset, COPY or REPLACE.
+func isValidTaggingDirective(header http.Header) bool {
+	switch header.Get("X-Amz-Tagging-Directive") {
+	case "", "COPY", "REPLACE":
+		return true
+	}
+	return false
+}
+
+// isTaggingReplace - returns true if the tags of a copied object are
+// replaced with the tags sent in the request.
+func isTaggingReplace(header http.Header) bool {
+	return header.Get("X-Amz-Tagging-Directive") == "REPLACE"
+}
+
+// guessContentType - guesses the content-type of an object from its
+// extension, returns "" if unknown.
+func guessContentType(object string) string {
+	if objectExt := filepath.Ext(object); objectExt != "" {
+		if content, ok := mimedb.DB[strings.ToLower(strings.TrimPrefix(objectExt, "."))]; ok {
+			return content.ContentType
+		}
+	}
+	return ""
+}
+
+// getUploadContentType - returns the content-type of an uploaded object
+// sent without one, guessed from its extension and defaults to
+// "application/octet-stream".
+func getUploadContentType(object string) string {
+	if contentType := guessContentType(object); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// getCopyObjectMetadata - returns the standard and user defined
+// metadata of the source object of a copy.
+func getCopyObjectMetadata(objInfo ObjectInfo) map[string]string {
+	metadata := make(map[string]string)
+	metadata["content-type"] = objInfo.ContentType
+	metadata["content-encoding"] = objInfo.ContentEncoding
+	for key, value := range objInfo.UserDefined {
+		if isUserMetadataKey(key) {
+			metadata[key] = value
+		}
+	}
+	return metadata
+}
+
+// trimAwsChunkedContentEncoding - removes aws-chunked from the content
+// encoding, it only describes the payload of streaming signed requests.
+func trimAwsChunkedContentEncoding(contentEnc string) string {
+	var encodings []string
+	for _, enc := range strings.Split(contentEnc, ",") {
+		if enc = strings.TrimSpace(enc); enc != "" && enc != "aws-chunked" {
+			encodings = append(encodings, enc)
+		}
+	}
+	return strings.Join(encodings, ",")
+}
+
+// isUserMetadataKey - returns true if key is prefixed with either
+// `x-amz-meta-` or `x-minio-meta-`, comparison is case insensitive.
+func isUserMetadataKey(key string) bool {
+	lKey := strings.ToLower(key)
+	return strings.HasPrefix(lKey, "x-amz-meta-") || strings.HasPrefix(lKey, "x-minio-meta-")
+}
@@ -19,9 +19,14 @@ package main
 import (
 	"bytes"
 	"encoding/xml"
+	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/url"
+	"reflect"
 	"testing"
+	"time"
 )
 
 // Tests validate bucket LocationConstraint.
@@ -64,3 +69,211 @@ func TestIsValidLocationContraint(t *testing.T) {
 		}
 	}
 }
+
+// Tests validate metadata extraction from http headers.
+func TestExtractMetadataFromHeader(t *testing.T) {
+	testCases := []struct {
+		header   http.Header
+		metadata map[string]string
+	}{
+		// Test case - 1.
+		// Only standard headers.
+		{
+			header: http.Header{
+				"Content-Type": []string{"image/png"},
+			},
+			metadata: map[string]string{
+				"content-type":     "image/png",
+				"content-encoding": "",
+			},
+		},
+		// Test case - 2.
+		// User defined metadata with both prefixes.
+		{
+			header: http.Header{
+				"X-Amz-Meta-Appid":   []string{"amz-meta"},
+				"X-Minio-Meta-Appid": []string{"minio-meta"},
+				"X-Amz-Date":         []string{"20160711T000000Z"},
+			},
+			metadata: map[string]string{
+				"content-type":       "",
+				"content-encoding":   "",
+				"X-Amz-Meta-Appid":   "amz-meta",
+				"X-Minio-Meta-Appid": "minio-meta",
+			},
+		},
+		// Test case - 3.
+		// Non canonical header keys.
+		{
+			header: http.Header{
+				"x-amz-meta-appid": []string{"amz-meta"},
+			},
+			metadata: map[string]string{
+				"content-type":     "",
+				"content-encoding": "",
+				"X-Amz-Meta-Appid": "amz-meta",
+			},
+		},
+	}
+	for i, testCase := range testCases {
+		metadata := extractMetadataFromHeader(testCase.header)
+		if !reflect.DeepEqual(metadata, testCase.metadata) {
+			t.Errorf("Test %d: Expected metadata %v, but instead found %v", i+1, testCase.metadata, metadata)
+		}
+	}
+}
+
+// Tests extracting bucket and object of path style and virtual host
+// style requests.
+func TestGetRequestBucketObject(t *testing.T) {
+	globalServerDomain = "s3.example.com"
+	defer func() { globalServerDomain = "" }()
+
+	testCases := []struct {
+		urlStr         string
+		bucket, object string
+		pathStyleURL   string
+	}{
+		{"http://s3.example.com/", "", "", "/"},
+		{"http://s3.example.com/bucket", "bucket", "", "/bucket"},
+		{"http://s3.example.com/bucket/a/b", "bucket", "a/b", "/bucket/a/b"},
+		{"http://bucket.s3.example.com/", "bucket", "", "/bucket/"},
+		{"http://bucket.s3.example.com:9000/a/b?acl", "bucket", "a/b", "/bucket/a/b"},
+		{"http://my.bucket.s3.example.com/bucket/a", "my.bucket", "bucket/a", "/my.bucket/bucket/a"},
+		{"http://bucket.example.com/a", "a", "", "/a"},
+	}
+	for i, testCase := range testCases {
+		req, err := http.NewRequest("GET", testCase.urlStr, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bucket, object := getRequestBucketObject(req)
+		if bucket != testCase.bucket || object != testCase.object {
+			t.Errorf("Test %d: Expected %s/%s, got %s/%s", i+1, testCase.bucket, testCase.object, bucket, object)
+		}
+		if pathStyleURL := getPathStyleURL(req); pathStyleURL.Path != testCase.pathStyleURL || pathStyleURL.RawQuery != req.URL.RawQuery {
+			t.Errorf("Test %d: Expected path %s, got %s", i+1, testCase.pathStyleURL, pathStyleURL)
+		}
+	}
+}
+
+// Tests virtual host style requests are routed to bucket and object
+// handlers.
+func TestVirtualHostStyleRequests(t *testing.T) {
+	for _, instanceType := range []string{"FS", "XL"} {
+		testVirtualHostStyleRequests(instanceType, t)
+	}
+}
+
+func testVirtualHostStyleRequests(instanceType string, t *testing.T) {
+	// Routes are registered with the domain on server start.
+	globalServerDomain = "s3.local"
+	defer func() { globalServerDomain = "" }()
+	testServer := StartTestServer(t, instanceType)
+	defer testServer.Stop()
+
+	serverURL, err := url.Parse(testServer.Server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, port := splitHostPort(serverURL.Host)
+	// All hosts under the domain resolve to the test server.
+	client := http.Client{Transport: &http.Transport{
+		Dial: func(network, addr string) (net.Conn, error) {
+			return net.Dial(network, serverURL.Host)
+		},
+	}}
+	expectStatus := func(req *http.Request, status int) []byte {
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		respBody, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != status {
+			t.Fatalf("%s: %s %s: Expected status %d, got %d: %s", instanceType, req.Method, req.URL, status, resp.StatusCode, respBody)
+		}
+		return respBody
+	}
+	newRequest := func(method, urlStr string, body []byte) *http.Request {
+		req, err := newTestRequest(method, urlStr, int64(len(body)), bytes.NewReader(body), testServer.AccessKey, testServer.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+	newRequestV2 := func(method, urlStr string, body []byte) *http.Request {
+		req, err := newTestRequestV2(method, urlStr, body, testServer.AccessKey, testServer.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+
+	data := []byte("hello, virtual host")
+	bucketURL := fmt.Sprintf("http://vhost-bucket.s3.local:%s", port)
+	pathStyleURL := fmt.Sprintf("http://s3.local:%s/vhost-bucket", port)
+	expectStatus(newRequest("PUT", bucketURL+"/", nil), http.StatusOK)
+	expectStatus(newRequest("PUT", bucketURL+"/dir/object", data), http.StatusOK)
+	if respData := expectStatus(newRequest("GET", pathStyleURL+"/dir/object", nil), http.StatusOK); !bytes.Equal(respData, data) {
+		t.Fatalf("%s: Expected object put virtual host style to be readable path style", instanceType)
+	}
+	if respData := expectStatus(newRequest("GET", bucketURL+"/dir/object", nil), http.StatusOK); !bytes.Equal(respData, data) {
+		t.Fatalf("%s: Unexpected object data %s", instanceType, respData)
+	}
+	if respData := expectStatus(newRequest("GET", bucketURL+"/?prefix=dir/", nil), http.StatusOK); !bytes.Contains(respData, []byte("<Key>dir/object</Key>")) {
+		t.Fatalf("%s: Expected object in listing, got %s", instanceType, respData)
+	}
+	expectStatus(newRequest("HEAD", bucketURL+"/", nil), http.StatusOK)
+	expectStatus(newRequest("GET", bucketURL+"/?acl", nil), http.StatusNotImplemented)
+
+	// Signature version '2' signs the bucket as part of the resource.
+	expectStatus(newRequestV2("PUT", bucketURL+"/v2-object", data), http.StatusOK)
+	expectStatus(newRequestV2("GET", bucketURL+"/v2-object", nil), http.StatusOK)
+	presignedReq, err := newPresignedTestRequestV2("GET", bucketURL+"/v2-object", time.Now().Add(time.Minute), testServer.AccessKey, testServer.SecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(presignedReq, http.StatusOK)
+
+	// Bucket policies apply to the bucket of the host.
+	anonymousReq, err := http.NewRequest("GET", bucketURL+"/dir/object", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(anonymousReq, http.StatusForbidden)
+	policy := `{"Version": "2012-10-17", "Statement": [{"Action": ["s3:GetObject"], "Effect": "Allow", "Principal": {"AWS": ["*"]}, "Resource": ["arn:aws:s3:::vhost-bucket/dir/*"]}]}`
+	expectStatus(newRequest("PUT", bucketURL+"/?policy", []byte(policy)), http.StatusNoContent)
+	expectStatus(anonymousReq, http.StatusOK)
+
+	expectStatus(newRequest("DELETE", bucketURL+"/dir/object", nil), http.StatusNoContent)
+	expectStatus(newRequest("DELETE", bucketURL+"/v2-object", nil), http.StatusNoContent)
+	expectStatus(newRequest("DELETE", bucketURL+"/", nil), http.StatusNoContent)
+	expectStatus(newRequest("HEAD", pathStyleURL, nil), http.StatusNotFound)
+}
+
+// Tests guessing the content-type from the object extension.
+func TestGuessContentType(t *testing.T) {
+	testCases := []struct {
+		object            string
+		contentType       string
+		uploadContentType string
+	}{
+		{"photo.jpg", "image/jpeg", "image/jpeg"},
+		{"dir/Photo.JPG", "image/jpeg", "image/jpeg"},
+		{"archive.unknown-ext", "", "application/octet-stream"},
+		{"noext", "", "application/octet-stream"},
+		{"dir.json/noext", "", "application/octet-stream"},
+	}
+	for i, testCase := range testCases {
+		if contentType := guessContentType(testCase.object); contentType != testCase.contentType {
+			t.Errorf("Test %d: Expected %q, got %q", i+1, testCase.contentType, contentType)
+		}
+		if contentType := getUploadContentType(testCase.object); contentType != testCase.uploadContentType {
+			t.Errorf("Test %d: Expected upload content-type %q, got %q", i+1, testCase.uploadContentType, contentType)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+// command specific flags.
+var (
+	healFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "help, h",
+			Usage: "Help for heal.",
+		},
+		cli.BoolFlag{
+			Name:  "recursive, r",
+			Usage: "Heal all objects under the prefix.",
+		},
+		cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "List what would be healed, without healing.",
+		},
+	}
+)
+
+// Heal disks, buckets and objects of a server.
+var healCmd = cli.Command{
+	Name:   "heal",
+	Usage:  "Heal disks, buckets and objects on a minio server.",
+	Action: mainHeal,
+	Flags:  healFlags,
+	CustomHelpTemplate: `NAME:
+   minio {{.Name}} - {{.Usage}}
+
+USAGE:
+   minio {{.Name}} [FLAGS] URL
+
+FLAGS:
+  {{range .Flags}}{{.}}
+  {{end}}
+ENVIRONMENT VARIABLES:
+  MINIO_ACCESS_KEY: Access key of the server, if not the one in config.
+  MINIO_SECRET_KEY: Secret key of the server, if not the one in config.
+
+EXAMPLES:
+   1. Heal all buckets and objects, along with format.json of replaced disks.
+      $ minio {{.Name}} http://localhost:9000
+
+   2. List what would be healed in a bucket.
+      $ minio {{.Name}} --dry-run http://localhost:9000/photos
+
+   3. Heal all objects under a prefix.
+      $ minio {{.Name}} --recursive http://localhost:9000/photos/2016/
+
+   4. Heal a single object.
+      $ minio {{.Name}} http://localhost:9000/photos/2016/august.jpg
+`,
+}
+
+// newHealRequest - returns a signed admin heal request for the server,
+// bucket, prefix or object at the target URL.
+func newHealRequest(target string, recursive, dryRun bool, cred credential, region string) (*http.Request, error) {
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	if targetURL.Scheme == "" || targetURL.Host == "" {
+		return nil, errors.New("URL should be of the form http://host:port/bucket/object")
+	}
+
+	query := make(url.Values)
+	splits := strings.SplitN(strings.TrimPrefix(targetURL.Path, "/"), "/", 2)
+	if splits[0] != "" {
+		query.Set("bucket", splits[0])
+	}
+	if len(splits) == 2 && splits[1] != "" {
+		object := splits[1]
+		if recursive {
+			query.Set("prefix", object)
+		} else {
+			query.Set("object", object)
+		}
+	}
+	if dryRun {
+		query.Set("dryRun", strconv.FormatBool(dryRun))
+	}
+
+	healURL := url.URL{
+		Scheme:   targetURL.Scheme,
+		Host:     targetURL.Host,
+		Path:     reservedBucket + adminHealPath,
+		RawQuery: query.Encode(),
+	}
+	req, err := http.NewRequest("POST", healURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	signRequestV4(req, cred.AccessKeyID, cred.SecretAccessKey, region)
+	return req, nil
+}
+
+// newHealStatusRequest - returns a signed admin request for the status
+// of the heal task with id, on the server at the target URL.
+func newHealStatusRequest(target, id string, cred credential, region string) (*http.Request, error) {
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	statusURL := url.URL{
+		Scheme:   targetURL.Scheme,
+		Host:     targetURL.Host,
+		Path:     reservedBucket + adminHealPath,
+		RawQuery: url.Values{"id": []string{id}}.Encode(),
+	}
+	req, err := http.NewRequest("GET", statusURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	signRequestV4(req, cred.AccessKeyID, cred.SecretAccessKey, region)
+	return req, nil
+}
+
+// Interval the status of a heal is polled at.
+const healPollInterval = time.Second
+
+// doHealRequest - sends an admin heal request, returns the status of
+// the heal task in the response.
+func doHealRequest(req *http.Request, target string) healTask {
+	resp, err := http.DefaultClient.Do(req)
+	fatalIf(err, "Unable to connect to ‘%s’.", target)
+	defer resp.Body.Close()
+	respBytes, err := ioutil.ReadAll(resp.Body)
+	fatalIf(err, "Unable to read heal response.")
+
+	if resp.StatusCode != http.StatusOK {
+		errResp := APIErrorResponse{}
+		if err = xml.Unmarshal(respBytes, &errResp); err != nil {
+			errResp.Message = strings.TrimSpace(string(respBytes))
+		}
+		console.Fatalln("Unable to heal ‘" + target + "’: " + errResp.Message)
+	}
+	var task healTask
+	err = json.Unmarshal(respBytes, &task)
+	fatalIf(err, "Unable to parse heal response.")
+	return task
+}
+
+// String - human readable heal result.
+func (r healResult) String() string {
+	verb := "Healed"
+	if r.DryRun {
+		verb = "Would heal"
+	}
+	var buf bytes.Buffer
+	for _, index := range r.FormatDisks {
+		fmt.Fprintf(&buf, "%s format.json on disk %d.\n", verb, index)
+	}
+	for _, bucket := range r.Buckets {
+		fmt.Fprintf(&buf, "%s bucket ‘%s’ on %d disk(s).\n", verb, bucket.Bucket, bucket.Disks)
+	}
+	for _, object := range r.Objects {
+		if object.Error != "" {
+			fmt.Fprintf(&buf, "Unable to heal ‘%s/%s’: %s\n", object.Bucket, object.Object, object.Error)
+			continue
+		}
+		fmt.Fprintf(&buf, "%s ‘%s/%s’ on %d disk(s).\n", verb, object.Bucket, object.Object, object.Disks)
+	}
+	if r.Truncated {
+		fmt.Fprintf(&buf, "Only the first %d object(s) are listed.\n", len(r.Objects))
+	}
+	fmt.Fprintf(&buf, "Scanned %d object(s).", r.Scanned)
+	return buf.String()
+}
+
+func mainHeal(ctx *cli.Context) {
+	if len(ctx.Args()) != 1 || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "heal", 1)
+	}
+
+	// Credentials of the server, from config unless set in environment.
+	cred := serverConfig.GetCredential()
+	if accessKey, secretKey := os.Getenv("MINIO_ACCESS_KEY"), os.Getenv("MINIO_SECRET_KEY"); accessKey != "" && secretKey != "" {
+		cred = credential{
+			AccessKeyID:     accessKey,
+			SecretAccessKey: secretKey,
+		}
+	}
+
+	target := ctx.Args().First()
+	req, err := newHealRequest(target, ctx.Bool("recursive"), ctx.Bool("dry-run"), cred, serverConfig.GetRegion())
+	fatalIf(err, "Invalid URL ‘%s’.", target)
+	task := doHealRequest(req, target)
+
+	// Heal runs in background on the server, poll until it is done.
+	for !task.Done {
+		time.Sleep(healPollInterval)
+		req, err = newHealStatusRequest(target, task.ID, cred, serverConfig.GetRegion())
+		fatalIf(err, "Invalid URL ‘%s’.", target)
+		task = doHealRequest(req, target)
+	}
+	if task.Error != "" {
+		console.Fatalln("Unable to heal ‘" + target + "’: " + task.Error)
+	}
+	console.Println(task.Result)
+}
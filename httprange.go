@@ -25,6 +25,9 @@ import (
 
 const (
 	b = "bytes="
+
+	// Maximum number of ranges allowed in a multi-range request.
+	maxHTTPRanges = 100
 )
 
 // InvalidRange - invalid range
@@ -46,19 +49,47 @@ func (r *httpRange) String() string {
 
 // Grab new range from request header
 func getRequestedRange(hrange string, size int64) (*httpRange, error) {
-	r := &httpRange{
-		start:  0,
-		length: 0,
-		size:   0,
+	ranges, err := getRequestedRanges(hrange, size)
+	if err != nil {
+		return nil, err
 	}
-	r.size = size
-	if hrange != "" {
-		err := r.parseRange(hrange)
-		if err != nil {
+	if len(ranges) > 1 {
+		return nil, errors.New("multiple ranges specified")
+	}
+	return ranges[0], nil
+}
+
+// Grab all the ranges from request header, a missing header or a
+// single range returns one range. In a multi-range request every range
+// must be satisfiable.
+func getRequestedRanges(hrange string, size int64) ([]*httpRange, error) {
+	if hrange == "" {
+		return []*httpRange{{start: 0, length: 0, size: size}}, nil
+	}
+	if !strings.HasPrefix(hrange, b) {
+		return nil, InvalidRange{}
+	}
+
+	ras := strings.Split(hrange[len(b):], ",")
+	if len(ras) > maxHTTPRanges {
+		return nil, InvalidRange{}
+	}
+	var ranges []*httpRange
+	for _, ra := range ras {
+		ra = strings.TrimSpace(ra)
+		if ra == "" {
+			return nil, InvalidRange{}
+		}
+		r := &httpRange{size: size}
+		if err := r.parse(ra); err != nil {
 			return nil, err
 		}
+		if len(ras) > 1 && r.length == 0 {
+			return nil, InvalidRange{}
+		}
+		ranges = append(ranges, r)
 	}
-	return r, nil
+	return ranges, nil
 }
 
 func (r *httpRange) parse(ra string) error {
@@ -102,27 +133,28 @@ func (r *httpRange) parse(ra string) error {
 	return nil
 }
 
-// parseRange parses a Range header string as per RFC 2616.
-func (r *httpRange) parseRange(s string) error {
-	if s == "" {
-		return errors.New("header not present")
+// Grab copy part range from x-amz-copy-source-range header, unlike
+// Range both offsets are mandatory and must be within the source.
+func getCopyPartRange(hrange string, size int64) (*httpRange, error) {
+	if hrange == "" {
+		// Copy the whole source.
+		return &httpRange{start: 0, length: size, size: size}, nil
 	}
-	if !strings.HasPrefix(s, b) {
-		return InvalidRange{}
+	if !strings.HasPrefix(hrange, b) {
+		return nil, InvalidRange{}
 	}
-
-	ras := strings.Split(s[len(b):], ",")
-	if len(ras) == 0 {
-		return errors.New("invalid request")
+	ra := strings.TrimSpace(hrange[len(b):])
+	i := strings.Index(ra, "-")
+	if i <= 0 || i == len(ra)-1 {
+		return nil, InvalidRange{}
 	}
-	// Just pick the first one and ignore the rest, we only support one range per object
-	if len(ras) > 1 {
-		return errors.New("multiple ranges specified")
+	start, err := strconv.ParseInt(ra[:i], 10, 64)
+	if err != nil || start < 0 {
+		return nil, InvalidRange{}
 	}
-
-	ra := strings.TrimSpace(ras[0])
-	if ra == "" {
-		return InvalidRange{}
+	end, err := strconv.ParseInt(ra[i+1:], 10, 64)
+	if err != nil || start > end || end >= size {
+		return nil, InvalidRange{}
 	}
-	return r.parse(ra)
+	return &httpRange{start: start, length: end - start + 1, size: size}, nil
 }
@@ -0,0 +1,70 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import "testing"
+
+// Tests parsing single and multiple ranges.
+func TestGetRequestedRanges(t *testing.T) {
+	testCases := []struct {
+		hrange    string
+		ranges    []string
+		expectErr bool
+	}{
+		{"", []string{"bytes 0--1/10"}, false},
+		{"bytes=0-4", []string{"bytes 0-4/10"}, false},
+		{"bytes=0-1,4-5, -2", []string{"bytes 0-1/10", "bytes 4-5/10", "bytes 8-9/10"}, false},
+		{"bytes=8-20,0-0", []string{"bytes 8-9/10", "bytes 0-0/10"}, false},
+		{"bytes=0-1,", nil, true},
+		{"bytes=0-1,20-30", nil, true},
+		{"bytes=0-1,10-", nil, true},
+		{"bytes=0-1,5-2", nil, true},
+		{"items=0-1,2-3", nil, true},
+	}
+	for i, testCase := range testCases {
+		ranges, err := getRequestedRanges(testCase.hrange, 10)
+		if testCase.expectErr {
+			if err == nil {
+				t.Fatalf("Test %d: Expected error for %s", i+1, testCase.hrange)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Test %d: %s", i+1, err)
+		}
+		if len(ranges) != len(testCase.ranges) {
+			t.Fatalf("Test %d: Expected %d ranges, got %d", i+1, len(testCase.ranges), len(ranges))
+		}
+		for j, hrange := range ranges {
+			if hrange.String() != testCase.ranges[j] {
+				t.Fatalf("Test %d: Expected range %s, got %s", i+1, testCase.ranges[j], hrange)
+			}
+		}
+	}
+
+	// Single range is required by getRequestedRange.
+	if _, err := getRequestedRange("bytes=0-1,4-5", 10); err == nil {
+		t.Fatal("Expected error for multiple ranges")
+	}
+	many := "bytes=0-0"
+	for i := 0; i < maxHTTPRanges; i++ {
+		many += ",0-0"
+	}
+	if _, err := getRequestedRanges(many, 10); err == nil {
+		t.Fatalf("Expected error for more than %d ranges", maxHTTPRanges)
+	}
+}
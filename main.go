@@ -107,6 +107,7 @@ func registerApp() *cli.App {
 	registerCommand(serverCmd)
 	registerCommand(versionCmd)
 	registerCommand(updateCmd)
+	registerCommand(healCmd)
 
 	// Set up app.
 	app := cli.NewApp()
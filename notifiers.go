@@ -0,0 +1,90 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+// Notification target types.
+const (
+	// Minio SQS ARN prefix.
+	minioSqs = "arn:minio:sqs:"
+
+	// Static string indicating queue type 'webhook'.
+	queueTypeWebhook = "webhook"
+)
+
+// notifier carries notification targets configuration for various
+// supported targets. Currently supported targets are
+//
+//   - webhook
+//
+type notifier struct {
+	Webhook map[string]webhookNotify `json:"webhook"`
+	// Add new notification queues.
+}
+
+// errInvalidQueueARN - queue ARN is not well formed.
+var errInvalidQueueARN = errors.New("Invalid queue ARN")
+
+// queueARN - parsed form of a queue ARN of the form
+// 'arn:minio:sqs:<region>:<account-id>:<type>'.
+type queueARN struct {
+	Region    string
+	AccountID string
+	Type      string
+}
+
+// String - returns the queue ARN.
+func (arn queueARN) String() string {
+	return minioSqs + arn.Region + ":" + arn.AccountID + ":" + arn.Type
+}
+
+// parseQueueARN - parses a queue ARN.
+func parseQueueARN(arn string) (queueARN, error) {
+	if !strings.HasPrefix(arn, minioSqs) {
+		return queueARN{}, errInvalidQueueARN
+	}
+	fields := strings.Split(strings.TrimPrefix(arn, minioSqs), ":")
+	if len(fields) != 3 || fields[1] == "" || fields[2] == "" {
+		return queueARN{}, errInvalidQueueARN
+	}
+	return queueARN{
+		Region:    fields[0],
+		AccountID: fields[1],
+		Type:      fields[2],
+	}, nil
+}
+
+// isValidQueueARN - validates if the queue ARN refers to an enabled
+// notification target of this server.
+func isValidQueueARN(arn string) bool {
+	sqsARN, err := parseQueueARN(arn)
+	if err != nil {
+		return false
+	}
+	if sqsARN.Region != serverConfig.GetRegion() {
+		return false
+	}
+	switch sqsARN.Type {
+	case queueTypeWebhook:
+		return serverConfig.GetWebhookNotifyByID(sqsARN.AccountID).Enable
+	}
+	return false
+}
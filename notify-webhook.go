@@ -0,0 +1,134 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	// Timeout for delivering an event to a webhook endpoint.
+	webhookTimeout = 10 * time.Second
+	// Maximum number of events waiting to be delivered to a webhook
+	// endpoint, events are dropped while the queue is full.
+	webhookQueueSize = 10000
+)
+
+// errWebhookQueueFull - events cannot be queued for delivery.
+var errWebhookQueueFull = errors.New("Webhook event queue is full")
+
+// webhookNotify - webhook notification target configuration.
+type webhookNotify struct {
+	Enable   bool   `json:"enable"`
+	Endpoint string `json:"endpoint"`
+}
+
+// httpConn - webhook hook posting events to an endpoint, events are
+// queued and delivered in background in the order they were fired.
+type httpConn struct {
+	*http.Client
+	Endpoint string
+	queueCh  chan []byte
+}
+
+// newWebhookNotify - initializes a logger delivering events to the
+// webhook endpoint configured for the account id.
+func newWebhookNotify(accountID string) (*logrus.Logger, error) {
+	whNotify := serverConfig.GetWebhookNotifyByID(accountID)
+	if !whNotify.Enable {
+		return nil, errNotifyNotEnabled
+	}
+	if _, err := url.ParseRequestURI(whNotify.Endpoint); err != nil {
+		return nil, err
+	}
+
+	conn := httpConn{
+		Client:   &http.Client{Timeout: webhookTimeout},
+		Endpoint: whNotify.Endpoint,
+		queueCh:  make(chan []byte, webhookQueueSize),
+	}
+	go conn.deliverEvents()
+
+	notifyLog := logrus.New()
+	notifyLog.Out = ioutil.Discard
+
+	// Set default JSON formatter.
+	notifyLog.Formatter = new(logrus.JSONFormatter)
+
+	notifyLog.Hooks.Add(conn)
+
+	// Success
+	return notifyLog, nil
+}
+
+// Fire is called when an event should be sent to the message broker,
+// the event is queued for delivery without waiting for the endpoint.
+func (n httpConn) Fire(entry *logrus.Entry) error {
+	body, err := entry.Reader()
+	if err != nil {
+		return err
+	}
+	select {
+	case n.queueCh <- body.Bytes():
+		return nil
+	default:
+		return errWebhookQueueFull
+	}
+}
+
+// deliverEvents - posts the queued events to the endpoint one at a
+// time, events failing to be delivered are logged and dropped.
+func (n httpConn) deliverEvents() {
+	for body := range n.queueCh {
+		errorIf(n.postEvent(body), "Unable to deliver event to %s.", n.Endpoint)
+	}
+}
+
+// postEvent - posts an event to the endpoint.
+func (n httpConn) postEvent(body []byte) error {
+	req, err := http.NewRequest("POST", n.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Unable to send event to %s, received %s", n.Endpoint, resp.Status)
+	}
+	return nil
+}
+
+// Levels are Required for logrus hook implementation
+func (httpConn) Levels() []logrus.Level {
+	return []logrus.Level{
+		logrus.InfoLevel,
+	}
+}
@@ -0,0 +1,130 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+// Wrapper for calling conditional write tests for both XL multiple disks and single node setup.
+func TestObjectAPIConditionalWrites(t *testing.T) {
+	ExecObjectLayerTest(t, testObjectAPIConditionalWrites)
+}
+
+// Tests validate PutObjectIf, CompleteMultipartUploadIf and DeleteObjectIf.
+func testObjectAPIConditionalWrites(obj ObjectLayer, instanceType string, t *testing.T) {
+	bucket := "conditional-bucket"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	data := []byte("hello, conditional writes")
+	putObject := func(object string, cond writeCondition) (string, error) {
+		objInfo, err := obj.PutObjectIf(bucket, object, int64(len(data)), bytes.NewReader(data), nil, cond)
+		return objInfo.MD5Sum, err
+	}
+	isPreconditionFailed := func(err error) bool {
+		_, ok := err.(PreconditionFailed)
+		return ok
+	}
+
+	// Objects are created only if missing with If-None-Match "*".
+	md5Sum, err := putObject("object", writeCondition{IfNoneMatch: "*"})
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if _, err = putObject("object", writeCondition{IfNoneMatch: "*"}); !isPreconditionFailed(err) {
+		t.Fatalf("%s: Expected precondition failure, got %v", instanceType, err)
+	}
+
+	// Objects are replaced only if their ETag matches.
+	if _, err = putObject("object", writeCondition{IfMatch: "\"" + md5Sum + "\""}); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if _, err = putObject("object", writeCondition{IfMatch: "mismatch"}); !isPreconditionFailed(err) {
+		t.Fatalf("%s: Expected precondition failure, got %v", instanceType, err)
+	}
+	if _, err = putObject("missing", writeCondition{IfMatch: "*"}); !isPreconditionFailed(err) {
+		t.Fatalf("%s: Expected precondition failure, got %v", instanceType, err)
+	}
+	if _, err = obj.GetObjectInfo(bucket, "missing"); err == nil {
+		t.Fatalf("%s: Expected failed writes not to create objects", instanceType)
+	}
+
+	// Uploads are left intact when the condition is not met.
+	uploadID, err := obj.NewMultipartUpload(bucket, "object", nil)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	partMD5Sum, err := obj.PutObjectPart(bucket, "object", uploadID, 1, int64(len(data)), bytes.NewReader(data), "")
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if _, err = obj.PutObjectPart(bucket, "object", uploadID, 2, int64(len(data)), bytes.NewReader(data), ""); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if _, err = obj.CompleteMultipartUploadIf(bucket, "object", uploadID, []completePart{{PartNumber: 2, ETag: partMD5Sum}}, writeCondition{IfNoneMatch: "*"}); !isPreconditionFailed(err) {
+		t.Fatalf("%s: Expected precondition failure, got %v", instanceType, err)
+	}
+	partsInfo, err := obj.ListObjectParts(bucket, "object", uploadID, 0, 10)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if len(partsInfo.Parts) != 2 {
+		t.Fatalf("%s: Expected 2 parts left intact, got %d", instanceType, len(partsInfo.Parts))
+	}
+	parts := []completePart{{PartNumber: 1, ETag: partMD5Sum}}
+	objInfo, err := obj.CompleteMultipartUploadIf(bucket, "object", uploadID, parts, writeCondition{IfMatch: md5Sum})
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	multipartMD5Sum := objInfo.MD5Sum
+
+	// Objects are deleted only if their ETag matches.
+	if _, err = obj.DeleteObjectIf(bucket, "object", writeCondition{IfMatch: md5Sum}); !isPreconditionFailed(err) {
+		t.Fatalf("%s: Expected precondition failure, got %v", instanceType, err)
+	}
+	if _, err = obj.DeleteObjectIf(bucket, "object", writeCondition{IfMatch: multipartMD5Sum}); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if _, err = obj.GetObjectInfo(bucket, "object"); err == nil {
+		t.Fatalf("%s: Expected object to be deleted", instanceType)
+	}
+
+	// Only one of the concurrent writers creates the object.
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	created := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, pErr := putObject("lease", writeCondition{IfNoneMatch: "*"}); pErr == nil {
+				mu.Lock()
+				created++
+				mu.Unlock()
+			} else if !isPreconditionFailed(pErr) {
+				t.Errorf("%s: %s", instanceType, pErr)
+			}
+		}()
+	}
+	wg.Wait()
+	if created != 1 {
+		t.Fatalf("%s: Expected exactly one writer to create the object, got %d", instanceType, created)
+	}
+}
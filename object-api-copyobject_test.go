@@ -0,0 +1,128 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// Wrapper for calling CopyObject tests for both XL multiple disks and single node setup.
+func TestObjectAPICopyObject(t *testing.T) {
+	ExecObjectLayerTest(t, testObjectAPICopyObject)
+}
+
+// Tests validate CopyObject.
+func testObjectAPICopyObject(obj ObjectLayer, instanceType string, t *testing.T) {
+	bucket := "copy-object-bucket"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	expectObject := func(object string, data []byte, md5Sum string, metadata map[string]string) {
+		objInfo, err := obj.GetObjectInfo(bucket, object)
+		if err != nil {
+			t.Fatalf("%s: %s: %s", instanceType, object, err)
+		}
+		if objInfo.MD5Sum != md5Sum {
+			t.Fatalf("%s: %s: Expected md5sum %s, got %s", instanceType, object, md5Sum, objInfo.MD5Sum)
+		}
+		for key, value := range metadata {
+			if objInfo.UserDefined[key] != value {
+				t.Fatalf("%s: %s: Expected %s to be %s, got %s", instanceType, object, key, value, objInfo.UserDefined[key])
+			}
+		}
+		var buffer bytes.Buffer
+		if err = obj.GetObject(bucket, object, 0, objInfo.Size, &buffer); err != nil {
+			t.Fatalf("%s: %s: %s", instanceType, object, err)
+		}
+		if !bytes.Equal(buffer.Bytes(), data) {
+			t.Fatalf("%s: %s: Copied data does not match", instanceType, object)
+		}
+	}
+
+	// Single part source.
+	data := []byte("hello, copy object")
+	md5Sum, err := obj.PutObject(bucket, "source", int64(len(data)), bytes.NewReader(data), map[string]string{"X-Amz-Meta-Color": "red"})
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	copyInfo, err := obj.CopyObject(bucket, "source", bucket, "copy", map[string]string{"X-Amz-Meta-Color": "blue"})
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if copyInfo.MD5Sum != md5Sum {
+		t.Fatalf("%s: Expected md5sum %s, got %s", instanceType, md5Sum, copyInfo.MD5Sum)
+	}
+	expectObject("copy", data, md5Sum, map[string]string{"X-Amz-Meta-Color": "blue"})
+	expectObject("source", data, md5Sum, map[string]string{"X-Amz-Meta-Color": "red"})
+
+	// Copies are independent of the source.
+	if err = obj.DeleteObject(bucket, "source"); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	expectObject("copy", data, md5Sum, nil)
+
+	// Multipart source keeps its parts.
+	multipartData := make([]byte, minPartSize+1024)
+	rand.New(rand.NewSource(1)).Read(multipartData)
+	uploadID, err := obj.NewMultipartUpload(bucket, "multipart", nil)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	var parts []completePart
+	for i, partData := range [][]byte{multipartData[:minPartSize], multipartData[minPartSize:]} {
+		partMD5Sum, err := obj.PutObjectPart(bucket, "multipart", uploadID, i+1, int64(len(partData)), bytes.NewReader(partData), "")
+		if err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+		parts = append(parts, completePart{PartNumber: i + 1, ETag: partMD5Sum})
+	}
+	md5Sum, err = obj.CompleteMultipartUpload(bucket, "multipart", uploadID, parts)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if _, err = obj.CopyObject(bucket, "multipart", bucket, "multipart-copy", nil); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	expectObject("multipart-copy", multipartData, md5Sum, nil)
+
+	// Copying onto itself replaces the metadata.
+	if _, err = obj.CopyObject(bucket, "copy", bucket, "copy", map[string]string{"X-Amz-Meta-Color": "green"}); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	expectObject("copy", data, copyInfo.MD5Sum, map[string]string{"X-Amz-Meta-Color": "green"})
+
+	testCases := []struct {
+		srcBucket, srcObject, destBucket, destObject string
+		expectedErr                                  error
+	}{
+		{bucket, "missing", bucket, "target", ObjectNotFound{Bucket: bucket, Object: "missing"}},
+		{bucket, "copy", "missing-bucket", "target", BucketNotFound{Bucket: "missing-bucket"}},
+		{bucket, "", bucket, "target", ObjectNameInvalid{Bucket: bucket, Object: ""}},
+		{".bucket", "copy", bucket, "target", BucketNameInvalid{Bucket: ".bucket"}},
+	}
+	for i, testCase := range testCases {
+		_, err = obj.CopyObject(testCase.srcBucket, testCase.srcObject, testCase.destBucket, testCase.destObject, nil)
+		if err == nil || err.Error() != testCase.expectedErr.Error() {
+			t.Errorf("%s: Test %d: Expected error %v, got %v", instanceType, i+1, testCase.expectedErr, err)
+		}
+	}
+	if _, err = obj.GetObjectInfo(bucket, "target"); err == nil {
+		t.Errorf("%s: Expected failed copies not to create objects", instanceType)
+	}
+}
@@ -105,6 +105,65 @@ func testGetObjectInfo(obj ObjectLayer, instanceType string, t *testing.T) {
 	}
 }
 
+// Wrapper for calling GetObjectInfo metadata tests for both XL multiple disks and single node setup.
+func TestGetObjectInfoMetadata(t *testing.T) {
+	ExecObjectLayerTest(t, testGetObjectInfoMetadata)
+}
+
+// Testing GetObjectInfo() returns metadata saved by PutObject() and CompleteMultipartUpload().
+func testGetObjectInfoMetadata(obj ObjectLayer, instanceType string, t *testing.T) {
+	bucket := "test-getobjectinfo-meta"
+	err := obj.MakeBucket(bucket)
+	if err != nil {
+		t.Fatalf("%s : %s", instanceType, err.Error())
+	}
+	metadata := map[string]string{
+		"content-type":     "application/json",
+		"content-encoding": "gzip",
+		"X-Amz-Meta-Owner": "minio",
+	}
+	_, err = obj.PutObject(bucket, "object", int64(len("hello")), bytes.NewBufferString("hello"), metadata)
+	if err != nil {
+		t.Fatalf("%s : %s", instanceType, err.Error())
+	}
+
+	uploadID, err := obj.NewMultipartUpload(bucket, "multipart-object", map[string]string{
+		"content-type":     "application/json",
+		"content-encoding": "gzip",
+		"X-Amz-Meta-Owner": "minio",
+	})
+	if err != nil {
+		t.Fatalf("%s : %s", instanceType, err.Error())
+	}
+	md5Hex, err := obj.PutObjectPart(bucket, "multipart-object", uploadID, 1, int64(len("hello")), bytes.NewBufferString("hello"), "")
+	if err != nil {
+		t.Fatalf("%s : %s", instanceType, err.Error())
+	}
+	_, err = obj.CompleteMultipartUpload(bucket, "multipart-object", uploadID, []completePart{{PartNumber: 1, ETag: md5Hex}})
+	if err != nil {
+		t.Fatalf("%s : %s", instanceType, err.Error())
+	}
+
+	for i, object := range []string{"object", "multipart-object"} {
+		objInfo, err := obj.GetObjectInfo(bucket, object)
+		if err != nil {
+			t.Fatalf("Test %d: %s: Expected to pass, but failed with: <ERROR> %s", i+1, instanceType, err.Error())
+		}
+		if objInfo.ContentType != "application/json" {
+			t.Errorf("Test %d: %s: Expected Content Type to be application/json, but found %s", i+1, instanceType, objInfo.ContentType)
+		}
+		if objInfo.ContentEncoding != "gzip" {
+			t.Errorf("Test %d: %s: Expected Content Encoding to be gzip, but found %s", i+1, instanceType, objInfo.ContentEncoding)
+		}
+		if objInfo.MD5Sum == "" {
+			t.Errorf("Test %d: %s: Expected MD5Sum to be set, but found empty", i+1, instanceType)
+		}
+		if objInfo.UserDefined["X-Amz-Meta-Owner"] != "minio" {
+			t.Errorf("Test %d: %s: Expected user metadata X-Amz-Meta-Owner to be minio, but found %s", i+1, instanceType, objInfo.UserDefined["X-Amz-Meta-Owner"])
+		}
+	}
+}
+
 // Benchmarks for ObjectLayer.PutObject().
 // The intent is to benchamrk PutObject for various sizes ranging from few bytes to 100MB.
 // Also each of these Benchmarks are run both XL and FS backends.
@@ -0,0 +1,233 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Wrapper for calling object versioning tests for both XL multiple disks and single node setup.
+func TestObjectVersioning(t *testing.T) {
+	rootPath, err := ioutil.TempDir("", "minio-versioning")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeAll(rootPath)
+	setGlobalConfigPath(rootPath)
+	defer setGlobalConfigPath("")
+
+	ExecObjectLayerTest(t, testObjectVersioning)
+}
+
+// Testing object versioning.
+func testObjectVersioning(obj ObjectLayer, instanceType string, t *testing.T) {
+	bucket := "test-versioning-" + strings.ToLower(instanceType)
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+
+	// Object written before versioning is configured has no version id.
+	if _, err := obj.PutObject(bucket, "object", int64(len("v0")), bytes.NewBufferString("v0"), nil); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	objInfo, err := obj.GetObjectInfo(bucket, "object")
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if objInfo.VersionID != "" {
+		t.Fatalf("%s: Expected empty version id, got %s", instanceType, objInfo.VersionID)
+	}
+
+	if err = writeBucketVersioning(bucket, versioningConfiguration{Status: versioningEnabled}); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+
+	// Overwrite twice, every write is kept as a version.
+	var versionIDs []string
+	for _, data := range []string{"v1", "v2"} {
+		var putInfo ObjectInfo
+		putInfo, err = obj.PutObjectIf(bucket, "object", int64(len(data)), bytes.NewBufferString(data), nil, writeCondition{})
+		if err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+		objInfo, err = obj.GetObjectInfo(bucket, "object")
+		if err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+		if objInfo.VersionID == "" || objInfo.VersionID == nullVersionID {
+			t.Fatalf("%s: Expected a unique version id, got %q", instanceType, objInfo.VersionID)
+		}
+		// Version id of the write is returned by the object layer.
+		if putInfo.VersionID != objInfo.VersionID {
+			t.Fatalf("%s: Expected version id %s to be returned, got %s", instanceType, objInfo.VersionID, putInfo.VersionID)
+		}
+		versionIDs = append(versionIDs, objInfo.VersionID)
+	}
+
+	result, err := obj.ListObjectVersions(bucket, "", "", "", "", 1000)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	expectedIDs := []string{versionIDs[1], versionIDs[0], nullVersionID}
+	if len(result.Objects) != len(expectedIDs) {
+		t.Fatalf("%s: Expected %d versions, got %d", instanceType, len(expectedIDs), len(result.Objects))
+	}
+	for i, objInfo := range result.Objects {
+		if objInfo.VersionID != expectedIDs[i] {
+			t.Errorf("%s: Version %d: Expected version id %s, got %s", instanceType, i+1, expectedIDs[i], objInfo.VersionID)
+		}
+		if objInfo.IsLatest != (i == 0) {
+			t.Errorf("%s: Version %d: Unexpected IsLatest %v", instanceType, i+1, objInfo.IsLatest)
+		}
+	}
+
+	// Read older versions.
+	for versionID, expected := range map[string]string{versionIDs[0]: "v1", nullVersionID: "v0", "": "v2"} {
+		var buffer bytes.Buffer
+		if err = obj.GetObjectVersion(bucket, "object", versionID, 0, 2, &buffer); err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+		if buffer.String() != expected {
+			t.Errorf("%s: Version %q: Expected %s, got %s", instanceType, versionID, expected, buffer.String())
+		}
+	}
+	if _, err = obj.GetObjectVersionInfo(bucket, "object", "unknown"); err == nil {
+		t.Fatalf("%s: Expected to fail reading an unknown version", instanceType)
+	}
+	if _, ok := err.(VersionNotFound); !ok {
+		t.Fatalf("%s: Expected VersionNotFound, got %#v", instanceType, err)
+	}
+
+	// Replacing the metadata of the object keeps the previous version.
+	copyInfo, err := obj.CopyObject(bucket, "object", bucket, "object", map[string]string{"x-amz-meta-replaced": "true"})
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if copyInfo.VersionID == "" || copyInfo.VersionID == versionIDs[1] {
+		t.Fatalf("%s: Expected a new version id, got %q", instanceType, copyInfo.VersionID)
+	}
+	objInfo, err = obj.GetObjectVersionInfo(bucket, "object", versionIDs[1])
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if _, ok := objInfo.UserDefined["x-amz-meta-replaced"]; ok {
+		t.Fatalf("%s: Expected metadata of the previous version to be unchanged", instanceType)
+	}
+	versionIDs = append(versionIDs, copyInfo.VersionID)
+
+	// Delete creates a delete marker.
+	markerInfo, err := obj.DeleteObjectIf(bucket, "object", writeCondition{})
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if _, err = obj.GetObjectInfo(bucket, "object"); err == nil {
+		t.Fatalf("%s: Expected object to be not found after delete", instanceType)
+	}
+	marker, err := obj.GetObjectVersionInfo(bucket, "object", "")
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if !marker.DeleteMarker || !marker.IsLatest {
+		t.Fatalf("%s: Expected latest version to be a delete marker, got %#v", instanceType, marker)
+	}
+	if !markerInfo.DeleteMarker || markerInfo.VersionID != marker.VersionID {
+		t.Fatalf("%s: Expected delete marker %s to be returned, got %#v", instanceType, marker.VersionID, markerInfo)
+	}
+
+	// Bucket holding versions cannot be deleted.
+	if err = obj.DeleteBucket(bucket); err == nil {
+		t.Fatalf("%s: Expected bucket delete to fail", instanceType)
+	}
+
+	// Removing the delete marker restores the object.
+	deletedInfo, err := obj.DeleteObjectVersion(bucket, "object", marker.VersionID)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if !deletedInfo.DeleteMarker || deletedInfo.VersionID != marker.VersionID {
+		t.Fatalf("%s: Expected deleted delete marker %s, got %+v", instanceType, marker.VersionID, deletedInfo)
+	}
+	objInfo, err = obj.GetObjectInfo(bucket, "object")
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if objInfo.VersionID != versionIDs[2] {
+		t.Fatalf("%s: Expected version %s to be restored, got %s", instanceType, versionIDs[2], objInfo.VersionID)
+	}
+
+	// Suspended versioning replaces the null version.
+	if err = writeBucketVersioning(bucket, versioningConfiguration{Status: versioningSuspended}); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	for _, data := range []string{"s1", "s2"} {
+		if _, err = obj.PutObject(bucket, "object", int64(len(data)), bytes.NewBufferString(data), nil); err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+	}
+	result, err = obj.ListObjectVersions(bucket, "", "", "", "", 1000)
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	expectedIDs = []string{nullVersionID, versionIDs[2], versionIDs[1], versionIDs[0]}
+	if len(result.Objects) != len(expectedIDs) {
+		t.Fatalf("%s: Expected %d versions, got %d", instanceType, len(expectedIDs), len(result.Objects))
+	}
+	for i, objInfo := range result.Objects {
+		if objInfo.VersionID != expectedIDs[i] {
+			t.Errorf("%s: Version %d: Expected version id %s, got %s", instanceType, i+1, expectedIDs[i], objInfo.VersionID)
+		}
+	}
+
+	// Remove all versions, the bucket can be deleted afterwards.
+	for _, versionID := range expectedIDs {
+		if deletedInfo, err = obj.DeleteObjectVersion(bucket, "object", versionID); err != nil {
+			t.Fatalf("%s: %s", instanceType, err)
+		}
+		if deletedInfo.DeleteMarker || deletedInfo.VersionID != versionID {
+			t.Fatalf("%s: Expected deleted version %s, got %+v", instanceType, versionID, deletedInfo)
+		}
+	}
+	if err = obj.DeleteBucket(bucket); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+}
+
+// Tests list object versions response.
+func TestGenerateListVersionsResponse(t *testing.T) {
+	modTime := time.Date(2016, 8, 1, 0, 0, 0, 0, time.UTC)
+	resp := generateListVersionsResponse("bucket", "", "", "", "", 1000, ListObjectVersionsInfo{
+		Objects: []ObjectInfo{
+			{Name: "object", VersionID: "v2", DeleteMarker: true, IsLatest: true, ModTime: modTime},
+			{Name: "object", VersionID: "v1", MD5Sum: "abcd", Size: 4, ModTime: modTime},
+		},
+	})
+	data, err := xml.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<ListVersionsResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Name>bucket</Name><Prefix></Prefix><KeyMarker></KeyMarker><VersionIdMarker></VersionIdMarker><MaxKeys>1000</MaxKeys><Delimiter></Delimiter><IsTruncated>false</IsTruncated>` +
+		`<DeleteMarker><Key>object</Key><VersionId>v2</VersionId><IsLatest>true</IsLatest><LastModified>2016-08-01T00:00:00.000Z</LastModified><Owner><ID>minio</ID><DisplayName>minio</DisplayName></Owner></DeleteMarker>` +
+		`<Version><Key>object</Key><VersionId>v1</VersionId><IsLatest>false</IsLatest><LastModified>2016-08-01T00:00:00.000Z</LastModified><ETag>&#34;abcd&#34;</ETag><Size>4</Size><Owner><ID>minio</ID><DisplayName>minio</DisplayName></Owner><StorageClass>STANDARD</StorageClass></Version></ListVersionsResult>`
+	if string(data) != expected {
+		t.Fatalf("Expected %s, got %s", expected, string(data))
+	}
+}
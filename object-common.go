@@ -32,15 +32,35 @@ const (
 	readSizeV1 = 128 * 1024 // 128KiB.
 )
 
+// Callback functions called in order of registration when process
+// shutsdown.
+var shutdownCallbacks = struct {
+	sync.Mutex
+	callbacks []func()
+	once      sync.Once
+}{}
+
 // Register callback functions that needs to be called when process shutsdown.
 // For now, SIGINT triggers the callbacks, in future controller can trigger
-// shutdown callbacks.
+// shutdown callbacks. Process exits once all the callbacks return.
 func registerShutdown(callback func()) {
-	go func() {
-		trapCh := signalTrap(os.Interrupt, syscall.SIGTERM)
-		<-trapCh
-		callback()
-	}()
+	shutdownCallbacks.Lock()
+	shutdownCallbacks.callbacks = append(shutdownCallbacks.callbacks, callback)
+	shutdownCallbacks.Unlock()
+
+	shutdownCallbacks.once.Do(func() {
+		go func() {
+			trapCh := signalTrap(os.Interrupt, syscall.SIGTERM)
+			<-trapCh
+			shutdownCallbacks.Lock()
+			callbacks := shutdownCallbacks.callbacks
+			shutdownCallbacks.Unlock()
+			for _, callback := range callbacks {
+				callback()
+			}
+			os.Exit(0)
+		}()
+	})
 }
 
 // House keeping code needed for FS.
@@ -152,3 +172,40 @@ func cleanupDir(storage StorageAPI, volume, dirPath string) error {
 	err := delFunc(retainSlash(pathJoin(dirPath)))
 	return err
 }
+
+// writeCondition - preconditions on the current object to be met for
+// an object to be replaced or deleted, empty fields are not checked.
+type writeCondition struct {
+	IfMatch     string // ETag of the current object, "*" matches any object.
+	IfNoneMatch string // ETag the current object must not have, "*" requires no object.
+}
+
+// checkWriteCondition - verifies the write condition against the
+// current object, to be called with the object write lock held so
+// that the object cannot change before it is replaced. Delete markers
+// are not objects.
+func checkWriteCondition(layer versionedObjects, bucket, object string, cond writeCondition) error {
+	if cond.IfMatch == "" && cond.IfNoneMatch == "" {
+		return nil
+	}
+	exists := layer.isObject(bucket, object)
+	var etag string
+	if exists {
+		objInfo, err := layer.getObjectInfo(bucket, object)
+		if err != nil {
+			return err
+		}
+		etag = objInfo.MD5Sum
+	}
+	if cond.IfMatch != "" {
+		if !exists || !(isETagEqual(cond.IfMatch, "*") || isETagEqual(cond.IfMatch, etag)) {
+			return PreconditionFailed{Bucket: bucket, Object: object}
+		}
+	}
+	if cond.IfNoneMatch != "" && exists {
+		if isETagEqual(cond.IfNoneMatch, "*") || isETagEqual(cond.IfNoneMatch, etag) {
+			return PreconditionFailed{Bucket: bucket, Object: object}
+		}
+	}
+	return nil
+}
@@ -62,6 +62,22 @@ type ObjectInfo struct {
 	// what decoding mechanisms must be applied to obtain the object referenced
 	// by the Content-Type header field.
 	ContentEncoding string
+
+	// User defined metadata saved along with the object.
+	UserDefined map[string]string
+
+	// Version id of the object, empty if versioning was never
+	// configured on the bucket.
+	VersionID string
+
+	// DeleteMarker indicates if the version is a delete marker.
+	DeleteMarker bool
+
+	// IsLatest indicates if the version is the latest version of the object.
+	IsLatest bool
+
+	// Parts of a multipart object, empty for objects not written in parts.
+	Parts []objectPartInfo
 }
 
 // ListPartsInfo - represents list of all parts.
@@ -95,6 +111,9 @@ type ListPartsInfo struct {
 	// List of all parts.
 	Parts []partInfo
 
+	// User defined metadata saved when the upload was initiated.
+	UserDefined map[string]string
+
 	EncodingType string // Not supported yet.
 }
 
@@ -168,6 +187,26 @@ type ListObjectsInfo struct {
 	Prefixes []string
 }
 
+// ListObjectVersionsInfo - container for list object versions.
+type ListObjectVersionsInfo struct {
+	// Indicates whether the returned list object versions response is
+	// truncated. A value of true indicates that the list was truncated.
+	IsTruncated bool
+
+	// When response is truncated (the IsTruncated element value in the response
+	// is true), you can use the key name and version id in these fields as
+	// key marker and version id marker in the subsequent request.
+	NextKeyMarker       string
+	NextVersionIDMarker string
+
+	// List of object versions and delete markers for this request, latest
+	// version of each object first.
+	Objects []ObjectInfo
+
+	// List of prefixes for this request.
+	Prefixes []string
+}
+
 // partInfo - represents individual part metadata.
 type partInfo struct {
 	// Part number that identifies the part. This is a positive integer between
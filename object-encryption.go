@@ -0,0 +1,480 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+)
+
+// Server side encryption algorithm supported.
+const sseAlgorithmAES256 = "AES256"
+
+// Header requesting SSE-S3 encryption with the master key.
+const sseHeader = "X-Amz-Server-Side-Encryption"
+
+// Metadata saved along with encrypted objects, these keys are never
+// returned to clients.
+const (
+	// Random IV used to derive the key sealing the object key.
+	sseMetaIV = "X-Minio-Internal-Server-Side-Encryption-Iv"
+	// Object key sealed with the customer provided key or the master key.
+	sseMetaSealedKey = "X-Minio-Internal-Server-Side-Encryption-Sealed-Key"
+	// MD5 sum of the customer provided key.
+	sseMetaCustomerKeyMD5 = "X-Minio-Internal-Server-Side-Encryption-Customer-Key-Md5"
+)
+
+// Domains of object keys sealed with customer provided keys and with
+// the master key.
+const (
+	sseDomainCustomer = "SSE-C"
+	sseDomainS3       = "SSE-S3"
+)
+
+// sseHeaders - names of the headers carrying a customer provided key.
+type sseHeaders struct {
+	algorithm string
+	key       string
+	keyMD5    string
+}
+
+// SSE-C headers of the object in the request.
+var sseCustomerHeaders = sseHeaders{
+	algorithm: "X-Amz-Server-Side-Encryption-Customer-Algorithm",
+	key:       "X-Amz-Server-Side-Encryption-Customer-Key",
+	keyMD5:    "X-Amz-Server-Side-Encryption-Customer-Key-Md5",
+}
+
+// SSE-C headers of the copy source object in the request.
+var sseCopyCustomerHeaders = sseHeaders{
+	algorithm: "X-Amz-Copy-Source-Server-Side-Encryption-Customer-Algorithm",
+	key:       "X-Amz-Copy-Source-Server-Side-Encryption-Customer-Key",
+	keyMD5:    "X-Amz-Copy-Source-Server-Side-Encryption-Customer-Key-Md5",
+}
+
+// hasSSECustomerHeader - returns true if any of the SSE-C headers is set.
+func hasSSECustomerHeader(header http.Header, hdrs sseHeaders) bool {
+	return header.Get(hdrs.algorithm) != "" || header.Get(hdrs.key) != "" || header.Get(hdrs.keyMD5) != ""
+}
+
+// parseSSECustomerKey - validates the SSE-C headers and returns the
+// customer provided key.
+func parseSSECustomerKey(header http.Header, hdrs sseHeaders) ([]byte, APIErrorCode) {
+	if header.Get(hdrs.algorithm) != sseAlgorithmAES256 {
+		return nil, ErrInvalidSSECustomerAlgorithm
+	}
+	if header.Get(hdrs.key) == "" {
+		return nil, ErrMissingSSECustomerKey
+	}
+	if header.Get(hdrs.keyMD5) == "" {
+		return nil, ErrMissingSSECustomerKeyMD5
+	}
+	key, err := base64.StdEncoding.DecodeString(header.Get(hdrs.key))
+	if err != nil || len(key) != encObjectKeySize {
+		return nil, ErrInvalidSSECustomerKey
+	}
+	keyMD5 := md5.Sum(key)
+	if base64.StdEncoding.EncodeToString(keyMD5[:]) != header.Get(hdrs.keyMD5) {
+		return nil, ErrSSECustomerKeyMD5Mismatch
+	}
+	return key, ErrNone
+}
+
+// sealingKey - derives the key sealing object keys of an object from
+// the external key.
+func sealingKey(extKey, iv []byte, domain, bucket, object string) []byte {
+	mac := hmac.New(sha256.New, extKey)
+	mac.Write(iv)
+	mac.Write([]byte(domain))
+	mac.Write([]byte(pathJoin(bucket, object)))
+	return mac.Sum(nil)
+}
+
+// sealObjectKey - seals the object key with the external key, the
+// sealed key is bound to the bucket and object name.
+func sealObjectKey(extKey, objectKey []byte, domain, bucket, object string) (iv, sealedKey []byte, err error) {
+	iv = make([]byte, 32)
+	if _, err = io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, nil, err
+	}
+	aead, err := newObjectKeyAEAD(sealingKey(extKey, iv, domain, bucket, object))
+	if err != nil {
+		return nil, nil, err
+	}
+	// Every sealing key is used only once, a zero nonce is safe.
+	nonce := make([]byte, aead.NonceSize())
+	return iv, aead.Seal(nil, nonce, objectKey, nil), nil
+}
+
+// unsealObjectKey - unseals an object key sealed by sealObjectKey.
+func unsealObjectKey(extKey, iv, sealedKey []byte, domain, bucket, object string) ([]byte, error) {
+	aead, err := newObjectKeyAEAD(sealingKey(extKey, iv, domain, bucket, object))
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	objectKey, err := aead.Open(nil, nonce, sealedKey, nil)
+	if err != nil {
+		return nil, errObjectTampered
+	}
+	return objectKey, nil
+}
+
+// isEncrypted - returns true if the object metadata describes an
+// encrypted object.
+func isEncrypted(metadata map[string]string) bool {
+	return metadata[sseMetaSealedKey] != ""
+}
+
+// isSSECustomerEncrypted - returns true if the object metadata
+// describes an object encrypted with a customer provided key.
+func isSSECustomerEncrypted(metadata map[string]string) bool {
+	return metadata[sseMetaCustomerKeyMD5] != ""
+}
+
+// newSealedObjectKey - generates a new object key and saves it sealed
+// with the external key in the object metadata.
+func newSealedObjectKey(extKey []byte, domain, bucket, object string, metadata map[string]string) ([]byte, APIErrorCode) {
+	objectKey := make([]byte, encObjectKeySize)
+	if _, err := io.ReadFull(rand.Reader, objectKey); err != nil {
+		errorIf(err, "Unable to generate object key.")
+		return nil, ErrInternalError
+	}
+	if s3Error := sealMetadataObjectKey(extKey, objectKey, domain, bucket, object, metadata); s3Error != ErrNone {
+		return nil, s3Error
+	}
+	return objectKey, ErrNone
+}
+
+// sealMetadataObjectKey - saves the object key sealed with the external
+// key in the object metadata.
+func sealMetadataObjectKey(extKey, objectKey []byte, domain, bucket, object string, metadata map[string]string) APIErrorCode {
+	iv, sealedKey, err := sealObjectKey(extKey, objectKey, domain, bucket, object)
+	if err != nil {
+		errorIf(err, "Unable to seal object key.")
+		return ErrInternalError
+	}
+	metadata[sseMetaIV] = base64.StdEncoding.EncodeToString(iv)
+	metadata[sseMetaSealedKey] = base64.StdEncoding.EncodeToString(sealedKey)
+	return ErrNone
+}
+
+// unsealMetadataObjectKey - unseals the object key saved in the object
+// metadata with the external key.
+func unsealMetadataObjectKey(extKey []byte, domain, bucket, object string, metadata map[string]string) ([]byte, error) {
+	iv, err := base64.StdEncoding.DecodeString(metadata[sseMetaIV])
+	if err != nil {
+		return nil, err
+	}
+	sealedKey, err := base64.StdEncoding.DecodeString(metadata[sseMetaSealedKey])
+	if err != nil {
+		return nil, err
+	}
+	return unsealObjectKey(extKey, iv, sealedKey, domain, bucket, object)
+}
+
+// newSSECustomerObjectKey - generates a new object key for the SSE-C
+// request and saves it sealed with the customer provided key in the
+// object metadata.
+func newSSECustomerObjectKey(header http.Header, bucket, object string, metadata map[string]string) ([]byte, APIErrorCode) {
+	clientKey, s3Error := parseSSECustomerKey(header, sseCustomerHeaders)
+	if s3Error != ErrNone {
+		return nil, s3Error
+	}
+	objectKey, s3Error := newSealedObjectKey(clientKey, sseDomainCustomer, bucket, object, metadata)
+	if s3Error != ErrNone {
+		return nil, s3Error
+	}
+	metadata[sseMetaCustomerKeyMD5] = header.Get(sseCustomerHeaders.keyMD5)
+	return objectKey, ErrNone
+}
+
+// getSSECustomerObjectKey - returns the object key of an object
+// encrypted with the customer provided key in the request.
+func getSSECustomerObjectKey(header http.Header, hdrs sseHeaders, bucket, object string, metadata map[string]string) ([]byte, APIErrorCode) {
+	if !hasSSECustomerHeader(header, hdrs) {
+		return nil, ErrSSEEncryptedObject
+	}
+	clientKey, s3Error := parseSSECustomerKey(header, hdrs)
+	if s3Error != ErrNone {
+		return nil, s3Error
+	}
+	if header.Get(hdrs.keyMD5) != metadata[sseMetaCustomerKeyMD5] {
+		return nil, ErrSSECustomerKeyMismatch
+	}
+	objectKey, err := unsealMetadataObjectKey(clientKey, sseDomainCustomer, bucket, object, metadata)
+	if err != nil {
+		return nil, ErrSSECustomerKeyMismatch
+	}
+	return objectKey, ErrNone
+}
+
+// newSSES3ObjectKey - generates a new object key and saves it sealed
+// with the master key in the object metadata.
+func newSSES3ObjectKey(bucket, object string, metadata map[string]string) ([]byte, APIErrorCode) {
+	if globalSSEMasterKey == nil {
+		return nil, ErrMasterKeyNotConfigured
+	}
+	return newSealedObjectKey(globalSSEMasterKey, sseDomainS3, bucket, object, metadata)
+}
+
+// getSSES3ObjectKey - returns the object key of an object encrypted
+// with the master key.
+func getSSES3ObjectKey(bucket, object string, metadata map[string]string) ([]byte, APIErrorCode) {
+	if globalSSEMasterKey == nil {
+		return nil, ErrMasterKeyNotConfigured
+	}
+	objectKey, err := unsealMetadataObjectKey(globalSSEMasterKey, sseDomainS3, bucket, object, metadata)
+	if err != nil {
+		errorIf(err, "Unable to unseal object key of %s/%s.", bucket, object)
+		return nil, ErrInternalError
+	}
+	return objectKey, ErrNone
+}
+
+// getRequestSSEDomain - returns the domain of the object key of an
+// object written by the request, either customer provided or master key
+// as requested by the SSE headers or by the bucket default encryption.
+// Returns an empty domain for objects stored unencrypted.
+func getRequestSSEDomain(header http.Header, bucket string) (string, APIErrorCode) {
+	sseAlgorithm := header.Get(sseHeader)
+	if hasSSECustomerHeader(header, sseCustomerHeaders) {
+		// SSE-C and SSE-S3 are mutually exclusive.
+		if sseAlgorithm != "" {
+			return "", ErrInvalidEncryptionParameters
+		}
+		return sseDomainCustomer, ErrNone
+	}
+	if sseAlgorithm == "" {
+		// Apply the bucket default encryption, if any.
+		ec, err := readBucketEncryption(bucket)
+		if err != nil {
+			if _, ok := err.(BucketEncryptionNotFound); ok {
+				return "", ErrNone
+			}
+			errorIf(err, "Unable to read encryption configuration.")
+			return "", toAPIErrorCode(err)
+		}
+		sseAlgorithm = ec.algorithm()
+	}
+	if sseAlgorithm != sseAlgorithmAES256 {
+		return "", ErrInvalidEncryptionMethod
+	}
+	return sseDomainS3, ErrNone
+}
+
+// newRequestObjectKey - returns a new object key for an object written
+// by the request, encrypted either with the customer provided key or
+// with the master key. Returns nil for objects stored unencrypted.
+func newRequestObjectKey(header http.Header, bucket, object string, metadata map[string]string) ([]byte, APIErrorCode) {
+	domain, s3Error := getRequestSSEDomain(header, bucket)
+	if s3Error != ErrNone {
+		return nil, s3Error
+	}
+	switch domain {
+	case sseDomainCustomer:
+		return newSSECustomerObjectKey(header, bucket, object, metadata)
+	case sseDomainS3:
+		return newSSES3ObjectKey(bucket, object, metadata)
+	}
+	return nil, ErrNone
+}
+
+// sealCopyObjectKey - saves the object key of the copy source sealed
+// for the destination object in metadata, when the destination is to
+// be encrypted with the same key as the source. Returns false if the
+// data of the source cannot be copied as is and has to be re-encrypted.
+func sealCopyObjectKey(header http.Header, sourceKey []byte, srcMetadata map[string]string, bucket, object string, metadata map[string]string) (bool, APIErrorCode) {
+	domain, s3Error := getRequestSSEDomain(header, bucket)
+	if s3Error != ErrNone {
+		return false, s3Error
+	}
+	switch {
+	case !isEncrypted(srcMetadata):
+		return domain == "", ErrNone
+	case isSSECustomerEncrypted(srcMetadata):
+		if domain != sseDomainCustomer || header.Get(sseCustomerHeaders.keyMD5) != srcMetadata[sseMetaCustomerKeyMD5] {
+			return false, ErrNone
+		}
+		clientKey, s3Error := parseSSECustomerKey(header, sseCustomerHeaders)
+		if s3Error != ErrNone {
+			return false, s3Error
+		}
+		if s3Error = sealMetadataObjectKey(clientKey, sourceKey, sseDomainCustomer, bucket, object, metadata); s3Error != ErrNone {
+			return false, s3Error
+		}
+		metadata[sseMetaCustomerKeyMD5] = srcMetadata[sseMetaCustomerKeyMD5]
+		return true, ErrNone
+	}
+	if domain != sseDomainS3 {
+		return false, ErrNone
+	}
+	if globalSSEMasterKey == nil {
+		return false, ErrMasterKeyNotConfigured
+	}
+	if s3Error = sealMetadataObjectKey(globalSSEMasterKey, sourceKey, sseDomainS3, bucket, object, metadata); s3Error != ErrNone {
+		return false, s3Error
+	}
+	return true, ErrNone
+}
+
+// getObjectKey - returns the object key of an encrypted object, the
+// customer provided key is read from the hdrs of the request.
+func getObjectKey(header http.Header, hdrs sseHeaders, bucket, object string, metadata map[string]string) ([]byte, APIErrorCode) {
+	if isSSECustomerEncrypted(metadata) {
+		return getSSECustomerObjectKey(header, hdrs, bucket, object, metadata)
+	}
+	if hasSSECustomerHeader(header, hdrs) {
+		return nil, ErrInvalidEncryptionParameters
+	}
+	return getSSES3ObjectKey(bucket, object, metadata)
+}
+
+// decryptObjectInfo - verifies the SSE-C headers of the request match
+// the object and returns the object key of an encrypted object, objects
+// encrypted with the master key need no headers. Size
+// of encrypted objects is updated to the size of the plain text while
+// Parts keep describing the encrypted parts.
+func decryptObjectInfo(header http.Header, hdrs sseHeaders, objInfo *ObjectInfo) ([]byte, APIErrorCode) {
+	if !isEncrypted(objInfo.UserDefined) {
+		if hasSSECustomerHeader(header, hdrs) {
+			return nil, ErrInvalidEncryptionParameters
+		}
+		return nil, ErrNone
+	}
+	objectKey, s3Error := getObjectKey(header, hdrs, objInfo.Bucket, objInfo.Name, objInfo.UserDefined)
+	if s3Error != ErrNone {
+		return nil, s3Error
+	}
+	// Objects written in a single operation are a single encrypted stream.
+	if len(objInfo.Parts) == 0 {
+		objInfo.Parts = []objectPartInfo{{Number: 1, Size: objInfo.Size}}
+	}
+	var size int64
+	for _, part := range objInfo.Parts {
+		partSize, err := decryptedSize(part.Size)
+		if err != nil {
+			errorIf(err, "Invalid encrypted object size for %s/%s.", objInfo.Bucket, objInfo.Name)
+			return nil, ErrInternalError
+		}
+		size += partSize
+	}
+	objInfo.Size = size
+	return objectKey, ErrNone
+}
+
+// locateEncryptedPackage - returns the part holding the plain text
+// offset of an encrypted object, the offset of the part and the
+// sequence number of the package holding the offset.
+func locateEncryptedPackage(parts []objectPartInfo, offset int64) (partIdx int, partOffset int64, seq int64, err error) {
+	for i, part := range parts {
+		partSize, err := decryptedSize(part.Size)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		if offset < partSize {
+			return i, partOffset, offset / encPayloadSize, nil
+		}
+		offset -= partSize
+		partOffset += part.Size
+	}
+	return 0, 0, 0, InvalidRange{}
+}
+
+// newDecryptRangeWriter - returns the range of the encrypted object
+// to be read for the plain text range and a writer decrypting it to w.
+// objInfo must be prepared by decryptObjectInfo.
+func newDecryptRangeWriter(w io.Writer, objectKey []byte, objInfo ObjectInfo, offset, length int64) (encOffset, encLength int64, dw io.WriteCloser, err error) {
+	if length == 0 {
+		dw, err = newDecryptWriter(w, objectKey, 0, nil, 0, 0)
+		return 0, 0, dw, err
+	}
+	startIdx, startPartOffset, startSeq, err := locateEncryptedPackage(objInfo.Parts, offset)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	endIdx, endPartOffset, endSeq, err := locateEncryptedPackage(objInfo.Parts, offset+length-1)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	// Read from the start of the first package to the end of the last.
+	encOffset = startPartOffset + startSeq*encPackageSize
+	encEnd := endPartOffset + (endSeq+1)*encPackageSize
+	if partEnd := endPartOffset + objInfo.Parts[endIdx].Size; encEnd > partEnd {
+		encEnd = partEnd
+	}
+
+	// Every part is an encrypted stream of its own.
+	streamSizes := []int64{startPartOffset + objInfo.Parts[startIdx].Size - encOffset}
+	for _, part := range objInfo.Parts[startIdx+1 : endIdx+1] {
+		streamSizes = append(streamSizes, part.Size)
+	}
+	skip := offset - startSeq*encPayloadSize
+	for _, part := range objInfo.Parts[:startIdx] {
+		partSize, _ := decryptedSize(part.Size)
+		skip -= partSize
+	}
+	dw, err = newDecryptWriter(w, objectKey, uint32(startSeq), streamSizes, skip, length)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	return encOffset, encEnd - encOffset, dw, nil
+}
+
+// md5VerifyReader - verifies the md5 sum of the data read once the
+// underlying reader is exhausted.
+type md5VerifyReader struct {
+	src      io.Reader
+	md5      hash.Hash
+	expected []byte
+}
+
+// Read - implements io.Reader, returns BadDigest instead of io.EOF if
+// the md5 sum does not match.
+func (r *md5VerifyReader) Read(p []byte) (int, error) {
+	n, err := r.src.Read(p)
+	r.md5.Write(p[:n])
+	if err == io.EOF && len(r.expected) != 0 {
+		if calculated := r.md5.Sum(nil); !bytes.Equal(calculated, r.expected) {
+			return n, BadDigest{hex.EncodeToString(r.expected), hex.EncodeToString(calculated)}
+		}
+	}
+	return n, err
+}
+
+// encryptRequestReader - returns a reader encrypting data with the
+// object key after verifying the md5 sum sent by the client, data is
+// returned as is for unencrypted objects.
+func encryptRequestReader(data io.Reader, objectKey, md5Bytes []byte) io.Reader {
+	if objectKey == nil {
+		return data
+	}
+	return newEncryptReader(&md5VerifyReader{
+		src:      data,
+		md5:      md5.New(),
+		expected: md5Bytes,
+	}, objectKey)
+}
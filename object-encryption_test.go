@@ -0,0 +1,323 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Encrypts data with the object key.
+func encryptTestData(t *testing.T, objectKey, data []byte) []byte {
+	encData, err := ioutil.ReadAll(newEncryptReader(bytes.NewReader(data), objectKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encData
+}
+
+// Tests encrypting and decrypting objects of different sizes.
+func TestEncryptionStream(t *testing.T) {
+	objectKey := make([]byte, encObjectKeySize)
+	if _, err := rand.Read(objectKey); err != nil {
+		t.Fatal(err)
+	}
+	sizes := []int{0, 1, encPayloadSize - 1, encPayloadSize, encPayloadSize + 1, 3*encPayloadSize + 17}
+	for i, size := range sizes {
+		data := make([]byte, size)
+		if _, err := rand.Read(data); err != nil {
+			t.Fatal(err)
+		}
+		encData := encryptTestData(t, objectKey, data)
+		if int64(len(encData)) != encryptedSize(int64(size)) {
+			t.Fatalf("Test %d: Expected encrypted size %d, got %d", i+1, encryptedSize(int64(size)), len(encData))
+		}
+		if decSize, err := decryptedSize(int64(len(encData))); err != nil || decSize != int64(size) {
+			t.Fatalf("Test %d: Expected decrypted size %d, got %d (%v)", i+1, size, decSize, err)
+		}
+
+		var buffer bytes.Buffer
+		decWriter, err := newDecryptWriter(&buffer, objectKey, 0, []int64{int64(len(encData))}, 0, int64(size))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = decWriter.Write(encData); err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if err = decWriter.Close(); err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if !bytes.Equal(buffer.Bytes(), data) {
+			t.Fatalf("Test %d: Decrypted data does not match", i+1)
+		}
+		if size == 0 {
+			continue
+		}
+
+		// Modified data must fail authentication.
+		encData[len(encData)/2] ^= 0x01
+		decWriter, _ = newDecryptWriter(ioutil.Discard, objectKey, 0, []int64{int64(len(encData))}, 0, int64(size))
+		if _, err = decWriter.Write(encData); err != errObjectTampered {
+			t.Fatalf("Test %d: Expected %v, got %v", i+1, errObjectTampered, err)
+		}
+	}
+}
+
+// Tests decrypting ranges of an object encrypted in parts.
+func TestDecryptRangeWriter(t *testing.T) {
+	objectKey := make([]byte, encObjectKeySize)
+	if _, err := rand.Read(objectKey); err != nil {
+		t.Fatal(err)
+	}
+	var data, encData []byte
+	var parts []objectPartInfo
+	for i, size := range []int{2*encPayloadSize + 5, encPayloadSize, 3} {
+		partData := make([]byte, size)
+		if _, err := rand.Read(partData); err != nil {
+			t.Fatal(err)
+		}
+		encPartData := encryptTestData(t, objectKey, partData)
+		data = append(data, partData...)
+		encData = append(encData, encPartData...)
+		parts = append(parts, objectPartInfo{Number: i + 1, Size: int64(len(encPartData))})
+	}
+	objInfo := ObjectInfo{Size: int64(len(data)), Parts: parts}
+
+	testCases := []struct {
+		offset, length int64
+	}{
+		// Whole object.
+		{0, int64(len(data))},
+		// Within the first package.
+		{10, 100},
+		// Across packages of the first part.
+		{encPayloadSize - 10, 20},
+		// Across parts.
+		{2*encPayloadSize + 1, encPayloadSize + 5},
+		// Last byte.
+		{int64(len(data)) - 1, 1},
+		// Empty range.
+		{0, 0},
+	}
+	for i, testCase := range testCases {
+		var buffer bytes.Buffer
+		encOffset, encLength, decWriter, err := newDecryptRangeWriter(&buffer, objectKey, objInfo, testCase.offset, testCase.length)
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if _, err = decWriter.Write(encData[encOffset : encOffset+encLength]); err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if err = decWriter.Close(); err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if !bytes.Equal(buffer.Bytes(), data[testCase.offset:testCase.offset+testCase.length]) {
+			t.Fatalf("Test %d: Decrypted range does not match", i+1)
+		}
+	}
+}
+
+// Sets SSE-C headers with the key on the request.
+func setSSECustomerTestHeaders(req *http.Request, hdrs sseHeaders, key []byte) {
+	keyMD5 := md5.Sum(key)
+	req.Header.Set(hdrs.algorithm, sseAlgorithmAES256)
+	req.Header.Set(hdrs.key, base64.StdEncoding.EncodeToString(key))
+	req.Header.Set(hdrs.keyMD5, base64.StdEncoding.EncodeToString(keyMD5[:]))
+}
+
+// Tests object operations with customer provided keys.
+func TestSSECustomerHandlers(t *testing.T) {
+	for _, instanceType := range []string{"FS", "XL"} {
+		testSSECustomerHandlers(instanceType, t)
+	}
+}
+
+func testSSECustomerHandlers(instanceType string, t *testing.T) {
+	testServer := StartTestTLSServer(t, instanceType)
+	defer testServer.Stop()
+
+	key := bytes.Repeat([]byte("k"), 32)
+	otherKey := bytes.Repeat([]byte("o"), 32)
+	data := bytes.Repeat([]byte("minio"), 30000)
+
+	client := testServer.Server.Client()
+	doRequest := func(method, urlStr string, body []byte, hdrs *sseHeaders, key []byte, rangeHdr string) *http.Response {
+		req, err := newTestRequest(method, urlStr, int64(len(body)), bytes.NewReader(body), testServer.AccessKey, testServer.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdrs != nil {
+			setSSECustomerTestHeaders(req, *hdrs, key)
+		}
+		if rangeHdr != "" {
+			req.Header.Set("Range", rangeHdr)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	expectStatus := func(resp *http.Response, status int) []byte {
+		defer resp.Body.Close()
+		respBody, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != status {
+			t.Fatalf("%s: Expected status %d, got %d: %s", instanceType, status, resp.StatusCode, respBody)
+		}
+		return respBody
+	}
+
+	bucketURL := testServer.Server.URL + "/sse-bucket"
+	objectURL := bucketURL + "/object"
+	expectStatus(doRequest("PUT", bucketURL, nil, nil, nil, ""), http.StatusOK)
+	resp := doRequest("PUT", objectURL, data, &sseCustomerHeaders, key, "")
+	expectStatus(resp, http.StatusOK)
+	if resp.Header.Get(sseCustomerHeaders.algorithm) != sseAlgorithmAES256 {
+		t.Fatalf("%s: Expected SSE-C headers in response", instanceType)
+	}
+
+	// Data is encrypted at rest.
+	obj, err := newObjectLayer(testServer.Disks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	objInfo, err := obj.GetObjectInfo("sse-bucket", "object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objInfo.Size != encryptedSize(int64(len(data))) || !isEncrypted(objInfo.UserDefined) {
+		t.Fatalf("%s: Object is not encrypted", instanceType)
+	}
+
+	// Requests without the right key are rejected.
+	expectStatus(doRequest("GET", objectURL, nil, nil, nil, ""), http.StatusBadRequest)
+	expectStatus(doRequest("GET", objectURL, nil, &sseCustomerHeaders, otherKey, ""), http.StatusForbidden)
+	expectStatus(doRequest("HEAD", objectURL, nil, nil, nil, ""), http.StatusBadRequest)
+
+	if respData := expectStatus(doRequest("GET", objectURL, nil, &sseCustomerHeaders, key, ""), http.StatusOK); !bytes.Equal(respData, data) {
+		t.Fatalf("%s: Decrypted object does not match", instanceType)
+	}
+	resp = doRequest("HEAD", objectURL, nil, &sseCustomerHeaders, key, "")
+	expectStatus(resp, http.StatusOK)
+	if resp.ContentLength != int64(len(data)) {
+		t.Fatalf("%s: Expected size %d, got %d", instanceType, len(data), resp.ContentLength)
+	}
+	respData := expectStatus(doRequest("GET", objectURL, nil, &sseCustomerHeaders, key, "bytes=70000-70009"), http.StatusPartialContent)
+	if !bytes.Equal(respData, data[70000:70010]) {
+		t.Fatalf("%s: Decrypted range does not match", instanceType)
+	}
+
+	// Copy re-encrypts the object with a new key.
+	req, err := newTestRequest("PUT", bucketURL+"/copy", 0, nil, testServer.AccessKey, testServer.SecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Amz-Copy-Source", "/sse-bucket/object")
+	setSSECustomerTestHeaders(req, sseCopyCustomerHeaders, key)
+	setSSECustomerTestHeaders(req, sseCustomerHeaders, otherKey)
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(resp, http.StatusOK)
+	if respData = expectStatus(doRequest("GET", bucketURL+"/copy", nil, &sseCustomerHeaders, otherKey, ""), http.StatusOK); !bytes.Equal(respData, data) {
+		t.Fatalf("%s: Decrypted copy does not match", instanceType)
+	}
+
+	// Copy with the same key only re-seals the object key.
+	req, err = newTestRequest("PUT", bucketURL+"/same-key-copy", 0, nil, testServer.AccessKey, testServer.SecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Amz-Copy-Source", "/sse-bucket/copy")
+	setSSECustomerTestHeaders(req, sseCopyCustomerHeaders, otherKey)
+	setSSECustomerTestHeaders(req, sseCustomerHeaders, otherKey)
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(resp, http.StatusOK)
+	if respData = expectStatus(doRequest("GET", bucketURL+"/same-key-copy", nil, &sseCustomerHeaders, otherKey, ""), http.StatusOK); !bytes.Equal(respData, data) {
+		t.Fatalf("%s: Decrypted copy does not match", instanceType)
+	}
+	expectStatus(doRequest("GET", bucketURL+"/same-key-copy", nil, &sseCustomerHeaders, key, ""), http.StatusForbidden)
+
+	// Object copied onto itself is re-encrypted with the new key.
+	req, err = newTestRequest("PUT", bucketURL+"/same-key-copy", 0, nil, testServer.AccessKey, testServer.SecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Amz-Copy-Source", "/sse-bucket/same-key-copy")
+	req.Header.Set("X-Amz-Metadata-Directive", "REPLACE")
+	setSSECustomerTestHeaders(req, sseCopyCustomerHeaders, otherKey)
+	setSSECustomerTestHeaders(req, sseCustomerHeaders, key)
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(resp, http.StatusOK)
+	if respData = expectStatus(doRequest("GET", bucketURL+"/same-key-copy", nil, &sseCustomerHeaders, key, ""), http.StatusOK); !bytes.Equal(respData, data) {
+		t.Fatalf("%s: Decrypted copy does not match", instanceType)
+	}
+	expectStatus(doRequest("GET", bucketURL+"/same-key-copy", nil, &sseCustomerHeaders, otherKey, ""), http.StatusForbidden)
+
+	// Parts are encrypted with the key the upload was initiated with.
+	respData = expectStatus(doRequest("POST", bucketURL+"/multipart?uploads", nil, &sseCustomerHeaders, key, ""), http.StatusOK)
+	var initResponse InitiateMultipartUploadResponse
+	if err = xml.Unmarshal(respData, &initResponse); err != nil {
+		t.Fatal(err)
+	}
+	partURL := bucketURL + "/multipart?uploadId=" + initResponse.UploadID + "&partNumber=1"
+	expectStatus(doRequest("PUT", partURL, data, nil, nil, ""), http.StatusBadRequest)
+	resp = doRequest("PUT", partURL, data, &sseCustomerHeaders, key, "")
+	expectStatus(resp, http.StatusOK)
+	completeBytes, err := xml.Marshal(completeMultipartUpload{
+		Parts: []completePart{{PartNumber: 1, ETag: resp.Header.Get("ETag")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(doRequest("POST", bucketURL+"/multipart?uploadId="+initResponse.UploadID, completeBytes, nil, nil, ""), http.StatusOK)
+	respData = expectStatus(doRequest("GET", bucketURL+"/multipart", nil, &sseCustomerHeaders, key, "bytes=65530-65545"), http.StatusPartialContent)
+	if !bytes.Equal(respData, data[65530:65546]) {
+		t.Fatalf("%s: Decrypted multipart range does not match", instanceType)
+	}
+
+	// SSE-C requests over plain HTTP are rejected.
+	for _, hdrs := range []sseHeaders{sseCustomerHeaders, sseCopyCustomerHeaders} {
+		req, err = newTestRequest("GET", objectURL, 0, nil, testServer.AccessKey, testServer.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		setSSECustomerTestHeaders(req, hdrs, key)
+		rec := httptest.NewRecorder()
+		testServer.Server.Config.Handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: Expected status %d over plain HTTP, got %d", instanceType, http.StatusBadRequest, rec.Code)
+		}
+	}
+}
@@ -119,6 +119,24 @@ func (e ObjectNotFound) Error() string {
 	return "Object not found: " + e.Bucket + "#" + e.Object
 }
 
+// VersionNotFound object version does not exist.
+type VersionNotFound struct {
+	Bucket    string
+	Object    string
+	VersionID string
+}
+
+func (e VersionNotFound) Error() string {
+	return "Object version not found: " + e.Bucket + "#" + e.Object + "#" + e.VersionID
+}
+
+// PreconditionFailed object does not satisfy the write condition.
+type PreconditionFailed GenericError
+
+func (e PreconditionFailed) Error() string {
+	return "Precondition failed on object: " + e.Bucket + "#" + e.Object
+}
+
 // ObjectExistsAsDirectory object already exists as a directory.
 type ObjectExistsAsDirectory GenericError
 
@@ -177,6 +195,48 @@ func (e BucketPolicyNotFound) Error() string {
 	return "No bucket policy found for bucket: " + e.Bucket
 }
 
+// BucketLifecycleNotFound - no bucket lifecycle configuration found.
+type BucketLifecycleNotFound GenericError
+
+func (e BucketLifecycleNotFound) Error() string {
+	return "No bucket lifecycle configuration found for bucket: " + e.Bucket
+}
+
+// BucketTaggingNotFound - no bucket tags found.
+type BucketTaggingNotFound GenericError
+
+func (e BucketTaggingNotFound) Error() string {
+	return "No bucket tags found for bucket: " + e.Bucket
+}
+
+// BucketCorsNotFound - no bucket CORS configuration found.
+type BucketCorsNotFound GenericError
+
+func (e BucketCorsNotFound) Error() string {
+	return "No bucket CORS configuration found for bucket: " + e.Bucket
+}
+
+// BucketWebsiteNotFound - no bucket website configuration found.
+type BucketWebsiteNotFound GenericError
+
+func (e BucketWebsiteNotFound) Error() string {
+	return "No bucket website configuration found for bucket: " + e.Bucket
+}
+
+// BucketEncryptionNotFound - no bucket encryption configuration found.
+type BucketEncryptionNotFound GenericError
+
+func (e BucketEncryptionNotFound) Error() string {
+	return "No bucket encryption configuration found for bucket: " + e.Bucket
+}
+
+// BucketNotificationNotFound - no bucket notification configuration found.
+type BucketNotificationNotFound GenericError
+
+func (e BucketNotificationNotFound) Error() string {
+	return "No bucket notification configuration found for bucket: " + e.Bucket
+}
+
 /// Bucket related errors.
 
 // BucketNameInvalid - bucketname provided is invalid.
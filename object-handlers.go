@@ -17,13 +17,16 @@
 package main
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/xml"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
 	"net/url"
 	"sort"
 	"strconv"
@@ -85,18 +88,25 @@ func (api objectAPIHandlers) GetObjectHandler(w http.ResponseWriter, r *http.Req
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
-		if s3Error := enforceBucketPolicy("s3:GetObject", bucket, r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicyTags("s3:GetObject", bucket, getPathStyleURL(r), api.existingObjectTagConditions(bucket, object)); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
-	case authTypePresigned, authTypeSigned:
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
 		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
 	}
-	// Fetch object stat info.
-	objInfo, err := api.ObjectAPI.GetObjectInfo(bucket, object)
+	// Fetch object stat info, of the requested version if any.
+	versionID := r.URL.Query().Get("versionId")
+	var objInfo ObjectInfo
+	var err error
+	if versionID != "" {
+		objInfo, err = api.ObjectAPI.GetObjectVersionInfo(bucket, object, versionID)
+	} else {
+		objInfo, err = api.ObjectAPI.GetObjectInfo(bucket, object)
+	}
 	if err != nil {
 		errorIf(err, "Unable to fetch object info.")
 		apiErr := toAPIErrorCode(err)
@@ -107,12 +117,30 @@ func (api objectAPIHandlers) GetObjectHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	var hrange *httpRange
-	hrange, err = getRequestedRange(r.Header.Get("Range"), objInfo.Size)
+	// Delete markers have no data.
+	if objInfo.DeleteMarker {
+		setObjectVersionHeaders(w, objInfo)
+		writeErrorResponse(w, r, ErrMethodNotAllowed, r.URL.Path)
+		return
+	}
+
+	// Encrypted objects require the customer provided key.
+	objectKey, s3Error := decryptObjectInfo(r.Header, sseCustomerHeaders, &objInfo)
+	if s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
+	ranges, err := getRequestedRanges(r.Header.Get("Range"), objInfo.Size)
 	if err != nil {
 		writeErrorResponse(w, r, ErrInvalidRange, r.URL.Path)
 		return
 	}
+	hrange := ranges[0]
+	if len(ranges) > 1 {
+		// Multiple ranges are sent as parts of the response body.
+		hrange = nil
+	}
 
 	// Set standard object headers.
 	setObjectHeaders(w, objInfo, hrange)
@@ -130,19 +158,98 @@ func (api objectAPIHandlers) GetObjectHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Get the requested ranges as a multipart/byteranges body.
+	if len(ranges) > 1 {
+		if err = api.getObjectRanges(w, bucket, object, versionID, objInfo, objectKey, ranges); err != nil {
+			errorIf(err, "Writing to client failed.")
+		}
+		return
+	}
+
 	// Get the object.
 	startOffset := hrange.start
 	length := hrange.length
 	if length == 0 {
 		length = objInfo.Size - startOffset
 	}
-	if err := api.ObjectAPI.GetObject(bucket, object, startOffset, length, w); err != nil {
+	if err = api.getObjectRange(w, bucket, object, versionID, objInfo, objectKey, startOffset, length); err != nil {
 		errorIf(err, "Writing to client failed.")
 		// Do not send error response here, client would have already died.
 		return
 	}
 }
 
+// getObjectRange - writes length bytes of the object starting at
+// startOffset, of the requested version if any. Encrypted objects are
+// decrypted with objectKey.
+func (api objectAPIHandlers) getObjectRange(w io.Writer, bucket, object, versionID string, objInfo ObjectInfo, objectKey []byte, startOffset, length int64) (err error) {
+	var writer = w
+	var decWriter io.WriteCloser
+	if objectKey != nil {
+		// Read the encrypted packages holding the requested range.
+		startOffset, length, decWriter, err = newDecryptRangeWriter(w, objectKey, objInfo, startOffset, length)
+		if err != nil {
+			return err
+		}
+		writer = decWriter
+	}
+	if versionID != "" {
+		err = api.ObjectAPI.GetObjectVersion(bucket, object, versionID, startOffset, length, writer)
+	} else {
+		err = api.ObjectAPI.GetObject(bucket, object, startOffset, length, writer)
+	}
+	if err == nil && decWriter != nil {
+		err = decWriter.Close()
+	}
+	return err
+}
+
+// getObjectRanges - writes the ranges of the object as a
+// multipart/byteranges body as per RFC 7233, each part carries the
+// object content type and its range.
+func (api objectAPIHandlers) getObjectRanges(w http.ResponseWriter, bucket, object, versionID string, objInfo ObjectInfo, objectKey []byte, ranges []*httpRange) error {
+	contentType := w.Header().Get("Content-Type")
+	partHeader := func(hrange *httpRange) textproto.MIMEHeader {
+		header := make(textproto.MIMEHeader)
+		if contentType != "" {
+			header.Set("Content-Type", contentType)
+		}
+		header.Set("Content-Range", hrange.String())
+		return header
+	}
+
+	// Content length is the length of the multipart body without
+	// the ranges, along with the length of all the ranges.
+	var envelope bytes.Buffer
+	envelopeWriter := multipart.NewWriter(&envelope)
+	var contentLength int64
+	for _, hrange := range ranges {
+		envelopeWriter.CreatePart(partHeader(hrange))
+		contentLength += hrange.length
+	}
+	envelopeWriter.Close()
+	contentLength += int64(envelope.Len())
+
+	w.Header().Set("Content-Type", "multipart/byteranges; boundary="+envelopeWriter.Boundary())
+	w.Header().Set("Content-Length", strconv.FormatInt(contentLength, 10))
+	w.WriteHeader(http.StatusPartialContent)
+
+	partsWriter := multipart.NewWriter(w)
+	if err := partsWriter.SetBoundary(envelopeWriter.Boundary()); err != nil {
+		return err
+	}
+	for _, hrange := range ranges {
+		part, err := partsWriter.CreatePart(partHeader(hrange))
+		if err != nil {
+			return err
+		}
+		if err = api.getObjectRange(part, bucket, object, versionID, objInfo, objectKey, hrange.start, hrange.length); err != nil {
+			return err
+		}
+	}
+	return partsWriter.Close()
+}
+
 var unixEpochTime = time.Unix(0, 0)
 
 // checkLastModified implements If-Modified-Since and
@@ -249,6 +356,17 @@ func checkETag(w http.ResponseWriter, r *http.Request) bool {
 	return false
 }
 
+// getWriteCondition - returns the write condition set with If-Match
+// and If-None-Match on PUT, DELETE and complete multipart requests,
+// unlike checkETag it is verified by the object layer under the
+// object write lock.
+func getWriteCondition(r *http.Request) writeCondition {
+	return writeCondition{
+		IfMatch:     r.Header.Get("If-Match"),
+		IfNoneMatch: r.Header.Get("If-None-Match"),
+	}
+}
+
 // HeadObjectHandler - HEAD Object
 // -----------
 // The HEAD operation retrieves metadata from an object without returning the object itself.
@@ -265,18 +383,26 @@ func (api objectAPIHandlers) HeadObjectHandler(w http.ResponseWriter, r *http.Re
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
-		if s3Error := enforceBucketPolicy("s3:GetObject", bucket, r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicyTags("s3:GetObject", bucket, getPathStyleURL(r), api.existingObjectTagConditions(bucket, object)); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
-	case authTypePresigned, authTypeSigned:
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
 		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
 	}
 
-	objInfo, err := api.ObjectAPI.GetObjectInfo(bucket, object)
+	// Fetch object stat info, of the requested version if any.
+	versionID := r.URL.Query().Get("versionId")
+	var objInfo ObjectInfo
+	var err error
+	if versionID != "" {
+		objInfo, err = api.ObjectAPI.GetObjectVersionInfo(bucket, object, versionID)
+	} else {
+		objInfo, err = api.ObjectAPI.GetObjectInfo(bucket, object)
+	}
 	if err != nil {
 		errorIf(err, "Unable to fetch object info.")
 		apiErr := toAPIErrorCode(err)
@@ -287,6 +413,19 @@ func (api objectAPIHandlers) HeadObjectHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// Delete markers have no data.
+	if objInfo.DeleteMarker {
+		setObjectVersionHeaders(w, objInfo)
+		writeErrorResponse(w, r, ErrMethodNotAllowed, r.URL.Path)
+		return
+	}
+
+	// Encrypted objects require the customer provided key.
+	if _, s3Error := decryptObjectInfo(r.Header, sseCustomerHeaders, &objInfo); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+
 	// Set standard object headers.
 	setObjectHeaders(w, objInfo, nil)
 
@@ -321,11 +460,11 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
-		if s3Error := enforceBucketPolicy("s3:PutObject", bucket, r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy("s3:PutObject", bucket, getPathStyleURL(r)); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
-	case authTypePresigned, authTypeSigned:
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
 		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
@@ -337,27 +476,33 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 
 	// objectSource
 	objectSource := r.Header.Get("X-Amz-Copy-Source")
-
-	// Skip the first element if it is '/', split the rest.
-	if strings.HasPrefix(objectSource, "/") {
-		objectSource = objectSource[1:]
-	}
-	splits := strings.SplitN(objectSource, "/", 2)
-
-	// Save sourceBucket and sourceObject extracted from url Path.
-	var sourceBucket, sourceObject string
-	if len(splits) == 2 {
-		sourceBucket = splits[0]
-		sourceObject = splits[1]
-	}
+	sourceBucket, sourceObject := parseCopySource(objectSource)
 	// If source object is empty, reply back error.
 	if sourceObject == "" {
 		writeErrorResponse(w, r, ErrInvalidCopySource, r.URL.Path)
 		return
 	}
+	if s3Error := api.enforceCopySourcePolicy(r, sourceBucket, sourceObject); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, objectSource)
+		return
+	}
 
-	// Source and destination objects cannot be same, reply back error.
-	if sourceObject == object && sourceBucket == bucket {
+	// Verify x-amz-metadata-directive.
+	if !isValidMetadataDirective(r.Header) {
+		writeErrorResponse(w, r, ErrInvalidMetadataDirective, r.URL.Path)
+		return
+	}
+
+	// Verify x-amz-tagging-directive.
+	if !isValidTaggingDirective(r.Header) {
+		writeErrorResponse(w, r, ErrInvalidTaggingDirective, r.URL.Path)
+		return
+	}
+
+	// Source and destination objects cannot be same, unless the
+	// metadata is replaced, reply back error.
+	isSameObject := sourceObject == object && sourceBucket == bucket
+	if isSameObject && !isMetadataReplace(r.Header) {
 		writeErrorResponse(w, r, ErrInvalidCopyDest, r.URL.Path)
 		return
 	}
@@ -368,6 +513,14 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 		writeErrorResponse(w, r, toAPIErrorCode(err), objectSource)
 		return
 	}
+
+	// Encrypted source objects are decrypted, with the customer provided
+	// key if encrypted with one.
+	sourceKey, s3Error := decryptObjectInfo(r.Header, sseCopyCustomerHeaders, &objInfo)
+	if s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, objectSource)
+		return
+	}
 	// Verify before writing.
 
 	// Verify x-amz-copy-source-if-modified-since and
@@ -389,53 +542,157 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	pipeReader, pipeWriter := io.Pipe()
-	go func() {
-		startOffset := int64(0) // Read the whole file.
-		// Get the object.
-		gErr := api.ObjectAPI.GetObject(sourceBucket, sourceObject, startOffset, objInfo.Size, pipeWriter)
-		if gErr != nil {
-			errorIf(gErr, "Unable to read an object.")
-			pipeWriter.CloseWithError(gErr)
+	// Metadata of the new object is copied from the source, unless
+	// replaced with the one sent in the request. Do not set `md5sum`
+	// as CopyObject will not keep the same md5sum as the source.
+	var metadata map[string]string
+	if isMetadataReplace(r.Header) {
+		metadata = extractMetadataFromHeader(r.Header)
+	} else {
+		metadata = getCopyObjectMetadata(objInfo)
+	}
+
+	// Tags are copied from the source the same way.
+	tags := getObjectTags(objInfo.UserDefined)
+	if isTaggingReplace(r.Header) {
+		if tags, err = getRequestTags(r.Header); err != nil {
+			writeErrorResponse(w, r, ErrInvalidTag, r.URL.Path)
 			return
 		}
-		pipeWriter.Close() // Close.
-	}()
-
-	// Size of object.
-	size := objInfo.Size
+	}
+	setObjectTags(metadata, tags)
 
-	// Save metadata.
-	metadata := make(map[string]string)
-	// Save other metadata if available.
-	metadata["content-type"] = objInfo.ContentType
-	metadata["content-encoding"] = objInfo.ContentEncoding
-	// Do not set `md5sum` as CopyObject will not keep the
-	// same md5sum as the source.
-
-	// Create the object.
-	md5Sum, err := api.ObjectAPI.PutObject(bucket, object, size, pipeReader, metadata)
-	if err != nil {
-		errorIf(err, "Unable to create an object.")
-		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+	// Storage class is not copied from the source.
+	if !isValidStorageClass(r.Header) {
+		writeErrorResponse(w, r, ErrInvalidStorageClass, r.URL.Path)
 		return
 	}
+	setObjectStorageClass(metadata, r.Header)
 
-	objInfo, err = api.ObjectAPI.GetObjectInfo(bucket, object)
-	if err != nil {
-		errorIf(err, "Unable to fetch object info.")
-		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+	// Data is copied as is when the destination is encrypted the same
+	// way as the source, otherwise it is re-encrypted. Objects copied
+	// onto themselves are re-encrypted the same way.
+	isRawCopy, s3Error := sealCopyObjectKey(r.Header, sourceKey, objInfo.UserDefined, bucket, object, metadata)
+	if s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
 		return
 	}
 
-	response := generateCopyObjectResponse(md5Sum, objInfo.ModTime)
+	if isRawCopy {
+		// Copy the object within the object layer.
+		objInfo, err = api.ObjectAPI.CopyObject(sourceBucket, sourceObject, bucket, object, metadata)
+		if err != nil {
+			errorIf(err, "Unable to copy an object.")
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+			return
+		}
+	} else {
+		// Size of object.
+		size := objInfo.Size
+
+		// Encrypt the object if requested or by bucket default.
+		objectKey, s3Error := newRequestObjectKey(r.Header, bucket, object, metadata)
+		if s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+		if objectKey != nil {
+			size = encryptedSize(size)
+		}
+
+		// Read the whole source object.
+		pipeReader := api.newCopySourceReader(sourceBucket, sourceObject, objInfo, sourceKey, 0, objInfo.Size)
+
+		// Create the object.
+		objInfo, err = api.ObjectAPI.PutObjectIf(bucket, object, size, encryptRequestReader(pipeReader, objectKey, nil), metadata, writeCondition{})
+		// Explicitly close the reader, to avoid fd leaks.
+		pipeReader.Close()
+		if err != nil {
+			errorIf(err, "Unable to create an object.")
+			writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+			return
+		}
+	}
+
+	response := generateCopyObjectResponse(objInfo.MD5Sum, objInfo.ModTime)
 	encodedSuccessResponse := encodeResponse(response)
 	// write headers
 	setCommonHeaders(w)
+	setObjectVersionHeaders(w, objInfo)
+	setObjectEncryptionHeaders(w, objInfo.UserDefined)
 	// write success response.
 	writeSuccessResponse(w, encodedSuccessResponse)
-	// Explicitly close the reader, to avoid fd leaks.
-	pipeReader.Close()
+
+	// Notify object created event.
+	eventNotify(eventData{
+		Type:    ObjectCreatedCopy,
+		Bucket:  bucket,
+		ObjInfo: objInfo,
+		ReqParams: map[string]string{
+			"sourceIPAddress": r.RemoteAddr,
+		},
+	})
+}
+
+// parseCopySource - returns the bucket and object of the URL encoded
+// x-amz-copy-source header, of form [/]bucket/object.
+func parseCopySource(objectSource string) (bucket, object string) {
+	objectSource, err := url.QueryUnescape(objectSource)
+	if err != nil {
+		return "", ""
+	}
+	// Skip the first element if it is '/', split the rest.
+	if strings.HasPrefix(objectSource, "/") {
+		objectSource = objectSource[1:]
+	}
+	splits := strings.SplitN(objectSource, "/", 2)
+	if len(splits) == 2 {
+		return splits[0], splits[1]
+	}
+	return "", ""
+}
+
+// enforceCopySourcePolicy - anonymous copy requests need s3:GetObject
+// on the copy source besides s3:PutObject on the target.
+func (api objectAPIHandlers) enforceCopySourcePolicy(r *http.Request, sourceBucket, sourceObject string) APIErrorCode {
+	if getRequestAuthType(r) != authTypeAnonymous {
+		return ErrNone
+	}
+	sourceURL := &url.URL{Path: "/" + sourceBucket + "/" + sourceObject}
+	return enforceBucketPolicyTags("s3:GetObject", sourceBucket, sourceURL, api.existingObjectTagConditions(sourceBucket, sourceObject))
+}
+
+// newCopySourceReader - returns a reader streaming length bytes at
+// startOffset of the source object, decrypted with sourceKey if the
+// source is encrypted.
+func (api objectAPIHandlers) newCopySourceReader(bucket, object string, objInfo ObjectInfo, sourceKey []byte, startOffset, length int64) *io.PipeReader {
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		var writer io.Writer = pipeWriter
+		var decWriter io.WriteCloser
+		if sourceKey != nil {
+			var dErr error
+			startOffset, length, decWriter, dErr = newDecryptRangeWriter(pipeWriter, sourceKey, objInfo, startOffset, length)
+			if dErr != nil {
+				errorIf(dErr, "Unable to decrypt an object.")
+				pipeWriter.CloseWithError(dErr)
+				return
+			}
+			writer = decWriter
+		}
+		// Get the object.
+		gErr := api.ObjectAPI.GetObject(bucket, object, startOffset, length, writer)
+		if gErr == nil && decWriter != nil {
+			gErr = decWriter.Close()
+		}
+		if gErr != nil {
+			errorIf(gErr, "Unable to read an object.")
+			pipeWriter.CloseWithError(gErr)
+			return
+		}
+		pipeWriter.Close() // Close.
+	}()
+	return pipeReader
 }
 
 // checkCopySource implements x-amz-copy-source-if-modified-since and
@@ -553,6 +810,15 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 	}
 	/// if Content-Length is unknown/missing, deny the request
 	size := r.ContentLength
+	rAuthType := getRequestAuthType(r)
+	if rAuthType == authTypeStreamingSigned {
+		// Size of the object is the decoded length of the chunked payload.
+		size = getDecodedContentLength(r)
+		if size == -1 {
+			writeErrorResponse(w, r, ErrMissingContentLength, r.URL.Path)
+			return
+		}
+	}
 	if size == -1 && !contains(r.TransferEncoding, "chunked") {
 		writeErrorResponse(w, r, ErrMissingContentLength, r.URL.Path)
 		return
@@ -564,36 +830,77 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	// Save metadata.
-	metadata := make(map[string]string)
+	metadata := extractMetadataFromHeader(r.Header)
 	// Make sure we hex encode md5sum here.
 	metadata["md5Sum"] = hex.EncodeToString(md5Bytes)
-	// Save other metadata if available.
-	metadata["content-type"] = r.Header.Get("Content-Type")
-	metadata["content-encoding"] = r.Header.Get("Content-Encoding")
-	for key := range r.Header {
-		cKey := http.CanonicalHeaderKey(key)
-		if strings.HasPrefix(cKey, "x-amz-meta-") {
-			metadata[cKey] = r.Header.Get(cKey)
-		} else if strings.HasPrefix(key, "x-minio-meta-") {
-			metadata[cKey] = r.Header.Get(cKey)
-		}
+
+	// Save tags sent with x-amz-tagging.
+	tags, err := getRequestTags(r.Header)
+	if err != nil {
+		writeErrorResponse(w, r, ErrInvalidTag, r.URL.Path)
+		return
 	}
+	setObjectTags(metadata, tags)
 
-	var md5Sum string
-	switch getRequestAuthType(r) {
+	// Save storage class sent with x-amz-storage-class.
+	if !isValidStorageClass(r.Header) {
+		writeErrorResponse(w, r, ErrInvalidStorageClass, r.URL.Path)
+		return
+	}
+	setObjectStorageClass(metadata, r.Header)
+
+	// Encrypt the object if requested or by bucket default.
+	objectKey, s3Error := newRequestObjectKey(r.Header, bucket, object, metadata)
+	if s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
+	}
+	objectSize := size
+	if objectKey != nil {
+		// Object layer only sees the encrypted data, md5sum sent by
+		// the client is verified while encrypting.
+		delete(metadata, "md5Sum")
+		objectSize = encryptedSize(size)
+	}
+
+	// Object is replaced only if it meets 'If-Match' and 'If-None-Match'.
+	cond := getWriteCondition(r)
+
+	var objInfo ObjectInfo
+	switch rAuthType {
 	default:
 		// For all unknown auth types return error.
 		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
-		if s3Error := enforceBucketPolicy("s3:PutObject", bucket, r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicyTags("s3:PutObject", bucket, getPathStyleURL(r), getTagConditions(requestObjectTagConditionPrefix, tags)); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
 		// Create anonymous object.
-		md5Sum, err = api.ObjectAPI.PutObject(bucket, object, size, r.Body, metadata)
-	case authTypePresigned, authTypeSigned:
+		objInfo, err = api.ObjectAPI.PutObjectIf(bucket, object, objectSize, encryptRequestReader(r.Body, objectKey, md5Bytes), metadata, cond)
+	case authTypeStreamingSigned:
+		// Initialize stream signature verifier, every chunk is
+		// verified as the object is written.
+		reader, s3Error := newSignV4ChunkedReader(r)
+		if s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+		objInfo, err = api.ObjectAPI.PutObjectIf(bucket, object, objectSize, encryptRequestReader(reader, objectKey, md5Bytes), metadata, cond)
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
+		if rAuthType == authTypeSigned && isRequestUnsignedPayload(r) {
+			// Only headers are signed, verify them upfront and
+			// stream the payload directly.
+			validateRegion := true // Validate region.
+			if s3Error := doesSignatureMatch(unsignedPayload, r, validateRegion); s3Error != ErrNone {
+				writeErrorResponse(w, r, s3Error, r.URL.Path)
+				return
+			}
+			objInfo, err = api.ObjectAPI.PutObjectIf(bucket, object, objectSize, encryptRequestReader(r.Body, objectKey, md5Bytes), metadata, cond)
+			break
+		}
 		// Initialize a pipe for data pipe line.
 		reader, writer := io.Pipe()
 		var wg = &sync.WaitGroup{}
@@ -619,6 +926,10 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 				s3Error = doesSignatureMatch(hex.EncodeToString(shaPayload), r, validateRegion)
 			} else if isRequestPresignedSignatureV4(r) {
 				s3Error = doesPresignedSignatureMatch(hex.EncodeToString(shaPayload), r, validateRegion)
+			} else if isRequestSignatureV2(r) {
+				s3Error = doesSignatureV2Match(r)
+			} else if isRequestPresignedSignatureV2(r) {
+				s3Error = doesPresignedSignatureV2Match(r)
 			}
 			var sErr error
 			if s3Error != ErrNone {
@@ -634,7 +945,7 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 		}()
 
 		// Create object.
-		md5Sum, err = api.ObjectAPI.PutObject(bucket, object, size, reader, metadata)
+		objInfo, err = api.ObjectAPI.PutObjectIf(bucket, object, objectSize, encryptRequestReader(reader, objectKey, md5Bytes), metadata, cond)
 		// Close the pipe.
 		reader.Close()
 		// Wait for all the routines to finish.
@@ -645,10 +956,22 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
 		return
 	}
-	if md5Sum != "" {
-		w.Header().Set("ETag", "\""+md5Sum+"\"")
+	if objInfo.MD5Sum != "" {
+		w.Header().Set("ETag", "\""+objInfo.MD5Sum+"\"")
 	}
+	setObjectEncryptionHeaders(w, metadata)
+	setObjectVersionHeaders(w, objInfo)
 	writeSuccessResponse(w, nil)
+
+	// Notify object created event.
+	eventNotify(eventData{
+		Type:    ObjectCreatedPut,
+		Bucket:  bucket,
+		ObjInfo: objInfo,
+		ReqParams: map[string]string{
+			"sourceIPAddress": r.RemoteAddr,
+		},
+	})
 }
 
 /// Multipart objectAPIHandlers
@@ -667,11 +990,12 @@ func (api objectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/mpuAndPermissions.html
-		if s3Error := enforceBucketPolicy("s3:PutObject", bucket, r.URL); s3Error != ErrNone {
+		tags, _ := getRequestTags(r.Header)
+		if s3Error := enforceBucketPolicyTags("s3:PutObject", bucket, getPathStyleURL(r), getTagConditions(requestObjectTagConditionPrefix, tags)); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
-	case authTypePresigned, authTypeSigned:
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
 		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
@@ -679,17 +1003,30 @@ func (api objectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 	}
 
 	// Save metadata.
-	metadata := make(map[string]string)
-	// Save other metadata if available.
-	metadata["content-type"] = r.Header.Get("Content-Type")
-	metadata["content-encoding"] = r.Header.Get("Content-Encoding")
-	for key := range r.Header {
-		cKey := http.CanonicalHeaderKey(key)
-		if strings.HasPrefix(cKey, "x-amz-meta-") {
-			metadata[cKey] = r.Header.Get(cKey)
-		} else if strings.HasPrefix(key, "x-minio-meta-") {
-			metadata[cKey] = r.Header.Get(cKey)
-		}
+	metadata := extractMetadataFromHeader(r.Header)
+
+	// Save tags sent with x-amz-tagging, they are applied to the
+	// object on completion.
+	tags, err := getRequestTags(r.Header)
+	if err != nil {
+		writeErrorResponse(w, r, ErrInvalidTag, r.URL.Path)
+		return
+	}
+	setObjectTags(metadata, tags)
+
+	// Save storage class sent with x-amz-storage-class, all parts are
+	// erasure coded as the storage class.
+	if !isValidStorageClass(r.Header) {
+		writeErrorResponse(w, r, ErrInvalidStorageClass, r.URL.Path)
+		return
+	}
+	setObjectStorageClass(metadata, r.Header)
+
+	// Generate the object key encrypting all parts if requested or by
+	// bucket default.
+	if _, s3Error := newRequestObjectKey(r.Header, bucket, object, metadata); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, r.URL.Path)
+		return
 	}
 
 	uploadID, err := api.ObjectAPI.NewMultipartUpload(bucket, object, metadata)
@@ -703,6 +1040,7 @@ func (api objectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 	encodedSuccessResponse := encodeResponse(response)
 	// write headers
 	setCommonHeaders(w)
+	setObjectEncryptionHeaders(w, metadata)
 	// write success response.
 	writeSuccessResponse(w, encodedSuccessResponse)
 }
@@ -722,6 +1060,11 @@ func (api objectAPIHandlers) PutObjectPartHandler(w http.ResponseWriter, r *http
 
 	/// if Content-Length is unknown/missing, throw away
 	size := r.ContentLength
+	rAuthType := getRequestAuthType(r)
+	if rAuthType == authTypeStreamingSigned {
+		// Size of the part is the decoded length of the chunked payload.
+		size = getDecodedContentLength(r)
+	}
 	if size == -1 {
 		writeErrorResponse(w, r, ErrMissingContentLength, r.URL.Path)
 		return
@@ -748,23 +1091,73 @@ func (api objectAPIHandlers) PutObjectPartHandler(w http.ResponseWriter, r *http
 		return
 	}
 
-	var partMD5 string
-	switch getRequestAuthType(r) {
+	// Authenticate the request before the upload is looked up, signed
+	// payloads are verified as the part is written.
+	var streamReader io.Reader
+	switch rAuthType {
 	default:
 		// For all unknown auth types return error.
 		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/mpuAndPermissions.html
-		if s3Error := enforceBucketPolicy("s3:PutObject", bucket, r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy("s3:PutObject", bucket, getPathStyleURL(r)); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
+	case authTypeStreamingSigned:
+		// Initialize stream signature verifier, every chunk is
+		// verified as the part is written.
+		var s3Error APIErrorCode
+		if streamReader, s3Error = newSignV4ChunkedReader(r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
+		if s3Error := isReqHeaderAuthenticated(r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	// Parts of encrypted uploads are encrypted with the object key of
+	// the upload.
+	uploadInfo, err := api.ObjectAPI.ListObjectParts(bucket, object, uploadID, 0, 0)
+	if err != nil {
+		errorIf(err, "Unable to fetch upload info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	var objectKey []byte
+	partSize := size
+	md5SumHex := hex.EncodeToString(md5Bytes)
+	if isEncrypted(uploadInfo.UserDefined) {
+		var s3Error APIErrorCode
+		objectKey, s3Error = getObjectKey(r.Header, sseCustomerHeaders, bucket, object, uploadInfo.UserDefined)
+		if s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+		partSize = encryptedSize(size)
+		// md5sum is verified while encrypting.
+		md5SumHex = ""
+	} else if hasSSECustomerHeader(r.Header, sseCustomerHeaders) {
+		writeErrorResponse(w, r, ErrInvalidEncryptionParameters, r.URL.Path)
+		return
+	}
+
+	var partMD5 string
+	switch {
+	case rAuthType == authTypeAnonymous:
 		// No need to verify signature, anonymous request access is
 		// already allowed.
-		hexMD5 := hex.EncodeToString(md5Bytes)
-		partMD5, err = api.ObjectAPI.PutObjectPart(bucket, object, uploadID, partID, size, r.Body, hexMD5)
-	case authTypePresigned, authTypeSigned:
+		partMD5, err = api.ObjectAPI.PutObjectPart(bucket, object, uploadID, partID, partSize, encryptRequestReader(r.Body, objectKey, md5Bytes), md5SumHex)
+	case rAuthType == authTypeStreamingSigned:
+		partMD5, err = api.ObjectAPI.PutObjectPart(bucket, object, uploadID, partID, partSize, encryptRequestReader(streamReader, objectKey, md5Bytes), md5SumHex)
+	case rAuthType == authTypeSigned && isRequestUnsignedPayload(r):
+		// Only headers are signed, the payload is streamed directly.
+		partMD5, err = api.ObjectAPI.PutObjectPart(bucket, object, uploadID, partID, partSize, encryptRequestReader(r.Body, objectKey, md5Bytes), md5SumHex)
+	default:
 		// Initialize a pipe for data pipe line.
 		reader, writer := io.Pipe()
 		var wg = &sync.WaitGroup{}
@@ -803,8 +1196,7 @@ func (api objectAPIHandlers) PutObjectPartHandler(w http.ResponseWriter, r *http
 			// Close the writer.
 			writer.Close()
 		}()
-		md5SumHex := hex.EncodeToString(md5Bytes)
-		partMD5, err = api.ObjectAPI.PutObjectPart(bucket, object, uploadID, partID, size, reader, md5SumHex)
+		partMD5, err = api.ObjectAPI.PutObjectPart(bucket, object, uploadID, partID, partSize, encryptRequestReader(reader, objectKey, md5Bytes), md5SumHex)
 		// Close the pipe.
 		reader.Close()
 		// Wait for all the routines to finish.
@@ -819,9 +1211,148 @@ func (api objectAPIHandlers) PutObjectPartHandler(w http.ResponseWriter, r *http
 	if partMD5 != "" {
 		w.Header().Set("ETag", "\""+partMD5+"\"")
 	}
+	setObjectEncryptionHeaders(w, uploadInfo.UserDefined)
 	writeSuccessResponse(w, nil)
 }
 
+// CopyObjectPartHandler - Upload part copy
+// ----------
+// This implementation of the PUT operation uploads a part by copying
+// data, optionally a range of it, from an existing object.
+func (api objectAPIHandlers) CopyObjectPartHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	switch getRequestAuthType(r) {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypeAnonymous:
+		// http://docs.aws.amazon.com/AmazonS3/latest/dev/mpuAndPermissions.html
+		if s3Error := enforceBucketPolicy("s3:PutObject", bucket, getPathStyleURL(r)); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
+		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	uploadID := r.URL.Query().Get("uploadId")
+	partIDString := r.URL.Query().Get("partNumber")
+
+	partID, err := strconv.Atoi(partIDString)
+	if err != nil {
+		writeErrorResponse(w, r, ErrInvalidPart, r.URL.Path)
+		return
+	}
+
+	// check partID with maximum part ID for multipart objects
+	if isMaxPartID(partID) {
+		writeErrorResponse(w, r, ErrInvalidMaxParts, r.URL.Path)
+		return
+	}
+
+	// objectSource
+	objectSource := r.Header.Get("X-Amz-Copy-Source")
+	sourceBucket, sourceObject := parseCopySource(objectSource)
+	// If source object is empty, reply back error.
+	if sourceObject == "" {
+		writeErrorResponse(w, r, ErrInvalidCopySource, r.URL.Path)
+		return
+	}
+	if s3Error := api.enforceCopySourcePolicy(r, sourceBucket, sourceObject); s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, objectSource)
+		return
+	}
+
+	objInfo, err := api.ObjectAPI.GetObjectInfo(sourceBucket, sourceObject)
+	if err != nil {
+		errorIf(err, "Unable to fetch object info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), objectSource)
+		return
+	}
+
+	// Encrypted source objects are decrypted, with the customer provided
+	// key if encrypted with one.
+	sourceKey, s3Error := decryptObjectInfo(r.Header, sseCopyCustomerHeaders, &objInfo)
+	if s3Error != ErrNone {
+		writeErrorResponse(w, r, s3Error, objectSource)
+		return
+	}
+
+	// Verify x-amz-copy-source-if-modified-since and
+	// x-amz-copy-source-if-unmodified-since.
+	if checkCopySourceLastModified(w, r, objInfo.ModTime) {
+		return
+	}
+
+	// Verify x-amz-copy-source-if-match and
+	// x-amz-copy-source-if-none-match.
+	if checkCopySourceETag(w, r) {
+		return
+	}
+
+	// Range of the source to copy, whole source if not set.
+	copyRange, err := getCopyPartRange(r.Header.Get("X-Amz-Copy-Source-Range"), objInfo.Size)
+	if err != nil {
+		errorIf(err, "Unable to parse copy source range.")
+		writeErrorResponse(w, r, ErrInvalidCopyPartRange, r.URL.Path)
+		return
+	}
+
+	/// maximum Upload size for multipart objects in a single operation
+	if isMaxObjectSize(copyRange.length) {
+		writeErrorResponse(w, r, ErrEntityTooLarge, objectSource)
+		return
+	}
+
+	// Parts of encrypted uploads are encrypted with the object key of
+	// the upload.
+	uploadInfo, err := api.ObjectAPI.ListObjectParts(bucket, object, uploadID, 0, 0)
+	if err != nil {
+		errorIf(err, "Unable to fetch upload info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	var objectKey []byte
+	partSize := copyRange.length
+	if isEncrypted(uploadInfo.UserDefined) {
+		objectKey, s3Error = getObjectKey(r.Header, sseCustomerHeaders, bucket, object, uploadInfo.UserDefined)
+		if s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+		partSize = encryptedSize(copyRange.length)
+	} else if hasSSECustomerHeader(r.Header, sseCustomerHeaders) {
+		writeErrorResponse(w, r, ErrInvalidEncryptionParameters, r.URL.Path)
+		return
+	}
+
+	// Copy the part, md5sum of the part is calculated while writing.
+	pipeReader := api.newCopySourceReader(sourceBucket, sourceObject, objInfo, sourceKey, copyRange.start, copyRange.length)
+	partMD5, err := api.ObjectAPI.PutObjectPart(bucket, object, uploadID, partID, partSize, encryptRequestReader(pipeReader, objectKey, nil), "")
+	// Explicitly close the reader, to avoid fd leaks.
+	pipeReader.Close()
+	if err != nil {
+		errorIf(err, "Unable to create object part.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	response := generateCopyObjectPartResponse(partMD5, time.Now().UTC())
+	encodedSuccessResponse := encodeResponse(response)
+	// write headers
+	setCommonHeaders(w)
+	setObjectEncryptionHeaders(w, uploadInfo.UserDefined)
+	// write success response.
+	writeSuccessResponse(w, encodedSuccessResponse)
+}
+
 // AbortMultipartUploadHandler - Abort multipart upload
 func (api objectAPIHandlers) AbortMultipartUploadHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -835,11 +1366,11 @@ func (api objectAPIHandlers) AbortMultipartUploadHandler(w http.ResponseWriter,
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/mpuAndPermissions.html
-		if s3Error := enforceBucketPolicy("s3:AbortMultipartUpload", bucket, r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy("s3:AbortMultipartUpload", bucket, getPathStyleURL(r)); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
-	case authTypePresigned, authTypeSigned:
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
 		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
@@ -855,9 +1386,10 @@ func (api objectAPIHandlers) AbortMultipartUploadHandler(w http.ResponseWriter,
 	writeSuccessNoContent(w)
 }
 
-// Send whitespace character, once every 5secs, until CompleteMultipartUpload is done.
-// CompleteMultipartUpload method of the object layer indicates that it's done via doneCh
-func sendWhiteSpaceChars(w http.ResponseWriter, doneCh <-chan struct{}) {
+// Send whitespace character, once every 5secs, until doneCh signals the
+// response is done, e.g. CompleteMultipartUpload method of the object
+// layer indicates that it's done via doneCh.
+func sendWhiteSpaceChars(w io.Writer, doneCh <-chan struct{}) {
 	for {
 		select {
 		case <-time.After(5 * time.Second):
@@ -881,11 +1413,11 @@ func (api objectAPIHandlers) ListObjectPartsHandler(w http.ResponseWriter, r *ht
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/mpuAndPermissions.html
-		if s3Error := enforceBucketPolicy("s3:ListMultipartUploadParts", bucket, r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy("s3:ListMultipartUploadParts", bucket, getPathStyleURL(r)); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
-	case authTypePresigned, authTypeSigned:
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
 		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
@@ -907,6 +1439,18 @@ func (api objectAPIHandlers) ListObjectPartsHandler(w http.ResponseWriter, r *ht
 		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
 		return
 	}
+	// List the plain text size of encrypted parts.
+	if isEncrypted(listPartsInfo.UserDefined) {
+		for i := range listPartsInfo.Parts {
+			partSize, dErr := decryptedSize(listPartsInfo.Parts[i].Size)
+			if dErr != nil {
+				errorIf(dErr, "Invalid encrypted part size.")
+				writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+				return
+			}
+			listPartsInfo.Parts[i].Size = partSize
+		}
+	}
 	response := generateListPartsResponse(listPartsInfo)
 	encodedSuccessResponse := encodeResponse(response)
 	// Write headers.
@@ -924,7 +1468,7 @@ func (api objectAPIHandlers) CompleteMultipartUploadHandler(w http.ResponseWrite
 	// Get upload id.
 	uploadID, _, _, _ := getObjectResources(r.URL.Query())
 
-	var md5Sum string
+	var objInfo ObjectInfo
 	var err error
 	switch getRequestAuthType(r) {
 	default:
@@ -933,11 +1477,11 @@ func (api objectAPIHandlers) CompleteMultipartUploadHandler(w http.ResponseWrite
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/mpuAndPermissions.html
-		if s3Error := enforceBucketPolicy("s3:PutObject", bucket, r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy("s3:PutObject", bucket, getPathStyleURL(r)); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
-	case authTypePresigned, authTypeSigned:
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
 		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
@@ -978,7 +1522,7 @@ func (api objectAPIHandlers) CompleteMultipartUploadHandler(w http.ResponseWrite
 	doneCh := make(chan struct{})
 	// Signal that completeMultipartUpload is over via doneCh
 	go func(doneCh chan<- struct{}) {
-		md5Sum, err = api.ObjectAPI.CompleteMultipartUpload(bucket, object, uploadID, completeParts)
+		objInfo, err = api.ObjectAPI.CompleteMultipartUploadIf(bucket, object, uploadID, completeParts, getWriteCondition(r))
 		doneCh <- struct{}{}
 	}(doneCh)
 
@@ -1000,11 +1544,21 @@ func (api objectAPIHandlers) CompleteMultipartUploadHandler(w http.ResponseWrite
 	// Get object location.
 	location := getLocation(r)
 	// Generate complete multipart response.
-	response := generateCompleteMultpartUploadResponse(bucket, object, location, md5Sum)
+	response := generateCompleteMultpartUploadResponse(bucket, object, location, objInfo.MD5Sum)
 	encodedSuccessResponse := encodeResponse(response)
 	// write success response.
 	w.Write(encodedSuccessResponse)
 	w.(http.Flusher).Flush()
+
+	// Notify object created event.
+	eventNotify(eventData{
+		Type:    ObjectCreatedCompleteMultipartUpload,
+		Bucket:  bucket,
+		ObjInfo: objInfo,
+		ReqParams: map[string]string{
+			"sourceIPAddress": r.RemoteAddr,
+		},
+	})
 }
 
 /// Delete objectAPIHandlers
@@ -1022,11 +1576,11 @@ func (api objectAPIHandlers) DeleteObjectHandler(w http.ResponseWriter, r *http.
 		return
 	case authTypeAnonymous:
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
-		if s3Error := enforceBucketPolicy("s3:DeleteObject", bucket, r.URL); s3Error != ErrNone {
+		if s3Error := enforceBucketPolicy("s3:DeleteObject", bucket, getPathStyleURL(r)); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
 		}
-	case authTypeSigned, authTypePresigned:
+	case authTypeSigned, authTypePresigned, authTypeSignedV2, authTypePresignedV2:
 		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
 			writeErrorResponse(w, r, s3Error, r.URL.Path)
 			return
@@ -1035,6 +1589,52 @@ func (api objectAPIHandlers) DeleteObjectHandler(w http.ResponseWriter, r *http.
 	/// http://docs.aws.amazon.com/AmazonS3/latest/API/RESTObjectDELETE.html
 	/// Ignore delete object errors, since we are suppposed to reply
 	/// only 204.
-	api.ObjectAPI.DeleteObject(bucket, object)
+	if versionID := r.URL.Query().Get("versionId"); versionID != "" {
+		// Permanently delete the requested version.
+		objInfo, err := api.ObjectAPI.DeleteObjectVersion(bucket, object, versionID)
+		if err == nil {
+			setObjectVersionHeaders(w, objInfo)
+		}
+		writeSuccessNoContent(w)
+
+		// Notify object removed event, only if a version was removed.
+		if err != nil {
+			return
+		}
+		eventNotify(eventData{
+			Type:    ObjectRemovedDelete,
+			Bucket:  bucket,
+			ObjInfo: objInfo,
+			ReqParams: map[string]string{
+				"sourceIPAddress": r.RemoteAddr,
+			},
+		})
+		return
+	}
+	markerInfo, deleteErr := api.ObjectAPI.DeleteObjectIf(bucket, object, getWriteCondition(r))
+	if deleteErr != nil {
+		// Only a failed 'If-Match' or 'If-None-Match' is reported.
+		if _, ok := deleteErr.(PreconditionFailed); ok {
+			writeErrorResponse(w, r, ErrPreconditionFailed, r.URL.Path)
+			return
+		}
+	}
+	// Set delete marker headers if versioning is configured.
+	setObjectVersionHeaders(w, markerInfo)
 	writeSuccessNoContent(w)
+
+	// Notify object removed event, only if an object was removed.
+	if deleteErr != nil {
+		return
+	}
+	eventNotify(eventData{
+		Type:   ObjectRemovedDelete,
+		Bucket: bucket,
+		ObjInfo: ObjectInfo{
+			Name: object,
+		},
+		ReqParams: map[string]string{
+			"sourceIPAddress": r.RemoteAddr,
+		},
+	})
 }
@@ -0,0 +1,467 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"testing"
+)
+
+// Tests copy part range parsing.
+func TestGetCopyPartRange(t *testing.T) {
+	testCases := []struct {
+		hrange        string
+		start, length int64
+		expectErr     bool
+	}{
+		{"", 0, 100, false},
+		{"bytes=0-99", 0, 100, false},
+		{"bytes=10-19", 10, 10, false},
+		{"bytes=99-99", 99, 1, false},
+		{"bytes=0-100", 0, 0, true},
+		{"bytes=20-10", 0, 0, true},
+		{"bytes=-10", 0, 0, true},
+		{"bytes=10-", 0, 0, true},
+		{"bytes=a-b", 0, 0, true},
+		{"0-10", 0, 0, true},
+	}
+	for i, testCase := range testCases {
+		hrange, err := getCopyPartRange(testCase.hrange, 100)
+		if testCase.expectErr {
+			if err == nil {
+				t.Fatalf("Test %d: Expected error for %s", i+1, testCase.hrange)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Test %d: %s", i+1, err)
+		}
+		if hrange.start != testCase.start || hrange.length != testCase.length {
+			t.Fatalf("Test %d: Expected %d+%d, got %d+%d", i+1, testCase.start, testCase.length, hrange.start, hrange.length)
+		}
+	}
+}
+
+// Tests assembling an object from ranges of an existing object.
+func TestCopyObjectPartHandler(t *testing.T) {
+	for _, instanceType := range []string{"FS", "XL"} {
+		testCopyObjectPartHandler(instanceType, t)
+	}
+}
+
+func testCopyObjectPartHandler(instanceType string, t *testing.T) {
+	testServer := StartTestServer(t, instanceType)
+	defer testServer.Stop()
+
+	data := make([]byte, minPartSize+1024*1024)
+	rand.New(rand.NewSource(1)).Read(data)
+	client := http.Client{}
+	expectStatus := func(req *http.Request, status int) []byte {
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		respBody, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != status {
+			t.Fatalf("%s: %s %s: Expected status %d, got %d: %s", instanceType, req.Method, req.URL, status, resp.StatusCode, respBody)
+		}
+		return respBody
+	}
+	newRequest := func(method, urlStr string, body []byte, headers map[string]string) *http.Request {
+		req, err := newTestRequest(method, urlStr, int64(len(body)), bytes.NewReader(body), testServer.AccessKey, testServer.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Headers set after signing are not signed.
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		return req
+	}
+
+	bucketURL := testServer.Server.URL + "/copy-part-bucket"
+	expectStatus(newRequest("PUT", bucketURL, nil, nil), http.StatusOK)
+	expectStatus(newRequest("PUT", bucketURL+"/source", data, nil), http.StatusOK)
+
+	respData := expectStatus(newRequest("POST", bucketURL+"/target?uploads", nil, nil), http.StatusOK)
+	var initResponse InitiateMultipartUploadResponse
+	if err := xml.Unmarshal(respData, &initResponse); err != nil {
+		t.Fatal(err)
+	}
+	uploadID := initResponse.UploadID
+
+	// Invalid ranges and sources are rejected.
+	partURL := fmt.Sprintf("%s/target?partNumber=1&uploadId=%s", bucketURL, uploadID)
+	expectStatus(newRequest("PUT", partURL, nil, map[string]string{
+		"X-Amz-Copy-Source":       "/copy-part-bucket/source",
+		"X-Amz-Copy-Source-Range": fmt.Sprintf("bytes=0-%d", len(data)),
+	}), http.StatusBadRequest)
+	expectStatus(newRequest("PUT", partURL, nil, map[string]string{
+		"X-Amz-Copy-Source": "/copy-part-bucket/missing",
+	}), http.StatusNotFound)
+
+	// Copy the source in two ranges, in reverse order.
+	var parts []completePart
+	ranges := []string{
+		fmt.Sprintf("bytes=0-%d", minPartSize-1),
+		fmt.Sprintf("bytes=%d-%d", minPartSize, len(data)-1),
+	}
+	for i := len(ranges) - 1; i >= 0; i-- {
+		partURL = fmt.Sprintf("%s/target?partNumber=%d&uploadId=%s", bucketURL, i+1, uploadID)
+		respData = expectStatus(newRequest("PUT", partURL, nil, map[string]string{
+			"X-Amz-Copy-Source":       "/copy-part-bucket/source",
+			"X-Amz-Copy-Source-Range": ranges[i],
+		}), http.StatusOK)
+		var copyResponse CopyObjectPartResponse
+		if err := xml.Unmarshal(respData, &copyResponse); err != nil {
+			t.Fatal(err)
+		}
+		if copyResponse.ETag == "" || copyResponse.LastModified == "" {
+			t.Fatalf("%s: Expected ETag and LastModified, got %s", instanceType, respData)
+		}
+		parts = append([]completePart{{PartNumber: i + 1, ETag: copyResponse.ETag}}, parts...)
+	}
+	completeBytes, err := xml.Marshal(completeMultipartUpload{Parts: parts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(newRequest("POST", bucketURL+"/target?uploadId="+uploadID, completeBytes, nil), http.StatusOK)
+	if respData = expectStatus(newRequest("GET", bucketURL+"/target", nil, nil), http.StatusOK); !bytes.Equal(respData, data) {
+		t.Fatalf("%s: Copied object does not match the source", instanceType)
+	}
+
+	// Copy sources are URL encoded.
+	expectStatus(newRequest("PUT", bucketURL+"/source%20file", data[:1024], nil), http.StatusOK)
+	respData = expectStatus(newRequest("POST", bucketURL+"/encoded?uploads", nil, nil), http.StatusOK)
+	if err = xml.Unmarshal(respData, &initResponse); err != nil {
+		t.Fatal(err)
+	}
+	partURL = fmt.Sprintf("%s/encoded?partNumber=1&uploadId=%s", bucketURL, initResponse.UploadID)
+	expectStatus(newRequest("PUT", partURL, nil, map[string]string{
+		"X-Amz-Copy-Source": "/copy-part-bucket/source%20file",
+	}), http.StatusOK)
+
+	// Anonymous copies need read access to the source.
+	policy := `{"Version": "2012-10-17", "Statement": [{"Action": ["s3:PutObject"], "Effect": "Allow", "Principal": {"AWS": ["*"]}, "Resource": ["arn:aws:s3:::copy-part-bucket/*"]}]}`
+	expectStatus(newRequest("PUT", bucketURL+"?policy", []byte(policy), nil), http.StatusNoContent)
+	anonReq, err := http.NewRequest("PUT", partURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anonReq.Header.Set("X-Amz-Copy-Source", "/copy-part-bucket/source%20file")
+	expectStatus(anonReq, http.StatusForbidden)
+	policy = `{"Version": "2012-10-17", "Statement": [{"Action": ["s3:GetObject", "s3:PutObject"], "Effect": "Allow", "Principal": {"AWS": ["*"]}, "Resource": ["arn:aws:s3:::copy-part-bucket/*"]}]}`
+	expectStatus(newRequest("PUT", bucketURL+"?policy", []byte(policy), nil), http.StatusNoContent)
+	anonReq, err = http.NewRequest("PUT", partURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anonReq.Header.Set("X-Amz-Copy-Source", "/copy-part-bucket/source%20file")
+	expectStatus(anonReq, http.StatusOK)
+}
+
+// Tests copying objects with x-amz-metadata-directive.
+func TestCopyObjectMetadataDirective(t *testing.T) {
+	for _, instanceType := range []string{"FS", "XL"} {
+		testCopyObjectMetadataDirective(instanceType, t)
+	}
+}
+
+func testCopyObjectMetadataDirective(instanceType string, t *testing.T) {
+	testServer := StartTestServer(t, instanceType)
+	defer testServer.Stop()
+
+	data := []byte("hello, copy object")
+	client := http.Client{}
+	expectStatus := func(req *http.Request, status int) ([]byte, http.Header) {
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		respBody, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != status {
+			t.Fatalf("%s: %s %s: Expected status %d, got %d: %s", instanceType, req.Method, req.URL, status, resp.StatusCode, respBody)
+		}
+		return respBody, resp.Header
+	}
+	newRequest := func(method, urlStr string, body []byte, headers map[string]string) *http.Request {
+		req, err := newTestRequest(method, urlStr, int64(len(body)), bytes.NewReader(body), testServer.AccessKey, testServer.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Headers set after signing are not signed.
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		return req
+	}
+	expectMetadata := func(urlStr, contentType, color string) {
+		respData, respHeader := expectStatus(newRequest("GET", urlStr, nil, nil), http.StatusOK)
+		if !bytes.Equal(respData, data) {
+			t.Fatalf("%s: %s: Expected %s, got %s", instanceType, urlStr, data, respData)
+		}
+		if respHeader.Get("Content-Type") != contentType {
+			t.Fatalf("%s: %s: Expected content type %s, got %s", instanceType, urlStr, contentType, respHeader.Get("Content-Type"))
+		}
+		if respHeader.Get("X-Amz-Meta-Color") != color {
+			t.Fatalf("%s: %s: Expected color %s, got %s", instanceType, urlStr, color, respHeader.Get("X-Amz-Meta-Color"))
+		}
+	}
+
+	bucketURL := testServer.Server.URL + "/copy-bucket"
+	expectStatus(newRequest("PUT", bucketURL, nil, nil), http.StatusOK)
+	expectStatus(newRequest("PUT", bucketURL+"/source", data, map[string]string{
+		"Content-Type":     "text/plain",
+		"X-Amz-Meta-Color": "red",
+	}), http.StatusOK)
+
+	// Metadata is copied by default.
+	expectStatus(newRequest("PUT", bucketURL+"/copied", nil, map[string]string{
+		"X-Amz-Copy-Source":  "/copy-bucket/source",
+		"X-Amz-Meta-Ignored": "true",
+	}), http.StatusOK)
+	expectMetadata(bucketURL+"/copied", "text/plain", "red")
+
+	// Metadata is replaced with REPLACE.
+	expectStatus(newRequest("PUT", bucketURL+"/replaced", nil, map[string]string{
+		"X-Amz-Copy-Source":        "/copy-bucket/source",
+		"X-Amz-Metadata-Directive": "REPLACE",
+		"Content-Type":             "application/json",
+		"X-Amz-Meta-Color":         "blue",
+	}), http.StatusOK)
+	expectMetadata(bucketURL+"/replaced", "application/json", "blue")
+
+	// Unknown directives are rejected.
+	expectStatus(newRequest("PUT", bucketURL+"/invalid", nil, map[string]string{
+		"X-Amz-Copy-Source":        "/copy-bucket/source",
+		"X-Amz-Metadata-Directive": "MERGE",
+	}), http.StatusBadRequest)
+
+	// Objects can be copied onto themselves only with REPLACE.
+	expectStatus(newRequest("PUT", bucketURL+"/source", nil, map[string]string{
+		"X-Amz-Copy-Source": "/copy-bucket/source",
+	}), http.StatusBadRequest)
+	expectStatus(newRequest("PUT", bucketURL+"/source", nil, map[string]string{
+		"X-Amz-Copy-Source":        "/copy-bucket/source",
+		"X-Amz-Metadata-Directive": "REPLACE",
+		"Content-Type":             "text/html",
+		"X-Amz-Meta-Color":         "green",
+	}), http.StatusOK)
+	expectMetadata(bucketURL+"/source", "text/html", "green")
+}
+
+// Tests If-Match and If-None-Match on PUT, DELETE and complete multipart.
+func TestConditionalWriteHandlers(t *testing.T) {
+	for _, instanceType := range []string{"FS", "XL"} {
+		testConditionalWriteHandlers(instanceType, t)
+	}
+}
+
+func testConditionalWriteHandlers(instanceType string, t *testing.T) {
+	testServer := StartTestServer(t, instanceType)
+	defer testServer.Stop()
+
+	data := []byte("hello, conditional writes")
+	client := http.Client{}
+	expectStatus := func(req *http.Request, status int) ([]byte, http.Header) {
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		respBody, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != status {
+			t.Fatalf("%s: %s %s: Expected status %d, got %d: %s", instanceType, req.Method, req.URL, status, resp.StatusCode, respBody)
+		}
+		return respBody, resp.Header
+	}
+	newRequest := func(method, urlStr string, body []byte, headers map[string]string) *http.Request {
+		req, err := newTestRequest(method, urlStr, int64(len(body)), bytes.NewReader(body), testServer.AccessKey, testServer.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Headers set after signing are not signed.
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		return req
+	}
+
+	bucketURL := testServer.Server.URL + "/conditional-bucket"
+	objectURL := bucketURL + "/lease"
+	expectStatus(newRequest("PUT", bucketURL, nil, nil), http.StatusOK)
+
+	// Lease is acquired only once.
+	_, respHeader := expectStatus(newRequest("PUT", objectURL, data, map[string]string{"If-None-Match": "*"}), http.StatusOK)
+	etag := respHeader.Get("ETag")
+	expectStatus(newRequest("PUT", objectURL, data, map[string]string{"If-None-Match": "*"}), http.StatusPreconditionFailed)
+
+	// Lease is renewed only by its holder.
+	expectStatus(newRequest("PUT", objectURL, []byte("renewed"), map[string]string{"If-Match": "\"mismatch\""}), http.StatusPreconditionFailed)
+	_, respHeader = expectStatus(newRequest("PUT", objectURL, []byte("renewed"), map[string]string{"If-Match": etag}), http.StatusOK)
+	if respHeader.Get("ETag") == etag {
+		t.Fatalf("%s: Expected a new ETag for the renewed lease", instanceType)
+	}
+	expectStatus(newRequest("DELETE", objectURL, nil, map[string]string{"If-Match": etag}), http.StatusPreconditionFailed)
+
+	// Failed complete multipart reports the error in the response body.
+	respData, _ := expectStatus(newRequest("POST", objectURL+"?uploads", nil, nil), http.StatusOK)
+	var initResponse InitiateMultipartUploadResponse
+	if err := xml.Unmarshal(respData, &initResponse); err != nil {
+		t.Fatal(err)
+	}
+	_, partHeader := expectStatus(newRequest("PUT", objectURL+"?partNumber=1&uploadId="+initResponse.UploadID, data, nil), http.StatusOK)
+	completeBytes, err := xml.Marshal(completeMultipartUpload{
+		Parts: []completePart{{PartNumber: 1, ETag: partHeader.Get("ETag")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	completeURL := objectURL + "?uploadId=" + initResponse.UploadID
+	respData, _ = expectStatus(newRequest("POST", completeURL, completeBytes, map[string]string{"If-None-Match": "*"}), http.StatusOK)
+	if !bytes.Contains(respData, []byte("<Code>PreconditionFailed</Code>")) {
+		t.Fatalf("%s: Expected PreconditionFailed, got %s", instanceType, respData)
+	}
+	expectStatus(newRequest("POST", completeURL, completeBytes, map[string]string{"If-Match": respHeader.Get("ETag")}), http.StatusOK)
+
+	// Lease is released.
+	_, respHeader = expectStatus(newRequest("HEAD", objectURL, nil, nil), http.StatusOK)
+	expectStatus(newRequest("DELETE", objectURL, nil, map[string]string{"If-Match": respHeader.Get("ETag")}), http.StatusNoContent)
+	expectStatus(newRequest("HEAD", objectURL, nil, nil), http.StatusNotFound)
+	expectStatus(newRequest("DELETE", objectURL, nil, map[string]string{"If-Match": "*"}), http.StatusPreconditionFailed)
+}
+
+// Tests multi-range GET with multipart/byteranges responses.
+func TestGetObjectMultipleRanges(t *testing.T) {
+	for _, instanceType := range []string{"FS", "XL"} {
+		testGetObjectMultipleRanges(instanceType, t)
+	}
+}
+
+func testGetObjectMultipleRanges(instanceType string, t *testing.T) {
+	testServer := StartTestTLSServer(t, instanceType)
+	defer testServer.Stop()
+
+	data := make([]byte, 100*1024)
+	rand.New(rand.NewSource(1)).Read(data)
+	key := bytes.Repeat([]byte("k"), 32)
+	client := testServer.Server.Client()
+	doRequest := func(method, urlStr string, body []byte, headers map[string]string, encrypted bool) *http.Response {
+		req, err := newTestRequest(method, urlStr, int64(len(body)), bytes.NewReader(body), testServer.AccessKey, testServer.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if encrypted {
+			setSSECustomerTestHeaders(req, sseCustomerHeaders, key)
+		}
+		// Headers set after signing are not signed.
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	expectStatus := func(resp *http.Response, status int) {
+		if resp.StatusCode != status {
+			respBody, _ := ioutil.ReadAll(resp.Body)
+			t.Fatalf("%s: %s %s: Expected status %d, got %d: %s", instanceType, resp.Request.Method, resp.Request.URL, status, resp.StatusCode, respBody)
+		}
+	}
+
+	bucketURL := testServer.Server.URL + "/ranges-bucket"
+	resp := doRequest("PUT", bucketURL, nil, nil, false)
+	resp.Body.Close()
+	expectStatus(resp, http.StatusOK)
+
+	for _, encrypted := range []bool{false, true} {
+		objectURL := fmt.Sprintf("%s/object-%t", bucketURL, encrypted)
+		resp = doRequest("PUT", objectURL, data, map[string]string{"Content-Type": "video/mp4"}, encrypted)
+		resp.Body.Close()
+		expectStatus(resp, http.StatusOK)
+
+		// Each range is sent as a part with its content range.
+		resp = doRequest("GET", objectURL, nil, map[string]string{"Range": "bytes=0-99, 70000-70999,-10"}, encrypted)
+		expectStatus(resp, http.StatusPartialContent)
+		mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		if err != nil || mediaType != "multipart/byteranges" {
+			t.Fatalf("%s: Expected multipart/byteranges, got %s", instanceType, resp.Header.Get("Content-Type"))
+		}
+		respBody, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Header.Get("Content-Length") != fmt.Sprint(len(respBody)) {
+			t.Fatalf("%s: Expected content length %d, got %s", instanceType, len(respBody), resp.Header.Get("Content-Length"))
+		}
+		expectedParts := []struct {
+			contentRange string
+			data         []byte
+		}{
+			{"bytes 0-99/102400", data[:100]},
+			{"bytes 70000-70999/102400", data[70000:71000]},
+			{"bytes 102390-102399/102400", data[102390:]},
+		}
+		reader := multipart.NewReader(bytes.NewReader(respBody), params["boundary"])
+		for i, expectedPart := range expectedParts {
+			part, err := reader.NextPart()
+			if err != nil {
+				t.Fatalf("%s: Part %d: %s", instanceType, i+1, err)
+			}
+			if part.Header.Get("Content-Range") != expectedPart.contentRange || part.Header.Get("Content-Type") != "video/mp4" {
+				t.Fatalf("%s: Part %d: Unexpected part headers %v", instanceType, i+1, part.Header)
+			}
+			partData, err := ioutil.ReadAll(part)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(partData, expectedPart.data) {
+				t.Fatalf("%s: Part %d: Range data does not match (encrypted: %t)", instanceType, i+1, encrypted)
+			}
+		}
+		if _, err = reader.NextPart(); err != io.EOF {
+			t.Fatalf("%s: Expected %d parts, got %v", instanceType, len(expectedParts), err)
+		}
+
+		// Unsatisfiable ranges are rejected.
+		resp = doRequest("GET", objectURL, nil, map[string]string{"Range": "bytes=0-1,200000-200001"}, encrypted)
+		resp.Body.Close()
+		expectStatus(resp, http.StatusRequestedRangeNotSatisfiable)
+	}
+}
@@ -16,7 +16,10 @@
 
 package main
 
-import "io"
+import (
+	"io"
+	"net/url"
+)
 
 // ObjectLayer implements primitives for object API layer.
 type ObjectLayer interface {
@@ -35,6 +38,22 @@ type ObjectLayer interface {
 	GetObjectInfo(bucket, object string) (objInfo ObjectInfo, err error)
 	PutObject(bucket, object string, size int64, data io.Reader, metadata map[string]string) (md5 string, err error)
 	DeleteObject(bucket, object string) error
+	CopyObject(srcBucket, srcObject, destBucket, destObject string, metadata map[string]string) (objInfo ObjectInfo, err error)
+	UpdateObjectMetadata(bucket, object string, metadata map[string]string) (objInfo ObjectInfo, err error)
+	UpdateObjectTags(bucket, object string, tags url.Values) (objInfo ObjectInfo, err error)
+
+	// Conditional object operations, the write condition is verified
+	// under the object write lock. Info of the object written, or of
+	// the delete marker placed, is returned.
+	PutObjectIf(bucket, object string, size int64, data io.Reader, metadata map[string]string, cond writeCondition) (objInfo ObjectInfo, err error)
+	DeleteObjectIf(bucket, object string, cond writeCondition) (objInfo ObjectInfo, err error)
+	CompleteMultipartUploadIf(bucket, object, uploadID string, uploadedParts []completePart, cond writeCondition) (objInfo ObjectInfo, err error)
+
+	// Object version operations.
+	ListObjectVersions(bucket, prefix, keyMarker, versionIDMarker, delimiter string, maxKeys int) (result ListObjectVersionsInfo, err error)
+	GetObjectVersion(bucket, object, versionID string, startOffset int64, length int64, writer io.Writer) (err error)
+	GetObjectVersionInfo(bucket, object, versionID string) (objInfo ObjectInfo, err error)
+	DeleteObjectVersion(bucket, object, versionID string) (objInfo ObjectInfo, err error)
 
 	// Multipart operations.
 	ListMultipartUploads(bucket, prefix, keyMarker, uploadIDMarker, delimiter string, maxUploads int) (result ListMultipartsInfo, err error)
@@ -0,0 +1,203 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	mux "github.com/gorilla/mux"
+)
+
+// maximum supported tagging request size.
+const maxTaggingSize = 1024 * 1024 // 1MiB.
+
+// existingObjectTagConditions - returns bucket policy conditions on
+// the tags of an existing object, objects which cannot be read have
+// no tags.
+func (api objectAPIHandlers) existingObjectTagConditions(bucket, object string) map[string]string {
+	objInfo, err := api.ObjectAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		return nil
+	}
+	return getTagConditions(existingObjectTagConditionPrefix, getObjectTags(objInfo.UserDefined))
+}
+
+// updateObjectTags - replaces the tags of an existing object, rest of
+// the object metadata is left unchanged.
+func (api objectAPIHandlers) updateObjectTags(w http.ResponseWriter, r *http.Request, bucket, object string, tags url.Values) bool {
+	objInfo, err := api.ObjectAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		errorIf(err, "Unable to fetch object info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return false
+	}
+	// Delete markers have no tags.
+	if objInfo.DeleteMarker {
+		setObjectVersionHeaders(w, objInfo)
+		writeErrorResponse(w, r, ErrMethodNotAllowed, r.URL.Path)
+		return false
+	}
+
+	// Tags are merged with the current metadata under the object lock,
+	// a concurrent write in between is not overwritten.
+	if objInfo, err = api.ObjectAPI.UpdateObjectTags(bucket, object, tags); err != nil {
+		errorIf(err, "Unable to update object metadata.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return false
+	}
+	setObjectVersionHeaders(w, objInfo)
+	return true
+}
+
+// PutObjectTaggingHandler - PUT Object tagging
+// -----------------
+// This implementation of the PUT operation replaces the tags of an
+// existing object.
+func (api objectAPIHandlers) PutObjectTaggingHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	switch getRequestAuthType(r) {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypeAnonymous:
+		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
+		if s3Error := enforceBucketPolicyTags("s3:PutObjectTagging", bucket, getPathStyleURL(r), api.existingObjectTagConditions(bucket, object)); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
+		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	// Read tagging request, limited to maxTaggingSize.
+	taggingBytes, err := ioutil.ReadAll(io.LimitReader(r.Body, maxTaggingSize))
+	if err != nil {
+		errorIf(err, "Unable to read tagging request.")
+		writeErrorResponse(w, r, ErrInternalError, r.URL.Path)
+		return
+	}
+
+	var tagging Tagging
+	if err = xml.Unmarshal(taggingBytes, &tagging); err != nil {
+		errorIf(err, "Unable to parse tagging request.")
+		writeErrorResponse(w, r, ErrMalformedXML, r.URL.Path)
+		return
+	}
+	tags, err := tagging.toTags(maxObjectTags)
+	if err != nil {
+		errorIf(err, "Invalid object tags.")
+		writeErrorResponse(w, r, ErrInvalidTag, r.URL.Path)
+		return
+	}
+
+	if !api.updateObjectTags(w, r, bucket, object, tags) {
+		return
+	}
+	writeSuccessResponse(w, nil)
+}
+
+// GetObjectTaggingHandler - GET Object tagging
+// -----------------
+// This implementation of the GET operation returns the tags of an
+// object.
+func (api objectAPIHandlers) GetObjectTaggingHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	switch getRequestAuthType(r) {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypeAnonymous:
+		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
+		if s3Error := enforceBucketPolicyTags("s3:GetObjectTagging", bucket, getPathStyleURL(r), api.existingObjectTagConditions(bucket, object)); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
+		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	objInfo, err := api.ObjectAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		errorIf(err, "Unable to fetch object info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	// Delete markers have no tags.
+	if objInfo.DeleteMarker {
+		setObjectVersionHeaders(w, objInfo)
+		writeErrorResponse(w, r, ErrMethodNotAllowed, r.URL.Path)
+		return
+	}
+
+	encodedSuccessResponse := encodeResponse(newTagging(getObjectTags(objInfo.UserDefined)))
+	// Write headers
+	setCommonHeaders(w)
+	setObjectVersionHeaders(w, objInfo)
+	// Write success response.
+	writeSuccessResponse(w, encodedSuccessResponse)
+}
+
+// DeleteObjectTaggingHandler - DELETE Object tagging
+// -----------------
+// This implementation of the DELETE operation removes all the tags of
+// an object.
+func (api objectAPIHandlers) DeleteObjectTaggingHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	switch getRequestAuthType(r) {
+	default:
+		// For all unknown auth types return error.
+		writeErrorResponse(w, r, ErrAccessDenied, r.URL.Path)
+		return
+	case authTypeAnonymous:
+		// http://docs.aws.amazon.com/AmazonS3/latest/dev/using-with-s3-actions.html
+		if s3Error := enforceBucketPolicyTags("s3:DeleteObjectTagging", bucket, getPathStyleURL(r), api.existingObjectTagConditions(bucket, object)); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	case authTypePresigned, authTypeSigned, authTypePresignedV2, authTypeSignedV2:
+		if s3Error := isReqAuthenticated(r); s3Error != ErrNone {
+			writeErrorResponse(w, r, s3Error, r.URL.Path)
+			return
+		}
+	}
+
+	if !api.updateObjectTags(w, r, bucket, object, nil) {
+		return
+	}
+	writeSuccessNoContent(w)
+}
@@ -0,0 +1,168 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// amzTagging - header carrying the tags of an object on PUT and
+// multipart initiate, tags are saved in object metadata under the
+// same key in URL query encoded form.
+const amzTagging = "X-Amz-Tagging"
+
+// Tag limits.
+const (
+	// maximum number of tags allowed on an object.
+	maxObjectTags = 10
+	// maximum number of tags allowed on a bucket.
+	maxBucketTags = 50
+	// maximum length of a tag key in unicode characters.
+	maxTagKeyLength = 128
+	// maximum length of a tag value in unicode characters.
+	maxTagValueLength = 256
+)
+
+// Tagging - represents the tag set of an object.
+type Tagging struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ Tagging" json:"-"`
+	TagSet  tagSet   `xml:"TagSet"`
+}
+
+// tagSet - list of tags.
+type tagSet struct {
+	Tags []tag `xml:"Tag"`
+}
+
+// tag - a single key value pair.
+type tag struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
+
+// Tagging errors.
+var (
+	errTooManyTags       = errors.New("Number of tags exceeds the allowed limit")
+	errInvalidTagKey     = errors.New("Tag key must be between 1 and 128 unicode characters")
+	errInvalidTagValue   = errors.New("Tag value cannot be longer than 256 unicode characters")
+	errDuplicateTagKey   = errors.New("Tag keys must be unique")
+	errInvalidTagsHeader = errors.New("Tags must be URL query encoded")
+)
+
+// validateTags - validates the tags of an object or a bucket, with at
+// most maxTags tags.
+func validateTags(tags url.Values, maxTags int) error {
+	if len(tags) > maxTags {
+		return errTooManyTags
+	}
+	for key, values := range tags {
+		if len(values) != 1 {
+			return errDuplicateTagKey
+		}
+		if key == "" || utf8.RuneCountInString(key) > maxTagKeyLength {
+			return errInvalidTagKey
+		}
+		if utf8.RuneCountInString(values[0]) > maxTagValueLength {
+			return errInvalidTagValue
+		}
+	}
+	return nil
+}
+
+// toTags - returns the tags of the tag set, with at most maxTags tags.
+func (tagging Tagging) toTags(maxTags int) (url.Values, error) {
+	tags := make(url.Values)
+	for _, t := range tagging.TagSet.Tags {
+		tags.Add(t.Key, t.Value)
+	}
+	if err := validateTags(tags, maxTags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// newTagging - returns the tag set of the tags, sorted by key.
+func newTagging(tags url.Values) Tagging {
+	var keys []string
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	tagging := Tagging{}
+	for _, key := range keys {
+		tagging.TagSet.Tags = append(tagging.TagSet.Tags, tag{Key: key, Value: tags.Get(key)})
+	}
+	return tagging
+}
+
+// getRequestTags - returns the tags set with x-amz-tagging.
+func getRequestTags(header http.Header) (url.Values, error) {
+	if _, ok := header[amzTagging]; !ok {
+		return nil, nil
+	}
+	tags, err := url.ParseQuery(header.Get(amzTagging))
+	if err != nil {
+		return nil, errInvalidTagsHeader
+	}
+	if err = validateTags(tags, maxObjectTags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// getObjectTags - returns the tags saved in object metadata.
+func getObjectTags(metadata map[string]string) url.Values {
+	tags, err := url.ParseQuery(metadata[amzTagging])
+	if err != nil {
+		return nil
+	}
+	return tags
+}
+
+// setObjectTags - saves the tags in object metadata, replacing
+// existing tags.
+func setObjectTags(metadata map[string]string, tags url.Values) {
+	if len(tags) == 0 {
+		delete(metadata, amzTagging)
+		return
+	}
+	metadata[amzTagging] = tags.Encode()
+}
+
+// setObjectTaggingHeaders - sets the number of tags of an object.
+func setObjectTaggingHeaders(w http.ResponseWriter, metadata map[string]string) {
+	if tags := getObjectTags(metadata); len(tags) > 0 {
+		w.Header().Set("X-Amz-Tagging-Count", strconv.Itoa(len(tags)))
+	}
+}
+
+// getTagConditions - returns bucket policy conditions on the tags,
+// keyed by the condition key prefix without `s3:` and the tag key.
+func getTagConditions(conditionPrefix string, tags url.Values) map[string]string {
+	conditions := make(map[string]string)
+	for key := range tags {
+		conditions[strings.TrimPrefix(conditionPrefix, "s3:")+key] = tags.Get(key)
+	}
+	return conditions
+}
@@ -0,0 +1,231 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// Tests parsing x-amz-tagging.
+func TestGetRequestTags(t *testing.T) {
+	testCases := []struct {
+		tagging   string
+		numTags   int
+		expectErr bool
+	}{
+		{"", 0, false},
+		{"class=public", 1, false},
+		{"class=public&team=storage&empty=", 3, false},
+		{"a%20b=c%26d", 1, false},
+		{"=value", 0, true},
+		{"class=public&class=secret", 0, true},
+		{"k1&k2&k3&k4&k5&k6&k7&k8&k9&k10&k11", 0, true},
+		{strings.Repeat("k", maxTagKeyLength+1) + "=v", 0, true},
+		{"k=" + strings.Repeat("v", maxTagValueLength+1), 0, true},
+		{"class=%zz", 0, true},
+	}
+	for i, testCase := range testCases {
+		header := http.Header{}
+		header.Set(amzTagging, testCase.tagging)
+		tags, err := getRequestTags(header)
+		if testCase.expectErr {
+			if err == nil {
+				t.Fatalf("Test %d: Expected error for %s", i+1, testCase.tagging)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Test %d: %s", i+1, err)
+		}
+		if len(tags) != testCase.numTags {
+			t.Fatalf("Test %d: Expected %d tags, got %d", i+1, testCase.numTags, len(tags))
+		}
+	}
+	if tags, err := getRequestTags(http.Header{}); err != nil || tags != nil {
+		t.Fatalf("Expected no tags without x-amz-tagging, got %v, %v", tags, err)
+	}
+}
+
+// Tests that updating tags keeps the current metadata of the object.
+func TestObjectAPIUpdateObjectTags(t *testing.T) {
+	ExecObjectLayerTest(t, testObjectAPIUpdateObjectTags)
+}
+
+func testObjectAPIUpdateObjectTags(obj ObjectLayer, instanceType string, t *testing.T) {
+	bucket := "tagging-bucket"
+	if err := obj.MakeBucket(bucket); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	data := []byte("hello, tags")
+	if _, err := obj.PutObject(bucket, "object", int64(len(data)), bytes.NewReader(data), map[string]string{"content-type": "text/plain", amzTagging: "class=public"}); err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	// Object is replaced after its tags were read.
+	md5Sum, err := obj.PutObject(bucket, "object", int64(len(data)), bytes.NewReader(data), map[string]string{"content-type": "text/html"})
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	objInfo, err := obj.UpdateObjectTags(bucket, "object", url.Values{"team": []string{"storage"}})
+	if err != nil {
+		t.Fatalf("%s: %s", instanceType, err)
+	}
+	if objInfo.ContentType != "text/html" || objInfo.MD5Sum != md5Sum {
+		t.Fatalf("%s: Expected metadata of the current object, got %s, %s", instanceType, objInfo.ContentType, objInfo.MD5Sum)
+	}
+	if tags := getObjectTags(objInfo.UserDefined); tags.Encode() != "team=storage" {
+		t.Fatalf("%s: Expected tags to be replaced, got %s", instanceType, tags.Encode())
+	}
+	if _, err = obj.UpdateObjectTags(bucket, "missing", nil); err == nil {
+		t.Fatalf("%s: Expected error for a missing object", instanceType)
+	}
+}
+
+// Tests object tagging operations.
+func TestObjectTaggingHandlers(t *testing.T) {
+	for _, instanceType := range []string{"FS", "XL"} {
+		testObjectTaggingHandlers(instanceType, t)
+	}
+}
+
+func testObjectTaggingHandlers(instanceType string, t *testing.T) {
+	testServer := StartTestServer(t, instanceType)
+	defer testServer.Stop()
+
+	data := []byte("hello, tagging")
+	client := http.Client{}
+	expectStatus := func(req *http.Request, status int) ([]byte, http.Header) {
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		respBody, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != status {
+			t.Fatalf("%s: %s %s: Expected status %d, got %d: %s", instanceType, req.Method, req.URL, status, resp.StatusCode, respBody)
+		}
+		return respBody, resp.Header
+	}
+	newRequest := func(method, urlStr string, body []byte, headers map[string]string) *http.Request {
+		req, err := newTestRequest(method, urlStr, int64(len(body)), bytes.NewReader(body), testServer.AccessKey, testServer.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Headers set after signing are not signed.
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		return req
+	}
+	newAnonymousRequest := func(method, urlStr string) *http.Request {
+		req, err := http.NewRequest(method, urlStr, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+	expectTags := func(urlStr, expected string) {
+		respData, _ := expectStatus(newRequest("GET", urlStr+"?tagging", nil, nil), http.StatusOK)
+		var tagging Tagging
+		if err := xml.Unmarshal(respData, &tagging); err != nil {
+			t.Fatal(err)
+		}
+		var tags []string
+		for _, tag := range tagging.TagSet.Tags {
+			tags = append(tags, tag.Key+"="+tag.Value)
+		}
+		if strings.Join(tags, "&") != expected {
+			t.Fatalf("%s: %s: Expected tags %s, got %s", instanceType, urlStr, expected, respData)
+		}
+	}
+
+	bucketURL := testServer.Server.URL + "/tagging-bucket"
+	objectURL := bucketURL + "/object"
+	expectStatus(newRequest("PUT", bucketURL, nil, nil), http.StatusOK)
+
+	// Tags are set with x-amz-tagging.
+	expectStatus(newRequest("PUT", objectURL, data, map[string]string{amzTagging: "team=storage&class=public"}), http.StatusOK)
+	expectTags(objectURL, "class=public&team=storage")
+	respData, respHeader := expectStatus(newRequest("GET", objectURL, nil, nil), http.StatusOK)
+	if !bytes.Equal(respData, data) || respHeader.Get("X-Amz-Tagging-Count") != "2" {
+		t.Fatalf("%s: Expected object with 2 tags, got %s tags", instanceType, respHeader.Get("X-Amz-Tagging-Count"))
+	}
+	expectStatus(newRequest("PUT", bucketURL+"/invalid", data, map[string]string{amzTagging: "=value"}), http.StatusBadRequest)
+	expectStatus(newRequest("GET", bucketURL+"/missing?tagging", nil, nil), http.StatusNotFound)
+
+	// Tags are replaced, object data is left unchanged.
+	taggingBody := []byte(`<Tagging xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><TagSet><Tag><Key>class</Key><Value>secret</Value></Tag></TagSet></Tagging>`)
+	expectStatus(newRequest("PUT", objectURL+"?tagging", taggingBody, nil), http.StatusOK)
+	expectTags(objectURL, "class=secret")
+	if respData, _ = expectStatus(newRequest("GET", objectURL, nil, nil), http.StatusOK); !bytes.Equal(respData, data) {
+		t.Fatalf("%s: Object data changed with its tags", instanceType)
+	}
+	expectStatus(newRequest("PUT", objectURL+"?tagging", []byte("<Tagging>"), nil), http.StatusBadRequest)
+	duplicateTags := []byte(`<Tagging xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><TagSet><Tag><Key>a</Key><Value>1</Value></Tag><Tag><Key>a</Key><Value>2</Value></Tag></TagSet></Tagging>`)
+	expectStatus(newRequest("PUT", objectURL+"?tagging", duplicateTags, nil), http.StatusBadRequest)
+
+	// Copies keep the tags of the source unless replaced.
+	expectStatus(newRequest("PUT", bucketURL+"/copy", nil, map[string]string{
+		"X-Amz-Copy-Source": "/tagging-bucket/object",
+		amzTagging:          "ignored=true",
+	}), http.StatusOK)
+	expectTags(bucketURL+"/copy", "class=secret")
+	expectStatus(newRequest("PUT", bucketURL+"/replaced", nil, map[string]string{
+		"X-Amz-Copy-Source":       "/tagging-bucket/object",
+		"X-Amz-Tagging-Directive": "REPLACE",
+		amzTagging:                "class=public",
+	}), http.StatusOK)
+	expectTags(bucketURL+"/replaced", "class=public")
+
+	// Tags are set on multipart initiate.
+	respData, _ = expectStatus(newRequest("POST", bucketURL+"/multipart?uploads", nil, map[string]string{amzTagging: "class=public"}), http.StatusOK)
+	var initResponse InitiateMultipartUploadResponse
+	if err := xml.Unmarshal(respData, &initResponse); err != nil {
+		t.Fatal(err)
+	}
+	partURL := bucketURL + "/multipart?partNumber=1&uploadId=" + initResponse.UploadID
+	_, respHeader = expectStatus(newRequest("PUT", partURL, data, nil), http.StatusOK)
+	completeBytes, err := xml.Marshal(completeMultipartUpload{
+		Parts: []completePart{{PartNumber: 1, ETag: respHeader.Get("ETag")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(newRequest("POST", bucketURL+"/multipart?uploadId="+initResponse.UploadID, completeBytes, nil), http.StatusOK)
+	expectTags(bucketURL+"/multipart", "class=public")
+
+	// Anonymous access can be granted by tag.
+	policy := fmt.Sprintf(`{"Version": "2012-10-17", "Statement": [{"Action": ["s3:GetObject"], "Effect": "Allow", "Principal": {"AWS": ["*"]}, "Resource": ["arn:aws:s3:::tagging-bucket/*"], "Condition": {"StringEquals": {"%sclass": "public"}}}]}`, existingObjectTagConditionPrefix)
+	expectStatus(newRequest("PUT", bucketURL+"?policy", []byte(policy), nil), http.StatusNoContent)
+	expectStatus(newAnonymousRequest("GET", bucketURL+"/replaced"), http.StatusOK)
+	expectStatus(newAnonymousRequest("GET", bucketURL+"/copy"), http.StatusForbidden)
+	expectStatus(newAnonymousRequest("GET", bucketURL+"/copy?ExistingObjectTag/class=public"), http.StatusForbidden)
+
+	// Tags are removed.
+	expectStatus(newRequest("DELETE", bucketURL+"/replaced?tagging", nil, nil), http.StatusNoContent)
+	expectTags(bucketURL+"/replaced", "")
+	expectStatus(newAnonymousRequest("GET", bucketURL+"/replaced"), http.StatusForbidden)
+}
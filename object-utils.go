@@ -35,6 +35,10 @@ const (
 	mpartMetaPrefix = "multipart"
 	// Tmp meta prefix.
 	tmpMetaPrefix = "tmp"
+	// Bucket meta prefix, holds per object metadata for FS.
+	bucketMetaPrefix = "buckets"
+	// Versions meta prefix, holds all archived object versions.
+	versionsMetaPrefix = "versions"
 )
 
 // validBucket regexp.
@@ -0,0 +1,557 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	// Version id of objects written while versioning was never
+	// enabled or is suspended on the bucket.
+	nullVersionID = "null"
+
+	// Versions meta file carrying all versions of an object.
+	versionsJSONFile = "versions.json"
+)
+
+// A versionInfo represents a single version or a delete marker of an object.
+type versionInfo struct {
+	VersionID    string    `json:"versionId"`              // Unique version id.
+	DeleteMarker bool      `json:"deleteMarker,omitempty"` // Indicates if this version is a delete marker.
+	ModTime      time.Time `json:"modTime"`                // Time at which this version was created.
+	Size         int64     `json:"size"`                   // Size of the object version.
+	MD5Sum       string    `json:"md5Sum,omitempty"`       // md5sum of the object version.
+}
+
+// A versionsV1 represents `versions.json` metadata header, versions
+// are ordered from the latest to the oldest.
+type versionsV1 struct {
+	Version  string        `json:"version"`  // Version of the current `versions.json`
+	Format   string        `json:"format"`   // Format of the current `versions.json`
+	Versions []versionInfo `json:"versions"` // Captures all the versions of a given object.
+}
+
+// newVersionsV1 - initialize new versions v1.
+func newVersionsV1(format string) versionsV1 {
+	versions := versionsV1{}
+	versions.Version = "1.0.0" // Should follow semantic versioning.
+	versions.Format = format
+	return versions
+}
+
+// Index - returns the index of matching the version id.
+func (v versionsV1) Index(versionID string) int {
+	for i, version := range v.Versions {
+		if version.VersionID == versionID {
+			return i
+		}
+	}
+	return -1
+}
+
+// AddVersion - adds a new version as the latest version.
+func (v *versionsV1) AddVersion(version versionInfo) {
+	v.Versions = append([]versionInfo{version}, v.Versions...)
+}
+
+// RemoveVersion - removes the version at index.
+func (v *versionsV1) RemoveVersion(index int) {
+	v.Versions = append(v.Versions[:index], v.Versions[index+1:]...)
+}
+
+// readVersionsJSON - get all the saved versions JSON.
+func readVersionsJSON(bucket, object string, disk StorageAPI) (versions versionsV1, err error) {
+	versionsJSONPath := path.Join(versionsMetaPrefix, bucket, object, versionsJSONFile)
+	// Reads entire `versions.json`.
+	buf, err := disk.ReadAll(minioMetaBucket, versionsJSONPath)
+	if err != nil {
+		return versionsV1{}, err
+	}
+
+	// Decode `versions.json`.
+	if err = json.Unmarshal(buf, &versions); err != nil {
+		return versionsV1{}, err
+	}
+
+	// Success.
+	return versions, nil
+}
+
+// objectVersionPath - returns the location of an archived object
+// version inside minioMetaBucket.
+func objectVersionPath(bucket, object, versionID string) string {
+	return path.Join(versionsMetaPrefix, bucket, object, versionID)
+}
+
+// hasObjectVersions - returns true if any object versions are saved
+// for the bucket on the disk.
+func hasObjectVersions(bucket string, disk StorageAPI) bool {
+	entries, err := disk.ListDir(minioMetaBucket, retainSlash(path.Join(versionsMetaPrefix, bucket)))
+	return err == nil && len(entries) > 0
+}
+
+// versionedObjects - primitives implemented by object layers to
+// support object versioning. All the object versioning logic common
+// to both FS and XL is implemented on top of these.
+type versionedObjects interface {
+	// Initiates a new tree walk on a bucket.
+	startTreeWalk(bucket, prefix, marker string, recursive bool, isLeaf func(string, string) bool, endWalkCh chan struct{}) chan treeWalkResult
+	// Returns true if prefix is an object.
+	isObject(bucket, prefix string) bool
+	// Returns true if prefix has `versions.json`.
+	isVersionedObject(bucket, prefix string) bool
+	// Returns the object info for the current version of an object.
+	getObjectInfo(bucket, object string) (ObjectInfo, error)
+	// Returns the object info for an archived version of an object.
+	getArchivedObjectInfo(bucket, object, versionID string) (ObjectInfo, error)
+	// Deletes the current version of an object.
+	deleteObject(bucket, object string) error
+	// Reads and writes `versions.json` of an object, reading returns
+	// no versions if the object was never versioned and writing no
+	// versions removes `versions.json`.
+	readVersions(bucket, object string) (versionsV1, error)
+	writeVersions(bucket, object string, versions versionsV1) error
+	// Moves the current version of an object to its archive location
+	// and vice versa.
+	archiveObject(bucket, object, versionID string) error
+	restoreObject(bucket, object, versionID string) error
+	// Deletes an archived version of an object.
+	deleteObjectVersion(bucket, object, versionID string) error
+}
+
+// getVersionID - returns the version id of the object, objects written
+// before versioning was configured carry the "null" version id.
+func getVersionID(objInfo ObjectInfo) string {
+	if objInfo.VersionID == "" {
+		return nullVersionID
+	}
+	return objInfo.VersionID
+}
+
+// toVersionErr - converts errors to object version errors, missing
+// versions are reported as VersionNotFound.
+func toVersionErr(err error, bucket, object, versionID string) error {
+	if err == errFileNotFound && versionID != "" {
+		return VersionNotFound{Bucket: bucket, Object: object, VersionID: versionID}
+	}
+	return toObjectErr(err, bucket, object)
+}
+
+// deleteEmptyDir - deletes the directory along with its parents if
+// they are empty, used to cleanup after moving objects around.
+func deleteEmptyDir(disk StorageAPI, volume, dirPath string) {
+	if dirPath == "." || dirPath == "" {
+		return
+	}
+	// Ignore errors, non empty directories are never deleted.
+	_ = disk.DeleteFile(volume, dirPath)
+}
+
+// prepareObjectVersion - prepares an object for a new version
+// identified by versionID, to be called with the object write lock
+// held. Current version of the object is archived unless the new
+// version replaces it, any existing "null" version is replaced when
+// versioning is suspended. Returns the updated versions which are
+// already persisted.
+func prepareObjectVersion(layer versionedObjects, bucket, object, versionID string) (versionsV1, error) {
+	versions, err := layer.readVersions(bucket, object)
+	if err != nil {
+		return versionsV1{}, err
+	}
+
+	if layer.isObject(bucket, object) {
+		objInfo, err := layer.getObjectInfo(bucket, object)
+		if err != nil {
+			return versionsV1{}, err
+		}
+		currentID := getVersionID(objInfo)
+		// Objects written before versioning was configured are not
+		// saved in `versions.json`, add them as the latest version.
+		if versions.Index(currentID) == -1 {
+			versions.AddVersion(versionInfo{
+				VersionID: currentID,
+				ModTime:   objInfo.ModTime,
+				Size:      objInfo.Size,
+				MD5Sum:    objInfo.MD5Sum,
+			})
+		}
+		if currentID == nullVersionID && versionID == nullVersionID {
+			// Current "null" version is replaced by the new version.
+			if err = layer.deleteObject(bucket, object); err != nil {
+				return versionsV1{}, err
+			}
+			versions.RemoveVersion(versions.Index(currentID))
+		} else if err = layer.archiveObject(bucket, object, currentID); err != nil {
+			return versionsV1{}, err
+		}
+	}
+
+	// With versioning suspended any archived "null" version is replaced.
+	if versionID == nullVersionID {
+		if index := versions.Index(nullVersionID); index != -1 {
+			if !versions.Versions[index].DeleteMarker {
+				if err = layer.deleteObjectVersion(bucket, object, nullVersionID); err != nil {
+					return versionsV1{}, err
+				}
+			}
+			versions.RemoveVersion(index)
+		}
+	}
+
+	// Save versions, so that archived versions are never left unreferenced.
+	if err = layer.writeVersions(bucket, object, versions); err != nil {
+		return versionsV1{}, err
+	}
+	return versions, nil
+}
+
+// commitObjectVersion - saves the newly written version as the latest
+// version of the object.
+func commitObjectVersion(layer versionedObjects, bucket, object string, versions versionsV1, version versionInfo) error {
+	versions.AddVersion(version)
+	return layer.writeVersions(bucket, object, versions)
+}
+
+// deleteObjectWithMarker - archives the current version of the object
+// and places a delete marker identified by versionID as the latest
+// version, to be called with the object write lock held. Returns the
+// info of the delete marker.
+func deleteObjectWithMarker(layer versionedObjects, bucket, object, versionID string) (ObjectInfo, error) {
+	versions, err := prepareObjectVersion(layer, bucket, object, versionID)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	marker := versionInfo{
+		VersionID:    versionID,
+		DeleteMarker: true,
+		ModTime:      time.Now().UTC(),
+	}
+	if err = commitObjectVersion(layer, bucket, object, versions, marker); err != nil {
+		return ObjectInfo{}, err
+	}
+	return ObjectInfo{
+		Bucket:       bucket,
+		Name:         object,
+		ModTime:      marker.ModTime,
+		VersionID:    versionID,
+		DeleteMarker: true,
+	}, nil
+}
+
+// removeObjectVersion - permanently removes a version of an object, to
+// be called with the object write lock held. If the latest version is
+// removed the next version if any becomes the current version.
+func removeObjectVersion(layer versionedObjects, bucket, object, versionID string) error {
+	versions, err := layer.readVersions(bucket, object)
+	if err != nil {
+		return err
+	}
+
+	isCurrent := false
+	if layer.isObject(bucket, object) {
+		objInfo, err := layer.getObjectInfo(bucket, object)
+		if err != nil {
+			return err
+		}
+		isCurrent = getVersionID(objInfo) == versionID
+	}
+
+	index := versions.Index(versionID)
+	if index == -1 && !isCurrent {
+		return errFileNotFound
+	}
+
+	if isCurrent {
+		if err = layer.deleteObject(bucket, object); err != nil {
+			return err
+		}
+	} else if !versions.Versions[index].DeleteMarker {
+		if err = layer.deleteObjectVersion(bucket, object, versionID); err != nil {
+			return err
+		}
+	}
+	if index != -1 {
+		versions.RemoveVersion(index)
+	}
+
+	// Promote the next version as the current version, unless it is a
+	// delete marker.
+	if (index == 0 || isCurrent) && len(versions.Versions) > 0 {
+		latest := versions.Versions[0]
+		if !latest.DeleteMarker && !layer.isObject(bucket, object) {
+			if err = layer.restoreObject(bucket, object, latest.VersionID); err != nil {
+				return err
+			}
+		}
+	}
+	return layer.writeVersions(bucket, object, versions)
+}
+
+// resolveObjectVersion - returns the object info of the requested
+// version, empty versionID resolves to the latest version. Returned
+// archived flag indicates if the version is not the current object.
+func resolveObjectVersion(layer versionedObjects, bucket, object, versionID string) (objInfo ObjectInfo, archived bool, err error) {
+	objInfo, err = layer.getObjectInfo(bucket, object)
+	if err == nil && (versionID == "" || getVersionID(objInfo) == versionID) {
+		objInfo.IsLatest = true
+		return objInfo, false, nil
+	}
+	if err != nil && err != errFileNotFound {
+		return ObjectInfo{}, false, err
+	}
+
+	versions, err := layer.readVersions(bucket, object)
+	if err != nil {
+		return ObjectInfo{}, false, err
+	}
+	index := versions.Index(versionID)
+	if versionID == "" && len(versions.Versions) > 0 {
+		// Latest version is not the current object, only possible
+		// when it is a delete marker.
+		index = 0
+	}
+	if index == -1 {
+		return ObjectInfo{}, false, errFileNotFound
+	}
+	version := versions.Versions[index]
+	if version.DeleteMarker {
+		return ObjectInfo{
+			Bucket:       bucket,
+			Name:         object,
+			ModTime:      version.ModTime,
+			VersionID:    version.VersionID,
+			DeleteMarker: true,
+			IsLatest:     index == 0,
+		}, true, nil
+	}
+	objInfo, err = layer.getArchivedObjectInfo(bucket, object, version.VersionID)
+	if err != nil {
+		return ObjectInfo{}, false, err
+	}
+	objInfo.VersionID = version.VersionID
+	objInfo.IsLatest = index == 0
+	return objInfo, true, nil
+}
+
+// listVersionsOfObject - lists all versions of an object latest first.
+func listVersionsOfObject(layer versionedObjects, bucket, object string, versioned bool) ([]ObjectInfo, error) {
+	var objInfos []ObjectInfo
+	var versions versionsV1
+	if versioned {
+		var err error
+		versions, err = layer.readVersions(bucket, object)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Current object is listed first if not saved in `versions.json`.
+	if layer.isObject(bucket, object) {
+		objInfo, err := layer.getObjectInfo(bucket, object)
+		if err != nil {
+			return nil, err
+		}
+		if versions.Index(getVersionID(objInfo)) == -1 {
+			objInfo.VersionID = getVersionID(objInfo)
+			objInfo.IsLatest = true
+			objInfos = append(objInfos, objInfo)
+		}
+	}
+	for _, version := range versions.Versions {
+		objInfos = append(objInfos, ObjectInfo{
+			Bucket:       bucket,
+			Name:         object,
+			ModTime:      version.ModTime,
+			Size:         version.Size,
+			MD5Sum:       version.MD5Sum,
+			VersionID:    version.VersionID,
+			DeleteMarker: version.DeleteMarker,
+			IsLatest:     len(objInfos) == 0,
+		})
+	}
+	return objInfos, nil
+}
+
+// nextWalkEntry - returns the next entry from a tree walk with the
+// prefix trimmed, returns an empty entry once the walk is exhausted.
+func nextWalkEntry(walkResultCh chan treeWalkResult, trimPrefix string) (string, error) {
+	walkResult, ok := <-walkResultCh
+	if !ok {
+		// Closed channel.
+		return "", nil
+	}
+	if walkResult.err != nil {
+		// File not found is a valid case.
+		if walkResult.err == errFileNotFound {
+			return "", nil
+		}
+		return "", walkResult.err
+	}
+	return strings.TrimPrefix(walkResult.entry, trimPrefix), nil
+}
+
+// listBucketVersions - lists all versions of all objects in the bucket,
+// merges the tree walk of current objects along with the tree walk of
+// objects saved in '.minio/versions/bucket/'.
+func listBucketVersions(layer versionedObjects, bucket, prefix, keyMarker, versionIDMarker, delimiter string, maxKeys int) (ListObjectVersionsInfo, error) {
+	if !IsValidObjectPrefix(prefix) {
+		return ListObjectVersionsInfo{}, ObjectNameInvalid{Bucket: bucket, Object: prefix}
+	}
+	// Verify if delimiter is anything other than '/', which we do not support.
+	if delimiter != "" && delimiter != slashSeparator {
+		return ListObjectVersionsInfo{}, UnsupportedDelimiter{
+			Delimiter: delimiter,
+		}
+	}
+	// Verify if key marker has prefix.
+	if keyMarker != "" && !strings.HasPrefix(keyMarker, prefix) {
+		return ListObjectVersionsInfo{}, InvalidMarkerPrefixCombination{
+			Marker: keyMarker,
+			Prefix: prefix,
+		}
+	}
+
+	// With max keys of zero we have reached eof, return right here.
+	if maxKeys == 0 {
+		return ListObjectVersionsInfo{}, nil
+	}
+
+	// For delimiter and prefix as '/' we do not list anything at all
+	// since according to s3 spec we stop at the 'delimiter'.
+	if delimiter == slashSeparator && prefix == slashSeparator {
+		return ListObjectVersionsInfo{}, nil
+	}
+
+	// Over flowing count - reset to maxObjectList.
+	if maxKeys < 0 || maxKeys > maxObjectList {
+		maxKeys = maxObjectList
+	}
+
+	result := ListObjectVersionsInfo{}
+
+	// Default is recursive, if delimiter is set then list non recursive.
+	recursive := true
+	if delimiter == slashSeparator {
+		recursive = false
+	}
+
+	objectsDoneCh := make(chan struct{})
+	defer close(objectsDoneCh)
+	objectsCh := layer.startTreeWalk(bucket, prefix, keyMarker, recursive, layer.isObject, objectsDoneCh)
+
+	// Not using path.Join() as it strips off the trailing '/'.
+	versionsPrefix := retainSlash(pathJoin(versionsMetaPrefix, bucket))
+	versionsPrefixPath := pathJoin(versionsMetaPrefix, bucket, prefix)
+	if prefix == "" {
+		versionsPrefixPath = versionsPrefix
+	}
+	versionsMarkerPath := ""
+	if keyMarker != "" {
+		versionsMarkerPath = pathJoin(versionsMetaPrefix, bucket, keyMarker)
+	}
+	versionsDoneCh := make(chan struct{})
+	defer close(versionsDoneCh)
+	versionsCh := layer.startTreeWalk(minioMetaBucket, versionsPrefixPath, versionsMarkerPath, recursive, layer.isVersionedObject, versionsDoneCh)
+
+	objectEntry, err := nextWalkEntry(objectsCh, "")
+	if err != nil {
+		return ListObjectVersionsInfo{}, err
+	}
+	versionsEntry, err := nextWalkEntry(versionsCh, versionsPrefix)
+	if err != nil {
+		return ListObjectVersionsInfo{}, err
+	}
+
+	// List remaining versions of the marker key first.
+	var pending []ObjectInfo
+	if keyMarker != "" && versionIDMarker != "" {
+		pending, err = listVersionsOfObject(layer, bucket, keyMarker, layer.isVersionedObject(minioMetaBucket, path.Join(versionsMetaPrefix, bucket, keyMarker)))
+		if err != nil {
+			return ListObjectVersionsInfo{}, err
+		}
+		for i, objInfo := range pending {
+			if objInfo.VersionID == versionIDMarker {
+				pending = pending[i+1:]
+				break
+			}
+		}
+	}
+
+	count := 0
+	for {
+		for len(pending) > 0 && count < maxKeys {
+			result.Objects = append(result.Objects, pending[0])
+			result.NextKeyMarker = pending[0].Name
+			result.NextVersionIDMarker = pending[0].VersionID
+			pending = pending[1:]
+			count++
+		}
+		if count == maxKeys {
+			result.IsTruncated = len(pending) > 0 || objectEntry != "" || versionsEntry != ""
+			break
+		}
+
+		// Pick the lexically smaller entry of both walks.
+		var entry string
+		isObject, isVersioned := false, false
+		switch {
+		case objectEntry == "" && versionsEntry == "":
+		case versionsEntry == "" || (objectEntry != "" && objectEntry < versionsEntry):
+			entry, isObject = objectEntry, true
+		case objectEntry == "" || versionsEntry < objectEntry:
+			entry, isVersioned = versionsEntry, true
+		default:
+			entry, isObject, isVersioned = objectEntry, true, true
+		}
+		if entry == "" {
+			break
+		}
+		if isObject {
+			if objectEntry, err = nextWalkEntry(objectsCh, ""); err != nil {
+				return ListObjectVersionsInfo{}, err
+			}
+		}
+		if isVersioned {
+			if versionsEntry, err = nextWalkEntry(versionsCh, versionsPrefix); err != nil {
+				return ListObjectVersionsInfo{}, err
+			}
+		}
+
+		// All directory entries are common prefixes.
+		if strings.HasSuffix(entry, slashSeparator) {
+			result.Prefixes = append(result.Prefixes, entry)
+			result.NextKeyMarker = entry
+			result.NextVersionIDMarker = ""
+			count++
+			continue
+		}
+		pending, err = listVersionsOfObject(layer, bucket, entry, isVersioned)
+		if err != nil {
+			return ListObjectVersionsInfo{}, err
+		}
+	}
+
+	// Result is not truncated, reset the markers.
+	if !result.IsTruncated {
+		result.NextKeyMarker = ""
+		result.NextVersionIDMarker = ""
+	}
+	return result, nil
+}
@@ -789,3 +789,82 @@ func (s *posix) RenameFile(srcVolume, srcPath, dstVolume, dstPath string) (err e
 	}
 	return nil
 }
+
+// LinkFile - creates destination path as a hard link of the source
+// path, the file is copied if it cannot be linked.
+func (s *posix) LinkFile(srcVolume, srcPath, dstVolume, dstPath string) (err error) {
+	defer func() {
+		if err == syscall.EIO {
+			atomic.AddInt32(&s.ioErrCount, 1)
+		}
+	}()
+
+	if s.ioErrCount > maxAllowedIOError {
+		return errFaultyDisk
+	}
+
+	// Validate if disk is free.
+	if err = checkDiskFree(s.diskPath, s.minFreeDisk); err != nil {
+		return err
+	}
+
+	srcVolumeDir, err := s.getVolDir(srcVolume)
+	if err != nil {
+		return err
+	}
+	dstVolumeDir, err := s.getVolDir(dstVolume)
+	if err != nil {
+		return err
+	}
+	// Stat a volume entry.
+	for _, volumeDir := range []string{srcVolumeDir, dstVolumeDir} {
+		if _, err = os.Stat(preparePath(volumeDir)); err != nil {
+			if os.IsNotExist(err) {
+				return errVolumeNotFound
+			}
+			return err
+		}
+	}
+
+	srcFilePath := slashpath.Join(srcVolumeDir, srcPath)
+	if err = checkPathLength(srcFilePath); err != nil {
+		return err
+	}
+	dstFilePath := slashpath.Join(dstVolumeDir, dstPath)
+	if err = checkPathLength(dstFilePath); err != nil {
+		return err
+	}
+	// Creates all the parent directories, with mode 0777 mkdir honors system umask.
+	if err = mkdirAll(preparePath(slashpath.Dir(dstFilePath)), 0777); err != nil {
+		// File path cannot be verified since one of the parents is a file.
+		if strings.Contains(err.Error(), "not a directory") {
+			return errFileAccessDenied
+		}
+		return err
+	}
+	if err = os.Link(preparePath(srcFilePath), preparePath(dstFilePath)); err == nil {
+		return nil
+	}
+	if os.IsNotExist(err) {
+		return errFileNotFound
+	}
+
+	// Hard links are not supported, copy the file instead.
+	srcFile, err := os.Open(preparePath(srcFilePath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errFileNotFound
+		}
+		return err
+	}
+	defer srcFile.Close()
+	dstFile, err := os.OpenFile(preparePath(dstFilePath), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(dstFile, srcFile); err != nil {
+		dstFile.Close()
+		return err
+	}
+	return dstFile.Close()
+}
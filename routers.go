@@ -32,18 +32,60 @@ func newObjectLayer(exportPaths []string) (ObjectLayer, error) {
 		return newFSObjects(exportPath)
 	}
 	// Initialize XL object layer.
-	objAPI, err := newXLObjects(exportPaths)
+	objAPI, err := newXLObjects(exportPaths, serverConfig.GetStorageClass())
 	if err == errXLWriteQuorum {
 		return objAPI, errors.New("Disks are different with last minio server run.")
 	}
 	return objAPI, err
 }
 
-// configureServer handler returns final handler for the http server.
-func configureServerHandler(srvCmdConfig serverCmdConfig) http.Handler {
+// startBackgroundWorkers - starts the workers applying bucket lifecycle
+// rules and healing XL objects in background, they return when doneCh
+// is closed.
+func startBackgroundWorkers(objAPI ObjectLayer, doneCh <-chan struct{}) {
+	// Apply bucket lifecycle rules in background.
+	go startLifecycleWorker(objAPI, lifecycleInterval, doneCh)
+
+	xl, ok := objAPI.(xlObjects)
+	if !ok {
+		return
+	}
+	// Heal objects found degraded on read in background.
+	go xl.healRoutine(doneCh)
+
+	// Scan XL objects for healing in background.
+	if hConfig := serverConfig.GetHeal(); !hConfig.Disable {
+		interval, delay, err := hConfig.getDurations()
+		fatalIf(err, "Unable to parse heal scanner configuration.")
+		rate, err := hConfig.getRate()
+		fatalIf(err, "Unable to parse heal scanner configuration.")
+		go xl.startHealScanner(interval, delay, rate, doneCh)
+	}
+}
+
+// newServerObjectLayer - initializes the object layer shared by the
+// API and website servers.
+func newServerObjectLayer(srvCmdConfig serverCmdConfig) ObjectLayer {
 	objAPI, err := newObjectLayer(srvCmdConfig.exportPaths)
 	fatalIf(err, "Unable to intialize object layer.")
 
+	// Load the master key for server side encryption, if configured.
+	globalSSEMasterKey, err = loadMasterKey(serverConfig.GetEncryption())
+	fatalIf(err, "Unable to load server side encryption master key.")
+
+	// Initialize event notifier.
+	err = initEventNotifier(objAPI)
+	fatalIf(err, "Unable to initialize event notification.")
+
+	// Start background workers, running until server shutdown.
+	if srvCmdConfig.doneCh != nil {
+		startBackgroundWorkers(objAPI, srvCmdConfig.doneCh)
+	}
+	return objAPI
+}
+
+// configureServer handler returns final handler for the http server.
+func configureServerHandler(srvCmdConfig serverCmdConfig, objAPI ObjectLayer) http.Handler {
 	// Initialize storage rpc server.
 	storageRPC, err := newRPCServer(srvCmdConfig.exportPaths[0]) // FIXME: should only have one path.
 	fatalIf(err, "Unable to initialize storage RPC server.")
@@ -53,6 +95,12 @@ func configureServerHandler(srvCmdConfig serverCmdConfig) http.Handler {
 		ObjectAPI: objAPI,
 	}
 
+	// Initialize admin API.
+	adminHandlers := adminAPIHandlers{
+		ObjectAPI: objAPI,
+		healTasks: newHealTasks(),
+	}
+
 	// Initialize Web.
 	webHandlers := &webAPIHandlers{
 		ObjectAPI: objAPI,
@@ -63,7 +111,9 @@ func configureServerHandler(srvCmdConfig serverCmdConfig) http.Handler {
 
 	// Register all routers.
 	registerStorageRPCRouter(mux, storageRPC)
+	registerAdminRouter(mux, adminHandlers)
 	registerWebRouter(mux, webHandlers)
+	registerWebsiteRouter(mux, apiHandlers)
 	registerAPIRouter(mux, apiHandlers)
 	// Add new routers here.
 
@@ -81,6 +131,8 @@ func configureServerHandler(srvCmdConfig serverCmdConfig) http.Handler {
 		setBrowserCacheControlHandler,
 		// Validates all incoming requests to have a valid date header.
 		setTimeValidityHandler,
+		// Rejects SSE-C requests over plain HTTP.
+		setSSETLSHandler,
 		// CORS setting for all browser API requests.
 		setCorsHandler,
 		// Validates all incoming URL resources, for invalid/unsupported
@@ -96,3 +148,19 @@ func configureServerHandler(srvCmdConfig serverCmdConfig) http.Handler {
 	// Register rest of the handlers.
 	return registerHandlers(mux, handlerFns...)
 }
+
+// configureWebsiteHandler returns final handler for the website server,
+// which serves nothing but the website endpoint.
+func configureWebsiteHandler(objAPI ObjectLayer) http.Handler {
+	// Initialize router.
+	mux := router.NewRouter()
+	mux.NewRoute().HandlerFunc(objectAPIHandlers{ObjectAPI: objAPI}.WebsiteHandler)
+
+	var handlerFns = []HandlerFunc{
+		// Limits the number of concurrent http requests.
+		setRateLimitHandler,
+		// CORS setting for website requests.
+		setCorsHandler,
+	}
+	return registerHandlers(mux, handlerFns...)
+}
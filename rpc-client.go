@@ -232,3 +232,17 @@ func (n networkStorage) RenameFile(srcVolume, srcPath, dstVolume, dstPath string
 	}
 	return nil
 }
+
+// LinkFile - Link file.
+func (n networkStorage) LinkFile(srcVolume, srcPath, dstVolume, dstPath string) (err error) {
+	reply := GenericReply{}
+	if err = n.rpcClient.Call("Storage.LinkFileHandler", LinkFileArgs{
+		SrcVol:  srcVolume,
+		SrcPath: srcPath,
+		DstVol:  dstVolume,
+		DstPath: dstPath,
+	}, &reply); err != nil {
+		return toStorageErr(err)
+	}
+	return nil
+}
@@ -105,3 +105,18 @@ type RenameFileArgs struct {
 	// Destination path of renamed file.
 	DstPath string
 }
+
+// LinkFileArgs represents link file RPC arguments.
+type LinkFileArgs struct {
+	// Name of source volume.
+	SrcVol string
+
+	// Source path to be linked.
+	SrcPath string
+
+	// Name of destination volume.
+	DstVol string
+
+	// Destination path of linked file.
+	DstPath string
+}
@@ -110,6 +110,11 @@ func (s *storageServer) RenameFileHandler(arg *RenameFileArgs, reply *GenericRep
 	return s.storage.RenameFile(arg.SrcVol, arg.SrcPath, arg.DstVol, arg.DstPath)
 }
 
+// LinkFileHandler - link file handler is rpc wrapper to link file.
+func (s *storageServer) LinkFileHandler(arg *LinkFileArgs, reply *GenericReply) error {
+	return s.storage.LinkFile(arg.SrcVol, arg.SrcPath, arg.DstVol, arg.DstPath)
+}
+
 // Initialize new storage rpc.
 func newRPCServer(exportPath string) (*storageServer, error) {
 	// Initialize posix storage API.
@@ -40,6 +40,10 @@ var serverCmd = cli.Command{
 			Name:  "address",
 			Value: ":9000",
 		},
+		cli.StringFlag{
+			Name:  "website-address",
+			Usage: "Serve buckets as static websites on this address.",
+		},
 	},
 	Action: serverMain,
 	CustomHelpTemplate: `NAME:
@@ -54,6 +58,8 @@ OPTIONS:
 ENVIRONMENT VARIABLES:
   MINIO_ACCESS_KEY: Access key string of 5 to 20 characters in length.
   MINIO_SECRET_KEY: Secret key string of 8 to 40 characters in length.
+  MINIO_DOMAIN: Domain under which buckets are addressed virtual host style, as <bucket>.<domain>.
+  MINIO_WEBSITE_DOMAIN: Domain under which buckets are served as static websites, as <bucket>.<domain>.
 
 EXAMPLES:
   1. Start minio server.
@@ -69,23 +75,29 @@ EXAMPLES:
       $ minio {{.Name}} /mnt/export1/backend /mnt/export2/backend /mnt/export3/backend /mnt/export4/backend \
           /mnt/export5/backend /mnt/export6/backend /mnt/export7/backend /mnt/export8/backend /mnt/export9/backend \
           /mnt/export10/backend /mnt/export11/backend /mnt/export12/backend
+
+  5. Start minio server serving buckets as static websites on port 9001.
+      $ minio {{.Name}} --website-address :9001 /home/shared
 `,
 }
 
 type serverCmdConfig struct {
 	serverAddr  string
 	exportPaths []string
+	// Background workers run until doneCh is closed, they are not
+	// started without it.
+	doneCh <-chan struct{}
 }
 
 // configureServer configure a new server instance
-func configureServer(srvCmdConfig serverCmdConfig) *http.Server {
+func configureServer(srvCmdConfig serverCmdConfig, objAPI ObjectLayer) *http.Server {
 	// Minio server config
 	apiServer := &http.Server{
 		Addr: srvCmdConfig.serverAddr,
 		// Adding timeout of 10 minutes for unresponsive client connections.
 		ReadTimeout:    10 * time.Minute,
 		WriteTimeout:   10 * time.Minute,
-		Handler:        configureServerHandler(srvCmdConfig),
+		Handler:        configureServerHandler(srvCmdConfig, objAPI),
 		MaxHeaderBytes: 1 << 20,
 	}
 
@@ -93,6 +105,18 @@ func configureServer(srvCmdConfig serverCmdConfig) *http.Server {
 	return apiServer
 }
 
+// configureWebsiteServer configures the website server instance,
+// sharing the object layer of the API server.
+func configureWebsiteServer(websiteAddr string, apiServer *http.Server, objAPI ObjectLayer) *http.Server {
+	return &http.Server{
+		Addr:           websiteAddr,
+		ReadTimeout:    apiServer.ReadTimeout,
+		WriteTimeout:   apiServer.WriteTimeout,
+		Handler:        configureWebsiteHandler(objAPI),
+		MaxHeaderBytes: apiServer.MaxHeaderBytes,
+	}
+}
+
 // getListenIPs - gets all the ips to listen on.
 func getListenIPs(httpServerConf *http.Server) (hosts []string, port string) {
 	host, port, err := net.SplitHostPort(httpServerConf.Addr)
@@ -159,6 +183,16 @@ func initServerConfig(c *cli.Context) {
 		})
 	}
 
+	// Fetch server domain from environment variable, overriding the
+	// domain in config.
+	globalServerDomain = serverConfig.GetDomain()
+	if domain := os.Getenv("MINIO_DOMAIN"); domain != "" {
+		globalServerDomain = domain
+	}
+
+	// Fetch website domain from environment variable.
+	globalWebsiteDomain = os.Getenv("MINIO_WEBSITE_DOMAIN")
+
 	// Set maxOpenFiles, This is necessary since default operating
 	// system limits of 1024, 2048 are not enough for Minio server.
 	setMaxOpenFiles()
@@ -273,14 +307,35 @@ func serverMain(c *cli.Context) {
 	// Check if requested port is available.
 	checkPortAvailability(getPort(net.JoinHostPort(host, port)))
 
+	// Website address, served by its own listener.
+	websiteAddress := c.String("website-address")
+	if websiteAddress != "" {
+		checkPortAvailability(getPort(websiteAddress))
+	}
+
 	// Save all command line args as export paths.
 	exportPaths := c.Args()
 
+	// Background workers are stopped when the server shuts down.
+	doneCh := make(chan struct{})
+	registerShutdown(func() {
+		close(doneCh)
+	})
+
 	// Configure server.
-	apiServer := configureServer(serverCmdConfig{
+	srvConfig := serverCmdConfig{
 		serverAddr:  serverAddress,
 		exportPaths: exportPaths,
-	})
+		doneCh:      doneCh,
+	}
+	objAPI := newServerObjectLayer(srvConfig)
+	apiServer := configureServer(srvConfig, objAPI)
+
+	// Configure website server, if requested.
+	var websiteServer *http.Server
+	if websiteAddress != "" {
+		websiteServer = configureWebsiteServer(websiteAddress, apiServer, objAPI)
+	}
 
 	// Credential.
 	cred := serverConfig.GetCredential()
@@ -302,6 +357,13 @@ func serverMain(c *cli.Context) {
 	// Print browser listen ips.
 	printListenIPs(tls, hosts, port)
 
+	if websiteServer != nil {
+		console.Println("\nMinio Website:")
+		// Print website listen ips.
+		websiteHosts, websitePort := getListenIPs(websiteServer)
+		printListenIPs(tls, websiteHosts, websitePort)
+	}
+
 	console.Println("\nTo configure Minio Client:")
 
 	// Figure out right endpoint for 'mc'.
@@ -320,6 +382,19 @@ func serverMain(c *cli.Context) {
 		console.Printf("    $ ./mc config host add myminio %s %s %s\n", endpoint, cred.AccessKeyID, cred.SecretAccessKey)
 	}
 
+	// Start website server.
+	if websiteServer != nil {
+		go func() {
+			var err error
+			if isSSL() {
+				err = websiteServer.ListenAndServeTLS(mustGetCertFile(), mustGetKeyFile())
+			} else {
+				err = websiteServer.ListenAndServe()
+			}
+			fatalIf(err, "Failed to start minio website server.")
+		}()
+	}
+
 	// Start server.
 	var err error
 	// Configure TLS if certs are available.
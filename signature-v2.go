@@ -0,0 +1,261 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// This file implements helper functions to validate AWS
+// Signature Version '2' authorization.
+//
+// - Based on Authorization header.
+// - Based on Query parameters.
+// - Based on Form POST policy.
+//
+// http://docs.aws.amazon.com/AmazonS3/latest/dev/RESTAuthentication.html
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AWS Signature Version '2' constants.
+const (
+	signV2Algorithm = "AWS"
+	// Maximum skew allowed between the request date and server time.
+	signV2MaxSkew = 15 * time.Minute
+)
+
+// Sub-resources which are part of the canonicalized resource, all
+// other query parameters are not signed.
+var resourceListV2 = []string{
+	"acl",
+	"cors",
+	"delete",
+	"encryption",
+	"lifecycle",
+	"location",
+	"logging",
+	"notification",
+	"partNumber",
+	"policy",
+	"requestPayment",
+	"response-cache-control",
+	"response-content-disposition",
+	"response-content-encoding",
+	"response-content-language",
+	"response-content-type",
+	"response-expires",
+	"restore",
+	"tagging",
+	"torrent",
+	"uploadId",
+	"uploads",
+	"versionId",
+	"versioning",
+	"versions",
+	"website",
+}
+
+// getSignatureV2 - returns the base64 encoded HMAC-SHA1 of the string
+// to sign with the secret key.
+func getSignatureV2(secretKey, stringToSign string) string {
+	hash := hmac.New(sha1.New, []byte(secretKey))
+	hash.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(hash.Sum(nil))
+}
+
+// getCanonicalizedAmzHeadersV2 - returns the lower cased x-amz-*
+// headers sorted by name, one header per line.
+func getCanonicalizedAmzHeadersV2(headers http.Header) string {
+	var keys []string
+	vals := make(map[string]string)
+	for k, vv := range headers {
+		lk := strings.ToLower(k)
+		if !strings.HasPrefix(lk, "x-amz-") {
+			continue
+		}
+		var trimmed []string
+		for _, v := range vv {
+			trimmed = append(trimmed, strings.TrimSpace(v))
+		}
+		keys = append(keys, lk)
+		vals[lk] = strings.Join(trimmed, ",")
+	}
+	sort.Strings(keys)
+	var canonicalHeaders []string
+	for _, k := range keys {
+		canonicalHeaders = append(canonicalHeaders, k+":"+vals[k])
+	}
+	return strings.Join(canonicalHeaders, "\n")
+}
+
+// getCanonicalizedResourceV2 - returns the encoded path followed by
+// the signed sub-resources of the request.
+func getCanonicalizedResourceV2(r *http.Request) string {
+	query := r.URL.Query()
+	var subResources []string
+	for _, resource := range resourceListV2 {
+		vv, ok := query[resource]
+		if !ok {
+			continue
+		}
+		if len(vv) == 0 || vv[0] == "" {
+			subResources = append(subResources, resource)
+			continue
+		}
+		subResources = append(subResources, resource+"="+vv[0])
+	}
+	// resourceListV2 is sorted, no need to sort sub-resources.
+	// Virtual host style requests are signed with the bucket in the
+	// resource.
+	resource := getURLEncodedName(getPathStyleURL(r).Path)
+	if resource == "" {
+		resource = "/"
+	}
+	if len(subResources) > 0 {
+		resource += "?" + strings.Join(subResources, "&")
+	}
+	return resource
+}
+
+// getStringToSignV2 - returns the string to sign of the request, date
+// is the Date header or the expiry of presigned requests.
+func getStringToSignV2(r *http.Request, date string) string {
+	canonicalHeaders := getCanonicalizedAmzHeadersV2(r.Header)
+	if canonicalHeaders != "" {
+		canonicalHeaders += "\n"
+	}
+	return strings.Join([]string{
+		r.Method,
+		r.Header.Get("Content-Md5"),
+		r.Header.Get("Content-Type"),
+		date,
+		canonicalHeaders,
+	}, "\n") + getCanonicalizedResourceV2(r)
+}
+
+// doesSignatureV2Match - Verify authorization header with calculated header in accordance with
+//     - http://docs.aws.amazon.com/AmazonS3/latest/dev/RESTAuthentication.html
+// returns ErrNone if matches. Payload is not signed, Content-Md5 is.
+func doesSignatureV2Match(r *http.Request) APIErrorCode {
+	// Access credentials.
+	cred := serverConfig.GetCredential()
+
+	// Parse signature version '2' header, of form
+	// "AWS AccessKeyId:Signature".
+	v2Auth := strings.TrimPrefix(r.Header.Get("Authorization"), signV2Algorithm+" ")
+	authFields := strings.Split(v2Auth, ":")
+	if len(authFields) != 2 || authFields[0] == "" || authFields[1] == "" {
+		return ErrAuthorizationHeaderMalformed
+	}
+
+	// Verify if the access key id matches.
+	if authFields[0] != cred.AccessKeyID {
+		return ErrInvalidAccessKeyID
+	}
+
+	// Either date header is required to sign the request, reject
+	// requests dated outside of the allowed skew.
+	if r.Header.Get("Date") == "" && r.Header.Get("X-Amz-Date") == "" {
+		return ErrMissingDateHeader
+	}
+	date, apiErr := parseAmzDateHeader(r)
+	if apiErr != ErrNone {
+		return apiErr
+	}
+	if skew := time.Now().UTC().Sub(date); skew > signV2MaxSkew || skew < -signV2MaxSkew {
+		return ErrRequestTimeTooSkewed
+	}
+
+	// Verify signature, the Date line is empty when X-Amz-Date is
+	// sent, it is signed with the canonicalized amz headers.
+	signedDate := r.Header.Get("Date")
+	if r.Header.Get("X-Amz-Date") != "" {
+		signedDate = ""
+	}
+	stringToSign := getStringToSignV2(r, signedDate)
+	if authFields[1] != getSignatureV2(cred.SecretAccessKey, stringToSign) {
+		return ErrSignatureDoesNotMatch
+	}
+	return ErrNone
+}
+
+// doesPresignedSignatureV2Match - Verify query headers with presigned signature
+//     - http://docs.aws.amazon.com/AmazonS3/latest/dev/RESTAuthentication.html#RESTAuthenticationQueryStringAuth
+// returns ErrNone if matches.
+func doesPresignedSignatureV2Match(r *http.Request) APIErrorCode {
+	// Access credentials.
+	cred := serverConfig.GetCredential()
+
+	query := r.URL.Query()
+	accessKey := query.Get("AWSAccessKeyId")
+	signature := query.Get("Signature")
+	expires := query.Get("Expires")
+	if accessKey == "" || signature == "" || expires == "" {
+		return ErrInvalidQueryParams
+	}
+
+	// Verify if the access key id matches.
+	if accessKey != cred.AccessKeyID {
+		return ErrInvalidAccessKeyID
+	}
+
+	// Expires is the expiry time in seconds since epoch.
+	expiresInt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || expiresInt <= 0 {
+		return ErrMalformedExpires
+	}
+	if time.Now().UTC().Unix() > expiresInt {
+		return ErrExpiredPresignRequest
+	}
+
+	// Verify signature.
+	stringToSign := getStringToSignV2(r, expires)
+	if signature != getSignatureV2(cred.SecretAccessKey, stringToSign) {
+		return ErrSignatureDoesNotMatch
+	}
+	return ErrNone
+}
+
+// isPolicySignatureV2 - returns true if the form is signed with
+// signature version '2'.
+func isPolicySignatureV2(formValues map[string]string) bool {
+	_, ok := formValues["Awsaccesskeyid"]
+	return ok
+}
+
+// doesPolicySignatureV2Match - Verify form values with post policy
+//     - http://docs.aws.amazon.com/AmazonS3/latest/dev/HTTPPOSTForms.html
+// returns ErrNone if matches.
+func doesPolicySignatureV2Match(formValues map[string]string) APIErrorCode {
+	// Access credentials.
+	cred := serverConfig.GetCredential()
+
+	// Verify if the access key id matches.
+	if formValues["Awsaccesskeyid"] != cred.AccessKeyID {
+		return ErrInvalidAccessKeyID
+	}
+
+	// Policy is signed as is, base64 encoded.
+	if formValues["Signature"] != getSignatureV2(cred.SecretAccessKey, formValues["Policy"]) {
+		return ErrSignatureDoesNotMatch
+	}
+	return ErrNone
+}
@@ -0,0 +1,228 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// used to formulate HTTP v2 signed HTTP request.
+func newTestRequestV2(method, urlStr string, body []byte, accessKey, secretKey string) (*http.Request, error) {
+	req, err := http.NewRequest(method, urlStr, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	if len(body) > 0 {
+		req.Header.Set("Content-Md5", base64.StdEncoding.EncodeToString(sumMD5(body)))
+	}
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("X-Amz-Meta-Test", "v2")
+	signature := getSignatureV2(secretKey, getStringToSignV2(req, req.Header.Get("Date")))
+	req.Header.Set("Authorization", signV2Algorithm+" "+accessKey+":"+signature)
+	return req, nil
+}
+
+// used to formulate HTTP v2 presigned HTTP request.
+func newPresignedTestRequestV2(method, urlStr string, expires time.Time, accessKey, secretKey string) (*http.Request, error) {
+	req, err := http.NewRequest(method, urlStr, nil)
+	if err != nil {
+		return nil, err
+	}
+	expiresStr := strconv.FormatInt(expires.Unix(), 10)
+	signature := getSignatureV2(secretKey, getStringToSignV2(req, expiresStr))
+	query := req.URL.Query()
+	query.Set("AWSAccessKeyId", accessKey)
+	query.Set("Expires", expiresStr)
+	query.Set("Signature", signature)
+	req.URL.RawQuery = query.Encode()
+	return req, nil
+}
+
+// used to formulate HTTP v2 signed POST policy request.
+func newPostPolicyTestRequestV2(urlStr, bucket, object string, data []byte, accessKey, secretKey string) (*http.Request, error) {
+	policy := fmt.Sprintf(`{"expiration":"%s","conditions":[["eq","$bucket","%s"],["starts-with","$key","%s"]]}`,
+		time.Now().UTC().Add(10*time.Minute).Format(time.RFC3339Nano), bucket, object)
+	encodedPolicy := base64.StdEncoding.EncodeToString([]byte(policy))
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	formValues := map[string]string{
+		"AWSAccessKeyId": accessKey,
+		"key":            object,
+		"policy":         encodedPolicy,
+		"signature":      getSignatureV2(secretKey, encodedPolicy),
+	}
+	for name, value := range formValues {
+		if err := writer.WriteField(name, value); err != nil {
+			return nil, err
+		}
+	}
+	filePart, err := writer.CreateFormFile("file", object)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = filePart.Write(data); err != nil {
+		return nil, err
+	}
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", urlStr, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req, nil
+}
+
+// Tests the canonicalized resource includes only signed sub-resources.
+func TestCanonicalizedResourceV2(t *testing.T) {
+	testCases := []struct {
+		urlStr   string
+		resource string
+	}{
+		{"http://localhost/bucket/object", "/bucket/object"},
+		{"http://localhost/bucket?acl", "/bucket?acl"},
+		{"http://localhost/bucket/object?uploadId=abc&partNumber=1", "/bucket/object?partNumber=1&uploadId=abc"},
+		{"http://localhost/bucket?prefix=photos&max-keys=10", "/bucket"},
+		{"http://localhost/bucket/object?AWSAccessKeyId=a&Expires=1&Signature=s", "/bucket/object"},
+	}
+	for i, testCase := range testCases {
+		req, err := http.NewRequest("GET", testCase.urlStr, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resource := getCanonicalizedResourceV2(req); resource != testCase.resource {
+			t.Fatalf("Test %d: Expected %s, got %s", i+1, testCase.resource, resource)
+		}
+	}
+}
+
+// Tests requests signed with signature version '2'.
+func TestSignatureV2Handlers(t *testing.T) {
+	testServer := StartTestServer(t, "FS")
+	defer testServer.Stop()
+
+	data := []byte("hello, signature v2")
+	client := http.Client{}
+	expectStatus := func(req *http.Request, status int) []byte {
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		respBody, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != status {
+			t.Fatalf("%s %s: Expected status %d, got %d: %s", req.Method, req.URL, status, resp.StatusCode, respBody)
+		}
+		return respBody
+	}
+	newRequest := func(method, urlStr string, body []byte, secretKey string) *http.Request {
+		req, err := newTestRequestV2(method, urlStr, body, testServer.AccessKey, secretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+
+	bucketURL := testServer.Server.URL + "/v2-bucket"
+	objectURL := bucketURL + "/object"
+	expectStatus(newRequest("PUT", bucketURL, nil, testServer.SecretKey), http.StatusOK)
+	expectStatus(newRequest("PUT", objectURL, data, testServer.SecretKey), http.StatusOK)
+	expectStatus(newRequest("PUT", objectURL, data, "wrong-secret-key"), http.StatusForbidden)
+	if respData := expectStatus(newRequest("GET", objectURL, nil, testServer.SecretKey), http.StatusOK); !bytes.Equal(respData, data) {
+		t.Fatalf("Expected %s, got %s", data, respData)
+	}
+	expectStatus(newRequest("GET", bucketURL+"?location", nil, testServer.SecretKey), http.StatusOK)
+	expectStatus(newRequest("GET", testServer.Server.URL, nil, testServer.SecretKey), http.StatusOK)
+
+	// Signed headers cannot be modified.
+	req := newRequest("GET", objectURL, nil, testServer.SecretKey)
+	req.Header.Set("X-Amz-Meta-Test", "modified")
+	expectStatus(req, http.StatusForbidden)
+
+	// Requests dated outside of the allowed skew are rejected.
+	for _, skew := range []time.Duration{-signV2MaxSkew - time.Minute, signV2MaxSkew + time.Minute} {
+		req = newRequest("GET", objectURL, nil, testServer.SecretKey)
+		req.Header.Set("Date", time.Now().UTC().Add(skew).Format(http.TimeFormat))
+		signature := getSignatureV2(testServer.SecretKey, getStringToSignV2(req, req.Header.Get("Date")))
+		req.Header.Set("Authorization", signV2Algorithm+" "+testServer.AccessKey+":"+signature)
+		expectStatus(req, http.StatusForbidden)
+	}
+
+	// Requests with X-Amz-Date sign an empty Date line.
+	req = newRequest("GET", objectURL, nil, testServer.SecretKey)
+	req.Header.Set("X-Amz-Date", time.Now().UTC().Format(http.TimeFormat))
+	signature := getSignatureV2(testServer.SecretKey, getStringToSignV2(req, ""))
+	req.Header.Set("Authorization", signV2Algorithm+" "+testServer.AccessKey+":"+signature)
+	if respData := expectStatus(req, http.StatusOK); !bytes.Equal(respData, data) {
+		t.Fatalf("Expected %s, got %s", data, respData)
+	}
+
+	// Presigned requests.
+	req, err := newPresignedTestRequestV2("GET", objectURL, time.Now().Add(time.Minute), testServer.AccessKey, testServer.SecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if respData := expectStatus(req, http.StatusOK); !bytes.Equal(respData, data) {
+		t.Fatalf("Expected %s, got %s", data, respData)
+	}
+	req, err = newPresignedTestRequestV2("GET", objectURL, time.Now().Add(-time.Minute), testServer.AccessKey, testServer.SecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(req, http.StatusBadRequest)
+	req, err = newPresignedTestRequestV2("GET", objectURL, time.Now().Add(time.Minute), testServer.AccessKey, "wrong-secret-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(req, http.StatusForbidden)
+
+	// POST policy.
+	req, err = newPostPolicyTestRequestV2(bucketURL, "v2-bucket", "posted", data, testServer.AccessKey, testServer.SecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(req, http.StatusOK)
+	if respData := expectStatus(newRequest("GET", bucketURL+"/posted", nil, testServer.SecretKey), http.StatusOK); !bytes.Equal(respData, data) {
+		t.Fatalf("Expected %s, got %s", data, respData)
+	}
+	req, err = newPostPolicyTestRequestV2(bucketURL, "v2-bucket", "posted", data, testServer.AccessKey, "wrong-secret-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(req, http.StatusForbidden)
+
+	// Anonymous requests still go through the bucket policy.
+	req, err = http.NewRequest("GET", objectURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(req, http.StatusForbidden)
+}
@@ -153,7 +153,8 @@ func parsePostPolicyFormV4(policy string) (PostPolicyForm, error) {
 
 // checkPostPolicy - apply policy conditions and validate input values.
 func checkPostPolicy(formValues map[string]string) APIErrorCode {
-	if formValues["X-Amz-Algorithm"] != signV4Algorithm {
+	// Signature version '2' forms have no algorithm.
+	if formValues["X-Amz-Algorithm"] != signV4Algorithm && !isPolicySignatureV2(formValues) {
 		return ErrSignatureVersionNotSupported
 	}
 	/// Decoding policy
@@ -41,6 +41,7 @@ const (
 	signV4Algorithm = "AWS4-HMAC-SHA256"
 	iso8601Format   = "20060102T150405Z"
 	yyyymmdd        = "20060102"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
 )
 
 // getCanonicalHeaders generate a list of request headers with their values
@@ -236,7 +237,7 @@ func doesPresignedSignatureMatch(hashedPayload string, r *http.Request, validate
 	if req.URL.Query().Get("X-Amz-Content-Sha256") != "" {
 		query.Set("X-Amz-Content-Sha256", hashedPayload)
 	} else {
-		hashedPayload = "UNSIGNED-PAYLOAD"
+		hashedPayload = unsignedPayload
 	}
 	query.Set("X-Amz-Algorithm", signV4Algorithm)
 
@@ -385,3 +386,22 @@ func doesSignatureMatch(hashedPayload string, r *http.Request, validateRegion bo
 	}
 	return ErrNone
 }
+
+// signRequestV4 - signs a request without payload with signature
+// version '4', used by minio commands talking to the server.
+func signRequestV4(req *http.Request, accessKey, secretKey, region string) {
+	t := time.Now().UTC()
+	hashedPayload := hex.EncodeToString(sum256([]byte{}))
+	req.Header.Set("X-Amz-Date", t.Format(iso8601Format))
+	req.Header.Set("X-Amz-Content-Sha256", hashedPayload)
+
+	signedHeaders := http.Header{
+		"X-Amz-Date":           req.Header["X-Amz-Date"],
+		"X-Amz-Content-Sha256": req.Header["X-Amz-Content-Sha256"],
+	}
+	canonicalRequest := getCanonicalRequest(signedHeaders, hashedPayload, req.URL.Query().Encode(), req.URL.Path, req.Method, req.URL.Host)
+	stringToSign := getStringToSign(canonicalRequest, t, region)
+	signature := getSignature(getSigningKey(secretKey, t, region), stringToSign)
+	req.Header.Set("Authorization", signV4Algorithm+" Credential="+accessKey+"/"+getScope(t, region)+
+		", SignedHeaders="+getSignedHeaders(signedHeaders)+", Signature="+signature)
+}
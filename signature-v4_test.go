@@ -0,0 +1,138 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// used to formulate HTTP v4 signed HTTP request with unsigned payload.
+func newTestUnsignedPayloadRequest(method, urlStr string, data []byte, accessKey, secretKey string) (*http.Request, error) {
+	req, err := http.NewRequest(method, urlStr, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(data))
+	t := time.Now().UTC()
+	region := "us-east-1"
+	req.Header.Set("X-Amz-Date", t.Format(iso8601Format))
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	signedHeaders := extractSignedHeaders([]string{"x-amz-content-sha256", "x-amz-date"}, req.Header)
+	canonicalRequest := getCanonicalRequest(signedHeaders, unsignedPayload, req.URL.Query().Encode(), req.URL.Path, method, req.URL.Host)
+	signature := getSignature(getSigningKey(secretKey, t, region), getStringToSign(canonicalRequest, t, region))
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signV4Algorithm, accessKey, getScope(t, region), getSignedHeaders(signedHeaders), signature))
+	return req, nil
+}
+
+// Tests uploading objects and parts with unsigned payload.
+func TestUnsignedPayloadHandlers(t *testing.T) {
+	testServer := StartTestServer(t, "FS")
+	defer testServer.Stop()
+
+	data := bytes.Repeat([]byte("unsigned"), 10000)
+	client := http.Client{}
+	expectStatus := func(req *http.Request, status int) ([]byte, http.Header) {
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		respBody, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != status {
+			t.Fatalf("%s %s: Expected status %d, got %d: %s", req.Method, req.URL, status, resp.StatusCode, respBody)
+		}
+		return respBody, resp.Header
+	}
+	newRequest := func(method, urlStr string, body []byte) *http.Request {
+		req, err := newTestRequest(method, urlStr, int64(len(body)), bytes.NewReader(body), testServer.AccessKey, testServer.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+	newUnsignedRequest := func(method, urlStr string, body []byte, secretKey string) *http.Request {
+		req, err := newTestUnsignedPayloadRequest(method, urlStr, body, testServer.AccessKey, secretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+
+	// Only object uploads accept an unsigned payload.
+	bucketURL := testServer.Server.URL + "/unsigned-bucket"
+	expectStatus(newUnsignedRequest("PUT", bucketURL, nil, testServer.SecretKey), http.StatusForbidden)
+	expectStatus(newRequest("PUT", bucketURL, nil), http.StatusOK)
+	policyBytes := []byte(`{"Version":"2012-10-17","Statement":[]}`)
+	expectStatus(newUnsignedRequest("PUT", bucketURL+"?policy", policyBytes, testServer.SecretKey), http.StatusForbidden)
+
+	expectStatus(newUnsignedRequest("PUT", bucketURL+"/object", data, testServer.SecretKey), http.StatusOK)
+	if respData, _ := expectStatus(newRequest("GET", bucketURL+"/object", nil), http.StatusOK); !bytes.Equal(respData, data) {
+		t.Fatal("Uploaded object does not match")
+	}
+	expectStatus(newUnsignedRequest("PUT", bucketURL+"/wrong-key", data, "wrong-secret-key"), http.StatusForbidden)
+	expectStatus(newRequest("HEAD", bucketURL+"/wrong-key", nil), http.StatusNotFound)
+
+	// Signed headers cannot be modified.
+	req := newUnsignedRequest("PUT", bucketURL+"/modified", data, testServer.SecretKey)
+	req.Header.Set("X-Amz-Date", time.Now().UTC().Add(time.Second).Format(iso8601Format))
+	expectStatus(req, http.StatusForbidden)
+
+	// Parts are uploaded the same way.
+	respData, _ := expectStatus(newRequest("POST", bucketURL+"/multipart?uploads", nil), http.StatusOK)
+	var initResponse InitiateMultipartUploadResponse
+	if err := xml.Unmarshal(respData, &initResponse); err != nil {
+		t.Fatal(err)
+	}
+	partURL := bucketURL + "/multipart?partNumber=1&uploadId=" + initResponse.UploadID
+	expectStatus(newUnsignedRequest("PUT", partURL, data, "wrong-secret-key"), http.StatusForbidden)
+	// Unauthenticated requests cannot tell whether an upload exists.
+	missingPartURL := bucketURL + "/multipart?partNumber=1&uploadId=missing-upload"
+	expectStatus(newUnsignedRequest("PUT", missingPartURL, data, "wrong-secret-key"), http.StatusForbidden)
+	req, err := newTestRequest("PUT", missingPartURL, int64(len(data)), bytes.NewReader(data), testServer.AccessKey, "wrong-secret-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(req, http.StatusForbidden)
+	anonReq, err := http.NewRequest("PUT", missingPartURL, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(anonReq, http.StatusForbidden)
+	expectStatus(newUnsignedRequest("PUT", missingPartURL, data, testServer.SecretKey), http.StatusNotFound)
+	_, respHeader := expectStatus(newUnsignedRequest("PUT", partURL, data, testServer.SecretKey), http.StatusOK)
+	completeBytes, err := xml.Marshal(completeMultipartUpload{
+		Parts: []completePart{{PartNumber: 1, ETag: respHeader.Get("ETag")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(newRequest("POST", bucketURL+"/multipart?uploadId="+initResponse.UploadID, completeBytes), http.StatusOK)
+	if respData, _ = expectStatus(newRequest("GET", bucketURL+"/multipart", nil), http.StatusOK); !bytes.Equal(respData, data) {
+		t.Fatal("Uploaded part does not match")
+	}
+}
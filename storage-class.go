@@ -0,0 +1,101 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import "net/http"
+
+// amzStorageClass - header carrying the storage class of an object on
+// PUT, copy and multipart initiate, the storage class is saved in
+// object metadata under the same key. Objects without it are stored
+// as STANDARD.
+const amzStorageClass = "X-Amz-Storage-Class"
+
+// Supported storage classes.
+const (
+	standardStorageClass          = "STANDARD"
+	reducedRedundancyStorageClass = "REDUCED_REDUNDANCY"
+)
+
+// Parity disks of REDUCED_REDUNDANCY, unless configured.
+const defaultRRSParity = 2
+
+// storageClassConfig - parity disks of each storage class on XL, zero
+// picks the default of half the disks for STANDARD and two disks for
+// REDUCED_REDUNDANCY.
+type storageClassConfig struct {
+	Standard          int `json:"standard"`
+	ReducedRedundancy int `json:"reducedRedundancy"`
+}
+
+// getParity - returns the parity disks of each storage class for the
+// disk count. Parity should be at least one disk and at most half the
+// disks, REDUCED_REDUNDANCY cannot have more parity than STANDARD.
+func (s storageClassConfig) getParity(diskCount int) (standardParity, rrsParity int, err error) {
+	standardParity, rrsParity = s.Standard, s.ReducedRedundancy
+	if standardParity == 0 {
+		standardParity = diskCount / 2
+	}
+	if rrsParity == 0 {
+		rrsParity = defaultRRSParity
+		if rrsParity > standardParity {
+			rrsParity = standardParity
+		}
+	}
+	if standardParity < 1 || standardParity > diskCount/2 {
+		return 0, 0, errInvalidArgument
+	}
+	if rrsParity < 1 || rrsParity > standardParity {
+		return 0, 0, errInvalidArgument
+	}
+	return standardParity, rrsParity, nil
+}
+
+// isValidStorageClass - returns true if x-amz-storage-class is either
+// unset or a supported storage class.
+func isValidStorageClass(header http.Header) bool {
+	switch header.Get(amzStorageClass) {
+	case "", standardStorageClass, reducedRedundancyStorageClass:
+		return true
+	}
+	return false
+}
+
+// getStorageClass - returns the storage class saved in object
+// metadata.
+func getStorageClass(metadata map[string]string) string {
+	if storageClass := metadata[amzStorageClass]; storageClass != "" {
+		return storageClass
+	}
+	return standardStorageClass
+}
+
+// setObjectStorageClass - saves the storage class sent in the request
+// in object metadata, STANDARD is not saved.
+func setObjectStorageClass(metadata map[string]string, header http.Header) {
+	delete(metadata, amzStorageClass)
+	if storageClass := header.Get(amzStorageClass); storageClass != "" && storageClass != standardStorageClass {
+		metadata[amzStorageClass] = storageClass
+	}
+}
+
+// setStorageClassHeaders - sets the storage class of objects not
+// stored as STANDARD.
+func setStorageClassHeaders(w http.ResponseWriter, metadata map[string]string) {
+	if storageClass := getStorageClass(metadata); storageClass != standardStorageClass {
+		w.Header().Set(amzStorageClass, storageClass)
+	}
+}
@@ -0,0 +1,208 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Tests parity of each storage class.
+func TestStorageClassParity(t *testing.T) {
+	testCases := []struct {
+		config         storageClassConfig
+		diskCount      int
+		standardParity int
+		rrsParity      int
+		expectErr      bool
+	}{
+		{storageClassConfig{}, 16, 8, 2, false},
+		{storageClassConfig{}, 6, 3, 2, false},
+		{storageClassConfig{Standard: 4, ReducedRedundancy: 1}, 16, 4, 1, false},
+		{storageClassConfig{Standard: 1}, 8, 1, 1, false},
+		{storageClassConfig{Standard: 9}, 16, 0, 0, true},
+		{storageClassConfig{Standard: -1}, 16, 0, 0, true},
+		{storageClassConfig{Standard: 2, ReducedRedundancy: 3}, 16, 0, 0, true},
+		{storageClassConfig{ReducedRedundancy: -2}, 16, 0, 0, true},
+	}
+	for i, testCase := range testCases {
+		standardParity, rrsParity, err := testCase.config.getParity(testCase.diskCount)
+		if testCase.expectErr {
+			if err == nil {
+				t.Fatalf("Test %d: Expected error", i+1)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Test %d: %s", i+1, err)
+		}
+		if standardParity != testCase.standardParity || rrsParity != testCase.rrsParity {
+			t.Fatalf("Test %d: Expected parity %d, %d, got %d, %d", i+1, testCase.standardParity, testCase.rrsParity, standardParity, rrsParity)
+		}
+	}
+}
+
+// Tests erasure coding objects as their storage class.
+func TestXLStorageClass(t *testing.T) {
+	objLayer, disks, err := getXLObjectLayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(disks)
+	xl := objLayer.(xlObjects)
+
+	bucket := "storage-class-bucket"
+	if err = objLayer.MakeBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("hello, storage class")
+	rrsMeta := map[string]string{amzStorageClass: reducedRedundancyStorageClass}
+	expectParity := func(object string, parityBlocks int) {
+		for index, disk := range xl.storageDisks {
+			xlMeta, rErr := readXLMeta(disk, bucket, object)
+			if rErr != nil {
+				t.Fatalf("Disk %d: %s: %s", index, object, rErr)
+			}
+			if xlMeta.Erasure.ParityBlocks != parityBlocks || xlMeta.Erasure.DataBlocks != len(disks)-parityBlocks {
+				t.Fatalf("Disk %d: %s: Expected %d parity blocks, got %d data and %d parity blocks", index, object, parityBlocks, xlMeta.Erasure.DataBlocks, xlMeta.Erasure.ParityBlocks)
+			}
+		}
+	}
+	expectData := func(object string) {
+		var buffer bytes.Buffer
+		if gErr := objLayer.GetObject(bucket, object, 0, int64(len(data)), &buffer); gErr != nil {
+			t.Fatalf("%s: %s", object, gErr)
+		}
+		if !bytes.Equal(buffer.Bytes(), data) {
+			t.Fatalf("%s: Object data does not match", object)
+		}
+	}
+
+	// Reduced redundancy object survives as many disks as its parity.
+	if _, err = objLayer.PutObject(bucket, "rrs", int64(len(data)), bytes.NewReader(data), rrsMeta); err != nil {
+		t.Fatal(err)
+	}
+	expectParity("rrs", defaultRRSParity)
+	for _, disk := range disks[:defaultRRSParity] {
+		if err = os.RemoveAll(filepath.Join(disk, bucket, "rrs")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectData("rrs")
+	healed, err := xl.healObject(bucket, "rrs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if healed != defaultRRSParity {
+		t.Fatalf("Expected %d disks to be healed, got %d", defaultRRSParity, healed)
+	}
+	expectParity("rrs", defaultRRSParity)
+
+	// Standard objects are unchanged.
+	if _, err = objLayer.PutObject(bucket, "standard", int64(len(data)), bytes.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+	expectParity("standard", len(disks)/2)
+
+	// Multipart uploads are erasure coded as the storage class.
+	uploadID, err := objLayer.NewMultipartUpload(bucket, "multipart", map[string]string{amzStorageClass: reducedRedundancyStorageClass})
+	if err != nil {
+		t.Fatal(err)
+	}
+	md5Hex, err := objLayer.PutObjectPart(bucket, "multipart", uploadID, 1, int64(len(data)), bytes.NewReader(data), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = objLayer.CompleteMultipartUpload(bucket, "multipart", uploadID, []completePart{{PartNumber: 1, ETag: md5Hex}}); err != nil {
+		t.Fatal(err)
+	}
+	expectParity("multipart", defaultRRSParity)
+	expectData("multipart")
+
+	// Copies to another storage class are erasure coded again, md5sum
+	// of the source is preserved.
+	srcInfo, err := objLayer.GetObjectInfo(bucket, "multipart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	copyInfo, err := objLayer.CopyObject(bucket, "multipart", bucket, "copy", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if copyInfo.MD5Sum != srcInfo.MD5Sum {
+		t.Fatalf("Expected md5sum %s, got %s", srcInfo.MD5Sum, copyInfo.MD5Sum)
+	}
+	expectParity("copy", len(disks)/2)
+	expectData("copy")
+	if _, err = objLayer.CopyObject(bucket, "copy", bucket, "copy", rrsMeta); err != nil {
+		t.Fatal(err)
+	}
+	expectParity("copy", defaultRRSParity)
+	expectData("copy")
+	if _, err = objLayer.CopyObject(bucket, "copy", bucket, "rrs-copy", rrsMeta); err != nil {
+		t.Fatal(err)
+	}
+	expectParity("rrs-copy", defaultRRSParity)
+	expectData("rrs-copy")
+}
+
+// Tests the storage class header.
+func TestStorageClassHeader(t *testing.T) {
+	testServer := StartTestServer(t, "XL")
+	defer testServer.Stop()
+
+	expectStatus := func(method, urlStr string, body []byte, storageClass string, status int) http.Header {
+		req, err := newTestRequest(method, urlStr, int64(len(body)), bytes.NewReader(body), testServer.AccessKey, testServer.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if storageClass != "" {
+			req.Header.Set(amzStorageClass, storageClass)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		respBody, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != status {
+			t.Fatalf("%s %s: Expected status %d, got %d: %s", method, urlStr, status, resp.StatusCode, respBody)
+		}
+		return resp.Header
+	}
+
+	bucketURL := testServer.Server.URL + "/storage-class-bucket"
+	data := []byte("hello, storage class")
+	expectStatus("PUT", bucketURL, nil, "", http.StatusOK)
+	expectStatus("PUT", bucketURL+"/rrs", data, reducedRedundancyStorageClass, http.StatusOK)
+	if storageClass := expectStatus("HEAD", bucketURL+"/rrs", nil, "", http.StatusOK).Get(amzStorageClass); storageClass != reducedRedundancyStorageClass {
+		t.Fatalf("Expected storage class %s, got %q", reducedRedundancyStorageClass, storageClass)
+	}
+	expectStatus("PUT", bucketURL+"/standard", data, standardStorageClass, http.StatusOK)
+	if storageClass := expectStatus("HEAD", bucketURL+"/standard", nil, "", http.StatusOK).Get(amzStorageClass); storageClass != "" {
+		t.Fatalf("Expected no storage class for STANDARD, got %q", storageClass)
+	}
+	expectStatus("PUT", bucketURL+"/glacier", data, "GLACIER", http.StatusBadRequest)
+	expectStatus("POST", bucketURL+"/glacier?uploads", nil, "GLACIER", http.StatusBadRequest)
+}
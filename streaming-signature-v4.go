@@ -0,0 +1,209 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// This file implements helper functions to validate Streaming AWS
+// Signature Version '4' authorization header.
+//
+// http://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-streaming.html
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Streaming AWS Signature Version '4' constants.
+const (
+	streamingContentSHA256 = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	signV4ChunkedAlgorithm = "AWS4-HMAC-SHA256-PAYLOAD"
+	emptySHA256            = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	// Maximum size of a single chunk accepted.
+	maxChunkSize = 16 * 1024 * 1024 // 16MiB.
+)
+
+// errMalformedEncoding - chunked payload does not follow the
+// aws-chunked encoding.
+var errMalformedEncoding = errors.New("Malformed chunked encoding")
+
+// getChunkSignature - get chunk signature of the chunk data hash,
+// chunks are signed over the signature of the previous chunk.
+func getChunkSignature(signingKey []byte, prevSignature string, t time.Time, region string, hashedChunk string) string {
+	stringToSign := signV4ChunkedAlgorithm + "\n" +
+		t.Format(iso8601Format) + "\n" +
+		getScope(t, region) + "\n" +
+		prevSignature + "\n" +
+		emptySHA256 + "\n" +
+		hashedChunk
+	return getSignature(signingKey, stringToSign)
+}
+
+// calculateSeedSignature - verifies the seed signature of the request
+// and returns it along with the signing key and date of the request.
+func calculateSeedSignature(r *http.Request) (signature string, signingKey []byte, date time.Time, s3Error APIErrorCode) {
+	// Seed signature is calculated with the streaming payload
+	// constant in place of the payload hash.
+	validateRegion := true // Validate region.
+	if s3Error = doesSignatureMatch(streamingContentSHA256, r, validateRegion); s3Error != ErrNone {
+		return "", nil, time.Time{}, s3Error
+	}
+
+	signV4Values, s3Error := parseSignV4(r.Header.Get("Authorization"))
+	if s3Error != ErrNone {
+		return "", nil, time.Time{}, s3Error
+	}
+	dateStr := r.Header.Get(http.CanonicalHeaderKey("x-amz-date"))
+	if dateStr == "" {
+		dateStr = r.Header.Get("Date")
+	}
+	date, err := time.Parse(iso8601Format, dateStr)
+	if err != nil {
+		return "", nil, time.Time{}, ErrMalformedDate
+	}
+	signingKey = getSigningKey(serverConfig.GetCredential().SecretAccessKey, date, serverConfig.GetRegion())
+	return signV4Values.Signature, signingKey, date, ErrNone
+}
+
+// getDecodedContentLength - returns the size of the payload of a
+// streaming signed request, -1 if not set.
+func getDecodedContentLength(r *http.Request) int64 {
+	size, err := strconv.ParseInt(r.Header.Get("X-Amz-Decoded-Content-Length"), 10, 64)
+	if err != nil || size < 0 {
+		return -1
+	}
+	return size
+}
+
+// s3ChunkedReader - reads an aws-chunked encoded payload, every chunk
+// is verified against its signature before any of its data is
+// returned.
+type s3ChunkedReader struct {
+	reader        *bufio.Reader
+	signingKey    []byte
+	seedDate      time.Time
+	region        string
+	prevSignature string
+
+	// Verified chunk data not yet returned to the caller.
+	chunk     []byte
+	buffer    []byte
+	lastChunk bool
+	err       error
+}
+
+// newSignV4ChunkedReader - returns a reader decoding and verifying
+// the chunked payload of a streaming signed request.
+func newSignV4ChunkedReader(r *http.Request) (io.Reader, APIErrorCode) {
+	seedSignature, signingKey, seedDate, s3Error := calculateSeedSignature(r)
+	if s3Error != ErrNone {
+		return nil, s3Error
+	}
+	return &s3ChunkedReader{
+		reader:        bufio.NewReader(r.Body),
+		signingKey:    signingKey,
+		seedDate:      seedDate,
+		region:        serverConfig.GetRegion(),
+		prevSignature: seedSignature,
+	}, ErrNone
+}
+
+// Read - implements io.Reader.
+func (cr *s3ChunkedReader) Read(p []byte) (n int, err error) {
+	for n < len(p) {
+		if len(cr.chunk) == 0 {
+			if cr.err != nil {
+				break
+			}
+			cr.err = cr.readChunk()
+			continue
+		}
+		m := copy(p[n:], cr.chunk)
+		cr.chunk = cr.chunk[m:]
+		n += m
+	}
+	if n > 0 {
+		return n, nil
+	}
+	return 0, cr.err
+}
+
+// readChunk - reads and verifies the next chunk, a chunk is of form
+//
+//   hex(size);chunk-signature=signature\r\n
+//   data\r\n
+//
+// The last chunk is empty.
+func (cr *s3ChunkedReader) readChunk() error {
+	if cr.lastChunk {
+		return io.EOF
+	}
+	line, err := cr.reader.ReadSlice('\n')
+	if err != nil {
+		if err == io.EOF || err == bufio.ErrBufferFull {
+			return errMalformedEncoding
+		}
+		return err
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return errMalformedEncoding
+	}
+	line = line[:len(line)-2]
+	sep := bytes.IndexByte(line, ';')
+	if sep < 0 || !bytes.HasPrefix(line[sep+1:], []byte("chunk-signature=")) {
+		return errMalformedEncoding
+	}
+	signature := string(line[sep+1+len("chunk-signature="):])
+	size, err := strconv.ParseInt(string(line[:sep]), 16, 64)
+	if err != nil || size < 0 || size > maxChunkSize {
+		return errMalformedEncoding
+	}
+
+	if int64(cap(cr.buffer)) < size+2 {
+		cr.buffer = make([]byte, size+2)
+	}
+	data := cr.buffer[:size+2]
+	if _, err = io.ReadFull(cr.reader, data); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return errMalformedEncoding
+		}
+		return err
+	}
+	if !bytes.HasSuffix(data, []byte("\r\n")) {
+		return errMalformedEncoding
+	}
+	data = data[:size]
+
+	// Verify the chunk signature.
+	hashedChunk := hex.EncodeToString(sum256(data))
+	newSignature := getChunkSignature(cr.signingKey, cr.prevSignature, cr.seedDate, cr.region, hashedChunk)
+	if newSignature != signature {
+		return errSignatureMismatch
+	}
+	cr.prevSignature = newSignature
+
+	if size == 0 {
+		cr.lastChunk = true
+		return io.EOF
+	}
+	cr.chunk = data
+	return nil
+}
@@ -0,0 +1,158 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// used to formulate HTTP v4 streaming signed HTTP request, the chunk
+// with index badChunk is signed wrong if non-negative.
+func newTestStreamingRequest(method, urlStr string, data []byte, chunkSize, badChunk int, accessKey, secretKey string) (*http.Request, error) {
+	req, err := http.NewRequest(method, urlStr, nil)
+	if err != nil {
+		return nil, err
+	}
+	t := time.Now().UTC()
+	region := "us-east-1"
+	req.Header.Set("X-Amz-Date", t.Format(iso8601Format))
+	req.Header.Set("X-Amz-Content-Sha256", streamingContentSHA256)
+	req.Header.Set("X-Amz-Decoded-Content-Length", strconv.Itoa(len(data)))
+	req.Header.Set("Content-Encoding", "aws-chunked")
+
+	// Seed signature.
+	signedHeaders := extractSignedHeaders([]string{"content-encoding", "x-amz-content-sha256", "x-amz-date", "x-amz-decoded-content-length"}, req.Header)
+	canonicalRequest := getCanonicalRequest(signedHeaders, streamingContentSHA256, req.URL.Query().Encode(), req.URL.Path, method, req.URL.Host)
+	signingKey := getSigningKey(secretKey, t, region)
+	signature := getSignature(signingKey, getStringToSign(canonicalRequest, t, region))
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signV4Algorithm, accessKey, getScope(t, region), getSignedHeaders(signedHeaders), signature))
+
+	// Chunked payload, terminated by an empty chunk.
+	var body bytes.Buffer
+	for i := 0; ; i++ {
+		n := chunkSize
+		if n > len(data) {
+			n = len(data)
+		}
+		chunk := data[:n]
+		data = data[n:]
+		signature = getChunkSignature(signingKey, signature, t, region, hex.EncodeToString(sum256(chunk)))
+		chunkSignature := signature
+		if i == badChunk {
+			chunkSignature = getChunkSignature(signingKey, signature, t, region, emptySHA256)
+		}
+		fmt.Fprintf(&body, "%x;chunk-signature=%s\r\n", len(chunk), chunkSignature)
+		body.Write(chunk)
+		body.WriteString("\r\n")
+		if len(chunk) == 0 {
+			break
+		}
+	}
+	req.Body = ioutil.NopCloser(&body)
+	req.ContentLength = int64(body.Len())
+	return req, nil
+}
+
+// Tests uploading objects and parts with streaming signatures.
+func TestStreamingSignatureHandlers(t *testing.T) {
+	testServer := StartTestServer(t, "FS")
+	defer testServer.Stop()
+
+	data := bytes.Repeat([]byte("streaming"), 10000)
+	client := http.Client{}
+	expectStatus := func(req *http.Request, status int) ([]byte, http.Header) {
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		respBody, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != status {
+			t.Fatalf("%s %s: Expected status %d, got %d: %s", req.Method, req.URL, status, resp.StatusCode, respBody)
+		}
+		return respBody, resp.Header
+	}
+	newRequest := func(method, urlStr string, body []byte) *http.Request {
+		req, err := newTestRequest(method, urlStr, int64(len(body)), bytes.NewReader(body), testServer.AccessKey, testServer.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+	newStreamingRequest := func(urlStr string, chunkSize, badChunk int, secretKey string) *http.Request {
+		req, err := newTestStreamingRequest("PUT", urlStr, data, chunkSize, badChunk, testServer.AccessKey, secretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+
+	bucketURL := testServer.Server.URL + "/streaming-bucket"
+	expectStatus(newRequest("PUT", bucketURL, nil), http.StatusOK)
+
+	expectStatus(newStreamingRequest(bucketURL+"/object", 8*1024, -1, testServer.SecretKey), http.StatusOK)
+	respData, respHeader := expectStatus(newRequest("GET", bucketURL+"/object", nil), http.StatusOK)
+	if !bytes.Equal(respData, data) {
+		t.Fatal("Streamed object does not match")
+	}
+	if respHeader.Get("Content-Encoding") == "aws-chunked" {
+		t.Fatal("Expected aws-chunked content encoding not to be saved")
+	}
+
+	// Wrong seed and chunk signatures are rejected.
+	expectStatus(newStreamingRequest(bucketURL+"/wrong-seed", 8*1024, -1, "wrong-secret-key"), http.StatusForbidden)
+	expectStatus(newStreamingRequest(bucketURL+"/bad-chunk", 8*1024, 3, testServer.SecretKey), http.StatusForbidden)
+	expectStatus(newRequest("HEAD", bucketURL+"/bad-chunk", nil), http.StatusNotFound)
+
+	// Malformed payload is rejected.
+	req := newStreamingRequest(bucketURL+"/malformed", 8*1024, -1, testServer.SecretKey)
+	req.Body = ioutil.NopCloser(bytes.NewReader([]byte("zz;chunk-signature=0\r\n")))
+	req.ContentLength = int64(len("zz;chunk-signature=0\r\n"))
+	expectStatus(req, http.StatusBadRequest)
+
+	// Parts are streamed the same way.
+	respData, _ = expectStatus(newRequest("POST", bucketURL+"/multipart?uploads", nil), http.StatusOK)
+	var initResponse InitiateMultipartUploadResponse
+	if err := xml.Unmarshal(respData, &initResponse); err != nil {
+		t.Fatal(err)
+	}
+	partURL := bucketURL + "/multipart?partNumber=1&uploadId=" + initResponse.UploadID
+	expectStatus(newStreamingRequest(partURL, 16*1024, 2, testServer.SecretKey), http.StatusForbidden)
+	_, respHeader = expectStatus(newStreamingRequest(partURL, 16*1024, -1, testServer.SecretKey), http.StatusOK)
+	completeBytes, err := xml.Marshal(completeMultipartUpload{
+		Parts: []completePart{{PartNumber: 1, ETag: respHeader.Get("ETag")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(newRequest("POST", bucketURL+"/multipart?uploadId="+initResponse.UploadID, completeBytes), http.StatusOK)
+	if respData, _ = expectStatus(newRequest("GET", bucketURL+"/multipart", nil), http.StatusOK); !bytes.Equal(respData, data) {
+		t.Fatal("Streamed part does not match")
+	}
+}
@@ -103,6 +103,21 @@ type TestServer struct {
 
 // Starts the test server and returns the TestServer instance.
 func StartTestServer(t TestErrHandler, instanceType string) TestServer {
+	testServer := newTestServer(t, instanceType)
+	testServer.Server.Start()
+	return testServer
+}
+
+// Starts the test server over TLS and returns the TestServer instance,
+// requests are sent with testServer.Server.Client().
+func StartTestTLSServer(t TestErrHandler, instanceType string) TestServer {
+	testServer := newTestServer(t, instanceType)
+	testServer.Server.StartTLS()
+	return testServer
+}
+
+// Initializes the test server without starting it.
+func newTestServer(t TestErrHandler, instanceType string) TestServer {
 	// create an instance of TestServer.
 	testServer := TestServer{}
 	// create temporary backend for the test server.
@@ -135,8 +150,8 @@ func StartTestServer(t TestErrHandler, instanceType string) TestServer {
 	if err != nil {
 		t.Fatalf(err.Error())
 	}
-	// Run TestServer.
-	testServer.Server = httptest.NewServer(configureServerHandler(serverCmdConfig{exportPaths: erasureDisks}))
+	srvCmdConfig := serverCmdConfig{exportPaths: erasureDisks}
+	testServer.Server = httptest.NewUnstartedServer(configureServerHandler(srvCmdConfig, newServerObjectLayer(srvCmdConfig)))
 
 	return testServer
 }
@@ -541,7 +556,7 @@ func getXLObjectLayer() (ObjectLayer, []string, error) {
 	// Initialize name space lock.
 	initNSLock()
 
-	objLayer, err := newXLObjects(erasureDisks)
+	objLayer, err := newXLObjects(erasureDisks, storageClassConfig{})
 	if err != nil {
 		return nil, nil, err
 	}
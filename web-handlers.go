@@ -18,6 +18,7 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path"
@@ -142,6 +143,8 @@ type WebBucketInfo struct {
 	Name string `json:"name"`
 	// Date the bucket was created.
 	CreationDate time.Time `json:"creationDate"`
+	// Tags of the bucket, such as cost center and owner labels.
+	Tags map[string]string `json:"tags,omitempty"`
 }
 
 // ListBuckets - list buckets api.
@@ -159,6 +162,7 @@ func (web *webAPIHandlers) ListBuckets(r *http.Request, args *WebGenericArgs, re
 			reply.Buckets = append(reply.Buckets, WebBucketInfo{
 				Name:         bucket.Name,
 				CreationDate: bucket.Created,
+				Tags:         getWebBucketTags(bucket.Name),
 			})
 		}
 	}
@@ -166,6 +170,20 @@ func (web *webAPIHandlers) ListBuckets(r *http.Request, args *WebGenericArgs, re
 	return nil
 }
 
+// getWebBucketTags - returns the tags of a bucket, buckets without
+// tags or with unreadable tags have none.
+func getWebBucketTags(bucket string) map[string]string {
+	tagging, err := readBucketTagging(bucket)
+	if err != nil {
+		return nil
+	}
+	tags := make(map[string]string)
+	for _, t := range tagging.TagSet.Tags {
+		tags[t.Key] = t.Value
+	}
+	return tags
+}
+
 // ListObjectsArgs - list object args.
 type ListObjectsArgs struct {
 	BucketName string `json:"bucketName"`
@@ -356,7 +374,16 @@ func (web *webAPIHandlers) Upload(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	bucket := vars["bucket"]
 	object := vars["object"]
-	if _, err := web.ObjectAPI.PutObject(bucket, object, -1, r.Body, nil); err != nil {
+	// Encrypt the object if the bucket has default encryption.
+	metadata := make(map[string]string)
+	objectKey, s3Error := newRequestObjectKey(r.Header, bucket, object, metadata)
+	if s3Error != ErrNone {
+		apiErr := getAPIError(s3Error)
+		w.WriteHeader(apiErr.HTTPStatusCode)
+		w.Write([]byte(apiErr.Description))
+		return
+	}
+	if _, err := web.ObjectAPI.PutObject(bucket, object, -1, encryptRequestReader(r.Body, objectKey, nil), metadata); err != nil {
 		writeWebErrorResponse(w, err)
 	}
 }
@@ -387,8 +414,31 @@ func (web *webAPIHandlers) Download(w http.ResponseWriter, r *http.Request) {
 		writeWebErrorResponse(w, err)
 		return
 	}
+	// Objects encrypted with a customer key cannot be downloaded,
+	// objects encrypted with the master key are decrypted.
+	objectKey, s3Error := decryptObjectInfo(http.Header{}, sseCustomerHeaders, &objInfo)
+	if s3Error != ErrNone {
+		apiErr := getAPIError(s3Error)
+		w.WriteHeader(apiErr.HTTPStatusCode)
+		w.Write([]byte(apiErr.Description))
+		return
+	}
 	offset := int64(0)
-	err = web.ObjectAPI.GetObject(bucket, object, offset, objInfo.Size, w)
+	length := objInfo.Size
+	var writer io.Writer = w
+	var decWriter io.WriteCloser
+	if objectKey != nil {
+		offset, length, decWriter, err = newDecryptRangeWriter(w, objectKey, objInfo, offset, length)
+		if err != nil {
+			writeWebErrorResponse(w, err)
+			return
+		}
+		writer = decWriter
+	}
+	err = web.ObjectAPI.GetObject(bucket, object, offset, length, writer)
+	if err == nil && decWriter != nil {
+		err = decWriter.Close()
+	}
 	if err != nil {
 		/// No need to print error, response writer already written to.
 		return
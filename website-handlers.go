@@ -0,0 +1,216 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	router "github.com/gorilla/mux"
+)
+
+// splitHostPort - splits the host of a request into host name and
+// port, port is empty if not specified.
+func splitHostPort(hostPort string) (host, port string) {
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return hostPort, ""
+	}
+	return host, port
+}
+
+// isWebsiteRequest - returns true for requests to a bucket under the
+// website domain, requests to the website address are served by the
+// website server alone.
+func isWebsiteRequest(r *http.Request) bool {
+	host, _ := splitHostPort(r.Host)
+	return globalWebsiteDomain != "" && strings.HasSuffix(host, "."+globalWebsiteDomain)
+}
+
+// getWebsiteBucketObject - returns the bucket and object of a website
+// request along with the path prefix of keys in redirects. Buckets
+// are taken from the host under the website domain, otherwise from
+// the first element of the path.
+func getWebsiteBucketObject(r *http.Request) (bucket, object, pathPrefix string) {
+	host, _ := splitHostPort(r.Host)
+	if globalWebsiteDomain != "" && strings.HasSuffix(host, "."+globalWebsiteDomain) {
+		bucket = strings.TrimSuffix(host, "."+globalWebsiteDomain)
+		return bucket, strings.TrimPrefix(r.URL.Path, "/"), "/"
+	}
+	splits := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	bucket = splits[0]
+	if len(splits) == 2 {
+		object = splits[1]
+	}
+	return bucket, object, "/" + bucket + "/"
+}
+
+// registerWebsiteRouter - registers the website endpoint, which takes
+// precedence over all other routers for website requests.
+func registerWebsiteRouter(mux *router.Router, api objectAPIHandlers) {
+	website := mux.NewRoute().MatcherFunc(func(r *http.Request, rm *router.RouteMatch) bool {
+		return isWebsiteRequest(r)
+	}).Subrouter()
+	website.NewRoute().HandlerFunc(api.WebsiteHandler)
+}
+
+// WebsiteHandler - GET and HEAD on the website endpoint
+// -----------------
+// This implementation serves objects of buckets with a website
+// configuration to anonymous clients, index documents are served for
+// directory-style keys and the error document for errors.
+func (api objectAPIHandlers) WebsiteHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		writeErrorResponse(w, r, ErrMethodNotAllowed, r.URL.Path)
+		return
+	}
+	bucket, key, pathPrefix := getWebsiteBucketObject(r)
+	if bucket == "" {
+		writeErrorResponse(w, r, ErrNoSuchBucket, r.URL.Path)
+		return
+	}
+
+	// Verify if bucket exists.
+	if _, err := api.ObjectAPI.GetBucketInfo(bucket); err != nil {
+		errorIf(err, "Unable to fetch bucket info.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+	wc, err := readBucketWebsite(bucket)
+	if err != nil {
+		errorIf(err, "Unable to read website configuration.")
+		writeErrorResponse(w, r, toAPIErrorCode(err), r.URL.Path)
+		return
+	}
+
+	if wc.RedirectAllRequestsTo != nil {
+		http.Redirect(w, r, wc.RedirectAllRequestsTo.location(r, key), http.StatusMovedPermanently)
+		return
+	}
+	if rule, ok := wc.routingRule(key, 0); ok {
+		location, code := rule.location(r, pathPrefix, key)
+		http.Redirect(w, r, location, code)
+		return
+	}
+
+	// Directory-style keys are served their index document.
+	object := key
+	if object == "" || strings.HasSuffix(object, "/") {
+		object += wc.IndexDocument.Suffix
+	}
+	s3Error := api.serveWebsiteObject(w, r, bucket, object, http.StatusOK)
+	if s3Error == ErrNone {
+		return
+	}
+	errorCode := getAPIError(s3Error).HTTPStatusCode
+
+	// Directories without a trailing slash are redirected to it.
+	if errorCode == http.StatusNotFound && object == key {
+		if _, err = api.ObjectAPI.GetObjectInfo(bucket, key+"/"+wc.IndexDocument.Suffix); err == nil {
+			http.Redirect(w, r, pathPrefix+key+"/", http.StatusFound)
+			return
+		}
+	}
+	if rule, ok := wc.routingRule(key, errorCode); ok {
+		location, code := rule.location(r, pathPrefix, key)
+		http.Redirect(w, r, location, code)
+		return
+	}
+	if wc.ErrorDocument != nil {
+		if api.serveWebsiteObject(w, r, bucket, wc.ErrorDocument.Key, errorCode) == ErrNone {
+			return
+		}
+	}
+	writeErrorResponse(w, r, s3Error, r.URL.Path)
+}
+
+// serveWebsiteObject - writes the object with the given status if
+// the bucket policy allows anonymous access, otherwise returns the
+// error without writing a response.
+func (api objectAPIHandlers) serveWebsiteObject(w http.ResponseWriter, r *http.Request, bucket, object string, status int) APIErrorCode {
+	// Website content is served under the bucket policy of anonymous
+	// requests.
+	objectURL := &url.URL{Path: "/" + bucket + "/" + object}
+	if s3Error := enforceBucketPolicyTags("s3:GetObject", bucket, objectURL, api.existingObjectTagConditions(bucket, object)); s3Error != ErrNone {
+		return s3Error
+	}
+
+	objInfo, err := api.ObjectAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		return toAPIErrorCode(err)
+	}
+	// Delete markers have no data.
+	if objInfo.DeleteMarker {
+		return ErrNoSuchKey
+	}
+	// Objects encrypted with customer provided keys cannot be served.
+	objectKey, s3Error := decryptObjectInfo(http.Header{}, sseCustomerHeaders, &objInfo)
+	if s3Error != ErrNone {
+		return s3Error
+	}
+
+	// Ranges and conditions apply only to the requested object, not
+	// to error documents.
+	rangeHeader := r.Header.Get("Range")
+	if status != http.StatusOK {
+		rangeHeader = ""
+	}
+	hrange, err := getRequestedRange(rangeHeader, objInfo.Size)
+	if err != nil {
+		return ErrInvalidRange
+	}
+	setObjectHeaders(w, objInfo, hrange)
+	if status == http.StatusOK {
+		if checkLastModified(w, r, objInfo.ModTime) || checkETag(w, r) {
+			return ErrNone
+		}
+	} else {
+		w.WriteHeader(status)
+	}
+	if r.Method == "HEAD" {
+		return ErrNone
+	}
+
+	startOffset := hrange.start
+	length := hrange.length
+	if length == 0 {
+		length = objInfo.Size - startOffset
+	}
+	var writer io.Writer = w
+	var decWriter io.WriteCloser
+	if objectKey != nil {
+		// Read the encrypted packages holding the requested range.
+		startOffset, length, decWriter, err = newDecryptRangeWriter(w, objectKey, objInfo, startOffset, length)
+		if err != nil {
+			errorIf(err, "Unable to decrypt %s/%s.", bucket, object)
+			return ErrNone
+		}
+		writer = decWriter
+	}
+	err = api.ObjectAPI.GetObject(bucket, object, startOffset, length, writer)
+	if err == nil && decWriter != nil {
+		err = decWriter.Close()
+	}
+	if err != nil {
+		errorIf(err, "Writing to client failed.")
+		// Do not send error response here, client would have already died.
+	}
+	return ErrNone
+}
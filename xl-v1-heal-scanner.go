@@ -0,0 +1,453 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+const (
+	// Heal scan progress file, saved under minioMetaBucket.
+	healScanJSONFile = "heal-scan.json"
+	// Progress is saved after scanning these many objects.
+	healScanSaveCount = 100
+
+	// Default time to wait after a complete scan before the next one.
+	defaultHealScanInterval = 24 * time.Hour
+	// Default pause after scanning each object.
+	defaultHealScanDelay = 10 * time.Millisecond
+)
+
+// healConfig - background heal scanner configuration.
+type healConfig struct {
+	// Disables the background heal scanner.
+	Disable bool `json:"disable"`
+	// Time to wait after a complete scan before the next one, such
+	// as "24h".
+	Interval string `json:"interval"`
+	// Pause after scanning each object, such as "10ms".
+	Delay string `json:"delay"`
+	// Maximum object data scanned per second, such as "10MiB".
+	// Unlimited if not configured.
+	Rate string `json:"rate"`
+}
+
+// getDurations - returns the scan interval and delay, defaults are
+// used for the ones not configured.
+func (c healConfig) getDurations() (interval, delay time.Duration, err error) {
+	interval, delay = defaultHealScanInterval, defaultHealScanDelay
+	if c.Interval != "" {
+		if interval, err = time.ParseDuration(c.Interval); err != nil {
+			return 0, 0, err
+		}
+	}
+	if c.Delay != "" {
+		if delay, err = time.ParseDuration(c.Delay); err != nil {
+			return 0, 0, err
+		}
+	}
+	if interval <= 0 || delay < 0 {
+		return 0, 0, errInvalidArgument
+	}
+	return interval, delay, nil
+}
+
+// getRate - returns the maximum bytes scanned per second, zero if
+// unlimited.
+func (c healConfig) getRate() (uint64, error) {
+	if c.Rate == "" {
+		return 0, nil
+	}
+	return humanize.ParseBytes(c.Rate)
+}
+
+// healThrottle - throttles a scan to pause for delay after each
+// object and to scan at most rate bytes per second since its start.
+type healThrottle struct {
+	delay time.Duration
+	rate  uint64
+
+	start   time.Time
+	scanned uint64
+}
+
+// newHealThrottle - initialize a throttle for a scan starting now.
+func newHealThrottle(delay time.Duration, rate uint64) *healThrottle {
+	return &healThrottle{
+		delay: delay,
+		rate:  rate,
+		start: time.Now().UTC(),
+	}
+}
+
+// wait - pauses after scanning an object of size bytes, for at least
+// delay and until the bytes scanned are within the rate. Returns
+// errWalkAbort when doneCh is closed.
+func (t *healThrottle) wait(size int64, doneCh <-chan struct{}) error {
+	t.scanned += uint64(size)
+	wait := t.delay
+	if t.rate > 0 {
+		scanTime := time.Duration(float64(t.scanned) / float64(t.rate) * float64(time.Second))
+		if rateWait := t.start.Add(scanTime).Sub(time.Now().UTC()); rateWait > wait {
+			wait = rateWait
+		}
+	}
+	select {
+	case <-doneCh:
+		return errWalkAbort
+	case <-time.After(wait):
+	}
+	return nil
+}
+
+// healScanV1 - progress of the background heal scanner, saved in
+// `heal-scan.json` on all disks.
+type healScanV1 struct {
+	Version string `json:"version"`
+
+	// Start time of the scan in progress, zero when there is none.
+	Started time.Time `json:"started"`
+	// Bucket being scanned and the last object scanned in it.
+	Bucket string `json:"bucket"`
+	Object string `json:"object"`
+	// Number of objects scanned and healed by the scan in progress.
+	Scanned int64 `json:"scanned"`
+	Healed  int64 `json:"healed"`
+
+	// Finish time of the last complete scan.
+	Completed time.Time `json:"completed"`
+	// Time the progress was saved.
+	Updated time.Time `json:"updated"`
+}
+
+// readHealScan - returns the latest heal scan progress saved on any of
+// the disks, progress is empty if never saved.
+func (xl xlObjects) readHealScan() (scan healScanV1) {
+	for _, disk := range xl.storageDisks {
+		if disk == nil {
+			continue
+		}
+		buf, err := disk.ReadAll(minioMetaBucket, healScanJSONFile)
+		if err != nil {
+			continue
+		}
+		var diskScan healScanV1
+		if err = json.Unmarshal(buf, &diskScan); err != nil {
+			continue
+		}
+		if diskScan.Updated.After(scan.Updated) {
+			scan = diskScan
+		}
+	}
+	return scan
+}
+
+// writeHealScan - saves heal scan progress on all disks.
+func (xl xlObjects) writeHealScan(scan healScanV1) error {
+	scan.Version = "1.0.0"
+	scan.Updated = time.Now().UTC()
+	scanBytes, err := json.Marshal(scan)
+	if err != nil {
+		return err
+	}
+
+	tmpScanPath := path.Join(tmpMetaPrefix, getUUID())
+	var errs = make([]error, len(xl.storageDisks))
+	var wg = &sync.WaitGroup{}
+
+	// Update `heal-scan.json` for all the disks.
+	for index, disk := range xl.storageDisks {
+		if disk == nil {
+			errs[index] = errDiskNotFound
+			continue
+		}
+		wg.Add(1)
+		go func(index int, disk StorageAPI) {
+			defer wg.Done()
+			if wErr := disk.AppendFile(minioMetaBucket, tmpScanPath, scanBytes); wErr != nil {
+				errs[index] = wErr
+				return
+			}
+			if wErr := disk.RenameFile(minioMetaBucket, tmpScanPath, minioMetaBucket, healScanJSONFile); wErr != nil {
+				_ = disk.DeleteFile(minioMetaBucket, tmpScanPath)
+				errs[index] = wErr
+			}
+		}(index, disk)
+	}
+	wg.Wait()
+
+	if !isQuorum(errs, xl.writeQuorum) {
+		return errXLWriteQuorum
+	}
+	return nil
+}
+
+// startHealScanner - scans all objects for healing, a new scan starts
+// interval after the previous one has completed. A scan interrupted
+// by a restart is resumed from its saved progress. Returns when doneCh
+// is closed.
+func (xl xlObjects) startHealScanner(interval, delay time.Duration, rate uint64, doneCh <-chan struct{}) {
+	for {
+		var wait time.Duration
+		if scan := xl.readHealScan(); scan.Started.IsZero() && !scan.Completed.IsZero() {
+			wait = scan.Completed.Add(interval).Sub(time.Now().UTC())
+		}
+		select {
+		case <-doneCh:
+			return
+		case <-time.After(wait):
+		}
+		if err := xl.healScan(delay, rate, doneCh); err != nil {
+			if err == errWalkAbort {
+				return
+			}
+			errorIf(err, "Unable to scan objects for healing.")
+			// Retry after an interval.
+			select {
+			case <-doneCh:
+				return
+			case <-time.After(interval):
+			}
+		}
+	}
+}
+
+// healScan - scans all the buckets and the archived versions of their
+// objects, healing them. Throttled to pause for delay after each object
+// and to scan at most rate bytes per second. Resumes the scan in
+// progress if any, returns errWalkAbort when doneCh is closed.
+func (xl xlObjects) healScan(delay time.Duration, rate uint64, doneCh <-chan struct{}) error {
+	scan := xl.readHealScan()
+	if scan.Started.IsZero() {
+		scan = healScanV1{
+			Started:   time.Now().UTC(),
+			Completed: scan.Completed,
+		}
+	}
+	throttle := newHealThrottle(delay, rate)
+
+	// Archived versions are scanned first, minioMetaBucket sorts before
+	// all the buckets.
+	buckets := append([]string{minioMetaBucket}, xl.listAllBuckets()...)
+	for _, bucket := range buckets {
+		// Skip the buckets scanned before the scan was interrupted.
+		if bucket < scan.Bucket {
+			continue
+		}
+		if bucket != scan.Bucket {
+			scan.Bucket, scan.Object = bucket, ""
+		}
+		if err := xl.healScanBucket(bucket, &scan, throttle, doneCh); err != nil {
+			errorIf(xl.writeHealScan(scan), "Unable to save heal scan progress.")
+			return err
+		}
+	}
+
+	scan.Started = time.Time{}
+	scan.Bucket, scan.Object = "", ""
+	scan.Completed = time.Now().UTC()
+	return xl.writeHealScan(scan)
+}
+
+// healScanBucket - heals the bucket and all its objects after the last
+// object scanned. Objects of minioMetaBucket are the archived versions
+// of all the buckets.
+func (xl xlObjects) healScanBucket(bucket string, scan *healScanV1, throttle *healThrottle, doneCh <-chan struct{}) error {
+	if bucket == minioMetaBucket {
+		return xl.walkAllDisks(minioMetaBucket, retainSlash(versionsMetaPrefix), "", scan.Object, func(versionPath string) error {
+			// Archived versions are at `versions/bucket/object/versionID`.
+			versionBucket, versionObject := splitVersionPath(versionPath)
+			return xl.healScanObject(versionBucket, path.Dir(versionObject), path.Base(versionObject), versionPath, scan, throttle, doneCh)
+		})
+	}
+	if _, err := xl.healBucket(bucket, false); err != nil {
+		if err != errVolumeNotFound {
+			errorIf(err, "Unable to heal bucket %s.", bucket)
+		}
+		return nil
+	}
+	return xl.walkAllDisks(bucket, "", "", scan.Object, func(object string) error {
+		return xl.healScanObject(bucket, object, "", object, scan, throttle, doneCh)
+	})
+}
+
+// splitVersionPath - returns the bucket and the path of the archived
+// version under the bucket, from its path under minioMetaBucket.
+func splitVersionPath(versionPath string) (bucket, versionObject string) {
+	versionPath = strings.TrimPrefix(versionPath, retainSlash(versionsMetaPrefix))
+	if i := strings.Index(versionPath, slashSeparator); i >= 0 {
+		return versionPath[:i], versionPath[i+1:]
+	}
+	return versionPath, ""
+}
+
+// healScanObject - heals an object, or its archived version if
+// versionID is set, for the scan. Progress is saved with marker, the
+// path of the object in the bucket scanned. Throttles the scan
+// afterwards, returns errWalkAbort when doneCh is closed.
+func (xl xlObjects) healScanObject(bucket, object, versionID, marker string, scan *healScanV1, throttle *healThrottle, doneCh <-chan struct{}) error {
+	healed, size, err := xl.healObjectVersion(bucket, object, versionID)
+	errorIf(err, "Unable to heal %s/%s %s.", bucket, object, versionID)
+	scan.Object = marker
+	scan.Scanned++
+	if healed > 0 {
+		scan.Healed++
+	}
+	if scan.Scanned%healScanSaveCount == 0 {
+		errorIf(xl.writeHealScan(*scan), "Unable to save heal scan progress.")
+	}
+	return throttle.wait(size, doneCh)
+}
+
+// walkAllDisks - calls fn for all the objects under prefixDir whose
+// names start with prefix, found on any of the disks. Objects are
+// walked in lexical order, skipping the ones up to marker.
+func (xl xlObjects) walkAllDisks(bucket, prefixDir, prefix, marker string, fn func(object string) error) error {
+	entries := xl.listDirAllDisks(bucket, prefixDir)
+	// Directories holding `xl.json` on any disk are objects.
+	if i := sort.SearchStrings(entries, xlMetaJSONFile); prefixDir != "" && i < len(entries) && entries[i] == xlMetaJSONFile {
+		object := strings.TrimSuffix(prefixDir, slashSeparator)
+		if !strings.HasPrefix(object, prefix) {
+			return nil
+		}
+		return fn(object)
+	}
+	for _, entry := range entries {
+		// Only directories hold objects.
+		if !strings.HasSuffix(entry, slashSeparator) {
+			continue
+		}
+		entryDir := pathJoin(prefixDir, entry)
+		// Skip directories outside of the prefix.
+		if !strings.HasPrefix(entryDir, prefix) && !strings.HasPrefix(prefix, entryDir) {
+			continue
+		}
+		if isHealScanned(entryDir, marker) {
+			continue
+		}
+		if err := xl.walkAllDisks(bucket, entryDir, prefix, marker, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isHealScanned - returns true if the directory was scanned up to the
+// marker object, directories are scanned in lexical order.
+func isHealScanned(prefixDir, marker string) bool {
+	if marker == "" {
+		return false
+	}
+	markerDir := marker + slashSeparator
+	if prefixDir == markerDir {
+		return true
+	}
+	return prefixDir < markerDir && !strings.HasPrefix(markerDir, prefixDir)
+}
+
+// listDirAllDisks - lists the entries at a given prefix found on any
+// of the disks, sorted. Unlike listDir, entries missing on some disks
+// are listed as well, disks which cannot be listed are ignored.
+func (xl xlObjects) listDirAllDisks(bucket, prefixDir string) (entries []string) {
+	entrySet := make(map[string]struct{})
+	for _, disk := range xl.storageDisks {
+		if disk == nil {
+			continue
+		}
+		diskEntries, err := disk.ListDir(bucket, prefixDir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range diskEntries {
+			entrySet[entry] = struct{}{}
+		}
+	}
+	for entry := range entrySet {
+		entries = append(entries, entry)
+	}
+	sort.Strings(entries)
+	return entries
+}
+
+// listAllBuckets - lists the buckets found on at least read quorum
+// disks, sorted. Buckets found on fewer disks are likely partially
+// deleted and are not healed.
+func (xl xlObjects) listAllBuckets() (buckets []string) {
+	bucketCount := make(map[string]int)
+	for _, disk := range xl.storageDisks {
+		if disk == nil {
+			continue
+		}
+		volsInfo, err := disk.ListVols()
+		if err != nil {
+			continue
+		}
+		for _, volInfo := range volsInfo {
+			// StorageAPI can send volume names which are incompatible
+			// with buckets, handle it and skip them.
+			if !IsValidBucketName(volInfo.Name) {
+				continue
+			}
+			bucketCount[volInfo.Name]++
+		}
+	}
+	for bucket, count := range bucketCount {
+		if count >= xl.readQuorum {
+			buckets = append(buckets, bucket)
+		}
+	}
+	sort.Strings(buckets)
+	return buckets
+}
+
+// healBucket - creates the bucket on the disks missing it, returns
+// errVolumeNotFound unless found on read quorum disks. Returns the
+// number of disks healed, or which would be healed on dry run.
+func (xl xlObjects) healBucket(bucket string, dryRun bool) (int, error) {
+	nsMutex.Lock(bucket, "")
+	defer nsMutex.Unlock(bucket, "")
+
+	var missingDisks []StorageAPI
+	for _, disk := range xl.storageDisks {
+		if disk == nil {
+			continue
+		}
+		if _, err := disk.StatVol(bucket); err == errVolumeNotFound {
+			missingDisks = append(missingDisks, disk)
+		}
+	}
+	// Bucket may have been deleted since it was listed.
+	if len(xl.storageDisks)-len(missingDisks) < xl.readQuorum {
+		return 0, errVolumeNotFound
+	}
+	if dryRun {
+		return len(missingDisks), nil
+	}
+	for index, disk := range missingDisks {
+		if err := disk.MakeVol(bucket); err != nil && err != errVolumeExists {
+			return index, err
+		}
+	}
+	return len(missingDisks), nil
+}
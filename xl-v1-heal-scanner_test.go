@@ -0,0 +1,253 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Tests parsing heal scanner configuration.
+func TestHealConfigDurations(t *testing.T) {
+	testCases := []struct {
+		config    healConfig
+		interval  time.Duration
+		delay     time.Duration
+		expectErr bool
+	}{
+		{healConfig{}, defaultHealScanInterval, defaultHealScanDelay, false},
+		{healConfig{Interval: "1h", Delay: "0s"}, time.Hour, 0, false},
+		{healConfig{Interval: "day"}, 0, 0, true},
+		{healConfig{Interval: "0s"}, 0, 0, true},
+		{healConfig{Delay: "-1ms"}, 0, 0, true},
+	}
+	for i, testCase := range testCases {
+		interval, delay, err := testCase.config.getDurations()
+		if testCase.expectErr {
+			if err == nil {
+				t.Fatalf("Test %d: Expected error", i+1)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Test %d: %s", i+1, err)
+		}
+		if interval != testCase.interval || delay != testCase.delay {
+			t.Fatalf("Test %d: Expected %s, %s, got %s, %s", i+1, testCase.interval, testCase.delay, interval, delay)
+		}
+	}
+}
+
+// Tests parsing the heal scanner rate.
+func TestHealConfigRate(t *testing.T) {
+	testCases := []struct {
+		config    healConfig
+		rate      uint64
+		expectErr bool
+	}{
+		{healConfig{}, 0, false},
+		{healConfig{Rate: "10MiB"}, 10 * 1024 * 1024, false},
+		{healConfig{Rate: "1KB"}, 1000, false},
+		{healConfig{Rate: "fast"}, 0, true},
+	}
+	for i, testCase := range testCases {
+		rate, err := testCase.config.getRate()
+		if testCase.expectErr {
+			if err == nil {
+				t.Fatalf("Test %d: Expected error", i+1)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Test %d: %s", i+1, err)
+		}
+		if rate != testCase.rate {
+			t.Fatalf("Test %d: Expected %d, got %d", i+1, testCase.rate, rate)
+		}
+	}
+}
+
+// Tests throttling a scan to its rate.
+func TestHealThrottle(t *testing.T) {
+	throttle := newHealThrottle(0, 1000)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := throttle.wait(50, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Fatalf("Expected 200 bytes at 1000 bytes per second to take 200ms, took %s", elapsed)
+	}
+	doneCh := make(chan struct{})
+	close(doneCh)
+	if err := throttle.wait(1000, doneCh); err != errWalkAbort {
+		t.Fatalf("Expected throttle to be stopped, got %v", err)
+	}
+}
+
+// Tests resuming a scan after the last object scanned.
+func TestIsHealScanned(t *testing.T) {
+	testCases := []struct {
+		prefixDir string
+		marker    string
+		scanned   bool
+	}{
+		{"a/", "", false},
+		{"a/", "a", true},
+		{"a/", "b", true},
+		{"b/", "a", false},
+		{"a-b/", "a", true},
+		{"a/", "a-b", false},
+		{"dir/", "dir/obj", false},
+		{"dir/obj/", "dir/obj", true},
+		{"dir/obj-1/", "dir/obj", true},
+		{"dir/obj2/", "dir/obj", false},
+	}
+	for i, testCase := range testCases {
+		if scanned := isHealScanned(testCase.prefixDir, testCase.marker); scanned != testCase.scanned {
+			t.Errorf("Test %d: Expected %s scanned up to %s to be %v", i+1, testCase.prefixDir, testCase.marker, testCase.scanned)
+		}
+	}
+}
+
+// Tests scanning all objects for healing.
+func TestXLHealScan(t *testing.T) {
+	objLayer, disks, err := getXLObjectLayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(disks)
+	xl := objLayer.(xlObjects)
+
+	buckets := []string{"scan-bucket-a", "scan-bucket-b"}
+	objects := []string{"dir/obj-1", "obj-2"}
+	data := []byte("hello, heal scanner")
+	for _, bucket := range buckets {
+		if err = objLayer.MakeBucket(bucket); err != nil {
+			t.Fatal(err)
+		}
+		for _, object := range objects {
+			if _, err = objLayer.PutObject(bucket, object, int64(len(data)), bytes.NewReader(data), nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	expectHealthy := func(bucket, object string) {
+		for index, disk := range xl.storageDisks {
+			xlMeta, rErr := readXLMeta(disk, bucket, object)
+			if rErr != nil {
+				t.Fatalf("Disk %d: %s/%s: %s", index, bucket, object, rErr)
+			}
+			checkSum := xlMeta.Erasure.PartObjectChecksum("part.1")
+			if !isValidBlock(disk, bucket, pathJoin(object, "part.1"), xlMeta.Parts[0].Size, xlMeta.Erasure, checkSum) {
+				t.Fatalf("Disk %d: %s/%s: Expected part to pass its checksum", index, bucket, object)
+			}
+		}
+	}
+	expectProgress := func(scanned, healed int64) {
+		scan := xl.readHealScan()
+		if !scan.Started.IsZero() || scan.Completed.IsZero() || scan.Bucket != "" {
+			t.Fatalf("Expected complete scan, got %+v", scan)
+		}
+		if scan.Scanned != scanned || scan.Healed != healed {
+			t.Fatalf("Expected %d objects scanned and %d healed, got %d and %d", scanned, healed, scan.Scanned, scan.Healed)
+		}
+	}
+
+	// Replaced disk missing a whole bucket, corrupted part on another.
+	if err = os.RemoveAll(filepath.Join(disks[0], buckets[0])); err != nil {
+		t.Fatal(err)
+	}
+	partFile, err := os.OpenFile(filepath.Join(disks[1], buckets[1], objects[1], "part.1"), os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = partFile.WriteAt([]byte("corrupted"), 0); err != nil {
+		t.Fatal(err)
+	}
+	partFile.Close()
+	// Bucket found on too few disks is not recreated.
+	if err = os.Mkdir(filepath.Join(disks[2], "partial-bucket"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = xl.healScan(0, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, bucket := range buckets {
+		for _, object := range objects {
+			expectHealthy(bucket, object)
+		}
+	}
+	expectProgress(4, 3)
+	if _, err = xl.storageDisks[3].StatVol("partial-bucket"); err != errVolumeNotFound {
+		t.Fatalf("Expected partial bucket not to be healed, got %v", err)
+	}
+
+	// Interrupted scan resumes after the last object scanned.
+	for _, bucket := range buckets {
+		if err = os.RemoveAll(filepath.Join(disks[4], bucket, objects[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = xl.writeHealScan(healScanV1{Started: time.Now().UTC(), Bucket: buckets[1], Object: objects[0]}); err != nil {
+		t.Fatal(err)
+	}
+	if err = xl.healScan(0, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	expectHealthy(buckets[1], objects[1])
+	if _, err = readXLMeta(xl.storageDisks[4], buckets[0], objects[1]); err != errFileNotFound {
+		t.Fatalf("Expected object scanned before interruption not to be healed, got %v", err)
+	}
+	expectProgress(1, 1)
+
+	// Stopped scan saves its progress.
+	doneCh := make(chan struct{})
+	close(doneCh)
+	if err = xl.healScan(time.Second, 0, doneCh); err != errWalkAbort {
+		t.Fatalf("Expected scan to be stopped, got %v", err)
+	}
+	scan := xl.readHealScan()
+	if scan.Started.IsZero() || scan.Bucket != buckets[0] || scan.Object != objects[0] || scan.Healed != 0 {
+		t.Fatalf("Expected progress after the first object, got %+v", scan)
+	}
+	if err = xl.healScan(0, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	expectHealthy(buckets[0], objects[1])
+	expectProgress(4, 1)
+
+	// Archived object versions are healed.
+	versionID := getUUID()
+	if err = xl.archiveObject(buckets[0], objects[0], versionID); err != nil {
+		t.Fatal(err)
+	}
+	versionPath := objectVersionPath(buckets[0], objects[0], versionID)
+	if err = os.RemoveAll(filepath.Join(disks[5], minioMetaBucket, versionPath)); err != nil {
+		t.Fatal(err)
+	}
+	if err = xl.healScan(0, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	expectHealthy(minioMetaBucket, versionPath)
+	expectProgress(4, 1)
+}
@@ -16,7 +16,15 @@
 
 package main
 
-import "sync"
+import (
+	"path"
+	"reflect"
+	"sync"
+)
+
+// Maximum number of objects waiting to be healed, objects found
+// degraded while the queue is full are queued again on next read.
+const maxHealQueueSize = 1000
 
 // Get the highest integer from a given integer slice.
 func highestInt(intSlice []int64, highestInt int64) (highestInteger int64) {
@@ -24,7 +32,6 @@ func highestInt(intSlice []int64, highestInt int64) (highestInteger int64) {
 	for _, integer := range intSlice {
 		if highestInteger < integer {
 			highestInteger = integer
-			break
 		}
 	}
 	return highestInteger
@@ -77,18 +84,41 @@ func (xl xlObjects) readAllXLMetadata(bucket, object string) ([]xlMetaV1, []erro
 	return metadataArray, errs
 }
 
-func (xl xlObjects) shouldHeal(onlineDisks []StorageAPI) (heal bool) {
-	onlineDiskCount := diskCount(onlineDisks)
-	// If online disks count is lesser than configured disks, most
-	// probably we need to heal the file, additionally verify if the
-	// count is lesser than readQuorum, if not we throw an error.
-	if onlineDiskCount < len(xl.storageDisks) {
-		// Online disks lesser than total storage disks, needs to be
-		// healed. unless we do not have readQuorum.
+// outDatedDisks - returns the disks holding a stale or missing
+// `xl.json` of the object, offline disks cannot be healed and are
+// not returned.
+func (xl xlObjects) outDatedDisks(onlineDisks []StorageAPI, errs []error) []StorageAPI {
+	outDatedDisks := make([]StorageAPI, len(xl.storageDisks))
+	for index, disk := range xl.storageDisks {
+		if disk == nil || errs[index] == errDiskNotFound {
+			continue
+		}
+		if onlineDisks[index] == nil || errs[index] != nil {
+			outDatedDisks[index] = disk
+		}
+	}
+	return outDatedDisks
+}
+
+// shouldHeal - returns true if the object, erasure coded with the data
+// blocks, has disks to be healed and enough latest disks to heal them
+// from.
+func (xl xlObjects) shouldHeal(onlineDisks []StorageAPI, errs []error, dataBlocks int) (heal bool) {
+	outDatedDisks := xl.outDatedDisks(onlineDisks, errs)
+	// Online disks which are not outdated hold the latest version.
+	latestDiskCount := 0
+	for index, disk := range onlineDisks {
+		if disk != nil && outDatedDisks[index] == nil {
+			latestDiskCount++
+		}
+	}
+	if diskCount(outDatedDisks) > 0 {
+		// Outdated disks need to be healed, unless we do not have
+		// the read quorum of the object.
 		heal = true
-		// Verify if online disks count are lesser than readQuorum
-		// threshold, return an error.
-		if onlineDiskCount < xl.readQuorum {
+		// Verify if latest disks count are lesser than the read
+		// quorum threshold, return an error.
+		if latestDiskCount < xl.objectQuorum(dataBlocks) {
 			errorIf(errXLReadQuorum, "Unable to establish read quorum, disks are offline.")
 			return false
 		}
@@ -120,3 +150,354 @@ func (xl xlObjects) listOnlineDisks(partsMetadata []xlMetaV1, errs []error) (onl
 	}
 	return onlineDisks, highestVersion, nil
 }
+
+// healRequest - an object queued for healing.
+type healRequest struct {
+	bucket string
+	object string
+}
+
+// healQueue - objects found degraded on read, queued for asynchronous
+// healing. An object is queued only once until it is healed.
+type healQueue struct {
+	queued  map[healRequest]struct{}
+	queueCh chan healRequest
+	lock    *sync.Mutex
+}
+
+// newHealQueue - initialize new heal queue.
+func newHealQueue() *healQueue {
+	return &healQueue{
+		queued:  make(map[healRequest]struct{}),
+		queueCh: make(chan healRequest, maxHealQueueSize),
+		lock:    &sync.Mutex{},
+	}
+}
+
+// Add - queues an object for healing unless already queued, objects
+// are dropped if the queue is full.
+func (q *healQueue) Add(bucket, object string) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	req := healRequest{bucket, object}
+	if _, ok := q.queued[req]; ok {
+		return
+	}
+	select {
+	case q.queueCh <- req:
+		q.queued[req] = struct{}{}
+	default:
+		// Queue is full.
+	}
+}
+
+// Done - removes a healed object from the queue, it can be queued
+// again when found degraded.
+func (q *healQueue) Done(req healRequest) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	delete(q.queued, req)
+}
+
+// healRoutine - heals the queued objects one at a time, returns when
+// doneCh is closed.
+func (xl xlObjects) healRoutine(doneCh <-chan struct{}) {
+	for {
+		select {
+		case <-doneCh:
+			return
+		case req := <-xl.healQueue.queueCh:
+			if _, err := xl.healObject(req.bucket, req.object); err != nil {
+				errorIf(err, "Unable to heal %s/%s.", req.bucket, req.object)
+			}
+			xl.healQueue.Done(req)
+		}
+	}
+}
+
+// listObjectHealDisks - returns the disks holding the latest version
+// of the object along with all its parts passing their checksums, the
+// outdated disks holding a stale or missing `xl.json` or parts which
+// fail their checksums, and the latest `xl.json`. Returns
+// errXLReadQuorum if outdated disks cannot be healed.
+func (xl xlObjects) listObjectHealDisks(bucket, object string) (latestDisks, outDatedDisks []StorageAPI, latestMeta xlMetaV1, err error) {
+	// Read metadata associated with the object from all disks.
+	partsMetadata, errs := xl.readAllXLMetadata(bucket, object)
+	if !isQuorum(errs, xl.readQuorum) {
+		return nil, nil, xlMetaV1{}, errXLReadQuorum
+	}
+	onlineDisks, highestVersion, err := xl.listOnlineDisks(partsMetadata, errs)
+	if err != nil {
+		return nil, nil, xlMetaV1{}, err
+	}
+
+	outDatedDisks = xl.outDatedDisks(onlineDisks, errs)
+	latestDisks = make([]StorageAPI, len(xl.storageDisks))
+	for index, disk := range onlineDisks {
+		if disk == nil || outDatedDisks[index] != nil {
+			continue
+		}
+		if !partsMetadata[index].IsValid() || partsMetadata[index].Stat.Version != highestVersion {
+			outDatedDisks[index] = disk
+			continue
+		}
+		for _, part := range partsMetadata[index].Parts {
+			checkSum := partsMetadata[index].Erasure.PartObjectChecksum(part.Name)
+			if !isValidBlock(disk, bucket, pathJoin(object, part.Name), part.Size, partsMetadata[index].Erasure, checkSum) {
+				outDatedDisks[index] = disk
+				break
+			}
+		}
+		if outDatedDisks[index] == nil {
+			latestDisks[index] = disk
+			latestMeta = partsMetadata[index]
+		}
+	}
+	if diskCount(outDatedDisks) > 0 && diskCount(latestDisks) < xl.objectQuorum(latestMeta.Erasure.DataBlocks) {
+		return nil, nil, xlMetaV1{}, errXLReadQuorum
+	}
+	return latestDisks, outDatedDisks, latestMeta, nil
+}
+
+// checkHealObject - returns the number of disks healObject would heal,
+// without healing them.
+func (xl xlObjects) checkHealObject(bucket, object string) (int, error) {
+	nsMutex.RLock(bucket, object)
+	defer nsMutex.RUnlock(bucket, object)
+
+	if !xl.isObject(bucket, object) {
+		return 0, nil
+	}
+	_, outDatedDisks, _, err := xl.listObjectHealDisks(bucket, object)
+	if err != nil {
+		return 0, err
+	}
+	return diskCount(outDatedDisks), nil
+}
+
+// healObject - rewrites the parts and `xl.json` of an object on the
+// disks holding a stale or missing `xl.json` or parts which fail
+// their checksums, rebuilt from the disks holding the latest version.
+// The object is verified and rebuilt holding its read lock, the write
+// lock is held only while the rebuilt object is renamed into place.
+// Returns the number of disks healed.
+func (xl xlObjects) healObject(bucket, object string) (int, error) {
+	healed, _, err := xl.healObjectVersion(bucket, object, "")
+	return healed, err
+}
+
+// healObjectVersion - heals the archived version versionID of an
+// object, or the object itself if versionID is empty, as healObject.
+// Returns the number of disks healed and the size of the object.
+func (xl xlObjects) healObjectVersion(bucket, object, versionID string) (int, int64, error) {
+	// Archived versions are locked along with their object.
+	dataBucket, dataObject := bucket, object
+	if versionID != "" {
+		dataBucket, dataObject = minioMetaBucket, objectVersionPath(bucket, object, versionID)
+	}
+
+	minioMetaTmpBucket := path.Join(minioMetaBucket, tmpMetaPrefix)
+	tempObj := getUUID()
+	defer xl.deleteObject(minioMetaTmpBucket, tempObj)
+
+	healDisks, latestMeta, checkSums, err := xl.rebuildObject(bucket, object, dataBucket, dataObject, tempObj)
+	if err != nil || diskCount(healDisks) == 0 {
+		return 0, latestMeta.Stat.Size, err
+	}
+
+	nsMutex.Lock(bucket, object)
+	defer nsMutex.Unlock(bucket, object)
+
+	// Object may have been replaced or removed since it was rebuilt,
+	// its metadata may have been updated in place.
+	partsMetadata, errs := xl.readAllXLMetadata(dataBucket, dataObject)
+	currentMeta, ok := findObjectData(partsMetadata, errs, latestMeta)
+	if !ok {
+		return 0, latestMeta.Stat.Size, nil
+	}
+
+	// Replace the object on the outdated disks, disks which fail are
+	// left for the next heal.
+	healed := 0
+	for index, disk := range healDisks {
+		if disk == nil {
+			continue
+		}
+		newMeta := currentMeta
+		newMeta.Erasure.Index = index + 1
+		newMeta.Erasure.Checksum = checkSums[index]
+		if err = writeXLMetadata(disk, minioMetaTmpBucket, tempObj, newMeta); err != nil {
+			errorIf(err, "Unable to heal %s/%s on disk %d.", dataBucket, dataObject, index)
+			continue
+		}
+		if err = cleanupDir(disk, dataBucket, dataObject); err != nil && err != errFileNotFound {
+			errorIf(err, "Unable to heal %s/%s on disk %d.", dataBucket, dataObject, index)
+			continue
+		}
+		if err = disk.RenameFile(minioMetaTmpBucket, retainSlash(tempObj), dataBucket, retainSlash(dataObject)); err != nil {
+			errorIf(err, "Unable to heal %s/%s on disk %d.", dataBucket, dataObject, index)
+			continue
+		}
+		healed++
+	}
+	if healed == 0 {
+		return 0, currentMeta.Stat.Size, err
+	}
+	return healed, currentMeta.Stat.Size, nil
+}
+
+// rebuildObject - holding the read lock of the object, rebuilds all
+// the parts of the object at dataObject on dataBucket, at tempObj on
+// the outdated disks. Returns the disks all parts were rebuilt on, the
+// latest `xl.json` and the checksums of the rebuilt parts of each disk.
+func (xl xlObjects) rebuildObject(bucket, object, dataBucket, dataObject, tempObj string) (healDisks []StorageAPI, latestMeta xlMetaV1, checkSums [][]checkSumInfo, err error) {
+	nsMutex.RLock(bucket, object)
+	defer nsMutex.RUnlock(bucket, object)
+
+	// Object may have been removed since it was queued.
+	if !xl.isObject(dataBucket, dataObject) {
+		return nil, xlMetaV1{}, nil, nil
+	}
+
+	latestDisks, healDisks, latestMeta, err := xl.listObjectHealDisks(dataBucket, dataObject)
+	if err != nil || diskCount(healDisks) == 0 {
+		return nil, latestMeta, nil, err
+	}
+
+	minioMetaTmpBucket := path.Join(minioMetaBucket, tmpMetaPrefix)
+	checkSums = make([][]checkSumInfo, len(xl.storageDisks))
+	for _, part := range latestMeta.Parts {
+		partCheckSums, hErr := erasureHealFile(latestDisks, healDisks, dataBucket, pathJoin(dataObject, part.Name), minioMetaTmpBucket, pathJoin(tempObj, part.Name), part.Name, part.Size, latestMeta.Erasure)
+		if hErr != nil {
+			return nil, xlMetaV1{}, nil, hErr
+		}
+		for index, disk := range healDisks {
+			if disk == nil {
+				continue
+			}
+			// Disks which failed to be written are not healed.
+			if partCheckSums[index].Name == "" {
+				healDisks[index] = nil
+				continue
+			}
+			checkSums[index] = append(checkSums[index], partCheckSums[index])
+		}
+	}
+	return healDisks, latestMeta, checkSums, nil
+}
+
+// findObjectData - returns the `xl.json` of the latest version of the
+// object if its data is the one of latestMeta, metadata may differ.
+func findObjectData(partsMetadata []xlMetaV1, errs []error, latestMeta xlMetaV1) (xlMetaV1, bool) {
+	for index, xlMeta := range partsMetadata {
+		if errs[index] != nil || xlMeta.Stat.Version != latestMeta.Stat.Version {
+			continue
+		}
+		if xlMeta.Stat.Size == latestMeta.Stat.Size && xlMeta.Stat.ModTime.Equal(latestMeta.Stat.ModTime) &&
+			xlMeta.VersionID == latestMeta.VersionID && reflect.DeepEqual(xlMeta.Parts, latestMeta.Parts) {
+			return xlMeta, true
+		}
+	}
+	return xlMetaV1{}, false
+}
+
+// healFormat - repairs missing `format.json` on replaced disks.
+// Returns the indexes of the disks healed, or which would be healed on
+// dry run.
+func (xl xlObjects) healFormat(dryRun bool) ([]int, error) {
+	_, errs := loadAllFormats(xl.storageDisks)
+	var formatDisks []int
+	for index, err := range errs {
+		if err == errUnformattedDisk {
+			formatDisks = append(formatDisks, index)
+		}
+	}
+	if len(formatDisks) == 0 || dryRun {
+		return formatDisks, nil
+	}
+	// Replaced disks are empty, create `.minio` to hold `format.json`
+	// and `.minio/tmp` to heal objects onto.
+	for _, index := range formatDisks {
+		for _, volume := range []string{minioMetaBucket, path.Join(minioMetaBucket, tmpMetaPrefix)} {
+			if err := xl.storageDisks[index].MakeVol(volume); err != nil && err != errVolumeExists {
+				return nil, err
+			}
+		}
+	}
+	if err := healFormatXL(xl.storageDisks); err != nil {
+		return nil, err
+	}
+	return formatDisks, nil
+}
+
+// heal - heals the whole deployment when bucket is empty, otherwise a
+// bucket, all objects under a prefix or a single object. Nothing is
+// healed on dry run, the result lists what would be healed.
+func (xl xlObjects) heal(bucket, prefix, object string, dryRun bool) (healResult, error) {
+	result := healResult{DryRun: dryRun}
+
+	allBuckets := bucket == ""
+	buckets := []string{bucket}
+	if allBuckets {
+		// Heal `format.json` first, buckets and objects are then healed
+		// onto the replaced disks.
+		formatDisks, err := xl.healFormat(dryRun)
+		if err != nil {
+			return result, err
+		}
+		result.FormatDisks = formatDisks
+		buckets = xl.listAllBuckets()
+	}
+
+	healObject := xl.healObject
+	if dryRun {
+		healObject = xl.checkHealObject
+	}
+	addObjectResult := func(bucket, object string) {
+		result.Scanned++
+		disks, err := healObject(bucket, object)
+		if err == nil && disks == 0 {
+			return
+		}
+		if len(result.Objects) == maxHealObjectResults {
+			result.Truncated = true
+			return
+		}
+		if err != nil {
+			result.Objects = append(result.Objects, healObjectResult{Bucket: bucket, Object: object, Error: err.Error()})
+		} else {
+			result.Objects = append(result.Objects, healObjectResult{Bucket: bucket, Object: object, Disks: disks})
+		}
+	}
+
+	for _, bucket := range buckets {
+		disks, err := xl.healBucket(bucket, dryRun)
+		if err != nil {
+			// Buckets may be deleted while the deployment is healed.
+			if err == errVolumeNotFound && allBuckets {
+				continue
+			}
+			return result, toObjectErr(err, bucket)
+		}
+		if disks > 0 {
+			result.Buckets = append(result.Buckets, healBucketResult{Bucket: bucket, Disks: disks})
+		}
+		if object != "" {
+			if !xl.isObject(bucket, object) {
+				return result, ObjectNotFound{Bucket: bucket, Object: object}
+			}
+			addObjectResult(bucket, object)
+			continue
+		}
+		err = xl.walkAllDisks(bucket, "", prefix, "", func(object string) error {
+			addObjectResult(bucket, object)
+			return nil
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
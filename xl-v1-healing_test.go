@@ -0,0 +1,160 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Tests healing objects with missing and corrupted parts.
+func TestXLHealObject(t *testing.T) {
+	objLayer, disks, err := getXLObjectLayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(disks)
+	xl := objLayer.(xlObjects)
+
+	bucket, object := "heal-bucket", "object"
+	if err = objLayer.MakeBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+	// Object spans two erasure blocks.
+	data := make([]byte, blockSizeV1+1024)
+	rand.New(rand.NewSource(1)).Read(data)
+	if _, err = objLayer.PutObject(bucket, object, int64(len(data)), bytes.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+	expectData := func() {
+		var buffer bytes.Buffer
+		if err = objLayer.GetObject(bucket, object, 0, int64(len(data)), &buffer); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buffer.Bytes(), data) {
+			t.Fatal("Object data does not match")
+		}
+	}
+	expectHealthy := func(index int) {
+		xlMeta, rErr := readXLMeta(xl.storageDisks[index], bucket, object)
+		if rErr != nil {
+			t.Fatalf("Disk %d: %s", index, rErr)
+		}
+		if xlMeta.Erasure.Index != index+1 {
+			t.Fatalf("Disk %d: Expected erasure index %d, got %d", index, index+1, xlMeta.Erasure.Index)
+		}
+		checkSum := xlMeta.Erasure.PartObjectChecksum("part.1")
+		if !isValidBlock(xl.storageDisks[index], bucket, pathJoin(object, "part.1"), xlMeta.Parts[0].Size, xlMeta.Erasure, checkSum) {
+			t.Fatalf("Disk %d: Expected part to pass its checksum", index)
+		}
+	}
+
+	// Object missing on one disk and corrupted on another.
+	if err = os.RemoveAll(filepath.Join(disks[0], bucket, object)); err != nil {
+		t.Fatal(err)
+	}
+	partFile, err := os.OpenFile(filepath.Join(disks[1], bucket, object, "part.1"), os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = partFile.WriteAt([]byte("corrupted"), 1024); err != nil {
+		t.Fatal(err)
+	}
+	partFile.Close()
+
+	healed, err := xl.healObject(bucket, object)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if healed != 2 {
+		t.Fatalf("Expected 2 disks to be healed, got %d", healed)
+	}
+	for index := range disks {
+		expectHealthy(index)
+	}
+	expectData()
+	if healed, err = xl.healObject(bucket, object); err != nil || healed != 0 {
+		t.Fatalf("Expected healthy object not to be healed, got %d, %v", healed, err)
+	}
+
+	// Objects found degraded on read are healed asynchronously.
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	go xl.healRoutine(doneCh)
+	if err = os.RemoveAll(filepath.Join(disks[3], bucket, object)); err != nil {
+		t.Fatal(err)
+	}
+	expectData()
+	for i := 0; ; i++ {
+		if _, err = os.Stat(filepath.Join(disks[3], bucket, object, xlMetaJSONFile)); err == nil {
+			break
+		}
+		if i == 100 {
+			t.Fatal("Expected object to be healed on read")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	// Healed object is renamed into place once fully rebuilt.
+	expectData()
+	expectHealthy(3)
+
+	// Removed objects are not healed.
+	if err = objLayer.DeleteObject(bucket, object); err != nil {
+		t.Fatal(err)
+	}
+	if healed, err = xl.healObject(bucket, object); err != nil || healed != 0 {
+		t.Fatalf("Expected removed object not to be healed, got %d, %v", healed, err)
+	}
+}
+
+// Tests objects are healed only with enough latest disks to read them.
+func TestXLShouldHeal(t *testing.T) {
+	objLayer, disks, err := getXLObjectLayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(disks)
+	xl := objLayer.(xlObjects)
+
+	testCases := []struct {
+		outDated   int
+		dataBlocks int
+		heal       bool
+	}{
+		{0, len(disks) / 2, false},
+		{3, len(disks) / 2, true},
+		{len(disks)/2 + 1, len(disks) / 2, false},
+		// Reduced redundancy objects need more latest disks.
+		{defaultRRSParity, len(disks) - defaultRRSParity, true},
+		{defaultRRSParity + 1, len(disks) - defaultRRSParity, false},
+	}
+	for i, testCase := range testCases {
+		onlineDisks := make([]StorageAPI, len(xl.storageDisks))
+		copy(onlineDisks, xl.storageDisks)
+		errs := make([]error, len(xl.storageDisks))
+		for index := 0; index < testCase.outDated; index++ {
+			onlineDisks[index] = nil
+		}
+		if heal := xl.shouldHeal(onlineDisks, errs, testCase.dataBlocks); heal != testCase.heal {
+			t.Errorf("Test %d: Expected heal %v, got %v", i+1, testCase.heal, heal)
+		}
+	}
+}
@@ -46,11 +46,12 @@ func (t byObjectPartNumber) Len() int           { return len(t) }
 func (t byObjectPartNumber) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
 func (t byObjectPartNumber) Less(i, j int) bool { return t[i].Number < t[j].Number }
 
-// checkSumInfo - carries checksums of individual scattered parts per disk.
+// checkSumInfo - carries checksums of individual scattered parts per
+// disk. Parts with block hashes carry no whole part hash.
 type checkSumInfo struct {
 	Name      string `json:"name"`
 	Algorithm string `json:"algorithm"`
-	Hash      string `json:"hash"`
+	Hash      string `json:"hash,omitempty"`
 }
 
 // erasureInfo - carries erasure coding related information, block
@@ -101,6 +102,8 @@ type xlMetaV1 struct {
 	Minio struct {
 		Release string `json:"release"`
 	} `json:"minio"`
+	// Version id of the object, empty if versioning was never configured.
+	VersionID string `json:"versionId,omitempty"`
 	// Metadata map for current object `xl.json`.
 	Meta map[string]string `json:"meta"`
 	// Captures all the individual object `xl.json`.
@@ -279,13 +282,14 @@ func (xl xlObjects) deleteAllXLMetadata(bucket, prefix string, errs []error) {
 }
 
 // writeUniqueXLMetadata - writes unique `xl.json` content for each disk in order.
+// Disks with an empty `xl.json` are skipped, they hold no data of the object.
 func (xl xlObjects) writeUniqueXLMetadata(bucket, prefix string, xlMetas []xlMetaV1) error {
 	var wg = &sync.WaitGroup{}
 	var mErrs = make([]error, len(xl.storageDisks))
 
 	// Start writing `xl.json` to all disks in parallel.
 	for index, disk := range xl.storageDisks {
-		if disk == nil {
+		if disk == nil || !xlMetas[index].IsValid() {
 			mErrs[index] = errDiskNotFound
 			continue
 		}
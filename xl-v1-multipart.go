@@ -22,11 +22,9 @@ import (
 	"fmt"
 	"io"
 	"path"
-	"path/filepath"
 	"strings"
 	"time"
 
-	"github.com/minio/minio/pkg/mimedb"
 	"github.com/skyrings/skyring-common/tools/uuid"
 )
 
@@ -236,17 +234,10 @@ func (xl xlObjects) ListMultipartUploads(bucket, prefix, keyMarker, uploadIDMark
 // all the disks. `uploads.json` carries metadata regarding on going
 // multipart operation on the object.
 func (xl xlObjects) newMultipartUpload(bucket string, object string, meta map[string]string) (uploadID string, err error) {
-	xlMeta := newXLMetaV1(xl.dataBlocks, xl.parityBlocks)
+	xlMeta := newXLMetaV1(xl.getErasureBlocks(getStorageClass(meta)))
 	// If not set default to "application/octet-stream"
 	if meta["content-type"] == "" {
-		contentType := "application/octet-stream"
-		if objectExt := filepath.Ext(object); objectExt != "" {
-			content, ok := mimedb.DB[strings.ToLower(strings.TrimPrefix(objectExt, "."))]
-			if ok {
-				contentType = content.ContentType
-			}
-		}
-		meta["content-type"] = contentType
+		meta["content-type"] = getUploadContentType(object)
 	}
 	xlMeta.Stat.ModTime = time.Now().UTC()
 	xlMeta.Stat.Version = 1
@@ -350,7 +341,7 @@ func (xl xlObjects) putObjectPart(bucket string, object string, uploadID string,
 	}
 
 	// Erasure code data and write across all disks.
-	newEInfos, n, err := erasureCreateFile(onlineDisks, minioMetaBucket, tmpPartPath, partSuffix, teeReader, eInfos, xl.writeQuorum)
+	newEInfos, n, err := erasureCreateFile(onlineDisks, minioMetaBucket, tmpPartPath, partSuffix, teeReader, eInfos, xl.objectQuorum(xlMeta.Erasure.DataBlocks))
 	if err != nil {
 		return "", toObjectErr(err, minioMetaBucket, tmpPartPath)
 	}
@@ -446,6 +437,7 @@ func (xl xlObjects) listObjectParts(bucket, object, uploadID string, partNumberM
 	result.Object = object
 	result.UploadID = uploadID
 	result.MaxParts = maxParts
+	result.UserDefined = xlMeta.Meta
 
 	// For empty number of parts or maxParts as zero, return right here.
 	if len(xlMeta.Parts) == 0 || maxParts == 0 {
@@ -529,16 +521,24 @@ func (xl xlObjects) ListObjectParts(bucket, object, uploadID string, partNumberM
 //
 // Implements S3 compatible Complete multipart API.
 func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, uploadID string, parts []completePart) (string, error) {
+	objInfo, err := xl.CompleteMultipartUploadIf(bucket, object, uploadID, parts, writeCondition{})
+	return objInfo.MD5Sum, err
+}
+
+// CompleteMultipartUploadIf - completes an ongoing multipart
+// transaction if the current object meets the write condition, the
+// upload is left intact otherwise.
+func (xl xlObjects) CompleteMultipartUploadIf(bucket string, object string, uploadID string, parts []completePart, cond writeCondition) (ObjectInfo, error) {
 	// Verify if bucket is valid.
 	if !IsValidBucketName(bucket) {
-		return "", BucketNameInvalid{Bucket: bucket}
+		return ObjectInfo{}, BucketNameInvalid{Bucket: bucket}
 	}
 	// Verify whether the bucket exists.
 	if !xl.isBucketExist(bucket) {
-		return "", BucketNotFound{Bucket: bucket}
+		return ObjectInfo{}, BucketNotFound{Bucket: bucket}
 	}
 	if !IsValidObjectName(object) {
-		return "", ObjectNameInvalid{
+		return ObjectInfo{}, ObjectNameInvalid{
 			Bucket: bucket,
 			Object: object,
 		}
@@ -550,12 +550,12 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 	defer nsMutex.Unlock(minioMetaBucket, pathJoin(mpartMetaPrefix, bucket, object, uploadID))
 
 	if !xl.isUploadIDExists(bucket, object, uploadID) {
-		return "", InvalidUploadID{UploadID: uploadID}
+		return ObjectInfo{}, InvalidUploadID{UploadID: uploadID}
 	}
 	// Calculate s3 compatible md5sum for complete multipart.
 	s3MD5, err := completeMultipartMD5(parts...)
 	if err != nil {
-		return "", err
+		return ObjectInfo{}, err
 	}
 
 	uploadIDPath := pathJoin(mpartMetaPrefix, bucket, object, uploadID)
@@ -564,7 +564,7 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 	partsMetadata, errs := xl.readAllXLMetadata(minioMetaBucket, uploadIDPath)
 	// Do we have writeQuorum?.
 	if !isQuorum(errs, xl.writeQuorum) {
-		return "", toObjectErr(errXLWriteQuorum, bucket, object)
+		return ObjectInfo{}, toObjectErr(errXLWriteQuorum, bucket, object)
 	}
 
 	// Calculate full object size.
@@ -584,17 +584,17 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 		partIdx := currentXLMeta.ObjectPartIndex(part.PartNumber)
 		// All parts should have same part number.
 		if partIdx == -1 {
-			return "", InvalidPart{}
+			return ObjectInfo{}, InvalidPart{}
 		}
 
 		// All parts should have same ETag as previously generated.
 		if currentXLMeta.Parts[partIdx].ETag != part.ETag {
-			return "", BadDigest{}
+			return ObjectInfo{}, BadDigest{}
 		}
 
 		// All parts except the last part has to be atleast 5MB.
 		if (i < len(parts)-1) && !isMinAllowedPartSize(currentXLMeta.Parts[partIdx].Size) {
-			return "", PartTooSmall{
+			return ObjectInfo{}, PartTooSmall{
 				PartNumber: part.PartNumber,
 				PartSize:   currentXLMeta.Parts[partIdx].Size,
 				PartETag:   part.ETag,
@@ -620,15 +620,32 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 		}
 	}
 
+	// Hold write lock on the destination before the upload is
+	// committed, the upload is left intact unless the current object
+	// meets the write condition.
+	nsMutex.Lock(bucket, object)
+	defer nsMutex.Unlock(bucket, object)
+
+	if err = checkWriteCondition(xl, bucket, object, cond); err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+
 	// Check if an object is present as one of the parent dir.
 	if xl.parentDirIsObject(bucket, path.Dir(object)) {
-		return "", toObjectErr(errFileAccessDenied, bucket, object)
+		return ObjectInfo{}, toObjectErr(errFileAccessDenied, bucket, object)
 	}
 
 	// Save the final object size and modtime.
 	xlMeta.Stat.Size = objectSize
 	xlMeta.Stat.ModTime = time.Now().UTC()
 
+	// Save the version id of the object.
+	versionID, err := newObjectVersionID(bucket)
+	if err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+	xlMeta.VersionID = versionID
+
 	// Save successfully calculated md5sum.
 	xlMeta.Meta["md5Sum"] = s3MD5
 	uploadIDPath = path.Join(mpartMetaPrefix, bucket, object, uploadID)
@@ -638,28 +655,32 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 	// checksum which are different on each disks.
 	for index := range partsMetadata {
 		partsMetadata[index].Stat = xlMeta.Stat
+		partsMetadata[index].VersionID = xlMeta.VersionID
 		partsMetadata[index].Meta = xlMeta.Meta
 		partsMetadata[index].Parts = xlMeta.Parts
 	}
 
 	// Write unique `xl.json` for each disk.
 	if err = xl.writeUniqueXLMetadata(minioMetaBucket, tempUploadIDPath, partsMetadata); err != nil {
-		return "", toObjectErr(err, minioMetaBucket, tempUploadIDPath)
+		return ObjectInfo{}, toObjectErr(err, minioMetaBucket, tempUploadIDPath)
 	}
 	rErr := xl.commitXLMetadata(tempUploadIDPath, uploadIDPath)
 	if rErr != nil {
-		return "", toObjectErr(rErr, minioMetaBucket, uploadIDPath)
+		return ObjectInfo{}, toObjectErr(rErr, minioMetaBucket, uploadIDPath)
 	}
-	// Hold write lock on the destination before rename.
-	nsMutex.Lock(bucket, object)
-	defer nsMutex.Unlock(bucket, object)
 
-	// Rename if an object already exists to temporary location.
+	// Rename if an object already exists to temporary location, with
+	// versioning configured it is archived instead.
+	var versions versionsV1
 	uniqueID := getUUID()
-	if xl.isObject(bucket, object) {
+	if versionID != "" {
+		if versions, err = prepareObjectVersion(xl, bucket, object, versionID); err != nil {
+			return ObjectInfo{}, toObjectErr(err, bucket, object)
+		}
+	} else if xl.isObject(bucket, object) {
 		err = xl.renameObject(bucket, object, minioMetaBucket, path.Join(tmpMetaPrefix, uniqueID))
 		if err != nil {
-			return "", toObjectErr(err, bucket, object)
+			return ObjectInfo{}, toObjectErr(err, bucket, object)
 		}
 	}
 
@@ -678,12 +699,25 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 
 	// Rename the multipart object to final location.
 	if err = xl.renameObject(minioMetaBucket, uploadIDPath, bucket, object); err != nil {
-		return "", toObjectErr(err, bucket, object)
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
 	}
 
 	// Delete the previously successfully renamed object.
 	xl.deleteObject(minioMetaBucket, path.Join(tmpMetaPrefix, uniqueID))
 
+	if versionID != "" {
+		err = commitObjectVersion(xl, bucket, object, versions, versionInfo{
+			VersionID: versionID,
+			ModTime:   xlMeta.Stat.ModTime,
+			Size:      xlMeta.Stat.Size,
+			MD5Sum:    s3MD5,
+		})
+		if err != nil {
+			return ObjectInfo{}, toObjectErr(err, bucket, object)
+		}
+	}
+	objInfo := newXLObjectInfo(bucket, object, xlMeta)
+
 	// Hold the lock so that two parallel complete-multipart-uploads do not
 	// leave a stale uploads.json behind.
 	nsMutex.Lock(minioMetaBucket, pathJoin(mpartMetaPrefix, bucket, object))
@@ -704,7 +738,7 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 		break
 	}
 	if err != nil {
-		return "", toObjectErr(err, minioMetaBucket, object)
+		return ObjectInfo{}, toObjectErr(err, minioMetaBucket, object)
 	}
 	// If we have successfully read `uploads.json`, then we proceed to
 	// purge or update `uploads.json`.
@@ -714,18 +748,18 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 	}
 	if len(uploadsJSON.Uploads) > 0 {
 		if err = xl.updateUploadsJSON(bucket, object, uploadsJSON); err != nil {
-			return "", toObjectErr(err, minioMetaBucket, path.Join(mpartMetaPrefix, bucket, object))
+			return ObjectInfo{}, toObjectErr(err, minioMetaBucket, path.Join(mpartMetaPrefix, bucket, object))
 		}
 		// Return success.
-		return s3MD5, nil
+		return objInfo, nil
 	} // No more pending uploads for the object, proceed to delete
 	// object completely from '.minio/multipart'.
 	if err = xl.deleteObject(minioMetaBucket, path.Join(mpartMetaPrefix, bucket, object)); err != nil {
-		return "", toObjectErr(err, minioMetaBucket, path.Join(mpartMetaPrefix, bucket, object))
+		return ObjectInfo{}, toObjectErr(err, minioMetaBucket, path.Join(mpartMetaPrefix, bucket, object))
 	}
 
-	// Return md5sum.
-	return s3MD5, nil
+	// Return info of the object.
+	return objInfo, nil
 }
 
 // abortMultipartUpload - wrapper for purging an ongoing multipart
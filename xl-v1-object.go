@@ -20,13 +20,10 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"io"
+	"net/url"
 	"path"
-	"path/filepath"
-	"strings"
 	"sync"
 	"time"
-
-	"github.com/minio/minio/pkg/mimedb"
 )
 
 /// Object Operations
@@ -52,17 +49,24 @@ func (xl xlObjects) GetObject(bucket, object string, startOffset int64, length i
 	nsMutex.RLock(bucket, object)
 	defer nsMutex.RUnlock(bucket, object)
 
+	return toObjectErr(xl.getObject(bucket, object, startOffset, length, writer), bucket, object)
+}
+
+// getObject - wrapper for reading an object erasure coded across
+// multiple disks, writes length bytes of the object at startOffset
+// to the writer.
+func (xl xlObjects) getObject(bucket, object string, startOffset int64, length int64, writer io.Writer) error {
 	// Read metadata associated with the object from all disks.
 	metaArr, errs := xl.readAllXLMetadata(bucket, object)
 	// Do we have read quorum?
 	if !isQuorum(errs, xl.readQuorum) {
-		return toObjectErr(errXLReadQuorum, bucket, object)
+		return errXLReadQuorum
 	}
 
 	// List all online disks.
 	onlineDisks, highestVersion, err := xl.listOnlineDisks(metaArr, errs)
 	if err != nil {
-		return toObjectErr(err, bucket, object)
+		return err
 	}
 
 	// Pick latest valid metadata.
@@ -74,16 +78,19 @@ func (xl xlObjects) GetObject(bucket, object string, startOffset int64, length i
 		}
 	}
 
+	// Disks with stale `xl.json` need to be healed.
+	heal := xl.shouldHeal(onlineDisks, errs, xlMeta.Erasure.DataBlocks)
+
 	// Get start part index and offset.
 	partIndex, partOffset, err := xlMeta.ObjectToPartOffset(startOffset)
 	if err != nil {
-		return toObjectErr(err, bucket, object)
+		return err
 	}
 
 	// Get last part index to read given length.
 	lastPartIndex, _, err := xlMeta.ObjectToPartOffset(startOffset + length - 1)
 	if err != nil {
-		return toObjectErr(err, bucket, object)
+		return err
 	}
 
 	// Collect all the previous erasure infos across the disk.
@@ -109,10 +116,11 @@ func (xl xlObjects) GetObject(bucket, object string, startOffset int64, length i
 		}
 
 		// Start reading the part name.
-		n, err := erasureReadFile(writer, onlineDisks, bucket, pathJoin(object, partName), partName, eInfos, partOffset, readSize, partSize)
+		n, partHeal, err := erasureReadFile(writer, onlineDisks, bucket, pathJoin(object, partName), partName, eInfos, partOffset, readSize, partSize)
 		if err != nil {
 			return err
 		}
+		heal = heal || partHeal
 
 		totalBytesRead += n
 
@@ -121,6 +129,12 @@ func (xl xlObjects) GetObject(bucket, object string, startOffset int64, length i
 		partOffset = 0
 	} // End of read all parts loop.
 
+	// Queue the object to be healed, archived object versions are
+	// locked by their object name hence are not healed.
+	if heal && bucket != minioMetaBucket {
+		xl.healQueue.Add(bucket, object)
+	}
+
 	// Return success.
 	return nil
 }
@@ -152,7 +166,12 @@ func (xl xlObjects) getObjectInfo(bucket, object string) (objInfo ObjectInfo, er
 		// Return error.
 		return ObjectInfo{}, err
 	}
-	objInfo = ObjectInfo{
+	return newXLObjectInfo(bucket, object, xlMeta), nil
+}
+
+// newXLObjectInfo - constructs ObjectInfo from `xl.json` of the object.
+func newXLObjectInfo(bucket, object string, xlMeta xlMetaV1) ObjectInfo {
+	return ObjectInfo{
 		IsDir:           false,
 		Bucket:          bucket,
 		Name:            object,
@@ -161,8 +180,10 @@ func (xl xlObjects) getObjectInfo(bucket, object string) (objInfo ObjectInfo, er
 		MD5Sum:          xlMeta.Meta["md5Sum"],
 		ContentType:     xlMeta.Meta["content-type"],
 		ContentEncoding: xlMeta.Meta["content-encoding"],
+		UserDefined:     xlMeta.Meta,
+		VersionID:       xlMeta.VersionID,
+		Parts:           xlMeta.Parts,
 	}
-	return objInfo, nil
 }
 
 func (xl xlObjects) undoRename(srcBucket, srcEntry, dstBucket, dstEntry string, isPart bool, errs []error) {
@@ -282,16 +303,23 @@ func (xl xlObjects) renameObject(srcBucket, srcObject, dstBucket, dstObject stri
 // writes `xl.json` which carries the necessary metadata for future
 // object operations.
 func (xl xlObjects) PutObject(bucket string, object string, size int64, data io.Reader, metadata map[string]string) (string, error) {
+	objInfo, err := xl.PutObjectIf(bucket, object, size, data, metadata, writeCondition{})
+	return objInfo.MD5Sum, err
+}
+
+// PutObjectIf - creates an object if the current object meets the
+// write condition.
+func (xl xlObjects) PutObjectIf(bucket string, object string, size int64, data io.Reader, metadata map[string]string, cond writeCondition) (ObjectInfo, error) {
 	// Verify if bucket is valid.
 	if !IsValidBucketName(bucket) {
-		return "", BucketNameInvalid{Bucket: bucket}
+		return ObjectInfo{}, BucketNameInvalid{Bucket: bucket}
 	}
 	// Verify bucket exists.
 	if !xl.isBucketExist(bucket) {
-		return "", BucketNotFound{Bucket: bucket}
+		return ObjectInfo{}, BucketNotFound{Bucket: bucket}
 	}
 	if !IsValidObjectName(object) {
-		return "", ObjectNameInvalid{
+		return ObjectInfo{}, ObjectNameInvalid{
 			Bucket: bucket,
 			Object: object,
 		}
@@ -300,33 +328,26 @@ func (xl xlObjects) PutObject(bucket string, object string, size int64, data io.
 	if metadata == nil {
 		metadata = make(map[string]string)
 	}
-	nsMutex.Lock(bucket, object)
-	defer nsMutex.Unlock(bucket, object)
 
 	uniqueID := getUUID()
 	tempErasureObj := path.Join(tmpMetaPrefix, uniqueID, "part.1")
 	minioMetaTmpBucket := path.Join(minioMetaBucket, tmpMetaPrefix)
 	tempObj := uniqueID
 
-	// Initialize xl meta.
-	xlMeta := newXLMetaV1(xl.dataBlocks, xl.parityBlocks)
+	// Initialize xl meta, erasure coded as the storage class.
+	xlMeta := newXLMetaV1(xl.getErasureBlocks(getStorageClass(metadata)))
 
 	// Read metadata associated with the object from all disks.
 	partsMetadata, errs := xl.readAllXLMetadata(bucket, object)
 	// Do we have write quroum?.
 	if !isQuorum(errs, xl.writeQuorum) {
-		return "", toObjectErr(errXLWriteQuorum, bucket, object)
+		return ObjectInfo{}, toObjectErr(errXLWriteQuorum, bucket, object)
 	}
 
 	// List all online disks.
-	onlineDisks, higherVersion, err := xl.listOnlineDisks(partsMetadata, errs)
+	onlineDisks, _, err := xl.listOnlineDisks(partsMetadata, errs)
 	if err != nil {
-		return "", toObjectErr(err, bucket, object)
-	}
-
-	// Increment version only if we have online disks less than configured storage disks.
-	if diskCount(onlineDisks) < len(xl.storageDisks) {
-		higherVersion++
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
 	}
 
 	// Initialize md5 writer.
@@ -343,9 +364,9 @@ func (xl xlObjects) PutObject(bucket string, object string, size int64, data io.
 	}
 
 	// Erasure code and write across all disks.
-	newEInfos, n, err := erasureCreateFile(onlineDisks, minioMetaBucket, tempErasureObj, "part.1", teeReader, eInfos, xl.writeQuorum)
+	newEInfos, n, err := erasureCreateFile(onlineDisks, minioMetaBucket, tempErasureObj, "part.1", teeReader, eInfos, xl.objectQuorum(xlMeta.Erasure.DataBlocks))
 	if err != nil {
-		return "", toObjectErr(err, minioMetaBucket, tempErasureObj)
+		return ObjectInfo{}, toObjectErr(err, minioMetaBucket, tempErasureObj)
 	}
 	if size == -1 {
 		size = n
@@ -361,11 +382,7 @@ func (xl xlObjects) PutObject(bucket string, object string, size int64, data io.
 
 	// Guess content-type from the extension if possible.
 	if metadata["content-type"] == "" {
-		if objectExt := filepath.Ext(object); objectExt != "" {
-			if content, ok := mimedb.DB[strings.ToLower(strings.TrimPrefix(objectExt, "."))]; ok {
-				metadata["content-type"] = content.ContentType
-			}
-		}
+		metadata["content-type"] = guessContentType(object)
 	}
 
 	// md5Hex representation.
@@ -375,23 +392,30 @@ func (xl xlObjects) PutObject(bucket string, object string, size int64, data io.
 			// MD5 mismatch, delete the temporary object.
 			xl.deleteObject(minioMetaTmpBucket, tempObj)
 			// Returns md5 mismatch.
-			return "", BadDigest{md5Hex, newMD5Hex}
+			return ObjectInfo{}, BadDigest{md5Hex, newMD5Hex}
 		}
 	}
 
-	// Check if an object is present as one of the parent dir.
-	// -- FIXME. (needs a new kind of lock).
-	if xl.parentDirIsObject(bucket, path.Dir(object)) {
-		return "", toObjectErr(errFileAccessDenied, bucket, object)
-	}
+	// Object is locked only while the new data is committed, data may
+	// be read from the object itself while it is written above.
+	nsMutex.Lock(bucket, object)
+	defer nsMutex.Unlock(bucket, object)
 
-	// Rename if an object already exists to temporary location.
-	newUniqueID := getUUID()
-	if xl.isObject(bucket, object) {
-		err = xl.renameObject(bucket, object, minioMetaTmpBucket, newUniqueID)
-		if err != nil {
-			return "", toObjectErr(err, bucket, object)
-		}
+	// Version of the new object is derived from the existing object,
+	// same as CopyObject.
+	currentMetadata, errs := xl.readAllXLMetadata(bucket, object)
+	if !isQuorum(errs, xl.writeQuorum) {
+		xl.deleteObject(minioMetaTmpBucket, tempObj)
+		return ObjectInfo{}, toObjectErr(errXLWriteQuorum, bucket, object)
+	}
+	currentDisks, higherVersion, err := xl.listOnlineDisks(currentMetadata, errs)
+	if err != nil {
+		xl.deleteObject(minioMetaTmpBucket, tempObj)
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+	// Increment version only if we have online disks less than configured storage disks.
+	if diskCount(currentDisks) < len(xl.storageDisks) {
+		higherVersion++
 	}
 
 	// Fill all the necessary metadata.
@@ -408,22 +432,429 @@ func (xl xlObjects) PutObject(bucket string, object string, size int64, data io.
 		partsMetadata[index].Erasure = newEInfos[index]
 	}
 
+	// Rename the successfully written temporary object to final location.
+	objInfo, err := xl.commitObject(bucket, object, tempObj, partsMetadata, cond)
+	if err != nil {
+		xl.deleteObject(minioMetaTmpBucket, tempObj)
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+
+	// Successfully wrote object.
+	return objInfo, nil
+}
+
+// UpdateObjectMetadata - replaces the metadata of an existing object,
+// object data and md5sum are left unchanged. A new `xl.json` is written
+// to a temporary location and renamed over the existing one on every
+// disk holding the object. In versioned buckets the object is copied
+// onto itself instead, as a new version.
+func (xl xlObjects) UpdateObjectMetadata(bucket, object string, metadata map[string]string) (ObjectInfo, error) {
+	versioned, err := isBucketVersioned(bucket)
+	if err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket)
+	}
+	if versioned {
+		return xl.CopyObject(bucket, object, bucket, object, metadata)
+	}
+	return xl.updateObjectMetadata(bucket, object, func(map[string]string) map[string]string {
+		return metadata
+	})
+}
+
+// UpdateObjectTags - replaces the tags of an existing object, rest of
+// the object metadata is left unchanged.
+func (xl xlObjects) UpdateObjectTags(bucket, object string, tags url.Values) (ObjectInfo, error) {
+	return xl.updateObjectMetadata(bucket, object, func(metadata map[string]string) map[string]string {
+		setObjectTags(metadata, tags)
+		return metadata
+	})
+}
+
+// updateObjectMetadata - replaces the metadata of an existing object
+// with the one returned by updateMeta for its current metadata, under
+// the object write lock.
+func (xl xlObjects) updateObjectMetadata(bucket, object string, updateMeta func(metadata map[string]string) map[string]string) (ObjectInfo, error) {
+	// Verify if bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return ObjectInfo{}, BucketNameInvalid{Bucket: bucket}
+	}
+	if !IsValidObjectName(object) {
+		return ObjectInfo{}, ObjectNameInvalid{Bucket: bucket, Object: object}
+	}
+	nsMutex.Lock(bucket, object)
+	defer nsMutex.Unlock(bucket, object)
+
+	// Validate object exists.
+	if !xl.isObject(bucket, object) {
+		return ObjectInfo{}, ObjectNotFound{bucket, object}
+	}
+
+	// Read metadata associated with the object from all disks.
+	partsMetadata, errs := xl.readAllXLMetadata(bucket, object)
+	// Do we have write quroum?.
+	if !isQuorum(errs, xl.writeQuorum) {
+		return ObjectInfo{}, toObjectErr(errXLWriteQuorum, bucket, object)
+	}
+	xlMeta := pickValidXLMeta(partsMetadata)
+
+	metadata := make(map[string]string)
+	for key, value := range xlMeta.Meta {
+		metadata[key] = value
+	}
+	newMeta := make(map[string]string)
+	for key, value := range updateMeta(metadata) {
+		newMeta[key] = value
+	}
+	newMeta["md5Sum"] = xlMeta.Meta["md5Sum"]
+	for index := range partsMetadata {
+		if errs[index] != nil {
+			// Disks without the object are left as is.
+			partsMetadata[index] = xlMetaV1{}
+			continue
+		}
+		partsMetadata[index].Meta = newMeta
+	}
+
+	// Write unique `xl.json` for each disk.
+	tempObj := getUUID()
+	minioMetaTmpBucket := path.Join(minioMetaBucket, tmpMetaPrefix)
+	defer xl.deleteObject(minioMetaTmpBucket, tempObj)
+	if err := xl.writeUniqueXLMetadata(minioMetaTmpBucket, tempObj, partsMetadata); err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+
+	// Rename `xl.json` over the existing one in parallel.
+	var wg = &sync.WaitGroup{}
+	var rErrs = make([]error, len(xl.storageDisks))
+	for index, disk := range xl.storageDisks {
+		if disk == nil || errs[index] != nil {
+			rErrs[index] = errDiskNotFound
+			continue
+		}
+		wg.Add(1)
+		go func(index int, disk StorageAPI) {
+			defer wg.Done()
+			rErrs[index] = disk.RenameFile(minioMetaTmpBucket, path.Join(tempObj, xlMetaJSONFile), bucket, path.Join(object, xlMetaJSONFile))
+		}(index, disk)
+	}
+	wg.Wait()
+	if !isQuorum(rErrs, xl.writeQuorum) {
+		return ObjectInfo{}, toObjectErr(errXLWriteQuorum, bucket, object)
+	}
+
+	objInfo, err := xl.getObjectInfo(bucket, object)
+	if err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+	return objInfo, nil
+}
+
+// CopyObject - copies an object without reading or erasure coding its
+// data again. The erasure coded parts of the source are linked on each
+// disk and a new `xl.json` with the given metadata is written for the
+// destination, md5sum of the source is preserved.
+func (xl xlObjects) CopyObject(srcBucket, srcObject, destBucket, destObject string, metadata map[string]string) (ObjectInfo, error) {
+	// Verify if buckets are valid.
+	if !IsValidBucketName(srcBucket) {
+		return ObjectInfo{}, BucketNameInvalid{Bucket: srcBucket}
+	}
+	if !IsValidBucketName(destBucket) {
+		return ObjectInfo{}, BucketNameInvalid{Bucket: destBucket}
+	}
+	// Verify destination bucket exists.
+	if !xl.isBucketExist(destBucket) {
+		return ObjectInfo{}, BucketNotFound{Bucket: destBucket}
+	}
+	if !IsValidObjectName(srcObject) {
+		return ObjectInfo{}, ObjectNameInvalid{Bucket: srcBucket, Object: srcObject}
+	}
+	if !IsValidObjectName(destObject) {
+		return ObjectInfo{}, ObjectNameInvalid{Bucket: destBucket, Object: destObject}
+	}
+
+	// Objects copied to another storage class are erasure coded again.
+	srcInfo, err := xl.getObjectInfo(srcBucket, srcObject)
+	if err != nil {
+		return ObjectInfo{}, toObjectErr(err, srcBucket, srcObject)
+	}
+	storageClass := getStorageClass(metadata)
+	encode := getStorageClass(srcInfo.UserDefined) != storageClass
+
+	// Copying an object onto itself only replaces its metadata, unless
+	// a new version of it is to be created.
+	if srcBucket == destBucket && srcObject == destObject && !encode {
+		versioned, vErr := isBucketVersioned(destBucket)
+		if vErr != nil {
+			return ObjectInfo{}, toObjectErr(vErr, destBucket)
+		}
+		if !versioned {
+			return xl.updateObjectMetadata(destBucket, destObject, func(map[string]string) map[string]string {
+				return metadata
+			})
+		}
+	}
+
+	tempObj := getUUID()
+	minioMetaTmpBucket := path.Join(minioMetaBucket, tmpMetaPrefix)
+
+	// Link the parts of the source to the temporary location, or erasure
+	// code the source again there, source is not locked beyond this
+	// point. Temporary directory is created under the meta bucket as it
+	// may not exist.
+	var partsMetadata []xlMetaV1
+	if encode {
+		partsMetadata, err = xl.encodeObject(srcBucket, srcObject, storageClass, minioMetaBucket, path.Join(tmpMetaPrefix, tempObj))
+	} else {
+		partsMetadata, err = xl.linkObjectParts(srcBucket, srcObject, minioMetaBucket, path.Join(tmpMetaPrefix, tempObj))
+	}
+	if err != nil {
+		xl.deleteObject(minioMetaTmpBucket, tempObj)
+		return ObjectInfo{}, toObjectErr(err, srcBucket, srcObject)
+	}
+
+	nsMutex.Lock(destBucket, destObject)
+	defer nsMutex.Unlock(destBucket, destObject)
+
+	// Version of the new object is derived from the existing
+	// destination, same as PutObject.
+	destMetadata, errs := xl.readAllXLMetadata(destBucket, destObject)
+	if !isQuorum(errs, xl.writeQuorum) {
+		xl.deleteObject(minioMetaTmpBucket, tempObj)
+		return ObjectInfo{}, toObjectErr(errXLWriteQuorum, destBucket, destObject)
+	}
+	onlineDisks, higherVersion, err := xl.listOnlineDisks(destMetadata, errs)
+	if err != nil {
+		xl.deleteObject(minioMetaTmpBucket, tempObj)
+		return ObjectInfo{}, toObjectErr(err, destBucket, destObject)
+	}
+	if diskCount(onlineDisks) < len(xl.storageDisks) {
+		higherVersion++
+	}
+
+	xlMeta := pickValidXLMeta(partsMetadata)
+	newMeta := make(map[string]string)
+	for key, value := range metadata {
+		newMeta[key] = value
+	}
+	newMeta["md5Sum"] = xlMeta.Meta["md5Sum"]
+	modTime := time.Now().UTC()
+	for index := range partsMetadata {
+		// Disks without a copy of the parts are left empty, `xl.json`
+		// is committed only to disks holding the copy.
+		if !partsMetadata[index].IsValid() {
+			continue
+		}
+		partsMetadata[index].Meta = newMeta
+		partsMetadata[index].Stat.ModTime = modTime
+		partsMetadata[index].Stat.Version = higherVersion
+	}
+
+	// Rename the linked object to its final location.
+	objInfo, err := xl.commitObject(destBucket, destObject, tempObj, partsMetadata, writeCondition{})
+	if err != nil {
+		xl.deleteObject(minioMetaTmpBucket, tempObj)
+		return ObjectInfo{}, toObjectErr(err, destBucket, destObject)
+	}
+	return objInfo, nil
+}
+
+// encodeObject - erasure codes the object again as the storage class,
+// as a single part at the destination prefix on every disk. Returns
+// `xl.json` of the object for each disk, the entries of disks without
+// a copy are left empty.
+func (xl xlObjects) encodeObject(bucket, object, storageClass, dstBucket, dstPrefix string) ([]xlMetaV1, error) {
+	// Lock the object before reading, its size and md5sum are read
+	// under the same lock as its data.
+	nsMutex.RLock(bucket, object)
+	defer nsMutex.RUnlock(bucket, object)
+
+	objInfo, err := xl.getObjectInfo(bucket, object)
+	if err != nil {
+		return nil, err
+	}
+
+	xlMeta := newXLMetaV1(xl.getErasureBlocks(storageClass))
+	eInfos := make([]erasureInfo, len(xl.storageDisks))
+	for index := range eInfos {
+		eInfos[index] = xlMeta.Erasure
+	}
+
+	// Read the object while it is erasure coded.
+	pipeReader, pipeWriter := io.Pipe()
+	readDoneCh := make(chan struct{})
+	go func() {
+		defer close(readDoneCh)
+		if objInfo.Size == 0 {
+			pipeWriter.Close()
+			return
+		}
+		pipeWriter.CloseWithError(xl.getObject(bucket, object, 0, objInfo.Size, pipeWriter))
+	}()
+	// Close the reader to unblock the object read on errors, the read
+	// is over before the lock is released.
+	defer func() {
+		pipeReader.Close()
+		<-readDoneCh
+	}()
+
+	md5Writer := md5.New()
+	teeReader := io.TeeReader(pipeReader, md5Writer)
+	newEInfos, n, err := erasureCreateFile(xl.storageDisks, dstBucket, path.Join(dstPrefix, "part.1"), "part.1", teeReader, eInfos, xl.objectQuorum(xlMeta.Erasure.DataBlocks))
+	if err != nil {
+		return nil, err
+	}
+
+	xlMeta.Meta = map[string]string{"md5Sum": objInfo.MD5Sum}
+	xlMeta.Stat.Size = n
+	xlMeta.AddObjectPart(1, "part.1", hex.EncodeToString(md5Writer.Sum(nil)), n)
+	partsMetadata := make([]xlMetaV1, len(xl.storageDisks))
+	for index := range partsMetadata {
+		if newEInfos[index].IsValid() {
+			partsMetadata[index] = xlMeta
+			partsMetadata[index].Erasure = newEInfos[index]
+		}
+	}
+	return partsMetadata, nil
+}
+
+// linkObjectParts - links the erasure coded parts of an object to the
+// destination prefix on every disk holding the latest version of the
+// object. Returns `xl.json` of the object read from each disk, the
+// entries of disks without a linked copy are left empty.
+func (xl xlObjects) linkObjectParts(bucket, object, dstBucket, dstPrefix string) ([]xlMetaV1, error) {
+	// Lock the object before reading.
+	nsMutex.RLock(bucket, object)
+	defer nsMutex.RUnlock(bucket, object)
+
+	// Validate object exists.
+	if !xl.isObject(bucket, object) {
+		return nil, errFileNotFound
+	}
+
+	// Read metadata associated with the object from all disks.
+	partsMetadata, errs := xl.readAllXLMetadata(bucket, object)
+	// Do we have write quorum?
+	if !isQuorum(errs, xl.writeQuorum) {
+		return nil, errXLWriteQuorum
+	}
+
+	// List all online disks.
+	onlineDisks, _, err := xl.listOnlineDisks(partsMetadata, errs)
+	if err != nil {
+		return nil, err
+	}
+
+	var wg = &sync.WaitGroup{}
+	var lErrs = make([]error, len(xl.storageDisks))
+	for index, disk := range onlineDisks {
+		if disk == nil || errs[index] != nil {
+			lErrs[index] = errDiskNotFound
+			continue
+		}
+		wg.Add(1)
+		// Link all the parts on a disk in a routine.
+		go func(index int, disk StorageAPI) {
+			defer wg.Done()
+			for _, part := range partsMetadata[index].Parts {
+				err := disk.LinkFile(bucket, path.Join(object, part.Name), dstBucket, path.Join(dstPrefix, part.Name))
+				if err != nil {
+					lErrs[index] = err
+					return
+				}
+			}
+		}(index, disk)
+	}
+	wg.Wait()
+
+	// Disks which failed to link are not part of the copy.
+	for index, err := range lErrs {
+		if err != nil {
+			partsMetadata[index] = xlMetaV1{}
+		}
+	}
+	// Do we have write quorum?
+	if !isQuorum(lErrs, xl.writeQuorum) {
+		return nil, errXLWriteQuorum
+	}
+	for _, err := range lErrs {
+		if err != nil && err != errDiskNotFound {
+			return nil, err
+		}
+	}
+	return partsMetadata, nil
+}
+
+// commitObject - writes `xl.json` of the object written at the
+// temporary location tempObj and renames it to its final location, if
+// the current object meets the write condition. An existing object is
+// replaced, with versioning configured it is archived instead. To be
+// called with the object write lock held, the version of the object is
+// decided here. Returns the info of the committed object.
+func (xl xlObjects) commitObject(bucket, object, tempObj string, partsMetadata []xlMetaV1, cond writeCondition) (ObjectInfo, error) {
+	minioMetaTmpBucket := path.Join(minioMetaBucket, tmpMetaPrefix)
+
+	if err := checkWriteCondition(xl, bucket, object, cond); err != nil {
+		return ObjectInfo{}, err
+	}
+
+	// Check if an object is present as one of the parent dir.
+	// -- FIXME. (needs a new kind of lock).
+	if xl.parentDirIsObject(bucket, path.Dir(object)) {
+		return ObjectInfo{}, errFileAccessDenied
+	}
+
+	versionID, err := newObjectVersionID(bucket)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	for index := range partsMetadata {
+		if partsMetadata[index].IsValid() {
+			partsMetadata[index].VersionID = versionID
+		}
+	}
+	xlMeta := pickValidXLMeta(partsMetadata)
+
+	// Rename if an object already exists to temporary location, with
+	// versioning configured it is archived instead.
+	newUniqueID := getUUID()
+	if versionID == "" && xl.isObject(bucket, object) {
+		if err = xl.renameObject(bucket, object, minioMetaTmpBucket, newUniqueID); err != nil {
+			return ObjectInfo{}, err
+		}
+	}
+
 	// Write unique `xl.json` for each disk.
 	if err = xl.writeUniqueXLMetadata(minioMetaTmpBucket, tempObj, partsMetadata); err != nil {
-		return "", toObjectErr(err, bucket, object)
+		return ObjectInfo{}, err
+	}
+
+	var versions versionsV1
+	if versionID != "" {
+		if versions, err = prepareObjectVersion(xl, bucket, object, versionID); err != nil {
+			return ObjectInfo{}, err
+		}
 	}
 
 	// Rename the successfully written temporary object to final location.
-	err = xl.renameObject(minioMetaTmpBucket, tempObj, bucket, object)
-	if err != nil {
-		return "", toObjectErr(err, bucket, object)
+	if err = xl.renameObject(minioMetaTmpBucket, tempObj, bucket, object); err != nil {
+		return ObjectInfo{}, err
 	}
 
 	// Delete the temporary object.
 	xl.deleteObject(minioMetaTmpBucket, newUniqueID)
 
-	// Return md5sum, successfully wrote object.
-	return newMD5Hex, nil
+	if versionID != "" {
+		err = commitObjectVersion(xl, bucket, object, versions, versionInfo{
+			VersionID: versionID,
+			ModTime:   xlMeta.Stat.ModTime,
+			Size:      xlMeta.Stat.Size,
+			MD5Sum:    xlMeta.Meta["md5Sum"],
+		})
+		if err != nil {
+			return ObjectInfo{}, err
+		}
+	}
+	return newXLObjectInfo(bucket, object, xlMeta), nil
 }
 
 // deleteObject - wrapper for delete object, deletes an object from
@@ -467,27 +898,52 @@ func (xl xlObjects) deleteObject(bucket, object string) error {
 // any error as it is not necessary for the handler to reply back a
 // response to the client request.
 func (xl xlObjects) DeleteObject(bucket, object string) (err error) {
+	_, err = xl.DeleteObjectIf(bucket, object, writeCondition{})
+	return err
+}
+
+// DeleteObjectIf - deletes an object if it meets the write condition.
+// With versioning configured, returns the info of the delete marker
+// placed.
+func (xl xlObjects) DeleteObjectIf(bucket, object string, cond writeCondition) (ObjectInfo, error) {
 	// Verify if bucket is valid.
 	if !IsValidBucketName(bucket) {
-		return BucketNameInvalid{Bucket: bucket}
+		return ObjectInfo{}, BucketNameInvalid{Bucket: bucket}
 	}
 	if !IsValidObjectName(object) {
-		return ObjectNameInvalid{Bucket: bucket, Object: object}
+		return ObjectInfo{}, ObjectNameInvalid{Bucket: bucket, Object: object}
 	}
 	nsMutex.Lock(bucket, object)
 	defer nsMutex.Unlock(bucket, object)
 
+	if err := checkWriteCondition(xl, bucket, object, cond); err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+
+	// With versioning configured the object is preserved behind a
+	// delete marker.
+	versionID, err := newObjectVersionID(bucket)
+	if err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+	if versionID != "" {
+		markerInfo, err := deleteObjectWithMarker(xl, bucket, object, versionID)
+		if err != nil {
+			return ObjectInfo{}, toObjectErr(err, bucket, object)
+		}
+		return markerInfo, nil
+	}
+
 	// Validate object exists.
 	if !xl.isObject(bucket, object) {
-		return ObjectNotFound{bucket, object}
+		return ObjectInfo{}, ObjectNotFound{bucket, object}
 	} // else proceed to delete the object.
 
 	// Delete the object on all disks.
-	err = xl.deleteObject(bucket, object)
-	if err != nil {
-		return toObjectErr(err, bucket, object)
+	if err = xl.deleteObject(bucket, object); err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
 	}
 
 	// Success.
-	return nil
+	return ObjectInfo{}, nil
 }
@@ -20,6 +20,8 @@ import (
 	"bytes"
 	"crypto/md5"
 	"encoding/hex"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -114,3 +116,43 @@ func TestRepeatPutObjectPart(t *testing.T) {
 	}
 
 }
+
+// Tests that copies are committed only to disks holding the source.
+func TestCopyObjectDegradedSource(t *testing.T) {
+	objLayer, disks, err := getXLObjectLayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(disks)
+	xl := objLayer.(xlObjects)
+
+	if err = objLayer.MakeBucket("bucket1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = objLayer.PutObject("bucket1", "obj1", 5, bytes.NewReader([]byte("hello")), nil); err != nil {
+		t.Fatal(err)
+	}
+	// Simulate a disk missing the source.
+	if err = os.RemoveAll(filepath.Join(disks[0], "bucket1", "obj1")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = objLayer.CopyObject("bucket1", "obj1", "bucket1", "obj2", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = readXLMeta(xl.storageDisks[0], "bucket1", "obj2"); err == nil {
+		t.Fatal("Expected no `xl.json` of the copy on the disk without the source")
+	}
+	for index, disk := range xl.storageDisks[1:] {
+		xlMeta, err := readXLMeta(disk, "bucket1", "obj2")
+		if err != nil {
+			t.Fatalf("Disk %d: %s", index+1, err)
+		}
+		if !xlMeta.Erasure.IsValid() || len(xlMeta.Erasure.Checksum) != 1 {
+			t.Fatalf("Disk %d: Expected erasure info of the copy, got %+v", index+1, xlMeta.Erasure)
+		}
+	}
+	if healed, err := xl.healObject("bucket1", "obj2"); err != nil || healed != 1 {
+		t.Fatalf("Expected copy to be healed on 1 disk, got %d, %v", healed, err)
+	}
+}
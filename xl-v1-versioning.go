@@ -0,0 +1,257 @@
+/*
+ * Minio Cloud Storage, (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"path"
+	"sync"
+)
+
+// Returns if the prefix has saved object versions.
+func (xl xlObjects) isVersionedObject(bucket, prefix string) bool {
+	for _, disk := range xl.getLoadBalancedQuorumDisks() {
+		if disk == nil {
+			continue
+		}
+		_, err := disk.StatFile(bucket, pathJoin(prefix, versionsJSONFile))
+		if err == nil {
+			return true
+		}
+		// For any reason disk was deleted or goes offline, continue
+		if err == errDiskNotFound || err == errFaultyDisk {
+			continue
+		}
+		return false
+	}
+	return false
+}
+
+// hasObjectVersions - returns true if any object versions are saved
+// for the bucket.
+func (xl xlObjects) hasObjectVersions(bucket string) bool {
+	for _, disk := range xl.getLoadBalancedQuorumDisks() {
+		if disk == nil {
+			continue
+		}
+		if hasObjectVersions(bucket, disk) {
+			return true
+		}
+	}
+	return false
+}
+
+// getArchivedObjectInfo - constructs ObjectInfo of an archived
+// object version.
+func (xl xlObjects) getArchivedObjectInfo(bucket, object, versionID string) (ObjectInfo, error) {
+	objInfo, err := xl.getObjectInfo(minioMetaBucket, objectVersionPath(bucket, object, versionID))
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	objInfo.Bucket = bucket
+	objInfo.Name = object
+	return objInfo, nil
+}
+
+// readVersions - reads `versions.json` of an object.
+func (xl xlObjects) readVersions(bucket, object string) (versions versionsV1, err error) {
+	for _, disk := range xl.getLoadBalancedQuorumDisks() {
+		if disk == nil {
+			continue
+		}
+		versions, err = readVersionsJSON(bucket, object, disk)
+		if err == errDiskNotFound || err == errFaultyDisk {
+			continue
+		}
+		break
+	}
+	if err == errFileNotFound {
+		// Set versions format to `xl`.
+		return newVersionsV1("xl"), nil
+	}
+	return versions, err
+}
+
+// writeVersions - writes `versions.json` of an object on all disks,
+// removes it if there are no versions left.
+func (xl xlObjects) writeVersions(bucket, object string, versions versionsV1) error {
+	versionsPath := path.Join(versionsMetaPrefix, bucket, object, versionsJSONFile)
+	tmpVersionsPath := path.Join(tmpMetaPrefix, getUUID())
+	versionsBytes, err := json.Marshal(versions)
+	if err != nil {
+		return err
+	}
+
+	var errs = make([]error, len(xl.storageDisks))
+	var wg = &sync.WaitGroup{}
+
+	// Update `versions.json` for all the disks.
+	for index, disk := range xl.storageDisks {
+		if disk == nil {
+			errs[index] = errDiskNotFound
+			continue
+		}
+		wg.Add(1)
+		// Update `versions.json` in routine.
+		go func(index int, disk StorageAPI) {
+			defer wg.Done()
+			if len(versions.Versions) == 0 {
+				if wErr := disk.DeleteFile(minioMetaBucket, versionsPath); wErr != nil && wErr != errFileNotFound {
+					errs[index] = wErr
+				}
+				return
+			}
+			if wErr := disk.AppendFile(minioMetaBucket, tmpVersionsPath, versionsBytes); wErr != nil {
+				errs[index] = wErr
+				return
+			}
+			if wErr := disk.RenameFile(minioMetaBucket, tmpVersionsPath, minioMetaBucket, versionsPath); wErr != nil {
+				disk.DeleteFile(minioMetaBucket, tmpVersionsPath)
+				errs[index] = wErr
+				return
+			}
+		}(index, disk)
+	}
+
+	// Wait for all the routines to finish updating `versions.json`
+	wg.Wait()
+
+	// Count all the errors and validate if we have write quorum.
+	if !isQuorum(errs, xl.writeQuorum) {
+		return errXLWriteQuorum
+	}
+	return nil
+}
+
+// archiveObject - moves the object to '.minio/versions/bucket/object/versionID'.
+func (xl xlObjects) archiveObject(bucket, object, versionID string) error {
+	if err := xl.renameObject(bucket, object, minioMetaBucket, objectVersionPath(bucket, object, versionID)); err != nil {
+		return err
+	}
+	// Cleanup the empty directories left behind.
+	for _, disk := range xl.storageDisks {
+		if disk == nil {
+			continue
+		}
+		deleteEmptyDir(disk, bucket, path.Dir(object))
+	}
+	return nil
+}
+
+// restoreObject - moves an archived object version back as the
+// current version of the object.
+func (xl xlObjects) restoreObject(bucket, object, versionID string) error {
+	return xl.renameObject(minioMetaBucket, objectVersionPath(bucket, object, versionID), bucket, object)
+}
+
+// deleteObjectVersion - deletes an archived object version.
+func (xl xlObjects) deleteObjectVersion(bucket, object, versionID string) error {
+	return xl.deleteObject(minioMetaBucket, objectVersionPath(bucket, object, versionID))
+}
+
+// GetObjectVersion - reads a version of an object erasure coded
+// across multiple disks, empty versionID reads the latest version.
+func (xl xlObjects) GetObjectVersion(bucket, object, versionID string, startOffset int64, length int64, writer io.Writer) error {
+	// Verify if bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return BucketNameInvalid{Bucket: bucket}
+	}
+	// Verify if object is valid.
+	if !IsValidObjectName(object) {
+		return ObjectNameInvalid{Bucket: bucket, Object: object}
+	}
+
+	// Lock the object before reading.
+	nsMutex.RLock(bucket, object)
+	defer nsMutex.RUnlock(bucket, object)
+
+	objInfo, archived, err := resolveObjectVersion(xl, bucket, object, versionID)
+	if err != nil {
+		return toVersionErr(err, bucket, object, versionID)
+	}
+	// Delete markers have no data.
+	if objInfo.DeleteMarker {
+		return toObjectErr(errFileNotFound, bucket, object)
+	}
+	if !archived {
+		return toObjectErr(xl.getObject(bucket, object, startOffset, length, writer), bucket, object)
+	}
+	err = xl.getObject(minioMetaBucket, objectVersionPath(bucket, object, objInfo.VersionID), startOffset, length, writer)
+	return toVersionErr(err, bucket, object, versionID)
+}
+
+// GetObjectVersionInfo - reads object metadata of a version of an
+// object, empty versionID reads the latest version.
+func (xl xlObjects) GetObjectVersionInfo(bucket, object, versionID string) (ObjectInfo, error) {
+	// Verify if bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return ObjectInfo{}, BucketNameInvalid{Bucket: bucket}
+	}
+	// Verify if object is valid.
+	if !IsValidObjectName(object) {
+		return ObjectInfo{}, ObjectNameInvalid{Bucket: bucket, Object: object}
+	}
+	nsMutex.RLock(bucket, object)
+	defer nsMutex.RUnlock(bucket, object)
+
+	objInfo, _, err := resolveObjectVersion(xl, bucket, object, versionID)
+	if err != nil {
+		return ObjectInfo{}, toVersionErr(err, bucket, object, versionID)
+	}
+	return objInfo, nil
+}
+
+// DeleteObjectVersion - permanently deletes a version of an object,
+// returns the object info of the deleted version.
+func (xl xlObjects) DeleteObjectVersion(bucket, object, versionID string) (ObjectInfo, error) {
+	// Verify if bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return ObjectInfo{}, BucketNameInvalid{Bucket: bucket}
+	}
+	if !IsValidObjectName(object) {
+		return ObjectInfo{}, ObjectNameInvalid{Bucket: bucket, Object: object}
+	}
+	nsMutex.Lock(bucket, object)
+	defer nsMutex.Unlock(bucket, object)
+
+	objInfo, _, err := resolveObjectVersion(xl, bucket, object, versionID)
+	if err != nil {
+		return ObjectInfo{}, toVersionErr(err, bucket, object, versionID)
+	}
+	if err = removeObjectVersion(xl, bucket, object, versionID); err != nil {
+		return ObjectInfo{}, toVersionErr(err, bucket, object, versionID)
+	}
+	return objInfo, nil
+}
+
+// ListObjectVersions - list all versions of all objects.
+func (xl xlObjects) ListObjectVersions(bucket, prefix, keyMarker, versionIDMarker, delimiter string, maxKeys int) (ListObjectVersionsInfo, error) {
+	// Verify if bucket is valid.
+	if !IsValidBucketName(bucket) {
+		return ListObjectVersionsInfo{}, BucketNameInvalid{Bucket: bucket}
+	}
+	// Verify if bucket exists.
+	if !xl.isBucketExist(bucket) {
+		return ListObjectVersionsInfo{}, BucketNotFound{Bucket: bucket}
+	}
+	result, err := listBucketVersions(xl, bucket, prefix, keyMarker, versionIDMarker, delimiter, maxKeys)
+	if err != nil {
+		return ListObjectVersionsInfo{}, toObjectErr(err, bucket, prefix)
+	}
+	return result, nil
+}
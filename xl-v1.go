@@ -48,8 +48,15 @@ type xlObjects struct {
 	readQuorum    int          // readQuorum minimum required disks to read data.
 	writeQuorum   int          // writeQuorum minimum required disks to write data.
 
+	// parityBlocks count of REDUCED_REDUNDANCY storage class, above
+	// counts are of STANDARD.
+	rrsParityBlocks int
+
 	// List pool management.
 	listPool *treeWalkPool
+
+	// Objects queued for healing.
+	healQueue *healQueue
 }
 
 // errXLMaxDisks - returned for reached maximum of disks.
@@ -70,6 +77,9 @@ var errXLWriteQuorum = errors.New("I/O error.  did not meet write quorum.")
 // errXLDataCorrupt - err data corrupt.
 var errXLDataCorrupt = errors.New("data likely corrupted, all blocks are zero in length")
 
+// errBitRot - block on a disk failed its hash.
+var errBitRot = errors.New("bit-rot detected, block failed its checksum")
+
 const (
 	// Maximum erasure blocks.
 	maxErasureBlocks = 16
@@ -103,8 +113,9 @@ func checkSufficientDisks(disks []string) error {
 	return nil
 }
 
-// newXLObjects - initialize new xl object layer.
-func newXLObjects(disks []string) (ObjectLayer, error) {
+// newXLObjects - initialize new xl object layer, objects are erasure
+// coded with the parity configured for their storage class.
+func newXLObjects(disks []string, storageClass storageClassConfig) (ObjectLayer, error) {
 	// Validate if input disks are sufficient.
 	if err := checkSufficientDisks(disks); err != nil {
 		return nil, err
@@ -161,15 +172,21 @@ func newXLObjects(disks []string) (ObjectLayer, error) {
 	}
 
 	// Calculate data and parity blocks.
-	dataBlocks, parityBlocks := len(newPosixDisks)/2, len(newPosixDisks)/2
+	parityBlocks, rrsParityBlocks, err := storageClass.getParity(len(newPosixDisks))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid storage class parity for %d disks, %s", len(newPosixDisks), err)
+	}
+	dataBlocks := len(newPosixDisks) - parityBlocks
 
 	// Initialize xl objects.
 	xl := xlObjects{
-		physicalDisks: disks,
-		storageDisks:  newPosixDisks,
-		dataBlocks:    dataBlocks,
-		parityBlocks:  parityBlocks,
-		listPool:      newTreeWalkPool(globalLookupTimeout),
+		physicalDisks:   disks,
+		storageDisks:    newPosixDisks,
+		dataBlocks:      dataBlocks,
+		parityBlocks:    parityBlocks,
+		rrsParityBlocks: rrsParityBlocks,
+		listPool:        newTreeWalkPool(globalLookupTimeout),
+		healQueue:       newHealQueue(),
 	}
 
 	// Figure out read and write quorum based on number of storage disks.
@@ -181,6 +198,25 @@ func newXLObjects(disks []string) (ObjectLayer, error) {
 	return xl, nil
 }
 
+// getErasureBlocks - returns data and parity blocks of the storage
+// class.
+func (xl xlObjects) getErasureBlocks(storageClass string) (dataBlocks, parityBlocks int) {
+	if storageClass == reducedRedundancyStorageClass {
+		return len(xl.storageDisks) - xl.rrsParityBlocks, xl.rrsParityBlocks
+	}
+	return xl.dataBlocks, xl.parityBlocks
+}
+
+// objectQuorum - returns the disks an object erasure coded with the
+// data blocks needs to be written to and read from, never lesser
+// than the quorum of `xl.json`.
+func (xl xlObjects) objectQuorum(dataBlocks int) int {
+	if dataBlocks > xl.readQuorum {
+		return dataBlocks
+	}
+	return xl.readQuorum
+}
+
 // byDiskTotal is a collection satisfying sort.Interface.
 type byDiskTotal []disk.Info
 